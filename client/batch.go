@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// CmdSendMany sends ETH or an ERC-20 token to multiple recipients in one batch
+// transaction, with arguments [<token>] <csv-file>. Each line of the CSV file
+// contains a payment as <receiver>,<amount>. Token amounts are given in the
+// token's smallest unit.
+func (c *Client) CmdSendMany(status chan *CmdStatus, args ...string) {
+	defer close(status)
+	if len(args) != 1 && len(args) != 2 {
+		status <- &CmdStatus{Err: errors.New("Command 'sendmany' needs arguments: [<token>] <csv-file>")}
+		return
+	}
+	token := tee.ETHToken
+	if len(args) == 2 {
+		var err error
+		if token, err = strToCommonAddress(args[0]); err != nil {
+			status <- &CmdStatus{Err: fmt.Errorf("Invalid <token>: %v", err)}
+			return
+		}
+		args = args[1:]
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Opening <csv-file>: %v", err)}
+		return
+	}
+	defer f.Close()
+	payments, err := ParsePayments(token, f)
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <csv-file>: %v", err)}
+		return
+	}
+
+	status <- &CmdStatus{Msg: fmt.Sprintf("Sending batch of %d payments to Operator", len(payments))}
+	if err := c.sendBatch(shortCtx(), token, payments); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
+}
+
+// sendBatch creates a batch transaction with the next nonce and sends it to
+// the operator. The nonce is only used up once the operator accepted it.
+func (c *Client) sendBatch(ctx context.Context, token common.Address, payments []tee.Payment) error {
+	c.nonceMtx.Lock()
+	defer c.nonceMtx.Unlock()
+	tx, err := c.createBatch(token, payments)
+	if err != nil {
+		return err
+	}
+	if err := c.conn.SendBatchTx(ctx, tx); err != nil {
+		return err
+	}
+	c.useNonce()
+	return nil
+}
+
+// createBatch creates a signed batch transaction with the next nonce.
+// c.nonceMtx must be held.
+func (c *Client) createBatch(token common.Address, payments []tee.Payment) (tee.BatchTransaction, error) {
+	tx := tee.BatchTransaction{
+		Nonce:    c.txNonce,
+		Epoch:    c.params.TxEpoch(c.ActiveBlock()),
+		Sender:   c.Address(),
+		Token:    token,
+		Payments: payments,
+	}
+	if fee := c.params.BatchFee(token, tx.Total()); fee.Sign() > 0 {
+		tx.Fee = (*tee.Amount)(fee)
+	}
+	if err := tx.Sign(c.params.Contract, c.ethClient.Account(), c.signer); err != nil {
+		return tx, fmt.Errorf("signing batch: %w", err)
+	}
+	return tx, nil
+}
+
+// ParsePayments parses the payments of a batch transaction from CSV records of
+// the form <receiver>,<amount>. ETH amounts are given in ETH and token amounts
+// in the token's smallest unit. Empty lines are skipped.
+func ParsePayments(token common.Address, r io.Reader) ([]tee.Payment, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	var payments []tee.Payment
+	for i := 1; ; i++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		recipient, err := strToCommonAddress(strings.TrimSpace(rec[0]))
+		if err != nil {
+			return nil, fmt.Errorf("payment %d: invalid receiver: %w", i, err)
+		}
+		amount, err := parseAmount(token, strings.TrimSpace(rec[1]))
+		if err != nil {
+			return nil, fmt.Errorf("payment %d: invalid amount: %w", i, err)
+		}
+		payments = append(payments, tee.Payment{Recipient: recipient, Amount: (*tee.Amount)(amount)})
+	}
+	if len(payments) == 0 {
+		return nil, errors.New("no payments")
+	} else if len(payments) > tee.MaxBatchSize {
+		return nil, fmt.Errorf("too many payments: %d > %d", len(payments), tee.MaxBatchSize)
+	}
+	return payments, nil
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/erdstall/client"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestParsePayments(t *testing.T) {
+	require := require.New(t)
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	csv := alice.Hex() + ",1.5\n\n" + bob.Hex() + ", 2\n"
+
+	payments, err := client.ParsePayments(tee.ETHToken, strings.NewReader(csv))
+	require.NoError(err)
+	require.Equal([]tee.Payment{
+		{Recipient: alice, Amount: (*tee.Amount)(eth.EthToWeiFloat(1.5))},
+		{Recipient: bob, Amount: (*tee.Amount)(eth.EthToWeiFloat(2))},
+	}, payments)
+
+	payments, err = client.ParsePayments(token, strings.NewReader(bob.Hex()+",100"))
+	require.NoError(err)
+	require.Zero((*big.Int)(payments[0].Amount).Cmp(big.NewInt(100)))
+
+	for _, invalid := range []string{
+		"",
+		alice.Hex() + ",1,2",
+		"nobody,1",
+		alice.Hex() + ",lots",
+	} {
+		_, err := client.ParsePayments(tee.ETHToken, strings.NewReader(invalid))
+		require.Error(err, invalid)
+	}
+	_, err = client.ParsePayments(token, strings.NewReader(alice.Hex()+",1.5"))
+	require.Error(err)
+}
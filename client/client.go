@@ -20,12 +20,14 @@ import (
 
 	pethwallet "perun.network/go-perun/backend/ethereum/wallet"
 	psync "perun.network/go-perun/pkg/sync"
+	patomic "perun.network/go-perun/pkg/sync/atomic"
 	pwallet "perun.network/go-perun/wallet"
 
 	"github.com/perun-network/erdstall/config"
 	"github.com/perun-network/erdstall/contracts/bindings"
 	"github.com/perun-network/erdstall/eth"
 	"github.com/perun-network/erdstall/tee"
+	"github.com/perun-network/erdstall/tee/attestation"
 )
 
 type Client struct {
@@ -38,23 +40,34 @@ type Client struct {
 	ethClient    *eth.Client
 	contractAddr common.Address
 	signer       tee.TextSigner
+	verifier     attestation.Verifier // nil: attestation is skipped.
 	txNonce      uint64
-	balances     map[uint64]EpochBalance // epoch => balance
+	balances     map[uint64]EpochBalance                    // epoch => balance
+	tokenBals    map[uint64]map[common.Address]EpochBalance // epoch => token => balance
+	deposits     map[depositKey]*depositState               // epoch, token => deposits
+	ready        chan struct{}                              // Closed on the first block.
+	db           *walletDB                                  // nil: in-memory only.
+	dbErr        error                                      // Error loading db, returned by Run.
 	// Initialized in Run()
-	lastBlock uint64 // Atomic
+	lastBlock uint64       // Atomic
+	readyOnce sync.Once    // Closes ready.
+	attested  patomic.Bool // Enclave attestation verified.
 	contract  *bindings.Erdstall
 	params    *tee.Parameters
 	events    chan *Event
-	// balMtx protects balances.
+	// balMtx protects balances, tokenBals and deposits.
 	balMtx            sync.RWMutex
+	nonceMtx          sync.Mutex // Protects txNonce.
+	observers         []Observer
+	obsMtx            sync.RWMutex
+	history           []HistoryEntry
+	histMtx           sync.Mutex
 	stopFrozenWatcher context.CancelFunc
 }
 
 // EpochBalance describes the balance that a specific user has/has in a epoch.
-// Currently, onls only one deposit per Epoch is allowed otherwise it would need
-// a slice of `*DepositProof`s.
 // The Proofs are initialized to nil, the DepositProof should be set after the
-// Deposit succeeded.
+// Deposits succeeded. It proves the sum of all deposits of the epoch.
 // The BalanceProof should be set at the end of a Transaction phase.
 type EpochBalance struct {
 	tee.Balance
@@ -105,6 +118,10 @@ const (
 	CHAIN_MSG // Chain related message.
 
 	BENCH
+
+	STATUS     // Progress of the typed API, see Observer.
+	HISTORY    // New history entry.
+	CONN_STATE // Operator connection lost or restored.
 )
 
 // Trust describes how we perceive the operator.
@@ -126,6 +143,9 @@ type Event struct {
 	BlockNum      uint64        // NEW_BLOCK
 	EpochNum      uint64        // NEW_EPOCH
 	ExitAvailable *EpochBalance // SET_EXIT_AVAIL
+	Status        *CmdStatus    // STATUS
+	History       *HistoryEntry // HISTORY
+	ConnState     ConnState     // CONN_STATE
 }
 
 type CmdStatus struct {
@@ -135,17 +155,35 @@ type CmdStatus struct {
 }
 
 func NewClient(cfg config.ClientConfig, ccfg config.OpClientConfig, conn *RPC, events chan *Event, ethClient *eth.Client, signer tee.TextSigner) *Client {
-	return &Client{
+	var verifier attestation.Verifier
+	if !cfg.SkipAttestation {
+		verifier = attestation.NewMockVerifier(cfg.AttestationSigner, cfg.TrustedMeasurements...)
+	}
+	txNonce := cfg.TxNonce
+	if txNonce == 0 {
+		txNonce = 1
+	}
+	c := &Client{
 		Config:       cfg,
 		OpConfig:     ccfg,
 		conn:         conn,
 		ethClient:    ethClient,
 		contractAddr: ccfg.Contract,
 		signer:       signer,
-		txNonce:      1,
+		verifier:     verifier,
+		txNonce:      txNonce,
 		balances:     make(map[uint64]EpochBalance),
+		tokenBals:    make(map[uint64]map[common.Address]EpochBalance),
+		deposits:     make(map[depositKey]*depositState),
 		events:       events,
+		ready:        make(chan struct{}),
+	}
+	if cfg.DBPath != "" {
+		if err := c.loadDB(cfg.DBPath); err != nil {
+			c.dbErr = fmt.Errorf("loading wallet database: %w", err)
+		}
 	}
+	return c
 }
 
 // ChainURL returns the URL of the blockchain the client is connected to.
@@ -154,30 +192,67 @@ func (c *Client) ChainURL() string {
 }
 
 func (c *Client) Run() error {
+	if c.dbErr != nil {
+		return c.dbErr
+	}
 	c.logOnChain("Connecting to contract...")
 	params, contract, err := c.ethClient.BindContract(shortCtx(), c.contractAddr)
 	if err != nil {
 		return err
 	}
 	c.logOnChain("Connected to contract")
-	c.events <- &Event{Type: SET_PARAMS, Params: *params}
+	if err := c.verifyAttestation(params.TEE); err != nil {
+		c.setOpTrust(UNTRUSTED)
+		return fmt.Errorf("verifying enclave attestation: %w", err)
+	}
+	// The fees are not stored in the contract but published by the operator.
+	params.FeeFlat, params.FeeRate = c.OpConfig.Fees.Flat, c.OpConfig.Fees.Rate
+	params.FeeCollector = c.OpConfig.Fees.Collector
+	c.emit(&Event{Type: SET_PARAMS, Params: *params})
 	c.setOpTrust(TRUSTED)
 
 	c.params = params
 	c.contract = contract
-	c.proofSub, err = c.conn.Subscribe(newCtx(5*time.Second), c.Address())
+	c.conn.OnConnState(c.handleConnState)
+	c.proofSub, err = c.conn.Subscribe(newCtx(5*time.Second), c.ethClient.Account(), c.signer)
 	if err != nil {
 		return fmt.Errorf("subscribing to proofs: %w", err)
 	}
 	return c.listenOnChain()
 }
 
+// verifyAttestation verifies that the operator's attestation binds the
+// contract's TEE address to a trusted measurement.
+func (c *Client) verifyAttestation(teeAddr common.Address) error {
+	if c.verifier == nil {
+		c.logOnChain("⚠ Skipping enclave attestation")
+	} else if err := attestation.Verify(c.verifier, teeAddr, c.OpConfig.Attestation); err != nil {
+		return err
+	} else {
+		c.logOnChain("Enclave attestation verified")
+	}
+	c.attested.Set()
+	return nil
+}
+
+// CmdSend sends ETH with arguments <receiver> <amount> or an ERC-20 token with
+// arguments <token> <receiver> <amount>. Token amounts are given in the
+// token's smallest unit.
 func (c *Client) CmdSend(status chan *CmdStatus, args ...string) {
 	defer close(status)
-	if len(args) != 2 {
-		status <- &CmdStatus{Err: errors.New("Command 'send' needs arguments: <receiver> <amount>")}
+	if len(args) != 2 && len(args) != 3 {
+		status <- &CmdStatus{Err: errors.New("Command 'send' needs arguments: [<token>] <receiver> <amount>")}
 		return
 	}
+	token := tee.ETHToken
+	if len(args) == 3 {
+		var err error
+		if token, err = strToCommonAddress(args[0]); err != nil {
+			status <- &CmdStatus{Err: fmt.Errorf("Invalid <token>: %v", err)}
+			return
+		}
+		args = args[1:]
+	}
 	receiver, err := strToCommonAddress(args[0])
 	if args[0] == "me" {
 		receiver = c.Address()
@@ -186,42 +261,75 @@ func (c *Client) CmdSend(status chan *CmdStatus, args ...string) {
 		status <- &CmdStatus{Err: fmt.Errorf("Invalid <receiver>: %v", err)}
 		return
 	}
-	_amount, err := strconv.ParseFloat(args[1], 64)
+	amount, err := parseAmount(token, args[1])
 	if err != nil {
 		status <- &CmdStatus{Err: fmt.Errorf("Invalid <amount>: %v", err)}
 		return
 	}
-	amount := eth.EthToWeiFloat(_amount)
-	status <- &CmdStatus{Msg: "Creating Message"}
-	tx, err := c.createTransfer(receiver, amount)
-	if err != nil {
-		status <- &CmdStatus{Err: err}
-		return
-	}
 	status <- &CmdStatus{Msg: "Forwarding to Operator"}
-	if err := c.conn.SendTx(shortCtx(), tx); err != nil {
+	if _, err := c.transfer(shortCtx(), token, receiver, amount); err != nil {
 		status <- &CmdStatus{Err: err}
 	}
 }
 
-func (c *Client) createTransfer(receiver common.Address, amount *big.Int) (tee.Transaction, error) {
+// transfer creates a transfer and sends it to the operator.
+func (c *Client) transfer(ctx context.Context, token, receiver common.Address, amount *big.Int) (*tee.Transaction, error) {
+	tx, err := c.sendTransfer(ctx, receiver, token, amount)
+	if err != nil {
+		return nil, err
+	}
+	c.persist(func(d *walletData) { d.Sent = append(d.Sent, tx) })
+	c.record(HistoryEntry{Type: HistoryTransfer, Epoch: tx.Epoch, Token: token, Amount: amount, Peer: receiver})
+	return &tx, nil
+}
+
+// sendTransfer creates a transfer with the next nonce and sends it to the
+// operator. The nonce is only used up once the operator accepted the transfer,
+// so that a rejected transfer does not leave a gap in the nonces.
+func (c *Client) sendTransfer(ctx context.Context, receiver, token common.Address, amount *big.Int) (tee.Transaction, error) {
+	c.nonceMtx.Lock()
+	defer c.nonceMtx.Unlock()
+	tx, err := c.createTransfer(receiver, token, amount)
+	if err != nil {
+		return tx, err
+	}
+	if err := c.conn.SendTx(ctx, tx); err != nil {
+		return tx, err
+	}
+	c.useNonce()
+	return tx, nil
+}
+
+// createTransfer creates a signed transfer with the next nonce. c.nonceMtx
+// must be held.
+func (c *Client) createTransfer(receiver, token common.Address, amount *big.Int) (tee.Transaction, error) {
 	tx := tee.Transaction{
 		Nonce:     c.txNonce,
 		Epoch:     c.params.TxEpoch(c.ActiveBlock()),
 		Sender:    c.Address(),
 		Recipient: receiver,
+		Token:     token,
 		Amount:    (*tee.Amount)(amount),
 	}
-	c.txNonce++
-	err := tx.Sign(c.params.Contract, c.ethClient.Account(), c.signer)
-	if err != nil {
-		fmt.Printf("Could not sign: %v", err)
+	if fee := c.params.TxFee(token, amount); fee.Sign() > 0 {
+		tx.Fee = (*tee.Amount)(fee)
 	}
-
-	if ok, err := tee.VerifyTransaction(c.params.Contract, tx); !ok || err != nil {
-		fmt.Printf("Could not sign: %v", err)
+	if err := tx.Sign(c.params.Contract, c.ethClient.Account(), c.signer); err != nil {
+		return tx, fmt.Errorf("signing transfer: %w", err)
+	}
+	if ok, err := tee.VerifyTransaction(c.params.Contract, tx); err != nil {
+		return tx, fmt.Errorf("verifying transfer signature: %w", err)
+	} else if !ok {
+		return tx, errors.New("invalid transfer signature")
 	}
-	return tx, err
+	return tx, nil
+}
+
+// useNonce advances the nonce after the operator accepted a transaction with
+// it and persists it. c.nonceMtx must be held.
+func (c *Client) useNonce() {
+	c.txNonce++
+	c.persistNonce()
 }
 
 func (c *Client) CmdBench(status chan *CmdStatus, args ...string) {
@@ -254,62 +362,80 @@ func (c *Client) CmdBench(status chan *CmdStatus, args ...string) {
 	}
 	status <- &CmdStatus{Msg: fmt.Sprintf("Sending %d payments", n)}
 	result, err := Benchmark(n, func() error {
-		tx, err := c.createTransfer(a, amount)
-		if err != nil {
-			return err
-		}
-		return c.conn.SendTx(shortCtx(), tx)
+		_, err := c.sendTransfer(shortCtx(), a, tee.ETHToken, amount)
+		return err
 	})
-	c.events <- &Event{Type: BENCH, Result: result}
+	c.emit(&Event{Type: BENCH, Result: result})
 	if err != nil {
 		status <- &CmdStatus{Err: err}
 	}
 }
 
+// CmdDeposit deposits ETH with argument <amount> or an ERC-20 token with
+// arguments <token> <amount>. Token amounts are given in the token's smallest
+// unit. The contract is approved to transfer the token amount before the
+// deposit.
 func (c *Client) CmdDeposit(status chan *CmdStatus, args ...string) {
 	defer close(status)
-	if len(args) != 1 {
-		status <- &CmdStatus{Err: errors.New("Command 'deposit' needs argument: <amount>")}
+	if len(args) != 1 && len(args) != 2 {
+		status <- &CmdStatus{Err: errors.New("Command 'deposit' needs arguments: [<token>] <amount>")}
 		return
 	}
-	_amount, err := strconv.ParseFloat(args[0], 64)
+	token := tee.ETHToken
+	if len(args) == 2 {
+		var err error
+		if token, err = strToCommonAddress(args[0]); err != nil {
+			status <- &CmdStatus{Err: fmt.Errorf("Invalid <token>: %v", err)}
+			return
+		}
+		args = args[1:]
+	}
+	amount, err := parseAmount(token, args[0])
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Could not parse <amount> as float: %s", args[0])}
+		status <- &CmdStatus{Err: fmt.Errorf("Could not parse <amount>: %s", args[0])}
 		return
 	}
-	amount := eth.EthToWeiFloat(_amount)
-	rec, err := c.sendTx("Deposit", func(opts *bind.TransactOpts) (*types.Transaction, error) {
-		opts.Value = amount
-		return c.contract.Deposit(opts)
-	}, status)
+	if _, err := c.deposit(c.Ctx(), token, amount, status); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
+}
+
+// deposit deposits the amount of ETH or a token and waits for the deposit
+// proof. Any number of deposits can be made per epoch, the operator proves
+// their sum. If the operator sends no valid proof, ETH deposits are
+// challenged.
+func (c *Client) deposit(ctx context.Context, token common.Address, amount *big.Int, status chan *CmdStatus) (*DepositResult, error) {
+	if !c.attested.IsSet() {
+		return nil, errors.New("Enclave not attested, refusing to deposit")
+	}
+
+	var rec *types.Receipt
+	var err error
+	if token == tee.ETHToken {
+		rec, err = c.sendTx("Deposit", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = amount
+			return c.contract.Deposit(opts)
+		}, status)
+	} else {
+		rec, err = c.depositToken(token, amount, status)
+	}
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Deposit TX: %w", err)}
-		return
+		return nil, fmt.Errorf("Deposit TX: %w", err)
 	}
 
 	c.logOnChain("Deposit TX mined in Block #%d", rec.BlockNumber.Uint64())
-	status <- &CmdStatus{Msg: "Deposit proof: Waiting for Operator"}
 	blockNum := rec.BlockNumber.Uint64()
 	// The epoch that we want to do the deposit in.
 	epoch := c.params.DepositEpoch(blockNum)
+	res := &DepositResult{TxHash: rec.TxHash, Block: blockNum, Epoch: epoch}
+	dep := c.addDeposit(Deposit{TxHash: rec.TxHash, Block: blockNum, Epoch: epoch, Token: token, Amount: amount})
+	c.record(HistoryEntry{Type: HistoryDeposit, Epoch: epoch, Token: token, Amount: amount})
+	status <- &CmdStatus{Msg: "Deposit proof: Waiting for Operator"}
 
-	proof := make(chan tee.DepositProof)
-	proofErr := make(chan error)
-	waitErr := make(chan error)
+	waitErr := make(chan error, 1)
 	// no specific wait ctx, since we do not know what the block time is.
-	ctx, cancel := context.WithCancel(c.Ctx())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	go func() {
-		if p, err := c.proofSub.DepositProof(ctx); err != nil {
-			if p.Balance.Epoch != epoch {
-				proofErr <- fmt.Errorf("Got proof for wrong epoch #%d", p.Balance.Epoch)
-			} else {
-				proofErr <- err
-			}
-		} else {
-			proof <- p
-		}
-	}()
 	go func() {
 		depEndBlock := c.params.TxDoneBlock(epoch)
 		c.logOffChain("Waiting for deposit proof until block #%d", depEndBlock)
@@ -329,40 +455,80 @@ func (c *Client) CmdDeposit(status chan *CmdStatus, args ...string) {
 			status <- &CmdStatus{War: "Deposit proof: Operator timed out - resuming protocol"}
 			c.setOpTrust(UNTRUSTED)
 		}
-	case p := <-proof:
+	case <-dep.received:
 		status <- &CmdStatus{Msg: "Deposit proof: Verifying"}
-		ok, err := tee.VerifyDepositProof(*c.params, p)
-		if !ok || err != nil {
-			status <- &CmdStatus{War: "Deposit proof: Invalid Signature - resuming protocol"}
-			c.setOpTrust(UNTRUSTED)
-		} else if (*big.Int)(p.Balance.Value).Cmp(amount) != 0 || p.Balance.Epoch != epoch || p.Balance.Account != c.Address() {
-			status <- &CmdStatus{War: "Deposit proof: Wrong Proof - resuming protocol"}
+		c.balMtx.Lock()
+		err := c.acceptDepositProof(dep)
+		proof := dep.proof
+		c.balMtx.Unlock()
+		if err != nil {
+			status <- &CmdStatus{War: fmt.Sprintf("Deposit proof: %v - resuming protocol", err)}
 			c.setOpTrust(UNTRUSTED)
 		} else {
-			c.balMtx.Lock()
-			defer c.balMtx.Unlock()
-			if _, ok := c.balances[epoch]; ok {
-				panic("Deposit can not be called twice in one epoch")
-			}
-			// TODO add instead of replace
-			c.balances[epoch] = EpochBalance{Balance: p.Balance, Dep: &p}
 			status <- &CmdStatus{Msg: "Deposit proof: Valid"}
-			return
+			res.Proof = proof
+			return res, nil
 		}
-	case e := <-proofErr:
-		status <- &CmdStatus{War: fmt.Sprintf("Deposit proof: '%s' - resuming protocol", e.Error())}
-		c.setOpTrust(UNKNOWN)
 	case <-ctx.Done():
 		status <- &CmdStatus{War: "Deposit proof: Operator timed out - resuming protocol"}
 		c.setOpTrust(UNKNOWN)
 	}
 
-	c.challengeDeposit(status)
+	// The contract sums all deposits of an epoch per token, so each token is
+	// challenged once.
+	c.balMtx.Lock()
+	challenged := dep.challenged
+	dep.challenged = true
+	c.balMtx.Unlock()
+	if challenged {
+		status <- &CmdStatus{War: "Deposit proof: Epoch already challenged"}
+		return res, nil
+	}
+	res.Challenged = true
+	if token != tee.ETHToken {
+		return res, c.challengeTokenDeposit(token, status)
+	}
+	return res, c.challengeDeposit(status)
+}
+
+// depositToken approves the contract to transfer amount of the token and
+// deposits it.
+func (c *Client) depositToken(token common.Address, amount *big.Int, status chan *CmdStatus) (*types.Receipt, error) {
+	erc20, err := bindings.NewIERC20(token, c.ethClient)
+	if err != nil {
+		return nil, fmt.Errorf("binding token: %w", err)
+	}
+	if _, err := c.sendTx("Approve", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return erc20.Approve(opts, c.contractAddr, amount)
+	}, status); err != nil {
+		return nil, fmt.Errorf("approving token: %w", err)
+	}
+	return c.sendTx("DepositToken", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.DepositToken(opts, token, amount)
+	}, status)
+}
+
+// setTokenBal stores a token balance of the balance's epoch.
+func (c *Client) setTokenBal(bal EpochBalance) {
+	c.balMtx.Lock()
+	defer c.balMtx.Unlock()
+	c.storeTokenBal(bal)
+}
+
+// storeTokenBal stores a token balance. c.balMtx must be held.
+func (c *Client) storeTokenBal(bal EpochBalance) {
+	bals, ok := c.tokenBals[bal.Epoch]
+	if !ok {
+		bals = make(map[common.Address]EpochBalance)
+		c.tokenBals[bal.Epoch] = bals
+	}
+	bals[bal.Token] = bal
+	c.persist(func(d *walletData) { d.setTokenBal(bal) })
 }
 
 // BalanceProofWatcher waits for the balance proof of an epoch and disputes
 // if non was received after the TxPhase + balanceProofGrace.
-// Can currently only deal with one deposit per deposit-phase.
+// Token balance proofs are stored for exiting.
 // Should be started in a go-routine.
 func (c *Client) BalanceProofWatcher() {
 	oldEpoch := uint64(0)
@@ -371,6 +537,15 @@ func (c *Client) BalanceProofWatcher() {
 		if err != nil {
 			c.logProof("Balance Proof error: %v", err)
 			c.setOpTrust(UNKNOWN)
+		} else if !proof.Balance.IsETH() {
+			if ok, err := tee.VerifyBalanceProof(*c.params, proof); !ok || err != nil {
+				c.setOpTrust(UNKNOWN)
+				c.logProof("Invalid token balance proof: err=%v ok=%t", err, ok)
+				return
+			}
+			c.logProof("Got Balance Proof for %v of token %s in epoch %d", proof.Balance.Value, proof.Balance.Token.Hex(), proof.Balance.Epoch)
+			c.setTokenBal(EpochBalance{Balance: proof.Balance, Bal: &proof})
+			continue
 		} else if proof.Balance.Epoch > oldEpoch {
 			c.logProof("Got Balance Proof for %v ETH in epoch %d", eth.WeiToEthFloat((*big.Int)(proof.Balance.Value)), proof.Balance.Epoch)
 			oldEpoch = proof.Balance.Epoch
@@ -385,13 +560,30 @@ func (c *Client) BalanceProofWatcher() {
 				return
 			}
 
-			c.events <- &Event{Type: SET_BALANCE, Report: BalanceReport{Balance: new(big.Int).Set((*big.Int)(proof.Balance.Value))}}
+			c.persist(func(d *walletData) {
+				d.Balances[proof.Balance.Epoch] = EpochBalance{Balance: proof.Balance, Bal: &proof}
+			})
+			c.emit(&Event{Type: SET_BALANCE, Report: BalanceReport{Balance: new(big.Int).Set((*big.Int)(proof.Balance.Value))}})
 			c.setOpTrust(TRUSTED)
 		}
 		time.Sleep(time.Second)
 	}
 }
 
+// txReceiptWatcher stores the incoming transactions that the operator
+// forwards.
+func (c *Client) txReceiptWatcher() {
+	for !c.IsClosed() {
+		tx, err := c.proofSub.TxReceipt(c.Ctx())
+		if err != nil {
+			continue // Only fails when closed.
+		}
+		c.logOffChain("Received %v from %s", (*big.Int)(tx.Amount), tx.Sender.Hex())
+		c.persist(func(d *walletData) { d.Received = append(d.Received, tx) })
+		c.record(HistoryEntry{Type: HistoryReceive, Epoch: tx.Epoch, Token: tx.Token, Amount: (*big.Int)(tx.Amount), Peer: tx.Sender})
+	}
+}
+
 // frozenWatcher listens for Frozen events and calls WithdrawFrozen
 // if a balance proof is available.
 func (c *Client) frozenWatcher() {
@@ -425,33 +617,62 @@ func (c *Client) handleFrozen(event *bindings.ErdstallFrozen) {
 	defer close(status)
 	go func() {
 		for msg := range status {
-			if msg.Err != nil {
-				c.logOnChain(msg.Err.Error())
-			} else {
-				c.logOnChain(msg.Msg)
-			}
+			c.logOnChain(msg.Msg)
 		}
 	}()
-	c.withdrawFrozen(status, epoch)
+	if err := c.withdrawFrozen(status, epoch); err != nil {
+		c.logOnChain(err.Error())
+	}
 }
 
-func (c *Client) withdrawFrozen(status chan *CmdStatus, epoch uint64) {
+// withdrawFrozen withdraws the ETH and token balances of the frozen epoch.
+// Balances that were already withdrawn, e.g., by a challenge withdrawal, are
+// skipped.
+func (c *Client) withdrawFrozen(status chan *CmdStatus, epoch uint64) error {
 	c.balMtx.RLock()
 	bal, ok := c.balances[epoch]
+	var tokenBals []EpochBalance
+	for _, tbal := range c.tokenBals[epoch] {
+		if tbal.Bal != nil && tbal.Bal.Sig != nil {
+			tokenBals = append(tokenBals, tbal)
+		}
+	}
 	c.balMtx.RUnlock()
-	if !ok {
+	if !ok && len(tokenBals) == 0 {
 		c.logOffChain("No balance-proof available for freeze")
-		return
+		return nil
 	}
 
-	_, err := c.sendTx("WithdrawFrozen", func(auth *bind.TransactOpts) (*types.Transaction, error) {
-		return c.contract.WithdrawFrozen(auth, bal.ToEthBal(), bal.Bal.Sig)
-	}, status)
-	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("WithdrawFrozen TX: %w", err)}
-		return
+	if ok {
+		if done, err := c.contract.FrozenWithdrawals(&bind.CallOpts{Context: shortCtx()}, c.Address()); err != nil {
+			return fmt.Errorf("reading frozen withdrawals: %w", err)
+		} else if !done {
+			_, err := c.sendTx("WithdrawFrozen", func(auth *bind.TransactOpts) (*types.Transaction, error) {
+				return c.contract.WithdrawFrozen(auth, bal.ToEthBal(), bal.Bal.Sig)
+			}, status)
+			if err != nil {
+				return fmt.Errorf("WithdrawFrozen TX: %w", err)
+			}
+			c.record(HistoryEntry{Type: HistoryWithdraw, Epoch: epoch, Token: tee.ETHToken, Amount: (*big.Int)(bal.Value)})
+		}
+	}
+	for _, tbal := range tokenBals {
+		tbal := tbal
+		if done, err := c.contract.FrozenTokenWithdrawals(&bind.CallOpts{Context: shortCtx()}, c.Address(), tbal.Token); err != nil {
+			return fmt.Errorf("reading frozen token withdrawals: %w", err)
+		} else if done {
+			continue
+		}
+		_, err := c.sendTx("WithdrawFrozenToken", func(auth *bind.TransactOpts) (*types.Transaction, error) {
+			return c.contract.WithdrawFrozenToken(auth, tbal.ToEthTokenBal(), tbal.Bal.Sig)
+		}, status)
+		if err != nil {
+			return fmt.Errorf("WithdrawFrozenToken TX: %w", err)
+		}
+		c.record(HistoryEntry{Type: HistoryWithdraw, Epoch: epoch, Token: tbal.Token, Amount: (*big.Int)(tbal.Value)})
 	}
 	c.logOnChain("❄️WithdrawFrozen: Complete")
+	return nil
 }
 
 func (c *Client) lastBal() *EpochBalance {
@@ -465,27 +686,146 @@ func (c *Client) lastBal() *EpochBalance {
 	return &bal
 }
 
+// CmdLeave exits and withdraws the whole balance without arguments, or the
+// given amount of ETH or a token with arguments [<token>] <amount>.
 func (c *Client) CmdLeave(status chan *CmdStatus, args ...string) {
 	defer close(status)
-	if len(args) != 0 {
-		status <- &CmdStatus{Err: errors.New("Command 'leave' does not accept arguments.")}
+	if len(args) > 2 {
+		status <- &CmdStatus{Err: errors.New("Command 'leave' needs arguments: [[<token>] <amount>]")}
 		return
 	}
+	if len(args) == 0 {
+		if err := c.exit(c.Ctx(), status); err != nil {
+			status <- &CmdStatus{Err: err}
+		}
+		return
+	}
+
+	token := tee.ETHToken
+	if len(args) == 2 {
+		var err error
+		if token, err = strToCommonAddress(args[0]); err != nil {
+			status <- &CmdStatus{Err: fmt.Errorf("Invalid <token>: %v", err)}
+			return
+		}
+		args = args[1:]
+	}
+	amount, err := parseAmount(token, args[0])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <amount>: %v", err)}
+		return
+	}
+	if err := c.exitAmount(c.Ctx(), token, amount, status); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
+}
+
+// exit exits and withdraws the whole balance, including all tokens.
+func (c *Client) exit(ctx context.Context, status chan *CmdStatus) error {
 	bal := c.lastBal()
 	if bal == nil {
-		status <- &CmdStatus{Err: errors.New("No balance proof available")}
-		return
+		return errors.New("No balance proof available")
 	}
 
 	rec, err := c.sendTx("Exit", func(opts *bind.TransactOpts) (*types.Transaction, error) {
 		return c.contract.Exit(opts, bal.ToEthBal(), bal.Bal.Sig)
 	}, status)
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Exit TX: %w", err)}
-		return
+		return fmt.Errorf("Exit TX: %w", err)
 	}
 	c.logOnChain("Exit mined in block #%d", rec.BlockNumber.Uint64())
-	c.withdraw(bal.Epoch, status)
+	c.record(HistoryEntry{Type: HistoryExit, Epoch: bal.Epoch, Token: tee.ETHToken, Amount: (*big.Int)(bal.Value)})
+
+	// Exiting removes the whole account, so all tokens have to be exited too.
+	c.balMtx.RLock()
+	var tokenBals []EpochBalance
+	for _, tbal := range c.tokenBals[bal.Epoch] {
+		if tbal.Bal != nil {
+			tokenBals = append(tokenBals, tbal)
+		}
+	}
+	c.balMtx.RUnlock()
+	for _, tbal := range tokenBals {
+		tbal := tbal
+		rec, err := c.sendTx("ExitToken", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return c.contract.ExitToken(opts, tbal.ToEthTokenBal(), tbal.Bal.Sig)
+		}, status)
+		if err != nil {
+			return fmt.Errorf("ExitToken TX: %w", err)
+		}
+		c.logOnChain("Token exit mined in block #%d", rec.BlockNumber.Uint64())
+		c.record(HistoryEntry{Type: HistoryExit, Epoch: bal.Epoch, Token: tbal.Token, Amount: (*big.Int)(tbal.Value)})
+	}
+
+	if err := c.withdraw(ctx, bal.Epoch, status); err != nil {
+		return err
+	}
+	for _, tbal := range tokenBals {
+		if err := c.withdrawToken(bal.Epoch, tbal.Token, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitAmount exits and withdraws the given amount of ETH or a token. The rest
+// of the balance stays in the system.
+func (c *Client) exitAmount(ctx context.Context, token common.Address, amount *big.Int, status chan *CmdStatus) error {
+	bal := c.lastBal()
+	if bal == nil {
+		return errors.New("No balance proof available")
+	}
+	exitEpoch := bal.Epoch
+
+	req := tee.ExitRequest{
+		Epoch:   exitEpoch,
+		Account: c.Address(),
+		Token:   token,
+		Value:   (*tee.Amount)(amount),
+	}
+	if err := req.Sign(c.params.Contract, c.ethClient.Account(), c.signer); err != nil {
+		return err
+	}
+	status <- &CmdStatus{Msg: "Requesting exit proof"}
+	proof, err := c.conn.RequestExit(shortCtx(), req)
+	if err != nil {
+		return err
+	}
+	if ok, err := tee.VerifyBalanceProof(*c.params, proof); err != nil || !ok {
+		return fmt.Errorf("Invalid exit proof: %v", err)
+	} else if b := proof.Balance; b.Epoch != exitEpoch || b.Account != c.Address() ||
+		b.Token != token || b.Value == nil || (*big.Int)(b.Value).Cmp(amount) != 0 {
+		return errors.New("Exit proof does not match request")
+	}
+
+	rec, err := c.sendTx("Exit", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if token == tee.ETHToken {
+			return c.contract.Exit(opts, proof.Balance.ToEthBal(), proof.Sig)
+		}
+		return c.contract.ExitToken(opts, proof.Balance.ToEthTokenBal(), proof.Sig)
+	}, status)
+	if err != nil {
+		return fmt.Errorf("Exit TX: %w", err)
+	}
+	c.logOnChain("Partial exit mined in block #%d", rec.BlockNumber.Uint64())
+	c.record(HistoryEntry{Type: HistoryExit, Epoch: exitEpoch, Token: token, Amount: amount})
+
+	// Wait for the end of the Exit epoch before sending the Withdraw TX.
+	if err := c.ethClient.WaitForBlock(ctx, c.params.DepositStartBlock(exitEpoch+1)); err != nil {
+		return err
+	}
+	if token != tee.ETHToken {
+		return c.withdrawToken(exitEpoch, token, status)
+	}
+	rec, err = c.sendTx("Withdraw", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Withdraw(opts, exitEpoch)
+	}, status)
+	if err != nil {
+		return fmt.Errorf("Withdraw TX: %w", err)
+	}
+	c.logOnChain("Withdraw mined in block #%d", rec.BlockNumber.Uint64())
+	c.record(HistoryEntry{Type: HistoryWithdraw, Epoch: exitEpoch, Token: token, Amount: amount})
+	return nil
 }
 
 func (c *Client) CmdChallenge(status chan *CmdStatus, args ...string) {
@@ -517,45 +857,70 @@ func (c *Client) CmdChallenge(status chan *CmdStatus, args ...string) {
 		return
 	}
 
-	c.challenge(status, bal.Bal)
+	if err := c.challenge(status, bal.Bal); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
 }
 
-func (c *Client) challengeDeposit(status chan *CmdStatus) {
+func (c *Client) challengeDeposit(status chan *CmdStatus) error {
 	tx, err := c.sendTx("ChallengeDeposit", func(auth *bind.TransactOpts) (*types.Transaction, error) {
 		return c.contract.ChallengeDeposit(auth)
 	}, status)
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("ChallengeDeposit TX: %w", err)}
-		return
+		return fmt.Errorf("ChallengeDeposit TX: %w", err)
+	}
+	return c.waitForResponseOrWithdraw(status, tee.ETHToken, tx.BlockNumber.Uint64())
+}
+
+func (c *Client) challengeTokenDeposit(token common.Address, status chan *CmdStatus) error {
+	tx, err := c.sendTx("ChallengeTokenDeposit", func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.ChallengeTokenDeposit(auth, token)
+	}, status)
+	if err != nil {
+		return fmt.Errorf("ChallengeTokenDeposit TX: %w", err)
 	}
-	c.waitForResponseOrWithdraw(status, tx.BlockNumber.Uint64())
+	return c.waitForResponseOrWithdraw(status, token, tx.BlockNumber.Uint64())
 }
 
-func (c *Client) challenge(status chan *CmdStatus, bp *tee.BalanceProof) {
+func (c *Client) challenge(status chan *CmdStatus, bp *tee.BalanceProof) error {
 	tx, err := c.sendTx("Challenge", func(auth *bind.TransactOpts) (*types.Transaction, error) {
 		return c.contract.Challenge(auth, bp.Balance.ToEthBal(), bp.Sig)
 	}, status)
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Challenge TX: %w", err)}
-		return
+		return fmt.Errorf("Challenge TX: %w", err)
 	}
-	c.waitForResponseOrWithdraw(status, tx.BlockNumber.Uint64())
+	return c.waitForResponseOrWithdraw(status, tee.ETHToken, tx.BlockNumber.Uint64())
 }
 
-// waitForResponseOrWithdraw waits for a challenge response. If it is not
-// received in time, it freezes the contract and withdraws.
-func (c *Client) waitForResponseOrWithdraw(status chan *CmdStatus, block uint64) {
+// waitForResponseOrWithdraw waits for a challenge response of the token. If
+// it is not received in time, it freezes the contract and withdraws.
+func (c *Client) waitForResponseOrWithdraw(status chan *CmdStatus, token common.Address, block uint64) error {
 	// Wait for the operator til the end of the epoch and Freeze otherwise.
 	status <- &CmdStatus{Msg: "Exiting event: Waiting"}
 	subCtx, cancel := context.WithCancel(c.Ctx())
 	defer cancel()
 	exitEpoch := c.params.ExitEpoch(block)
-	sub, err := c.ethClient.SubscribeExiting(subCtx, c.contract, []uint64{exitEpoch}, []common.Address{c.Address()})
-	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Exiting subscription: %w", err)}
-		return
+	// Only one of the event channels is set, nil channels block forever.
+	var (
+		exiting      <-chan *bindings.ErdstallExiting
+		tokenExiting <-chan *bindings.ErdstallTokenExiting
+		subErr       <-chan error
+	)
+	if token == tee.ETHToken {
+		sub, err := c.ethClient.SubscribeExiting(subCtx, c.contract, []uint64{exitEpoch}, []common.Address{c.Address()})
+		if err != nil {
+			return fmt.Errorf("Exiting subscription: %w", err)
+		}
+		defer sub.Unsubscribe()
+		exiting, subErr = sub.Events(), sub.Err()
+	} else {
+		sub, err := c.ethClient.SubscribeTokenExiting(subCtx, c.contract, []uint64{exitEpoch}, []common.Address{c.Address()}, []common.Address{token})
+		if err != nil {
+			return fmt.Errorf("TokenExiting subscription: %w", err)
+		}
+		defer sub.Unsubscribe()
+		tokenExiting, subErr = sub.Events(), sub.Err()
 	}
-	defer sub.Unsubscribe()
 
 	done := make(chan struct{})
 	go func() {
@@ -568,22 +933,43 @@ func (c *Client) waitForResponseOrWithdraw(status chan *CmdStatus, block uint64)
 	case <-done: // ChallengeReponse phase is over, freeze.
 		c.logOnChain("Freezing in epoch %d", exitEpoch)
 		c.stopFrozenWatcher()
-		_, err := c.sendTx("WithdrawChallenge", func(auth *bind.TransactOpts) (*types.Transaction, error) {
-			return c.contract.WithdrawChallenge(auth)
-		}, status)
+		if token == tee.ETHToken {
+			_, err := c.sendTx("WithdrawChallenge", func(auth *bind.TransactOpts) (*types.Transaction, error) {
+				return c.contract.WithdrawChallenge(auth)
+			}, status)
+			if err != nil {
+				return fmt.Errorf("WithdrawChallenge TX: %w", err)
+			}
+		} else {
+			_, err := c.sendTx("WithdrawTokenChallenge", func(auth *bind.TransactOpts) (*types.Transaction, error) {
+				return c.contract.WithdrawTokenChallenge(auth, token)
+			}, status)
+			if err != nil {
+				return fmt.Errorf("WithdrawTokenChallenge TX: %w", err)
+			}
+		}
+		// The challenge withdrawal only covers the challenged balance, the
+		// others are withdrawn from the frozen epoch.
+		frozen, err := c.contract.FrozenEpoch(&bind.CallOpts{Context: shortCtx()})
 		if err != nil {
-			status <- &CmdStatus{Err: fmt.Errorf("WithdrawChallenge TX: %w", err)}
-			return
+			return fmt.Errorf("reading frozen epoch: %w", err)
 		}
-	case err := <-sub.Err():
+		return c.withdrawFrozen(status, frozen)
+	case err := <-subErr:
 		if err != nil {
 			c.logError("Exiting subscription: %v", err)
 		}
-		return
-	case <-sub.Events(): // Challenge was posted on-chain by OP.
+	case <-exiting: // Challenge was posted on-chain by OP.
 		c.logOnChain("Received on-chain challenge response")
-		c.withdraw(exitEpoch, status)
+		return c.withdraw(c.Ctx(), exitEpoch, status)
+	case <-tokenExiting:
+		c.logOnChain("Received on-chain token challenge response")
+		if err := c.ethClient.WaitForBlock(c.Ctx(), c.params.DepositStartBlock(exitEpoch+1)); err != nil {
+			return err
+		}
+		return c.withdrawToken(exitEpoch, token, status)
 	}
+	return nil
 }
 
 // sendTx already checks the receipt status and returns an error
@@ -604,26 +990,40 @@ func (c *Client) sendTx(name string, f func(*bind.TransactOpts) (*types.Transact
 	return c.ethClient.ConfirmTransaction(txCtx(), tx, c.ethClient.Account())
 }
 
-func (c *Client) withdraw(exitEpoch uint64, status chan *CmdStatus) {
+func (c *Client) withdraw(ctx context.Context, exitEpoch uint64, status chan *CmdStatus) error {
 	// Wait for the end of the Exit epoch before sending the Withdraw TX.
 	endExitBlock := c.params.DepositStartBlock(exitEpoch + 1)
-	if err := c.ethClient.WaitForBlock(c.Ctx(), endExitBlock); err != nil {
-		status <- &CmdStatus{Err: err}
-		return
+	if err := c.ethClient.WaitForBlock(ctx, endExitBlock); err != nil {
+		return err
 	}
 
 	rec, err := c.sendTx("Withdraw", func(opts *bind.TransactOpts) (*types.Transaction, error) {
 		return c.contract.Withdraw(opts, exitEpoch)
 	}, status)
 	if err != nil {
-		status <- &CmdStatus{Err: fmt.Errorf("Withdraw TX: %w", err)}
-		return
+		return fmt.Errorf("Withdraw TX: %w", err)
 	}
 
 	c.logOnChain("Withdraw mined in block #%d", rec.BlockNumber.Uint64())
-	c.events <- &Event{Type: SET_BALANCE, Report: BalanceReport{Balance: big.NewInt(0)}}
+	c.record(HistoryEntry{Type: HistoryWithdraw, Epoch: exitEpoch, Token: tee.ETHToken})
+	c.emit(&Event{Type: SET_BALANCE, Report: BalanceReport{Balance: big.NewInt(0)}})
 	c.setOpTrust(TRUSTED)
 	c.stopFrozenWatcher()
+	return nil
+}
+
+// withdrawToken withdraws the exited balance of a token. It must only be
+// called after withdraw, which waits for the end of the exit epoch.
+func (c *Client) withdrawToken(exitEpoch uint64, token common.Address, status chan *CmdStatus) error {
+	rec, err := c.sendTx("WithdrawToken", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.WithdrawToken(opts, exitEpoch, token)
+	}, status)
+	if err != nil {
+		return fmt.Errorf("WithdrawToken TX: %w", err)
+	}
+	c.logOnChain("Token withdraw mined in block #%d", rec.BlockNumber.Uint64())
+	c.record(HistoryEntry{Type: HistoryWithdraw, Epoch: exitEpoch, Token: token})
+	return nil
 }
 
 // writes to chainVvents
@@ -636,45 +1036,75 @@ func (c *Client) listenOnChain() error {
 		subError <- c.ethClient.SubscribeEpochs(c.Ctx(), *c.params, epochs, blocks)
 	}()
 	go c.BalanceProofWatcher()
+	go c.depositProofWatcher()
+	go c.txReceiptWatcher()
 	go c.frozenWatcher()
 
 	for !c.IsClosed() {
 		select {
 		case epoch := <-epochs:
-			c.events <- &Event{Type: NEW_EPOCH, EpochNum: epoch}
+			c.emit(&Event{Type: NEW_EPOCH, EpochNum: epoch})
 		case block := <-blocks:
 			atomic.StoreUint64(&c.lastBlock, block)
-			c.events <- &Event{Type: NEW_BLOCK, BlockNum: block}
+			c.readyOnce.Do(func() { close(c.ready) })
+			c.emit(&Event{Type: NEW_BLOCK, BlockNum: block})
+			c.checkTransactions()
 		case err := <-subError:
 			return err
 		}
-		c.events <- &Event{Type: SET_EXIT_AVAIL, ExitAvailable: c.lastBal()}
+		c.emit(&Event{Type: SET_EXIT_AVAIL, ExitAvailable: c.lastBal()})
 	}
 	return nil
 }
 
+// checkTransactions logs the client's in-flight transactions that were dropped
+// by the node. Their nonces are reused by the next transactions.
+func (c *Client) checkTransactions() {
+	if len(c.ethClient.InFlightTransactions()) == 0 {
+		return
+	}
+	dropped, err := c.ethClient.CheckTransactions(shortCtx())
+	if err != nil {
+		c.logError("Checking transactions: %v", err)
+	}
+	for _, tx := range dropped {
+		c.logError("Transaction %s dropped by node, reusing nonce %d", tx.Hash().Hex(), tx.Nonce())
+	}
+}
+
 func (c *Client) logProof(format string, args ...interface{}) {
-	c.events <- &Event{Type: CHAIN_MSG, Message: "🔒 " + fmt.Sprintf(format, args...)}
+	c.emit(&Event{Type: CHAIN_MSG, Message: "🔒 " + fmt.Sprintf(format, args...)})
 }
 
 func (c *Client) logOnChain(format string, args ...interface{}) {
-	c.events <- &Event{Type: CHAIN_MSG, Message: "🔗 " + fmt.Sprintf(format, args...)}
+	c.emit(&Event{Type: CHAIN_MSG, Message: "🔗 " + fmt.Sprintf(format, args...)})
 }
 
 func (c *Client) logOffChain(format string, args ...interface{}) {
-	c.events <- &Event{Type: CHAIN_MSG, Message: "🍵 " + fmt.Sprintf(format, args...)}
+	c.emit(&Event{Type: CHAIN_MSG, Message: "🍵 " + fmt.Sprintf(format, args...)})
 }
 
 func (c *Client) logError(format string, args ...interface{}) {
-	c.events <- &Event{Type: CHAIN_MSG, Message: "⚠ " + fmt.Sprintf(format, args...)}
+	c.emit(&Event{Type: CHAIN_MSG, Message: "⚠ " + fmt.Sprintf(format, args...)})
 }
 
 func (c *Client) log(format string, args ...interface{}) {
-	c.events <- &Event{Type: CHAIN_MSG, Message: fmt.Sprintf(format, args...)}
+	c.emit(&Event{Type: CHAIN_MSG, Message: fmt.Sprintf(format, args...)})
+}
+
+// handleConnState reports changes of the operator connection.
+func (c *Client) handleConnState(s ConnState) {
+	switch s {
+	case Disconnected:
+		c.logError("Lost connection to operator, reconnecting...")
+	case Connected:
+		c.logOffChain("Reconnected to operator")
+	}
+	c.emit(&Event{Type: CONN_STATE, ConnState: s})
 }
 
 func (c *Client) setOpTrust(trust Trust) {
-	c.events <- &Event{Type: SET_OP_TRUST, OpTrust: trust}
+	c.emit(&Event{Type: SET_OP_TRUST, OpTrust: trust})
 }
 
 func (c *Client) Address() common.Address {
@@ -712,6 +1142,23 @@ func strToCommonAddress(s string) (common.Address, error) {
 	return pethwallet.AsEthAddr(walletAddr), nil
 }
 
+// parseAmount parses an amount of the given token. ETH amounts are given in
+// ETH and token amounts in the token's smallest unit.
+func parseAmount(token common.Address, s string) (*big.Int, error) {
+	if token == tee.ETHToken {
+		amount, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return eth.EthToWeiFloat(amount), nil
+	}
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid token amount %q", s)
+	}
+	return amount, nil
+}
+
 func txCtx() context.Context {
 	return newCtx(time.Second * 30)
 }
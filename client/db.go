@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+type (
+	// walletDB is the client's persistent wallet database. It is a JSON file
+	// that is atomically replaced on every update.
+	walletDB struct {
+		path string
+		mtx  sync.Mutex // Protects data and the file.
+		data walletData
+	}
+
+	// walletData is the content of the wallet database.
+	walletData struct {
+		Contract  common.Address                             `json:"contract"`
+		Account   common.Address                             `json:"account"`
+		TxNonce   uint64                                     `json:"txNonce"` // Next transaction nonce.
+		Balances  map[uint64]EpochBalance                    `json:"balances"`
+		TokenBals map[uint64]map[common.Address]EpochBalance `json:"tokenBalances"`
+		Sent      []tee.Transaction                          `json:"sent"`
+		Received  []tee.Transaction                          `json:"received"`
+		Deposits  []Deposit                                  `json:"deposits"`
+		History   []HistoryEntry                             `json:"history"`
+	}
+)
+
+// openWalletDB opens the wallet database at path, or creates it if it does not
+// exist. An existing database must belong to the given contract and account.
+func openWalletDB(path string, contract, account common.Address) (*walletDB, error) {
+	db := &walletDB{path: path, data: walletData{
+		Contract:  contract,
+		Account:   account,
+		Balances:  make(map[uint64]EpochBalance),
+		TokenBals: make(map[uint64]map[common.Address]EpochBalance),
+	}}
+
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, db.write()
+	} else if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
+	}
+
+	var data walletData
+	if err := json.Unmarshal(blob, &data); err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	} else if data.Contract != contract {
+		return nil, fmt.Errorf("database belongs to contract %s", data.Contract.Hex())
+	} else if data.Account != account {
+		return nil, fmt.Errorf("database belongs to account %s", data.Account.Hex())
+	}
+	if data.Balances == nil {
+		data.Balances = make(map[uint64]EpochBalance)
+	}
+	if data.TokenBals == nil {
+		data.TokenBals = make(map[uint64]map[common.Address]EpochBalance)
+	}
+	db.data = data
+	return db, nil
+}
+
+// update applies f to the data and writes the database. If writing fails, the
+// in-memory data stays updated and is written with the next update.
+func (db *walletDB) update(f func(*walletData)) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	f(&db.data)
+	return db.write()
+}
+
+// write atomically replaces the database file. The new content is synced to
+// disk before the replacement, so that a crash leaves either the old or the
+// new database. db.mtx must be held.
+func (db *walletDB) write() error {
+	blob, err := json.Marshal(&db.data)
+	if err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	tmp := db.path + ".tmp"
+	if err := writeSynced(tmp, blob); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	if err := os.Rename(tmp, db.path); err != nil {
+		return fmt.Errorf("replacing: %w", err)
+	}
+	// Persist the rename.
+	if dir, err := os.Open(filepath.Dir(db.path)); err == nil {
+		dir.Sync() // nolint: errcheck // not supported on all platforms
+		dir.Close()
+	}
+	return nil
+}
+
+// writeSynced writes the file and syncs it to disk.
+func writeSynced(path string, blob []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(blob); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing: %w", err)
+	}
+	return f.Close()
+}
+
+// setTokenBal stores a token balance in the data.
+func (d *walletData) setTokenBal(bal EpochBalance) {
+	bals, ok := d.TokenBals[bal.Epoch]
+	if !ok {
+		bals = make(map[common.Address]EpochBalance)
+		d.TokenBals[bal.Epoch] = bals
+	}
+	bals[bal.Token] = bal
+}
+
+// persist applies f to the wallet database, if any. Errors are logged, as the
+// in-memory state stays valid.
+func (c *Client) persist(f func(*walletData)) {
+	if c.db == nil {
+		return
+	}
+	if err := c.db.update(f); err != nil {
+		c.logError("Wallet database: %v", err)
+	}
+}
+
+// persistNonce stores the next transaction nonce. c.nonceMtx must be held.
+func (c *Client) persistNonce() {
+	nonce := c.txNonce
+	c.persist(func(d *walletData) { d.TxNonce = nonce })
+}
+
+// loadDB opens the wallet database and restores the client's state from it.
+func (c *Client) loadDB(path string) error {
+	db, err := openWalletDB(path, c.contractAddr, c.Address())
+	if err != nil {
+		return err
+	}
+	d := &db.data
+	if d.TxNonce > c.txNonce {
+		c.txNonce = d.TxNonce
+	}
+	for epoch, bal := range d.Balances {
+		c.balances[epoch] = bal
+	}
+	for epoch, bals := range d.TokenBals {
+		c.tokenBals[epoch] = make(map[common.Address]EpochBalance, len(bals))
+		for token, bal := range bals {
+			c.tokenBals[epoch][token] = bal
+		}
+	}
+	for _, dep := range d.Deposits {
+		s := c.depositState(dep.Epoch, dep.Token)
+		s.deposits = append(s.deposits, dep)
+	}
+	c.history = append(c.history, d.History...)
+	c.db = db
+	return nil
+}
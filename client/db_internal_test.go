@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	ttest "github.com/perun-network/erdstall/tee/test"
+)
+
+func TestWalletDB(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	contract, account := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	db, err := openWalletDB(path, contract, account)
+	require.NoError(err)
+	bp, tbp := ttest.RandomBP(rng), ttest.RandomBP(rng)
+	tbp.Balance = ttest.RandomTokenBalance(rng)
+	tx := ttest.RandomTx(t, rng)
+	require.NoError(db.update(func(d *walletData) {
+		d.TxNonce = 7
+		d.Balances[bp.Balance.Epoch] = EpochBalance{Balance: bp.Balance, Bal: bp}
+		d.setTokenBal(EpochBalance{Balance: tbp.Balance, Bal: tbp})
+		d.Sent = append(d.Sent, *tx)
+		d.History = append(d.History, HistoryEntry{Type: HistoryTransfer, Amount: big.NewInt(3)})
+	}))
+
+	t.Run("reopen", func(t *testing.T) {
+		db2, err := openWalletDB(path, contract, account)
+		require.NoError(err)
+		d := db2.data
+		require.Equal(uint64(7), d.TxNonce)
+		require.Equal(*bp, *d.Balances[bp.Balance.Epoch].Bal)
+		require.Equal(*tbp, *d.TokenBals[tbp.Balance.Epoch][tbp.Balance.Token].Bal)
+		require.Equal([]byte(tx.Sig), []byte(d.Sent[0].Sig))
+		require.Zero(d.History[0].Amount.Cmp(big.NewInt(3)))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		_, err := openWalletDB(path, eth.NewRandomAddress(rng), account)
+		require.Error(err)
+		_, err = openWalletDB(path, contract, eth.NewRandomAddress(rng))
+		require.Error(err)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		require.NoError(ioutil.WriteFile(path, []byte("{"), 0600))
+		_, err := openWalletDB(path, contract, account)
+		require.Error(err)
+	})
+}
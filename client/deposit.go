@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+type (
+	// Deposit describes a single deposit transaction.
+	Deposit struct {
+		TxHash common.Hash    `json:"txHash"`
+		Block  uint64         `json:"block"`
+		Epoch  uint64         `json:"epoch"`
+		Token  common.Address `json:"token"`
+		Amount *big.Int       `json:"amount"`
+	}
+
+	depositKey struct {
+		epoch uint64
+		token common.Address
+	}
+
+	// depositState tracks all deposits of one token in one epoch. The
+	// operator sends one deposit proof for their sum.
+	depositState struct {
+		deposits   []Deposit
+		proof      *tee.DepositProof // Received proof, nil until received.
+		accepted   bool              // Whether proof was validated and stored.
+		challenged bool              // Whether the epoch was challenged.
+		received   chan struct{}     // Closed when proof was received.
+	}
+)
+
+// sum returns the sum of all tracked deposits.
+func (s *depositState) sum() *big.Int {
+	sum := new(big.Int)
+	for _, d := range s.deposits {
+		sum.Add(sum, d.Amount)
+	}
+	return sum
+}
+
+// depositState returns the state of the token deposits in epoch, creating it
+// if needed. c.balMtx must be held.
+func (c *Client) depositState(epoch uint64, token common.Address) *depositState {
+	k := depositKey{epoch, token}
+	s, ok := c.deposits[k]
+	if !ok {
+		s = &depositState{received: make(chan struct{})}
+		c.deposits[k] = s
+	}
+	return s
+}
+
+// addDeposit tracks a mined deposit and returns the state of its epoch and
+// token. If the proof was already received, it is validated again, as it
+// might have arrived before the deposit was tracked.
+func (c *Client) addDeposit(d Deposit) *depositState {
+	c.balMtx.Lock()
+	defer c.balMtx.Unlock()
+	s := c.depositState(d.Epoch, d.Token)
+	s.deposits = append(s.deposits, d)
+	c.persist(func(data *walletData) { data.Deposits = append(data.Deposits, d) })
+	if s.proof != nil {
+		if err := c.acceptDepositProof(s); err != nil {
+			c.logProof("Deposit proof: %v", err)
+		}
+	}
+	return s
+}
+
+// Deposits returns all tracked deposits of the epoch, in the order in which
+// they were made.
+func (c *Client) Deposits(epoch uint64) []Deposit {
+	c.balMtx.RLock()
+	defer c.balMtx.RUnlock()
+	var deps []Deposit
+	for k, s := range c.deposits {
+		if k.epoch == epoch {
+			deps = append(deps, s.deposits...)
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].Block < deps[j].Block })
+	return deps
+}
+
+// depositProofWatcher receives the deposit proofs of the operator and
+// validates them against the tracked deposits.
+// Should be started in a go-routine.
+func (c *Client) depositProofWatcher() {
+	for !c.IsClosed() {
+		p, err := c.proofSub.DepositProof(c.Ctx())
+		if err != nil {
+			continue // Only fails when closed.
+		}
+		c.handleDepositProof(p)
+	}
+}
+
+// handleDepositProof stores a received deposit proof and wakes up all
+// deposits that wait for it.
+func (c *Client) handleDepositProof(p tee.DepositProof) {
+	c.balMtx.Lock()
+	defer c.balMtx.Unlock()
+	s := c.depositState(p.Balance.Epoch, p.Balance.Token)
+	if s.proof != nil {
+		c.logProof("Ignoring second deposit proof for epoch %d", p.Balance.Epoch)
+		return
+	}
+	s.proof = &p
+	close(s.received)
+	if len(s.deposits) == 0 {
+		c.logProof("Got deposit proof for epoch %d without known deposits", p.Balance.Epoch)
+		return
+	}
+	if err := c.acceptDepositProof(s); err != nil {
+		c.logProof("Deposit proof: %v", err)
+	}
+}
+
+// acceptDepositProof validates the received proof against the sum of the
+// tracked deposits and stores it as balance of the epoch. c.balMtx must be
+// held.
+func (c *Client) acceptDepositProof(s *depositState) error {
+	if s.accepted {
+		return nil
+	}
+	p := *s.proof
+	if ok, err := tee.VerifyDepositProof(*c.params, p); err != nil {
+		return fmt.Errorf("verifying signature: %w", err)
+	} else if !ok {
+		return errors.New("invalid signature")
+	}
+	if p.Balance.Account != c.Address() {
+		return fmt.Errorf("wrong account %s", p.Balance.Account.Hex())
+	}
+	if sum := s.sum(); (*big.Int)(p.Balance.Value).Cmp(sum) != 0 {
+		return fmt.Errorf("proven value %v does not match deposited %v", (*big.Int)(p.Balance.Value), sum)
+	}
+
+	s.accepted = true
+	bal := EpochBalance{Balance: p.Balance, Dep: &p}
+	if p.Balance.IsETH() {
+		c.balances[p.Balance.Epoch] = bal
+		c.persist(func(d *walletData) { d.Balances[p.Balance.Epoch] = bal })
+	} else {
+		c.storeTokenBal(bal)
+	}
+	return nil
+}
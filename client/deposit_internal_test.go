@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/config"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestClient_MultipleDeposits(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	user := eth.NewRandomAddress(rng)
+	params := tee.Parameters{Contract: eth.NewRandomAddress(rng)}
+	signDP := newDepositProofSigner(t, rng, &params)
+
+	c := NewClient(config.ClientConfig{}, config.OpClientConfig{Contract: params.Contract}, nil, nil,
+		eth.NewClient(peruneth.ContractBackend{}, accounts.Account{Address: user}), nil)
+	c.params = &params
+	deposit := func(block, epoch uint64, amount int64) *depositState {
+		return c.addDeposit(Deposit{
+			TxHash: common.BytesToHash(eth.NewRandomAddress(rng).Bytes()),
+			Block:  block, Epoch: epoch, Token: tee.ETHToken, Amount: big.NewInt(amount),
+		})
+	}
+
+	t.Run("accumulated", func(t *testing.T) {
+		s := deposit(30, 3, 10)
+		deposit(31, 3, 5)
+		c.handleDepositProof(signDP(tee.Balance{Epoch: 3, Account: user, Value: (*tee.Amount)(big.NewInt(15))}))
+		<-s.received
+		require.True(s.accepted)
+		require.Zero((*big.Int)(c.balances[3].Dep.Balance.Value).Cmp(big.NewInt(15)))
+
+		deps := c.Deposits(3)
+		require.Len(deps, 2)
+		require.Equal(uint64(30), deps[0].Block)
+		require.Equal(uint64(31), deps[1].Block)
+	})
+
+	t.Run("partial-sum", func(t *testing.T) {
+		s := deposit(40, 4, 10)
+		deposit(41, 4, 5)
+		c.handleDepositProof(signDP(tee.Balance{Epoch: 4, Account: user, Value: (*tee.Amount)(big.NewInt(10))}))
+		require.False(s.accepted)
+		require.Error(c.acceptDepositProof(s))
+		require.NotContains(c.balances, uint64(4))
+	})
+
+	t.Run("early-proof", func(t *testing.T) {
+		c.handleDepositProof(signDP(tee.Balance{Epoch: 5, Account: user, Value: (*tee.Amount)(big.NewInt(8))}))
+		s := deposit(50, 5, 8)
+		require.True(s.accepted)
+		require.Contains(c.balances, uint64(5))
+	})
+
+	t.Run("wrong-signer", func(t *testing.T) {
+		s := deposit(60, 6, 8)
+		p := signDP(tee.Balance{Epoch: 6, Account: user, Value: (*tee.Amount)(big.NewInt(8))})
+		p.Sig[0] ^= 1
+		c.handleDepositProof(p)
+		require.False(s.accepted)
+	})
+}
+
+// newDepositProofSigner sets a new TEE address in params and returns a
+// function that signs deposit proofs like the enclave.
+func newDepositProofSigner(t *testing.T, rng *rand.Rand, params *tee.Parameters) func(tee.Balance) tee.DepositProof {
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(t, err)
+	acc, err := w.NewAccount()
+	require.NoError(t, err)
+	params.TEE = acc.Account.Address
+
+	return func(b tee.Balance) tee.DepositProof {
+		msg, err := tee.EncodeDepositProof(params.Contract, b)
+		require.NoError(t, err)
+		sig, err := hdw.SignText(acc.Account, crypto.Keccak256(msg))
+		require.NoError(t, err)
+		sig[64] += 27
+		return tee.DepositProof{Balance: b, Sig: sig}
+	}
+}
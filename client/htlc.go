@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// CmdLock locks a hash-time-locked payment with arguments [<token>] <receiver>
+// <amount> <hashlock> <epochs>. The receiver can claim the payment by revealing
+// the preimage of the SHA-256 <hashlock> within the next <epochs> epochs.
+func (c *Client) CmdLock(status chan *CmdStatus, args ...string) {
+	defer close(status)
+	if len(args) != 4 && len(args) != 5 {
+		status <- &CmdStatus{Err: errors.New("Command 'lock' needs arguments: [<token>] <receiver> <amount> <hashlock> <epochs>")}
+		return
+	}
+	token := tee.ETHToken
+	if len(args) == 5 {
+		var err error
+		if token, err = strToCommonAddress(args[0]); err != nil {
+			status <- &CmdStatus{Err: fmt.Errorf("Invalid <token>: %v", err)}
+			return
+		}
+		args = args[1:]
+	}
+	receiver, err := strToCommonAddress(args[0])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <receiver>: %v", err)}
+		return
+	}
+	amount, err := parseAmount(token, args[1])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <amount>: %v", err)}
+		return
+	}
+	hashLock, err := parseHash(args[2])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <hashlock>: %v", err)}
+		return
+	}
+	epochs, err := strconv.ParseUint(args[3], 10, 64)
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <epochs>: %v", err)}
+		return
+	}
+
+	status <- &CmdStatus{Msg: "Forwarding to Operator"}
+	htlc, err := c.sendLock(shortCtx(), receiver, token, amount, hashLock, epochs)
+	if err != nil {
+		status <- &CmdStatus{Err: err}
+		return
+	}
+	c.logOffChain("Locked payment %s until epoch %d", htlc.ID().Hex(), htlc.Expiry)
+}
+
+// sendLock creates a hash-time-locked payment with the next nonce and sends it
+// to the operator. The nonce is only used up once the operator accepted it.
+func (c *Client) sendLock(ctx context.Context, receiver, token common.Address, amount *big.Int, hashLock common.Hash, epochs uint64) (tee.HTLC, error) {
+	c.nonceMtx.Lock()
+	defer c.nonceMtx.Unlock()
+	htlc, err := c.createLock(receiver, token, amount, hashLock, epochs)
+	if err != nil {
+		return htlc, err
+	}
+	if err := c.conn.LockHTLC(ctx, htlc); err != nil {
+		return htlc, err
+	}
+	c.useNonce()
+	return htlc, nil
+}
+
+// createLock creates a signed hash-time-locked payment with the next nonce.
+// c.nonceMtx must be held.
+func (c *Client) createLock(receiver, token common.Address, amount *big.Int, hashLock common.Hash, epochs uint64) (tee.HTLC, error) {
+	epoch := c.params.TxEpoch(c.ActiveBlock())
+	htlc := tee.HTLC{
+		Nonce:     c.txNonce,
+		Epoch:     epoch,
+		Sender:    c.Address(),
+		Recipient: receiver,
+		Token:     token,
+		Amount:    (*tee.Amount)(amount),
+		HashLock:  hashLock,
+		Expiry:    epoch + epochs,
+	}
+	if fee := c.params.TxFee(token, amount); fee.Sign() > 0 {
+		htlc.Fee = (*tee.Amount)(fee)
+	}
+	if err := htlc.Sign(c.params.Contract, c.ethClient.Account(), c.signer); err != nil {
+		return htlc, fmt.Errorf("signing lock: %w", err)
+	}
+	return htlc, nil
+}
+
+// CmdClaim claims a hash-time-locked payment with arguments <lock-id>
+// <preimage>.
+func (c *Client) CmdClaim(status chan *CmdStatus, args ...string) {
+	defer close(status)
+	if len(args) != 2 {
+		status <- &CmdStatus{Err: errors.New("Command 'claim' needs arguments: <lock-id> <preimage>")}
+		return
+	}
+	id, err := parseHash(args[0])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <lock-id>: %v", err)}
+		return
+	}
+	preimage, err := parseHash(args[1])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <preimage>: %v", err)}
+		return
+	}
+	status <- &CmdStatus{Msg: "Forwarding to Operator"}
+	if err := c.conn.ClaimHTLC(shortCtx(), tee.HTLCClaim{ID: id, Preimage: preimage}); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
+}
+
+// CmdRefund refunds an expired hash-time-locked payment with argument
+// <lock-id>.
+func (c *Client) CmdRefund(status chan *CmdStatus, args ...string) {
+	defer close(status)
+	if len(args) != 1 {
+		status <- &CmdStatus{Err: errors.New("Command 'refund' needs argument: <lock-id>")}
+		return
+	}
+	id, err := parseHash(args[0])
+	if err != nil {
+		status <- &CmdStatus{Err: fmt.Errorf("Invalid <lock-id>: %v", err)}
+		return
+	}
+	status <- &CmdStatus{Msg: "Forwarding to Operator"}
+	if err := c.conn.RefundHTLC(shortCtx(), tee.HTLCRefund{ID: id}); err != nil {
+		status <- &CmdStatus{Err: err}
+	}
+}
+
+// parseHash parses a 0x-prefixed 32 byte hex string.
+func parseHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return common.Hash{}, err
+	} else if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("expected %d bytes, got %d", common.HashLength, len(b))
+	}
+	return common.BytesToHash(b), nil
+}
@@ -4,21 +4,25 @@
 package client_test
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 	"os/exec"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/perun-network/erdstall/client"
 	"github.com/perun-network/erdstall/config"
 	"github.com/perun-network/erdstall/eth"
 	op "github.com/perun-network/erdstall/operator"
+	"github.com/perun-network/erdstall/tee/attestation"
 	"github.com/perun-network/erdstall/wallet"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	perunchannel "perun.network/go-perun/backend/ethereum/channel"
-	perunhd "perun.network/go-perun/backend/ethereum/wallet/hd"
 	pkgtest "perun.network/go-perun/pkg/test"
 )
 
@@ -29,6 +33,9 @@ const (
 	blockTime = 2 * time.Second
 )
 
+// attestationKey is the key of the mock attestation service.
+var attestationKey, _ = crypto.GenerateKey()
+
 func TestWalkthroughs(t *testing.T) {
 	log.SetLevel(log.InfoLevel)
 	log.SetFormatter(&log.TextFormatter{ForceColors: true})
@@ -154,6 +161,9 @@ func startClient(index int) (*client.Client, error) {
 		Mnemonic:     mnemonic,
 		AccountIndex: index + 2,
 		UserName:     fmt.Sprintf("client-%d", index),
+
+		AttestationSigner:   crypto.PubkeyToAddress(attestationKey.PublicKey),
+		TrustedMeasurements: []common.Hash{attestation.PrototypeMeasurement},
 	}
 	rpc, err := client.NewRPC(cfg.OpHost, uint16(cfg.OpPort))
 	if err != nil {
@@ -174,7 +184,11 @@ func startClient(index int) (*client.Client, error) {
 		}
 	}()
 
-	cb := perunchannel.NewContractBackend(eb, perunhd.NewTransactor(wallet.Wallet.Wallet()))
+	chainID, err := eb.ChainID(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	cb := perunchannel.NewContractBackend(eb, eth.NewDefaultTransactor(wallet.Wallet.Wallet(), chainID), eth.TxFinalityDepth)
 	chain := eth.NewClient(cb, wallet.Acc.Account) // ETHChain conn
 	log.Info(cfg.UserName, " address: ", wallet.Acc.Address())
 	client := client.NewClient(cfg, rpc.ClientCfg(), rpc, events, chain, wallet)
@@ -217,6 +231,7 @@ func startOp(honesty *op.Config) *op.Operator {
 		RespondChallenges:      honesty.RespondChallenges,
 		SendDepositProofs:      honesty.SendDepositProofs,
 		SendBalanceProofs:      honesty.SendBalanceProofs,
+		AttestationKey:         hex.EncodeToString(crypto.FromECDSA(attestationKey)),
 	}
 
 	operator := op.SetupWithPrototypeEnclave(cfg)
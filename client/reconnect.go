@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	gorilla "github.com/gorilla/websocket"
+
+	"github.com/perun-network/erdstall/tee"
+	"github.com/perun-network/erdstall/wire"
+)
+
+// ConnState is the state of the RPC's connection to the operator.
+type ConnState int
+
+const (
+	// Connected means that the connection was re-established and the
+	// subscription and pending transactions were restored.
+	Connected ConnState = iota
+	// Disconnected means that the connection was lost and the RPC is
+	// reconnecting.
+	Disconnected
+)
+
+const (
+	reconnectMinBackoff = 100 * time.Millisecond
+	reconnectMaxBackoff = 30 * time.Second
+	resubscribeTimeout  = 10 * time.Second
+)
+
+type (
+	// pendingTx is a sent transaction whose result did not arrive yet.
+	pendingTx struct {
+		call *wire.SendTx
+		err  error
+		done chan struct{} // Closed when the result arrived.
+	}
+
+	// subscriptionAuth holds what is needed to resubscribe.
+	subscriptionAuth struct {
+		account accounts.Account
+		signer  tee.TextSigner
+	}
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("ConnState(%d)", int(s))
+	}
+}
+
+// OnConnState sets a handler that is called on every change of the connection
+// state. It is called synchronously and must not block.
+func (r *RPC) OnConnState(handler func(ConnState)) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.onConnState = handler
+}
+
+func (r *RPC) setConnState(s ConnState) {
+	r.mtx.Lock()
+	handler := r.onConnState
+	r.mtx.Unlock()
+	if handler != nil {
+		handler(s)
+	}
+}
+
+// dial opens a new connection to the operator.
+func (r *RPC) dial() (*gorilla.Conn, error) {
+	conn, _, err := gorilla.DefaultDialer.Dial(r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// run handles incoming messages and reconnects whenever the connection drops,
+// until the RPC is closed.
+func (r *RPC) run(conn *gorilla.Conn) {
+	for {
+		err := r.handleConnection(conn)
+		if r.IsClosed() {
+			r.Log().WithError(err).Debug("RPC connection handler returned.")
+			return
+		}
+		r.Log().WithError(err).Warn("Connection to operator lost, reconnecting")
+		r.failCalls()
+		r.setConnState(Disconnected)
+
+		if conn = r.reconnect(); conn == nil {
+			return
+		}
+		go r.restore()
+	}
+}
+
+// reconnect dials the operator with exponential backoff until it succeeds and
+// returns the new connection. It returns nil if the RPC is closed first.
+func (r *RPC) reconnect() *gorilla.Conn {
+	backoff := reconnectMinBackoff
+	for {
+		select {
+		case <-r.Closed():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		conn, err := r.dial()
+		if err == nil {
+			r.connMtx.Lock()
+			r.conn = conn
+			r.connMtx.Unlock()
+			// Close might have missed the new connection.
+			if r.IsClosed() {
+				conn.Close()
+				return nil
+			}
+			return conn
+		}
+		r.Log().WithError(err).WithField("backoff", backoff).Debug("Reconnecting failed")
+		if backoff *= 2; backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+}
+
+// restore resubscribes and sends all pending transactions again after
+// reconnecting. Transactions are sent in nonce order. Transactions of the
+// subscribed account whose nonce is below the account's next nonce were
+// already delivered before the connection dropped, their calls succeed without
+// sending them again.
+func (r *RPC) restore() {
+	r.mtx.Lock()
+	auth := r.subAuth
+	calls := make([]*wire.SendTx, 0, len(r.pending))
+	for _, p := range r.pending {
+		calls = append(calls, p.call)
+	}
+	r.mtx.Unlock()
+
+	// Account queries require the subscription.
+	var (
+		who  common.Address
+		next uint64 // 0: unknown, all transactions are sent.
+	)
+	if auth != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), resubscribeTimeout)
+		defer cancel()
+		who = auth.account.Address
+		if err := r.subscribe(ctx, auth.account, auth.signer); err != nil {
+			r.Log().WithError(err).Error("Resubscribing")
+		} else if next, err = r.GetNonce(ctx, who); err != nil {
+			r.Log().WithError(err).Warn("Querying nonce, replaying all txs")
+		}
+	}
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Tx.Nonce < calls[j].Tx.Nonce })
+	for _, call := range calls {
+		if call.Tx.Sender == who && call.Tx.Nonce < next {
+			r.Log().WithField("id", call.ID).Debug("Tx already delivered")
+			r.callCallback(wire.Result{ID: call.ID}, nil)
+			continue
+		}
+		if err := r.sendJSON(call); err != nil {
+			r.Log().WithError(err).WithField("id", call.ID).Warn("Replaying tx")
+		}
+	}
+	r.Log().WithField("txs", len(calls)).Info("Reconnected to operator")
+	r.setConnState(Connected)
+}
+
+// addPendingTx returns the pending call of the transaction. If the transaction
+// is not pending yet, a new call is created and isNew is true.
+func (r *RPC) addPendingTx(tx tee.Transaction) (p *pendingTx, isNew bool) {
+	hash := tx.Hash()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if p, ok := r.pending[hash]; ok {
+		return p, false
+	}
+
+	p = &pendingTx{call: wire.NewSendTx(r.nextID(), tx), done: make(chan struct{})}
+	r.pending[hash] = p
+	r.registerCallback(p.call.ID, func(result wire.Result, _ []byte) {
+		if result.Error != "" {
+			p.err = fmt.Errorf("SendTx RPC result: %s", result.Error)
+		}
+		r.mtx.Lock()
+		delete(r.pending, hash)
+		r.mtx.Unlock()
+		close(p.done)
+	})
+	return p, true
+}
+
+// failCalls fails all calls that wait for a result, except for pending
+// transactions, which are sent again after reconnecting.
+func (r *RPC) failCalls() {
+	r.mtx.Lock()
+	replayed := make(map[wire.ID]bool, len(r.pending))
+	for _, p := range r.pending {
+		replayed[p.call.ID] = true
+	}
+	r.mtx.Unlock()
+
+	r.cbMtx.Lock()
+	failed := make(map[wire.ID]callback)
+	for id, cb := range r.callbacks {
+		if !replayed[id] {
+			failed[id] = cb
+			delete(r.callbacks, id)
+		}
+	}
+	r.cbMtx.Unlock()
+
+	for id, cb := range failed {
+		cb(wire.Result{ID: id, Error: "connection to operator lost"}, nil)
+	}
+}
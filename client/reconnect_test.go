@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	pkgtest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/client"
+	"github.com/perun-network/erdstall/config"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/operator"
+	optest "github.com/perun-network/erdstall/operator/test"
+	"github.com/perun-network/erdstall/tee"
+	ttest "github.com/perun-network/erdstall/tee/test"
+)
+
+// TestRPC_Reconnect tests that the RPC reconnects after the connection to the
+// operator drops, restores the subscription and sends pending transactions
+// again. The connection runs over a proxy that can drop it:
+// Client <-> RPC <-> proxy <-> MockedRPCOperator <-> OP
+func TestRPC_Reconnect(t *testing.T) {
+	rng := pkgtest.Prng(t)
+	enclave := optest.NewMockedEnclave()
+	params := tee.Parameters{PhaseDuration: 10, Contract: eth.NewRandomAddress(rng)}
+	op := optest.NewRPROperator(enclave, params)
+	op.Run()
+	rpcServer := operator.NewRPC(op, operator.OpServerConfig{
+		Port:         opRPCPort + 1,
+		ClientConfig: config.OpClientConfig{Contract: params.Contract},
+	})
+	go func() {
+		if err := rpcServer.Serve(); err != nil {
+			panic(err)
+		}
+	}()
+	defer rpcServer.Close()
+	time.Sleep(shortWait)
+
+	proxy := newDropProxy(t, fmt.Sprintf("127.0.0.1:%d", opRPCPort+1))
+	defer proxy.Close()
+	rpcClient, err := client.NewRPC("127.0.0.1", proxy.Port())
+	require.NoError(t, err)
+	defer rpcClient.Close()
+	states := make(chan client.ConnState, 10)
+	rpcClient.OnConnState(func(s client.ConnState) { states <- s })
+	requireState := func(s client.ConnState) {
+		select {
+		case got := <-states:
+			require.Equal(t, s, got)
+		case <-time.After(longWait):
+			t.Fatalf("timed out waiting for state %v", s)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), longWait)
+	defer cancel()
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(t, err)
+	acc, err := w.NewAccount()
+	require.NoError(t, err)
+	sub, err := rpcClient.Subscribe(ctx, acc.Account, hdw)
+	require.NoError(t, err)
+
+	t.Run("replay-pending-tx", func(t *testing.T) {
+		proxy.SetDown(true)
+		requireState(client.Disconnected)
+
+		// The same tx is sent twice while disconnected.
+		tx := ttest.RandomTx(t, rng)
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() { errs <- rpcClient.SendTx(ctx, *tx) }()
+		}
+		time.Sleep(shortWait)
+		proxy.SetDown(false)
+		requireState(client.Connected)
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, <-errs)
+		}
+		assert.Equal(t, tx, <-enclave.Transactions())
+		select {
+		case <-enclave.Transactions():
+			t.Error("tx was sent twice")
+		case <-time.After(shortWait):
+		}
+	})
+
+	t.Run("skip-delivered-tx", func(t *testing.T) {
+		proxy.SetDown(true)
+		requireState(client.Disconnected)
+
+		// The operator already processed the tx, its next nonce is higher.
+		tx := ttest.RandomTx(t, rng)
+		tx.Sender, tx.Nonce = acc.Account.Address, 5
+		enclave.SetAccount(acc.Account.Address, &tee.Account{Nonce: 5, Value: (*tee.Amount)(big.NewInt(0))})
+		errs := make(chan error, 1)
+		go func() { errs <- rpcClient.SendTx(ctx, *tx) }()
+		time.Sleep(shortWait)
+		proxy.SetDown(false)
+		requireState(client.Connected)
+
+		require.NoError(t, <-errs)
+		select {
+		case <-enclave.Transactions():
+			t.Error("delivered tx was sent again")
+		case <-time.After(shortWait):
+		}
+	})
+
+	t.Run("resubscribed", func(t *testing.T) {
+		bp := ttest.RandomBP(rng)
+		bp.Balance.Account = acc.Account.Address
+		enclave.PushBalanceProof(bp)
+		proof, err := sub.BalanceProof(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, *bp, proof)
+	})
+
+	t.Run("fail-calls", func(t *testing.T) {
+		proxy.SetDown(true)
+		// Calls that are not replayed fail when the connection drops.
+		_, err := rpcClient.GetParams(ctx)
+		assert.Error(t, err)
+		requireState(client.Disconnected)
+		proxy.SetDown(false)
+		requireState(client.Connected)
+		got, err := rpcClient.GetParams(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, params, got)
+	})
+}
+
+// dropProxy forwards TCP connections to a target and can drop them to
+// simulate connection losses.
+type dropProxy struct {
+	listener net.Listener
+	target   string
+
+	mtx   sync.Mutex // protects conns and down.
+	conns []net.Conn
+	down  bool // Whether new connections are refused.
+}
+
+func newDropProxy(t *testing.T, target string) *dropProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &dropProxy{listener: listener, target: target}
+	go p.serve()
+	return p
+}
+
+// Port returns the port that the proxy listens on.
+func (p *dropProxy) Port() uint16 {
+	return uint16(p.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (p *dropProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.mtx.Lock()
+		if p.down {
+			conn.Close()
+			p.mtx.Unlock()
+			continue
+		}
+		target, err := net.Dial("tcp", p.target)
+		if err != nil {
+			conn.Close()
+			p.mtx.Unlock()
+			continue
+		}
+		p.conns = append(p.conns, conn, target)
+		p.mtx.Unlock()
+		go pipe(conn, target)
+		go pipe(target, conn)
+	}
+}
+
+func pipe(dst, src net.Conn) {
+	io.Copy(dst, src) // nolint: errcheck
+	dst.Close()
+	src.Close()
+}
+
+// SetDown drops all connections and refuses new ones if down is true.
+func (p *dropProxy) SetDown(down bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.down = down
+	if !down {
+		return
+	}
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+}
+
+func (p *dropProxy) Close() error {
+	p.SetDown(true)
+	return p.listener.Close()
+}
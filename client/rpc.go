@@ -10,8 +10,8 @@ import (
 	"strconv"
 	"sync"
 	"sync/atomic"
-	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	gorilla "github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
@@ -23,13 +23,21 @@ import (
 )
 
 type (
-	// RPC connects the client with the operator over websockets.
+	// RPC connects the client with the operator over websockets. If the
+	// connection drops, it reconnects with exponential backoff, restores the
+	// subscription and sends pending transactions again.
 	RPC struct {
 		pkgsync.Closer
+		url string // The operator's websocket URL.
 
-		connMtx sync.Mutex // protects conn.send.
+		connMtx sync.Mutex // protects conn.send and replacing conn.
 		conn    *gorilla.Conn
 
+		mtx         sync.Mutex // protects onConnState, subAuth and pending.
+		onConnState func(ConnState)
+		subAuth     *subscriptionAuth // nil if not subscribed.
+		pending     map[common.Hash]*pendingTx
+
 		cbMtx     sync.RWMutex // protects callbacks.
 		id        uint64
 		callbacks map[wire.ID]callback
@@ -56,29 +64,31 @@ type (
 
 // NewRPC returns a new RPC object.
 // RPC immediately tries to connect to the operator and starts to handle
-// incomming data.
+// incomming data. Only the first connection attempt can fail, later
+// connection losses are handled by reconnecting until Close is called.
 // You may want to call Subscribe afterwards if you need balance and/or
 // deposit proofs.
 func NewRPC(host string, port uint16) (*RPC, error) {
 	u := url.URL{Scheme: "ws", Host: fmt.Sprintf("%s:%d", host, port), Path: "/ws"}
-	conn, _, err := gorilla.DefaultDialer.Dial(u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
 	rpc := &RPC{
-		conn:      conn,
+		url:       u.String(),
 		callbacks: make(map[wire.ID]callback),
+		pending:   make(map[common.Hash]*pendingTx),
 		hasConfig: make(chan struct{}),
 	}
-	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+	conn, err := rpc.dial()
+	if err != nil {
 		return nil, err
 	}
-	go func() {
-		err := rpc.handleConnections()
-		rpc.Log().WithError(err).Debug("RPC connection handler returned.")
-		err = rpc.Close()
-		rpc.Log().WithError(err).Debug("Stopped RPC client.")
-	}()
+	rpc.conn = conn
+	if !rpc.OnClose(func() {
+		rpc.connMtx.Lock()
+		defer rpc.connMtx.Unlock()
+		rpc.conn.Close()
+	}) {
+		panic("Could not add OnClose function")
+	}
+	go rpc.run(conn)
 
 	return rpc, nil
 }
@@ -93,14 +103,77 @@ func (r *RPC) Log() *log.Entry {
 	return log.WithField("role", "client")
 }
 
-// SendTx sends one transaction to the operator.
+// SendTx sends one transaction to the operator. If the connection drops
+// before the result arrived, the transaction is sent again after reconnecting.
+// Concurrent calls for the same transaction send it only once.
 func (r *RPC) SendTx(ctx context.Context, tx tee.Transaction) error {
-	call := wire.NewSendTx(r.nextID(), tx)
-	errChan := make(chan error)
+	p, isNew := r.addPendingTx(tx)
+	if isNew {
+		if err := r.sendJSON(p.call); err != nil {
+			// Sent again after reconnecting.
+			r.Log().WithError(err).WithField("id", p.call.ID).Debug("Sending tx failed")
+		}
+	}
+	// Return error from async response cb.
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendBatchTx sends one batch transaction to the operator.
+func (r *RPC) SendBatchTx(ctx context.Context, tx tee.BatchTransaction) error {
+	call := wire.NewSendBatchTx(r.nextID(), tx)
+	errChan := make(chan error, 1)
 	// Setup async response cb.
 	r.registerCallback(call.Call.ID, func(result wire.Result, msg []byte) {
 		if result.Error != "" {
-			errChan <- fmt.Errorf("SendTx RPC result: %s", result.Error)
+			errChan <- fmt.Errorf("SendBatchTx RPC result: %s", result.Error)
+		} else {
+			errChan <- nil
+		}
+	})
+	// Make the call.
+	if err := r.sendJSON(call); err != nil {
+		return fmt.Errorf("sending json object: %w", err)
+	}
+	// Return error from async response cb.
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LockHTLC sends a hash-time-locked payment to the operator.
+func (r *RPC) LockHTLC(ctx context.Context, htlc tee.HTLC) error {
+	call := wire.NewLockHTLC(r.nextID(), htlc)
+	return r.callNoResult(ctx, "LockHTLC", call.Call.ID, call)
+}
+
+// ClaimHTLC sends a claim of a hash-time-locked payment to the operator.
+func (r *RPC) ClaimHTLC(ctx context.Context, claim tee.HTLCClaim) error {
+	call := wire.NewClaimHTLC(r.nextID(), claim)
+	return r.callNoResult(ctx, "ClaimHTLC", call.Call.ID, call)
+}
+
+// RefundHTLC sends a refund of an expired hash-time-locked payment to the
+// operator.
+func (r *RPC) RefundHTLC(ctx context.Context, refund tee.HTLCRefund) error {
+	call := wire.NewRefundHTLC(r.nextID(), refund)
+	return r.callNoResult(ctx, "RefundHTLC", call.Call.ID, call)
+}
+
+// callNoResult makes a call whose result only contains a possible error.
+func (r *RPC) callNoResult(ctx context.Context, name string, id wire.ID, call interface{}) error {
+	errChan := make(chan error, 1)
+	// Setup async response cb.
+	r.registerCallback(id, func(result wire.Result, msg []byte) {
+		if result.Error != "" {
+			errChan <- fmt.Errorf("%s RPC result: %s", name, result.Error)
 		} else {
 			errChan <- nil
 		}
@@ -122,7 +195,11 @@ func (r *RPC) SendTx(ctx context.Context, tx tee.Transaction) error {
 // The user must always read the proofs via `DepositProof` and
 // `BalanceProof`. Calling this function more than once if it did not
 // error will cause undefined behaviour.
-func (r *RPC) Subscribe(ctx context.Context, user common.Address) (*Subscription, error) {
+//
+// The operator only accepts subscriptions from the owner of the account, so
+// Subscribe requests a challenge from the operator and signs it with the
+// account. After reconnecting, the RPC subscribes again with the same account.
+func (r *RPC) Subscribe(ctx context.Context, account accounts.Account, signer tee.TextSigner) (*Subscription, error) {
 	r.subscription = &Subscription{
 		// Buffer the proofs here, otherwise the client has to read them
 		// immediately to prevent that they get reordered by a race condition
@@ -132,36 +209,151 @@ func (r *RPC) Subscribe(ctx context.Context, user common.Address) (*Subscription
 		depProofs:  make(chan tee.DepositProof, 10),
 		txReceipts: make(chan tee.Transaction, 10),
 	}
+	if err := r.subscribe(ctx, account, signer); err != nil {
+		return nil, err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.subAuth = &subscriptionAuth{account: account, signer: signer}
+	return r.subscription, nil
+}
+
+// subscribe performs the subscription handshake on the current connection.
+func (r *RPC) subscribe(ctx context.Context, account accounts.Account, signer tee.TextSigner) error {
+	challenge, err := r.getChallenge(ctx)
+	if err != nil {
+		return fmt.Errorf("getting challenge: %w", err)
+	}
+	req := tee.SubscribeRequest{Who: account.Address, Challenge: challenge}
+	if err := req.Sign(r.ClientCfg().Contract, account, signer); err != nil {
+		return fmt.Errorf("signing subscribe request: %w", err)
+	}
+
+	call := wire.NewSubscribe(r.nextID(), req)
+	return r.callNoResult(ctx, "Subscribe", call.Call.ID, call)
+}
+
+// getChallenge requests a subscription challenge from the operator.
+func (r *RPC) getChallenge(ctx context.Context) (common.Hash, error) {
+	call := wire.NewGetChallenge(r.nextID())
+	var res wire.Challenge
+	err := r.callWithResult(ctx, "GetChallenge", call.Call.ID, call, &res)
+	return res.Challenge, err
+}
 
-	call := wire.NewSubscribe(r.nextID(), user)
-	errChan := make(chan error)
+// RequestExit requests an exit proof for a partial exit from the operator.
+func (r *RPC) RequestExit(ctx context.Context, req tee.ExitRequest) (tee.BalanceProof, error) {
+	call := wire.NewRequestExit(r.nextID(), req)
+	type exitProofResult struct {
+		proof tee.BalanceProof
+		err   error
+	}
+	resChan := make(chan exitProofResult, 1)
 	// Setup async response cb.
 	r.registerCallback(call.Call.ID, func(result wire.Result, msg []byte) {
 		if result.Error != "" {
-			errChan <- fmt.Errorf("Subscribe RPC result: %s", result.Error)
+			resChan <- exitProofResult{err: fmt.Errorf("RequestExit RPC result: %s", result.Error)}
+			return
+		}
+		var res wire.ExitProof
+		if err := json.Unmarshal(msg, &res); err != nil {
+			resChan <- exitProofResult{err: fmt.Errorf("decoding exit proof: %w", err)}
+			return
+		}
+		resChan <- exitProofResult{proof: res.Proof}
+	})
+	// Make the call.
+	if err := r.sendJSON(call); err != nil {
+		return tee.BalanceProof{}, fmt.Errorf("sending json object: %w", err)
+	}
+	// Return result from async response cb.
+	select {
+	case res := <-resChan:
+		return res.proof, res.err
+	case <-ctx.Done():
+		return tee.BalanceProof{}, ctx.Err()
+	}
+}
+
+// GetBalance queries the current off-chain state of the account, including
+// its ETH and token balances.
+func (r *RPC) GetBalance(ctx context.Context, who common.Address) (tee.Account, error) {
+	call := wire.NewGetBalance(r.nextID(), who)
+	var res wire.Balance
+	err := r.callWithResult(ctx, "GetBalance", call.Call.ID, call, &res)
+	return res.Account, err
+}
+
+// GetNonce queries the next transaction nonce of the account.
+func (r *RPC) GetNonce(ctx context.Context, who common.Address) (uint64, error) {
+	call := wire.NewGetNonce(r.nextID(), who)
+	var res wire.Nonce
+	err := r.callWithResult(ctx, "GetNonce", call.Call.ID, call, &res)
+	return res.Nonce, err
+}
+
+// GetBalanceProof queries the balance proof of the account's token at the end
+// of the given transaction epoch.
+func (r *RPC) GetBalanceProof(ctx context.Context, who, token common.Address, epoch tee.Epoch) (tee.BalanceProof, error) {
+	call := wire.NewGetBalanceProof(r.nextID(), who, token, epoch)
+	var res wire.BalanceProof
+	err := r.callWithResult(ctx, "GetBalanceProof", call.Call.ID, call, &res)
+	return res.Proof, err
+}
+
+// GetDepositProof queries the deposit proof of the account's token deposits
+// in the given epoch.
+func (r *RPC) GetDepositProof(ctx context.Context, who, token common.Address, epoch tee.Epoch) (tee.DepositProof, error) {
+	call := wire.NewGetDepositProof(r.nextID(), who, token, epoch)
+	var res wire.DepositProof
+	err := r.callWithResult(ctx, "GetDepositProof", call.Call.ID, call, &res)
+	return res.Proof, err
+}
+
+// GetParams queries the enclave parameters.
+func (r *RPC) GetParams(ctx context.Context) (tee.Parameters, error) {
+	call := wire.NewGetParams(r.nextID())
+	var res wire.Params
+	err := r.callWithResult(ctx, "GetParams", call.Call.ID, call, &res)
+	return res.Params, err
+}
+
+// callWithResult makes a call and decodes its result into res.
+func (r *RPC) callWithResult(ctx context.Context, name string, id wire.ID, call, res interface{}) error {
+	errChan := make(chan error, 1)
+	// Setup async response cb.
+	r.registerCallback(id, func(result wire.Result, msg []byte) {
+		if result.Error != "" {
+			errChan <- fmt.Errorf("%s RPC result: %s", name, result.Error)
+		} else if err := json.Unmarshal(msg, res); err != nil {
+			errChan <- fmt.Errorf("decoding %s result: %w", name, err)
 		} else {
 			errChan <- nil
 		}
 	})
 	// Make the call.
 	if err := r.sendJSON(call); err != nil {
-		return nil, fmt.Errorf("sending json object: %w", err)
+		return fmt.Errorf("sending json object: %w", err)
 	}
 	// Return error from async response cb.
 	select {
 	case err := <-errChan:
-		return r.subscription, err
+		return err
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return ctx.Err()
 	}
 }
 
-func (r *RPC) handleConnections() error {
+// handleConnection handles the incoming messages of one connection until it
+// fails. Every connection starts with the operator's config, which is only
+// stored for the first connection.
+func (r *RPC) handleConnection(conn *gorilla.Conn) error {
 	hasConfig := false
 
 	for !r.IsClosed() {
 		// gorilla has no async read method?!
-		_, data, err := r.conn.ReadMessage()
+		_, data, err := conn.ReadMessage()
 		if err != nil {
 			return fmt.Errorf("reading ws message: %w", err)
 		}
@@ -183,9 +375,13 @@ func (r *RPC) handleConnections() error {
 				r.Log().WithError(err).Error("decoding config message")
 				continue
 			}
-			r.clientCfg = msg.Config
 			hasConfig = true
-			close(r.hasConfig)
+			select {
+			case <-r.hasConfig:
+			default:
+				r.clientCfg = msg.Config
+				close(r.hasConfig)
+			}
 			continue
 		}
 
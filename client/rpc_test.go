@@ -9,12 +9,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
 	pkgtest "perun.network/go-perun/pkg/test"
 
 	"github.com/perun-network/erdstall/client"
 	"github.com/perun-network/erdstall/config"
+	"github.com/perun-network/erdstall/eth"
 	"github.com/perun-network/erdstall/operator"
 	optest "github.com/perun-network/erdstall/operator/test"
 	"github.com/perun-network/erdstall/tee"
@@ -35,12 +38,17 @@ const opRPCPort = 8401
 func TestRPC_ClientOp(t *testing.T) {
 	rng := pkgtest.Prng(t)
 	enclave := optest.NewMockedEnclave()
-	op := optest.NewRPROperator(enclave)
+	params := tee.Parameters{
+		PhaseDuration: 10,
+		TEE:           ttest.RandomDP(rng).Balance.Account,
+		Contract:      ttest.RandomDP(rng).Balance.Account,
+	}
+	op := optest.NewRPROperator(enclave, params)
 	op.Run()
 	osc := operator.OpServerConfig{
 		Host:         "",
 		Port:         opRPCPort,
-		ClientConfig: config.OpClientConfig{},
+		ClientConfig: config.OpClientConfig{Contract: params.Contract},
 	}
 	rpcServer := operator.NewRPC(op, osc)
 	go func() {
@@ -72,9 +80,63 @@ func TestRPC_ClientOp(t *testing.T) {
 		assert.Equal(t, tx, tx2)
 	})
 
+	t.Run("SendBatchTx-ok", func(t *testing.T) {
+		tx := tee.BatchTransaction{
+			Sender: ttest.RandomDP(rng).Balance.Account,
+			Payments: []tee.Payment{{
+				Recipient: ttest.RandomDP(rng).Balance.Account,
+				Amount:    (*tee.Amount)(big.NewInt(7)),
+			}},
+			Sig: ttest.RandomSig(rng),
+		}
+		require.NoError(t, rpcClient.SendBatchTx(ctx, tx))
+		tx2 := <-enclave.BatchTransactions()
+		assert.Equal(t, tx.Payments, tx2.Payments)
+	})
+
+	t.Run("HTLC-ok", func(t *testing.T) {
+		h := tee.HTLC{
+			Sender:    ttest.RandomDP(rng).Balance.Account,
+			Recipient: ttest.RandomDP(rng).Balance.Account,
+			Amount:    (*tee.Amount)(big.NewInt(7)),
+			Sig:       ttest.RandomSig(rng),
+		}
+		require.NoError(t, rpcClient.LockHTLC(ctx, h))
+		assert.Equal(t, h.Recipient, (<-enclave.HTLCOps()).Lock.Recipient)
+
+		claim := tee.HTLCClaim{ID: h.ID()}
+		require.NoError(t, rpcClient.ClaimHTLC(ctx, claim))
+		assert.Equal(t, claim, *(<-enclave.HTLCOps()).Claim)
+
+		refund := tee.HTLCRefund{ID: h.ID()}
+		require.NoError(t, rpcClient.RefundHTLC(ctx, refund))
+		assert.Equal(t, refund, *(<-enclave.HTLCOps()).Refund)
+	})
+
+	t.Run("RequestExit", func(t *testing.T) {
+		req := tee.ExitRequest{
+			Epoch:   3,
+			Account: ttest.RandomDP(rng).Balance.Account,
+			Value:   (*tee.Amount)(big.NewInt(42)),
+			Sig:     ttest.RandomSig(rng),
+		}
+		proof, err := rpcClient.RequestExit(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.Balance(), proof.Balance)
+	})
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(t, err)
+	acc1, err := w.NewAccount()
+	require.NoError(t, err)
+	acc2, err := w.NewAccount()
+	require.NoError(t, err)
+
 	dp := ttest.RandomDP(rng)
 	bp := ttest.RandomBP(rng)
-	user1 := dp.Balance.Account
+	user1 := acc1.Account.Address
+	dp.Balance.Account = user1
 	bp.Balance.Account = user1
 	var sub *client.Subscription
 
@@ -91,15 +153,21 @@ func TestRPC_ClientOp(t *testing.T) {
 
 	t.Run("Subscribe-error", func(t *testing.T) {
 		op.SetSubscribeProofsError(myErr)
-		_, err := rpcClient.Subscribe(ctx, user1)
+		_, err := rpcClient.Subscribe(ctx, acc1.Account, hdw)
 		assert.Error(t, err)
 		op.SetSubscribeProofsError(nil)
 	})
 
+	t.Run("Subscribe-unauthenticated", func(t *testing.T) {
+		// Signs user1's subscription with the key of acc2.
+		_, err := rpcClient.Subscribe(ctx, acc1.Account, alienSigner{hdw, acc2.Account})
+		assert.Error(t, err)
+	})
+
 	// Wait for the OP to process the enclave's proofs.
 	time.Sleep(shortWait)
 	t.Run("Subscribe-ok", func(t *testing.T) {
-		sub, err = rpcClient.Subscribe(ctx, user1)
+		sub, err = rpcClient.Subscribe(ctx, acc1.Account, hdw)
 		assert.NoError(t, err)
 		require.NotNil(t, sub)
 	})
@@ -136,6 +204,60 @@ func TestRPC_ClientOp(t *testing.T) {
 		assert.Equal(t, *dp, proof)
 	})
 
+	t.Run("GetBalance", func(t *testing.T) {
+		acc := tee.Account{
+			Epoch: 7,
+			Nonce: 3,
+			Value: (*tee.Amount)(big.NewInt(42)),
+		}
+		enclave.SetAccount(user1, &acc)
+		got, err := rpcClient.GetBalance(ctx, user1)
+		require.NoError(t, err)
+		assert.Equal(t, acc, got)
+
+		nonce, err := rpcClient.GetNonce(ctx, user1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), nonce)
+
+		_, err = rpcClient.GetBalance(ctx, acc2.Account.Address)
+		assert.Error(t, err, "other account")
+		_, err = rpcClient.GetNonce(ctx, acc2.Account.Address)
+		assert.Error(t, err, "other account")
+	})
+
+	t.Run("GetProofs", func(t *testing.T) {
+		proof, err := rpcClient.GetBalanceProof(ctx, user1, tee.ETHToken, 1234)
+		require.NoError(t, err)
+		assert.Equal(t, *bp, proof)
+		dproof, err := rpcClient.GetDepositProof(ctx, user1, tee.ETHToken, 1234)
+		require.NoError(t, err)
+		assert.Equal(t, *dp, dproof)
+
+		_, err = rpcClient.GetBalanceProof(ctx, user1, tee.ETHToken, 1235)
+		assert.Error(t, err)
+		_, err = rpcClient.GetBalanceProof(ctx, acc2.Account.Address, tee.ETHToken, 1234)
+		assert.Error(t, err, "other account")
+		_, err = rpcClient.GetDepositProof(ctx, acc2.Account.Address, tee.ETHToken, 1234)
+		assert.Error(t, err, "other account")
+	})
+
+	t.Run("GetParams", func(t *testing.T) {
+		got, err := rpcClient.GetParams(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, params, got)
+	})
+
 	assert.NoError(t, rpcClient.Close())
 	assert.NoError(t, rpcServer.Close())
 }
+
+// alienSigner signs all texts with its account, irrespective of the requested
+// account.
+type alienSigner struct {
+	w       accounts.Wallet
+	account accounts.Account
+}
+
+func (s alienSigner) SignText(_ accounts.Account, text []byte) ([]byte, error) {
+	return s.w.SignText(s.account, text)
+}
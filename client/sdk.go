@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// The typed API of the Client. In contrast to the Cmd* methods, which are
+// made for the GUI, it can be used to embed Erdstall payments into other
+// programs. Progress is reported to the observers as STATUS events.
+
+type (
+	// An Observer is notified of all client events.
+	Observer interface {
+		OnEvent(*Event)
+	}
+
+	// ObserverFunc is a function that implements Observer.
+	ObserverFunc func(*Event)
+
+	// DepositResult describes a deposit.
+	DepositResult struct {
+		TxHash     common.Hash       // Deposit transaction.
+		Block      uint64            // Block in which the deposit was mined.
+		Epoch      uint64            // Deposit epoch.
+		Proof      *tee.DepositProof // Of all deposits of the epoch, nil if the operator sent no valid proof.
+		Challenged bool              // Whether the operator was challenged.
+	}
+
+	// HistoryType is the type of a HistoryEntry.
+	HistoryType = string
+
+	// HistoryEntry describes an operation of the client.
+	HistoryEntry struct {
+		Time   time.Time
+		Type   HistoryType
+		Epoch  uint64
+		Token  common.Address
+		Amount *big.Int       // nil if unknown, e.g., for full withdrawals.
+		Peer   common.Address // Recipient of sent, sender of received transfers.
+	}
+)
+
+// History types.
+const (
+	HistoryDeposit  HistoryType = "deposit"
+	HistoryTransfer HistoryType = "transfer"
+	HistoryReceive  HistoryType = "receive"
+	HistoryExit     HistoryType = "exit"
+	HistoryWithdraw HistoryType = "withdraw"
+)
+
+// ErrNotReady is returned by the typed API before the client knows the
+// current block, see Ready.
+var ErrNotReady = errors.New("client not ready")
+
+// OnEvent calls f(e).
+func (f ObserverFunc) OnEvent(e *Event) { f(e) }
+
+// AddObserver adds an observer that is notified of all events. Observers are
+// called synchronously and must not block.
+func (c *Client) AddObserver(o Observer) {
+	c.obsMtx.Lock()
+	defer c.obsMtx.Unlock()
+	c.observers = append(c.observers, o)
+}
+
+// Ready returns a channel that is closed once the client is running and knows
+// the current block.
+func (c *Client) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// Deposit deposits the amount of wei and waits for the deposit proof. If the
+// operator sends no valid proof, the deposit is challenged.
+func (c *Client) Deposit(ctx context.Context, amount *big.Int) (*DepositResult, error) {
+	return c.DepositToken(ctx, tee.ETHToken, amount)
+}
+
+// DepositToken deposits the amount of the ERC-20 token and waits for the
+// deposit proof. The contract is approved to transfer the amount first.
+func (c *Client) DepositToken(ctx context.Context, token common.Address, amount *big.Int) (*DepositResult, error) {
+	if err := c.checkReady(); err != nil {
+		return nil, err
+	}
+	status, done := c.forwardStatus()
+	defer done()
+	return c.deposit(ctx, token, amount, status)
+}
+
+// Transfer sends the amount of wei to the recipient.
+func (c *Client) Transfer(ctx context.Context, to common.Address, amount *big.Int) (*tee.Transaction, error) {
+	return c.TransferToken(ctx, tee.ETHToken, to, amount)
+}
+
+// TransferToken sends the amount of the ERC-20 token to the recipient.
+func (c *Client) TransferToken(ctx context.Context, token, to common.Address, amount *big.Int) (*tee.Transaction, error) {
+	if err := c.checkReady(); err != nil {
+		return nil, err
+	}
+	return c.transfer(ctx, token, to, amount)
+}
+
+// Exit exits and withdraws the whole balance, including all tokens. It needs
+// the balance proof of the current exit epoch.
+func (c *Client) Exit(ctx context.Context) error {
+	if err := c.checkReady(); err != nil {
+		return err
+	}
+	status, done := c.forwardStatus()
+	defer done()
+	return c.exit(ctx, status)
+}
+
+// ExitAmount exits and withdraws the given amount of ETH or a token. The rest
+// of the balance stays in the system.
+func (c *Client) ExitAmount(ctx context.Context, token common.Address, amount *big.Int) error {
+	if err := c.checkReady(); err != nil {
+		return err
+	}
+	status, done := c.forwardStatus()
+	defer done()
+	return c.exitAmount(ctx, token, amount, status)
+}
+
+// Balance returns the ETH balance of the latest balance proof, or nil if no
+// balance proof was received yet.
+func (c *Client) Balance() *EpochBalance {
+	c.balMtx.RLock()
+	defer c.balMtx.RUnlock()
+	var latest *EpochBalance
+	for _, bal := range c.balances {
+		bal := bal
+		if bal.Bal != nil && (latest == nil || bal.Epoch > latest.Epoch) {
+			latest = &bal
+		}
+	}
+	return latest.Clone()
+}
+
+// TokenBalance returns the balance of the token of the latest balance proof,
+// or nil if no balance proof was received yet.
+func (c *Client) TokenBalance(token common.Address) *EpochBalance {
+	if token == tee.ETHToken {
+		return c.Balance()
+	}
+	c.balMtx.RLock()
+	defer c.balMtx.RUnlock()
+	var latest *EpochBalance
+	for _, bals := range c.tokenBals {
+		if bal, ok := bals[token]; ok && bal.Bal != nil && (latest == nil || bal.Epoch > latest.Epoch) {
+			latest = &bal
+		}
+	}
+	return latest.Clone()
+}
+
+// History returns all operations of the client, oldest first.
+func (c *Client) History() []HistoryEntry {
+	c.histMtx.Lock()
+	defer c.histMtx.Unlock()
+	return append([]HistoryEntry(nil), c.history...)
+}
+
+// emit sends the event to the event channel, if any, and all observers.
+func (c *Client) emit(e *Event) {
+	if c.events != nil {
+		c.events <- e
+	}
+	c.obsMtx.RLock()
+	defer c.obsMtx.RUnlock()
+	for _, o := range c.observers {
+		o.OnEvent(e)
+	}
+}
+
+// record adds the entry to the history and emits it as HISTORY event.
+func (c *Client) record(h HistoryEntry) {
+	h.Time = time.Now()
+	c.histMtx.Lock()
+	c.history = append(c.history, h)
+	c.histMtx.Unlock()
+	c.persist(func(d *walletData) { d.History = append(d.History, h) })
+	c.emit(&Event{Type: HISTORY, History: &h})
+}
+
+// checkReady returns ErrNotReady if the client is not ready.
+func (c *Client) checkReady() error {
+	select {
+	case <-c.ready:
+		return nil
+	default:
+		return ErrNotReady
+	}
+}
+
+// forwardStatus returns a status channel whose updates are emitted as STATUS
+// events. The returned function closes the channel and waits until all
+// updates were emitted.
+func (c *Client) forwardStatus() (chan *CmdStatus, func()) {
+	status := make(chan *CmdStatus)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s := range status {
+			c.emit(&Event{Type: STATUS, Status: s})
+		}
+	}()
+	return status, func() {
+		close(status)
+		<-done
+	}
+}
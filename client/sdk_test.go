@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/erdstall/client"
+	"github.com/perun-network/erdstall/config"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestClient_NotReady(t *testing.T) {
+	ctx := context.Background()
+	c := client.NewClient(config.ClientConfig{}, config.OpClientConfig{}, nil, nil, nil, nil)
+	var events []*client.Event
+	c.AddObserver(client.ObserverFunc(func(e *client.Event) { events = append(events, e) }))
+
+	select {
+	case <-c.Ready():
+		t.Fatal("client ready before running")
+	default:
+	}
+	_, err := c.Deposit(ctx, big.NewInt(1))
+	assert.Equal(t, client.ErrNotReady, err)
+	_, err = c.Transfer(ctx, tee.ETHToken, big.NewInt(1))
+	assert.Equal(t, client.ErrNotReady, err)
+	assert.Equal(t, client.ErrNotReady, c.Exit(ctx))
+	assert.Nil(t, c.Balance())
+	assert.Empty(t, c.History())
+	assert.Empty(t, events)
+}
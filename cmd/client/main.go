@@ -3,37 +3,66 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	perunchannel "perun.network/go-perun/backend/ethereum/channel"
-	perunhd "perun.network/go-perun/backend/ethereum/wallet/hd"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/perun-network/erdstall/client"
 	"github.com/perun-network/erdstall/config"
 	"github.com/perun-network/erdstall/eth"
 	"github.com/perun-network/erdstall/gui"
+	"github.com/perun-network/erdstall/headless"
 	"github.com/perun-network/erdstall/wallet"
 )
 
+// Without arguments, the client starts the GUI. Otherwise, the arguments are
+// run as a single command in headless mode, e.g., `client.bin deposit 1.5`.
 func main() {
+	timeout := flag.Duration("timeout", 2*time.Minute, "Headless mode: maximum time to wait for the client and balance proofs.")
 	cfg := config.ParseClientConfig()
+	isHeadless := flag.NArg() > 0
+
 	rpc, err := client.NewRPC(cfg.OpHost, uint16(cfg.OpPort))
 	if err != nil {
+		if isHeadless {
+			os.Exit(headless.SetupFailed(os.Stdout, fmt.Errorf("connecting to the operator: %w", err)))
+		}
 		log.WithError(err).Panicf("Connecting to the operator failed.")
 	}
 
 	ccfg := rpc.ClientCfg()
-	eb, err := ethclient.Dial(cfg.ChainURL(ccfg.NetworkID))
+	eb, err := eth.DialNodes(context.Background(), strings.Split(cfg.ChainURL(ccfg.NetworkID), ","), 0)
+	var chainID *big.Int
+	if err == nil {
+		chainID, err = eth.ChainID(context.Background(), eb)
+	}
 	if err != nil {
+		if isHeadless {
+			os.Exit(headless.SetupFailed(os.Stdout, fmt.Errorf("connecting to the chain: %w", err)))
+		}
 		panic(err)
 	}
 	events := make(chan *client.Event, 10) // GUI event pipe
 
 	wallet := wallet.NewWallet(cfg.Mnemonic, uint(cfg.AccountIndex)) // HD Wallet
-	cb := perunchannel.NewContractBackend(eb, perunhd.NewTransactor(wallet.Wallet.Wallet()))
-	chain := eth.NewClient(cb, wallet.Acc.Account)                    // ETHChain conn
+	if cfg.DBPath == "" {
+		cfg.DBPath = fmt.Sprintf("wallet-%s-%s.json", ccfg.Contract.Hex(), wallet.Acc.Account.Address.Hex())
+	}
+	cb := perunchannel.NewContractBackend(eb, eth.NewDefaultTransactor(wallet.Wallet.Wallet(), chainID), eth.TxFinalityDepth)
+	chain := eth.NewClient(cb, wallet.Acc.Account) // ETHChain conn
+	chain.SetFeePolicy(eth.FeePolicy{MaxFee: cfg.MaxFee, PriorityFee: cfg.PriorityFee, GasPriceBump: cfg.GasPriceBump, GasLimit: cfg.GasLimit})
 	client := client.NewClient(cfg, ccfg, rpc, events, chain, wallet) // Erdstall protocol client
-	go gui.RunGui(client, events)                                     // Run the GUI
+	if isHeadless {
+		os.Exit(headless.Run(client, events, flag.Args(), os.Stdout, *timeout))
+	}
+	go gui.RunGui(client, events) // Run the GUI
 
 	if err := client.Run(); err != nil { // Run the protocol
 		log.WithError(err).Fatal("Client crashed")
@@ -5,9 +5,13 @@ package config
 import (
 	"encoding/json"
 	"flag"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/perun-network/erdstall/tee/attestation"
 )
 
 type ClientConfig struct {
@@ -17,6 +21,18 @@ type ClientConfig struct {
 	Mnemonic     string
 	AccountIndex int
 	UserName     string
+	TxNonce      uint64 // Nonce of the next off-chain transaction, 0: 1.
+	DBPath       string // Wallet database file, empty: in-memory only.
+	MaxFee       uint64 // Maximal fee per gas in wei, 0: unlimited.
+	PriorityFee  uint64 // Priority fee per gas in wei, 0: suggested by the node.
+	GasPriceBump uint64 // Gas price in wei added to the node's suggested legacy gas price.
+	GasLimit     uint64 // Fixed transaction gas limit, 0: estimated per call.
+
+	// The client only deposits if the contract's TEE address is attested by
+	// AttestationSigner with one of the TrustedMeasurements.
+	AttestationSigner   common.Address
+	TrustedMeasurements []common.Hash
+	SkipAttestation     bool // Insecure, for testing only.
 }
 
 // OpClientConfig describes the part of the client's configuration which is sent
@@ -25,19 +41,67 @@ type OpClientConfig struct {
 	NetworkID string         `json:"networkID"` // Network-ID
 	Contract  common.Address `json:"contract"`
 	POWDepth  uint64         `json:"powDepth"`
+	Fees      Fees           `json:"fees"`
+
+	Attestation hexutil.Bytes `json:"attestation"` // Enclave attestation, see tee.Enclave.Init.
+}
+
+// Fees describes the operator's transaction fee schedule, see tee.Parameters.
+type Fees struct {
+	Flat      uint64         `json:"flat"`      // Flat fee in wei.
+	Rate      uint64         `json:"rate"`      // Fee of ETH transfers in basis points.
+	Collector common.Address `json:"collector"` // Account collecting the fees.
 }
 
+// demoAttestationSigner is the address of the attestation service key in the
+// demo operator configuration. Its private key is public, so it is only
+// trusted with -insecure-demo.
+const demoAttestationSigner = "0xF6872d7a46a2ca285615Ad4966478246a7CeD508"
+
 func ParseClientConfig() (cfg ClientConfig) {
-	var urlsJson string
-	flag.StringVar(&urlsJson, "chain-urls", `{"1337": "ws://127.0.0.1:8545"}`, `JSON dictionary {"chainID": Ethereum node URL}`)
+	var urlsJson, signer, measurements string
+	var insecureDemo bool
+	flag.StringVar(&urlsJson, "chain-urls", `{"1337": "ws://127.0.0.1:8545"}`, `JSON dictionary {"chainID": Ethereum node URL}, comma-separated URLs for failover`)
 	flag.StringVar(&cfg.OpHost, "op-host", "127.0.0.1", "IP/host name of operator")
 	flag.IntVar(&cfg.OpPort, "op-port", 8401, "Port of operator.")
 	flag.StringVar(&cfg.Mnemonic, "mnemonic", "pistol kiwi shrug future ozone ostrich match remove crucial oblige cream critic", "Wallet mnemonic.")
 	flag.IntVar(&cfg.AccountIndex, "account-index", 0, "Account derivation index.")
 	flag.StringVar(&cfg.UserName, "username", "<anonymous>", "Set an optional username.")
+	flag.Uint64Var(&cfg.TxNonce, "tx-nonce", 1, "Nonce of the next off-chain transaction.")
+	flag.StringVar(&cfg.DBPath, "db", "", "Wallet database file (default \"wallet-<contract>-<address>.json\").")
+	flag.Uint64Var(&cfg.MaxFee, "max-fee", 0, "Maximal fee per gas in wei (0: unlimited).")
+	flag.Uint64Var(&cfg.PriorityFee, "priority-fee", 0, "Priority fee per gas in wei (0: suggested by the node).")
+	flag.Uint64Var(&cfg.GasPriceBump, "gas-price-bump", 0, "Gas price in wei added to the node's suggested legacy gas price.")
+	flag.Uint64Var(&cfg.GasLimit, "gas-limit", 0, "Fixed transaction gas limit (0: estimated per call).")
+	flag.StringVar(&signer, "attestation-signer", "", "Address of the trusted attestation service (required).")
+	flag.StringVar(&measurements, "trusted-measurements", attestation.PrototypeMeasurement.Hex(), "Comma-separated trusted enclave measurements.")
+	flag.BoolVar(&cfg.SkipAttestation, "skip-attestation", false, "Do not verify the enclave attestation (insecure).")
+	flag.BoolVar(&insecureDemo, "insecure-demo", false, "Trust the demo attestation service, whose key is public (insecure).")
 	flag.Parse()
 
 	cfg.ChainURLs = parseChainURLs(urlsJson)
+	if signer == "" && insecureDemo {
+		signer = demoAttestationSigner
+		log.Warn("Client config: trusting the demo attestation signer (insecure)")
+	}
+	switch {
+	case signer == "" && cfg.SkipAttestation:
+	case signer == "":
+		log.Fatal("Client config: attestation signer required, see -attestation-signer")
+	case !common.IsHexAddress(signer):
+		log.Fatalf("Client config: attestation signer: no hex address: %s", signer)
+	case common.HexToAddress(signer) == common.HexToAddress(demoAttestationSigner) && !insecureDemo:
+		log.Fatal("Client config: the demo attestation signer's key is public, use -insecure-demo to trust it")
+	default:
+		cfg.AttestationSigner = common.HexToAddress(signer)
+	}
+	for _, m := range strings.Split(measurements, ",") {
+		h, err := hexutil.Decode(strings.TrimSpace(m))
+		if err != nil || len(h) != common.HashLength {
+			log.Fatalf("Client config: trusted measurements: invalid measurement: %s", m)
+		}
+		cfg.TrustedMeasurements = append(cfg.TrustedMeasurements, common.BytesToHash(h))
+	}
 
 	return
 }
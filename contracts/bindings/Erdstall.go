@@ -33,11 +33,19 @@ type ErdstallBalance struct {
 	Value   *big.Int
 }
 
+// ErdstallTokenBalance is an auto generated low-level Go binding around an user-defined struct.
+type ErdstallTokenBalance struct {
+	Epoch   uint64
+	Account common.Address
+	Token   common.Address
+	Value   *big.Int
+}
+
 // ECDSAABI is the input ABI used to generate the binding from.
 const ECDSAABI = "[]"
 
 // ECDSABin is the compiled bytecode used for deploying new contracts.
-var ECDSABin = "0x60566023600b82828239805160001a607314601657fe5b30600052607381538281f3fe73000000000000000000000000000000000000000030146080604052600080fdfea26469706673582212200b98ef93566f8dc78bee4ef49b2fe5aa148020ee6fd1682827427527903c9bda64736f6c63430007040033"
+var ECDSABin = "0x60566037600b82828239805160001a607314602a57634e487b7160e01b600052600060045260246000fd5b30600052607381538281f3fe73000000000000000000000000000000000000000030146080604052600080fdfea26469706673582212205e39e745028bdd3113161164b1702fd90c8f9de807ce8477badc297a37ff6bba64736f6c63430008150033"
 
 // DeployECDSA deploys a new Ethereum contract, binding an instance of ECDSA to it.
 func DeployECDSA(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *ECDSA, error) {
@@ -196,34 +204,47 @@ func (_ECDSA *ECDSATransactorRaw) Transact(opts *bind.TransactOpts, method strin
 }
 
 // ErdstallABI is the input ABI used to generate the binding from.
-const ErdstallABI = "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_tee\",\"type\":\"address\"},{\"internalType\":\"uint64\",\"name\":\"_phaseDuration\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"_responseDuration\",\"type\":\"uint64\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"Challenged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Deposited\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Exiting\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"}],\"name\":\"Frozen\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Withdrawn\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"bigBang\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"challenge\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"challengeDeposit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"challenges\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"deposit\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"deposits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"}],\"name\":\"encodeBalanceProof\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"ensureFrozen\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"exit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"exits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"frozenEpoch\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"frozenWithdrawals\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"name\":\"numChallenges\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"phaseDuration\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"responseDuration\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"tee\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"verifyBalance\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"}],\"name\":\"withdraw\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"withdrawChallenge\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"withdrawFrozen\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+const ErdstallABI = "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_tee\",\"type\":\"address\"},{\"internalType\":\"uint64\",\"name\":\"_phaseDuration\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"_responseDuration\",\"type\":\"uint64\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"Challenged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Deposited\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Exiting\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"}],\"name\":\"Frozen\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"TokenChallenged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"TokenDeposited\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"TokenExiting\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"TokenWithdrawn\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Withdrawn\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"bigBang\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"challenge\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"challengeDeposit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.TokenBalance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"challengeToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"challengeTokenDeposit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"challenges\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"deposit\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"depositToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"deposits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"}],\"name\":\"encodeBalanceProof\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.TokenBalance\",\"name\":\"balance\",\"type\":\"tuple\"}],\"name\":\"encodeTokenBalanceProof\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"ensureFrozen\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"exit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.TokenBalance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"exitToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"exits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"frozenEpoch\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"frozenTokenWithdrawals\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"frozenWithdrawals\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"name\":\"numChallenges\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"phaseDuration\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"responseDuration\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"tee\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"tokenChallenges\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"tokenDeposits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"tokenExits\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"verifyBalance\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.TokenBalance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"verifyTokenBalance\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"}],\"name\":\"withdraw\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"withdrawChallenge\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.Balance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"withdrawFrozen\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"internalType\":\"structErdstall.TokenBalance\",\"name\":\"balance\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"withdrawFrozenToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"epoch\",\"type\":\"uint64\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"withdrawToken\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"withdrawTokenChallenge\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
 
 // ErdstallFuncSigs maps the 4-byte function signature to its string representation.
 var ErdstallFuncSigs = map[string]string{
 	"03cf0678": "bigBang()",
 	"778a2707": "challenge((uint64,address,uint256),bytes)",
 	"0d13fd7b": "challengeDeposit()",
+	"10e6312f": "challengeToken((uint64,address,address,uint256),bytes)",
+	"4e6c2ded": "challengeTokenDeposit(address)",
 	"234c49a0": "challenges(uint64,address)",
 	"d0e30db0": "deposit()",
+	"338b5dea": "depositToken(address,uint256)",
 	"9b7c7725": "deposits(uint64,address)",
 	"0b7042d2": "encodeBalanceProof((uint64,address,uint256))",
+	"67a68415": "encodeTokenBalanceProof((uint64,address,address,uint256))",
 	"64c38ddd": "ensureFrozen()",
 	"63a3a27f": "exit((uint64,address,uint256),bytes)",
+	"35b892be": "exitToken((uint64,address,address,uint256),bytes)",
 	"70e4a2c4": "exits(uint64,address)",
 	"585db72a": "frozenEpoch()",
+	"bfe275e9": "frozenTokenWithdrawals(address,address)",
 	"3f48a2a8": "frozenWithdrawals(address)",
 	"f2910773": "numChallenges(uint64)",
 	"ac5553ce": "phaseDuration()",
 	"854b86d9": "responseDuration()",
 	"67eeb62b": "tee()",
+	"e99ce0ad": "tokenChallenges(uint64,address,address)",
+	"547780ce": "tokenDeposits(uint64,address,address)",
+	"91c99dd9": "tokenExits(uint64,address,address)",
 	"a608911d": "verifyBalance((uint64,address,uint256),bytes)",
+	"524a2bb3": "verifyTokenBalance((uint64,address,address,uint256),bytes)",
 	"750f0acc": "withdraw(uint64)",
 	"3de970e3": "withdrawChallenge()",
 	"f4a85043": "withdrawFrozen((uint64,address,uint256),bytes)",
+	"738508c8": "withdrawFrozenToken((uint64,address,address,uint256),bytes)",
+	"ccc40097": "withdrawToken(uint64,address)",
+	"4075d3f2": "withdrawTokenChallenge(address)",
 }
 
 // ErdstallBin is the compiled bytecode used for deploying new contracts.
-var ErdstallBin = "0x610100604052600580546001600160401b0319166002600160401b031790553480156200002b57600080fd5b5060405162001c0738038062001c078339810160408190526200004e91620000e9565b816001600160401b0316816002026001600160401b031611156200008f5760405162461bcd60e51b815260040162000086906200013e565b60405180910390fd5b60609290921b6001600160601b0319166080524360c090811b6001600160c01b031990811660a05291811b821681529190911b1660e05262000175565b80516001600160401b0381168114620000e457600080fd5b919050565b600080600060608486031215620000fe578283fd5b83516001600160a01b038116811462000115578384fd5b92506200012560208501620000cc565b91506200013560408501620000cc565b90509250925092565b60208082526019908201527f726573706f6e73654475726174696f6e20746f6f206c6f6e6700000000000000604082015260600190565b60805160601c60a05160c01c60c05160c01c60e05160c01c611a2d620001da60003980610af65280610fd0525080610b875280610ffa5280611058528061108c525080610382528061102452806110b65250806108695280610b415250611a2d6000f3fe60806040526004361061011f5760003560e01c806370e4a2c4116100a0578063a608911d11610064578063a608911d14610303578063ac5553ce14610323578063d0e30db014610338578063f291077314610340578063f4a85043146103605761011f565b806370e4a2c41461026e578063750f0acc1461028e578063778a2707146102ae578063854b86d9146102ce5780639b7c7725146102e35761011f565b80633f48a2a8116100e75780633f48a2a8146101d5578063585db72a1461020257806363a3a27f1461021757806364c38ddd1461023757806367eeb62b1461024c5761011f565b806303cf0678146101245780630b7042d21461014f5780630d13fd7b1461017c578063234c49a0146101935780633de970e3146101c0575b600080fd5b34801561013057600080fd5b50610139610380565b604051610146919061197c565b60405180910390f35b34801561015b57600080fd5b5061016f61016a36600461145c565b6103a4565b604051610146919061157e565b34801561018857600080fd5b506101916103e0565b005b34801561019f57600080fd5b506101b36101ae36600461152d565b61043f565b6040516101469190611973565b3480156101cc57600080fd5b5061019161045c565b3480156101e157600080fd5b506101f56101f03660046113b7565b6104b9565b6040516101469190611573565b34801561020e57600080fd5b506101396104ce565b34801561022357600080fd5b506101916102323660046113d8565b6104dd565b34801561024357600080fd5b506101916107d3565b34801561025857600080fd5b50610261610867565b604051610146919061155f565b34801561027a57600080fd5b506101b361028936600461152d565b61088b565b34801561029a57600080fd5b506101916102a9366004611513565b6108a8565b3480156102ba57600080fd5b506101916102c93660046113d8565b610a0b565b3480156102da57600080fd5b50610139610af4565b3480156102ef57600080fd5b506101b36102fe36600461152d565b610b18565b34801561030f57600080fd5b5061019161031e366004611477565b610b32565b34801561032f57600080fd5b50610139610b85565b610191610ba9565b34801561034c57600080fd5b506101b361035b366004611513565b610c65565b34801561036c57600080fd5b5061019161037b3660046113d8565b610c77565b7f000000000000000000000000000000000000000000000000000000000000000081565b6060308260000151836020015184604001516040516020016103c99493929190611782565b60405160208183030381529060405290505b919050565b6103e8610d47565b1561040e5760405162461bcd60e51b815260040161040590611812565b60405180910390fd5b610416610d61565b156104335760405162461bcd60e51b81526004016104059061194a565b61043d6000610d97565b565b600260209081526000928352604080842090915290825290205481565b6104646107d3565b6005546001600160401b03908116600101166000908152600260209081526040808320338452909152902054806104ad5760405162461bcd60e51b815260040161040590611839565b6104b681610eb1565b50565b60046020526000908152604090205460ff1681565b6005546001600160401b031681565b6104e5610d47565b156105025760405162461bcd60e51b815260040161040590611812565b61050a610d61565b156105275760405162461bcd60e51b81526004016104059061194a565b61052f610f64565b6001600160401b03166105456020850185611513565b6001600160401b03161461056b5760405162461bcd60e51b81526004016104059061191f565b6105b961057d3685900385018561145c565b83838080601f016020809104026020016040519081016040528093929190818152602001838380828437600092019190915250610b3292505050565b600260006105ca6020860186611513565b6001600160401b03166001600160401b0316815260200190815260200160002060008460200160208101906105ff91906113b7565b6001600160a01b03168152602081019190915260400160002054610659573361062e60408501602086016113b7565b6001600160a01b0316146106545760405162461bcd60e51b815260040161040590611729565b6106f2565b600060028161066b6020870187611513565b6001600160401b03166001600160401b0316815260200190815260200160002060008560200160208101906106a091906113b7565b6001600160a01b03168152602080820192909252604001600090812092909255600391906106d090860186611513565b6001600160401b03168152602081019190915260400160002080546000190190555b6040830135600160006107086020870187611513565b6001600160401b03166001600160401b03168152602001908152602001600020600085602001602081019061073d91906113b7565b6001600160a01b03166001600160a01b031681526020019081526020016000208190555082602001602081019061077491906113b7565b6001600160a01b031661078a6020850185611513565b6001600160401b03167f874e6a4ac09c210cf4cd123caaf949f43c3c6f07f2f46f26ccc5b0fd881c3d0485604001356040516107c69190611973565b60405180910390a3505050565b6107db610d47565b156107e55761043d565b6107ed610d61565b6108095760405162461bcd60e51b8152600401610405906118b1565b60006001610815610f76565b6005805467ffffffffffffffff1916929091036001600160401b0381169283179091556040519092507f5e20151a99b0432a9ac06d33b91b77d3134ce0638cc70d7df042947ca48a2caf90600090a250565b7f000000000000000000000000000000000000000000000000000000000000000081565b600160209081526000928352604080842090915290825290205481565b6108b0610d47565b156108cd5760405162461bcd60e51b815260040161040590611812565b6108d5610d61565b156108f25760405162461bcd60e51b81526004016104059061194a565b6108fa610f64565b6001600160401b0316816001600160401b03161061092a5760405162461bcd60e51b815260040161040590611755565b6001600160401b03811660009081526001602090815260408083203384529091529020548061096b5760405162461bcd60e51b81526004016104059061165f565b6001600160401b038216600090815260016020908152604080832033808552925280832083905551909183156108fc02918491818181858888f193505050501580156109bb573d6000803e3d6000fd5b50336001600160a01b0316826001600160401b03167f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533836040516109ff9190611973565b60405180910390a35050565b610a13610d47565b15610a305760405162461bcd60e51b815260040161040590611812565b610a38610d61565b15610a555760405162461bcd60e51b81526004016104059061194a565b33610a6660408501602086016113b7565b6001600160a01b031614610a8c5760405162461bcd60e51b815260040161040590611696565b610a94610f76565b6001600160401b0316610aaa6020850185611513565b6001600160401b031614610ad05760405162461bcd60e51b8152600401610405906116cd565b610ae261057d3685900385018561145c565b610aef8360400135610d97565b505050565b7f000000000000000000000000000000000000000000000000000000000000000081565b600060208181529281526040808220909352908152205481565b610b65610b3e836103a4565b827f0000000000000000000000000000000000000000000000000000000000000000610f82565b610b815760405162461bcd60e51b8152600401610405906115d1565b5050565b7f000000000000000000000000000000000000000000000000000000000000000081565b610bb1610d47565b15610bce5760405162461bcd60e51b815260040161040590611812565b610bd6610d61565b15610bf35760405162461bcd60e51b81526004016104059061194a565b6000610bfd610fbd565b6001600160401b038116600081815260208181526040808320338085529252918290208054349081019091559151939450927fe007c38a05fbf2010d1c1ed20f91e675c91d41699926124738a8c3fe9fc791b491610c5a91611973565b60405180910390a350565b60036020526000908152604090205481565b610c7f6107d3565b33610c9060408501602086016113b7565b6001600160a01b031614610cb65760405162461bcd60e51b81526004016104059061187a565b6005546001600160401b0316610ccf6020850185611513565b6001600160401b031614610cf55760405162461bcd60e51b8152600401610405906117db565b610d0761057d3685900385018561145c565b6005546001600160401b039081166001011660009081526020818152604080832033845290915290819020549084013501610d4181610eb1565b50505050565b6005546001600160401b031667fffffffffffffffe141590565b60008060036000610d70610f76565b6001600160401b03166001600160401b031681526020019081526020016000205411905090565b610d9f610fcc565b15610dbc5760405162461bcd60e51b8152600401610405906115fc565b6000610dc6610f64565b6001600160401b038116600090815260026020908152604080832033845290915290205490915015610e0a5760405162461bcd60e51b8152600401610405906116fd565b6001600160401b038116600090815260208181526040808320338452909152902054820180610e4b5760405162461bcd60e51b815260040161040590611633565b6001600160401b038216600081815260026020908152604080832033808552908352818420869055848452600390925280832080546001019055519092917f9f71686e9e2eed0a0a99340b1c3b230369f255b1d452130cead54f8308654dfd91a3505050565b3360009081526004602052604090205460ff1615610ee15760405162461bcd60e51b8152600401610405906118e8565b33600081815260046020526040808220805460ff191660011790555183156108fc0291849190818181858888f19350505050158015610f24573d6000803e3d6000fd5b5060055460405133916001600160401b0316907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d353390610c5a908590611973565b60006002610f70611088565b03905090565b60006003610f70611088565b600080610f9585805190602001206110ed565b90506000610fa3828661113e565b6001600160a01b0390811690851614925050509392505050565b6000610fc7611088565b905090565b60007f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f000000000000000000000000000000000000000000000000000000000000000043036001600160401b03168161105557fe5b067f0000000000000000000000000000000000000000000000000000000000000000036001600160401b03161115905090565b60007f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f000000000000000000000000000000000000000000000000000000000000000043036001600160401b0316816110e757fe5b04905090565b604080517f19457468657265756d205369676e6564204d6573736167653a0a333200000000602080830191909152603c8083019490945282518083039094018452605c909101909152815191012090565b60008151604114611196576040805162461bcd60e51b815260206004820152601f60248201527f45434453413a20696e76616c6964207369676e6174757265206c656e67746800604482015290519081900360640190fd5b60208201516040830151606084015160001a7f7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a08211156112075760405162461bcd60e51b81526004018080602001828103825260228152602001806119b46022913960400191505060405180910390fd5b8060ff16601b1415801561121f57508060ff16601c14155b1561125b5760405162461bcd60e51b81526004018080602001828103825260228152602001806119d66022913960400191505060405180910390fd5b600060018783868660405160008152602001604052604051808581526020018460ff1681526020018381526020018281526020019450505050506020604051602081039080840390855afa1580156112b7573d6000803e3d6000fd5b5050604051601f1901519150506001600160a01b03811661131f576040805162461bcd60e51b815260206004820152601860248201527f45434453413a20696e76616c6964207369676e61747572650000000000000000604482015290519081900360640190fd5b9695505050505050565b80356001600160a01b03811681146103db57600080fd5b600060608284031215611351578081fd5b604051606081018181106001600160401b038211171561136d57fe5b60405290508061137c836113a0565b815261138a60208401611329565b6020820152604083013560408201525092915050565b80356001600160401b03811681146103db57600080fd5b6000602082840312156113c8578081fd5b6113d182611329565b9392505050565b600080600083850360808112156113ed578283fd5b60608112156113fa578283fd5b5083925060608401356001600160401b0380821115611417578384fd5b818601915086601f83011261142a578384fd5b813581811115611438578485fd5b876020828501011115611449578485fd5b6020830194508093505050509250925092565b60006060828403121561146d578081fd5b6113d18383611340565b60008060808385031215611489578182fd5b6114938484611340565b915060608301356001600160401b03808211156114ae578283fd5b818501915085601f8301126114c1578283fd5b8135818111156114cd57fe5b6114e0601f8201601f1916602001611990565b91508082528660208285010111156114f6578384fd5b806020840160208401378101602001929092525090939092509050565b600060208284031215611524578081fd5b6113d1826113a0565b6000806040838503121561153f578182fd5b611548836113a0565b915061155660208401611329565b90509250929050565b6001600160a01b0391909116815260200190565b901515815260200190565b6000602080835283518082850152825b818110156115aa5785810183015185820160400152820161158e565b818111156115bb5783604083870101525b50601f01601f1916929092016040019392505050565b602080825260119082015270696e76616c6964207369676e617475726560781b604082015260600190565b6020808252601b908201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604082015260600190565b6020808252601290820152716e6f2076616c756520696e2073797374656d60701b604082015260600190565b60208082526018908201527f6e6f7468696e67206c65667420746f2077697468647261770000000000000000604082015260600190565b60208082526017908201527f6368616c6c656e67653a2077726f6e672073656e646572000000000000000000604082015260600190565b6020808252601690820152750c6d0c2d8d8cadcceca7440eee4dedcce40cae0dec6d60531b604082015260600190565b602080825260129082015271185b1c9958591e4818da185b1b195b99d95960721b604082015260600190565b60208082526012908201527132bc34ba1d103bb937b7339039b2b73232b960711b604082015260600190565b60208082526013908201527277697468647261773a20746f6f206561726c7960681b604082015260600190565b60a0808252600f908201526e4572647374616c6c42616c616e636560881b60c08201526001600160a01b0394851660208201526001600160401b0393909316604084015292166060820152608081019190915260e00190565b6020808252601b908201527f776974686472617746726f7a656e3a2077726f6e672065706f63680000000000604082015260600190565b6020808252600d908201526c383630b9b6b090333937bd32b760991b604082015260600190565b60208082526021908201527f6e6f7468696e67206c65667420746f207769746864726177202866726f7a656e6040820152602960f81b606082015260800190565b6020808252601c908201527f776974686472617746726f7a656e3a2077726f6e672073656e64657200000000604082015260600190565b6020808252601a908201527f6e6f206368616c6c656e676520696e206c6173742065706f6368000000000000604082015260600190565b6020808252601a908201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604082015260600190565b6020808252601190820152700caf0d2e87440eee4dedcce40cae0dec6d607b1b604082015260600190565b6020808252600f908201526e706c61736d6120667265657a696e6760881b604082015260600190565b90815260200190565b6001600160401b0391909116815260200190565b6040518181016001600160401b03811182821017156119ab57fe5b60405291905056fe45434453413a20696e76616c6964207369676e6174757265202773272076616c756545434453413a20696e76616c6964207369676e6174757265202776272076616c7565a264697066735822122021141d4d04ceab273065b3c35cd6c62bb9fb93b529f2aaf72b215841f4cbb22664736f6c63430007040033"
+var ErdstallBin = "0x6101006040526200001960016001600160401b036200011e565b600980546001600160401b0319166001600160401b03929092169190911790553480156200004657600080fd5b506040516200326b3803806200326b833981016040819052620000699162000165565b6001600160401b03821662000080826002620001bc565b6001600160401b03161115620000dc5760405162461bcd60e51b815260206004820152601960248201527f726573706f6e73654475726174696f6e20746f6f206c6f6e6700000000000000604482015260640160405180910390fd5b6001600160a01b039092166080526001600160401b0343811660a05290811660c0521660e052620001ea565b634e487b7160e01b600052601160045260246000fd5b6001600160401b0382811682821603908082111562000141576200014162000108565b5092915050565b80516001600160401b03811681146200016057600080fd5b919050565b6000806000606084860312156200017b57600080fd5b83516001600160a01b03811681146200019357600080fd5b9250620001a36020850162000148565b9150620001b36040850162000148565b90509250925092565b6001600160401b03818116838216028082169190828114620001e257620001e262000108565b505092915050565b60805160a05160c05160e051613016620002556000396000818161058c0152612696015260008181610653015281816126b8015281816127110152612749015260008181610205015281816126dc015261276d0152600081816104a8015261111c01526130166000f3fe6080604052600436106101ee5760003560e01c806367a684151161010d5780639b7c7725116100a0578063ccc400971161006f578063ccc40097146106b0578063d0e30db0146106d0578063e99ce0ad146106d8578063f291077314610716578063f4a850431461074357600080fd5b80639b7c7725146105ec578063a608911d14610621578063ac5553ce14610641578063bfe275e91461067557600080fd5b8063750f0acc116100dc578063750f0acc1461053a578063778a27071461055a578063854b86d91461057a57806391c99dd9146105ae57600080fd5b806367a684151461047657806367eeb62b1461049657806370e4a2c4146104e2578063738508c81461051a57600080fd5b80633f48a2a811610185578063547780ce11610154578063547780ce146103e3578063585db72a1461042157806363a3a27f1461044157806364c38ddd1461046157600080fd5b80633f48a2a8146103435780634075d3f2146103835780634e6c2ded146103a3578063524a2bb3146103c357600080fd5b8063234c49a0116101c1578063234c49a0146102a8578063338b5dea146102ee57806335b892be1461030e5780633de970e31461032e57600080fd5b806303cf0678146101f35780630b7042d2146102445780630d13fd7b1461027157806310e6312f14610288575b600080fd5b3480156101ff57600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b6040516001600160401b0390911681526020015b60405180910390f35b34801561025057600080fd5b5061026461025f366004612a5f565b610763565b60405161023b9190612a82565b34801561027d57600080fd5b506102866107e8565b005b34801561029457600080fd5b506102866102a3366004612b18565b610847565b3480156102b457600080fd5b506102e06102c3366004612b72565b600460209081526000928352604080842090915290825290205481565b60405190815260200161023b565b3480156102fa57600080fd5b50610286610309366004612ba5565b6109dc565b34801561031a57600080fd5b50610286610329366004612b18565b610be6565b34801561033a57600080fd5b50610286610fbb565b34801561034f57600080fd5b5061037361035e366004612bcf565b60076020526000908152604090205460ff1681565b604051901515815260200161023b565b34801561038f57600080fd5b5061028661039e366004612bcf565b611030565b3480156103af57600080fd5b506102866103be366004612bcf565b6110b8565b3480156103cf57600080fd5b506102866103de366004612cee565b61110d565b3480156103ef57600080fd5b506102e06103fe366004612d3c565b600260209081526000938452604080852082529284528284209052825290205481565b34801561042d57600080fd5b50600954610227906001600160401b031681565b34801561044d57600080fd5b5061028661045c366004612d7f565b611180565b34801561046d57600080fd5b506102866114d6565b34801561048257600080fd5b50610264610491366004612dc0565b611597565b3480156104a257600080fd5b506104ca7f000000000000000000000000000000000000000000000000000000000000000081565b6040516001600160a01b03909116815260200161023b565b3480156104ee57600080fd5b506102e06104fd366004612b72565b600160209081526000928352604080842090915290825290205481565b34801561052657600080fd5b50610286610535366004612b18565b611618565b34801561054657600080fd5b50610286610555366004612ddc565b6117af565b34801561056657600080fd5b50610286610575366004612d7f565b611958565b34801561058657600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b3480156105ba57600080fd5b506102e06105c9366004612d3c565b600360209081526000938452604080852082529284528284209052825290205481565b3480156105f857600080fd5b506102e0610607366004612b72565b600060208181529281526040808220909352908152205481565b34801561062d57600080fd5b5061028661063c366004612df7565b611a95565b34801561064d57600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b34801561068157600080fd5b50610373610690366004612e2f565b600860209081526000928352604080842090915290825290205460ff1681565b3480156106bc57600080fd5b506102866106cb366004612b72565b611aa1565b610286611d0d565b3480156106e457600080fd5b506102e06106f3366004612d3c565b600560209081526000938452604080852082529284528284209052825290205481565b34801561072257600080fd5b506102e0610731366004612ddc565b60066020526000908152604090205481565b34801561074f57600080fd5b5061028661075e366004612d7f565b611de0565b8051602080830151604080850151815160a0948101859052600f60c08201526e4572647374616c6c42616c616e636560881b60e082015230928101929092526001600160401b039094166060828101919091526001600160a01b03909216608082015291820192909252610100015b6040516020818303038152906040529050919050565b6107f0611f2a565b156108165760405162461bcd60e51b815260040161080d90612e4b565b60405180910390fd5b61081e611f55565b1561083b5760405162461bcd60e51b815260040161080d90612e72565b6108456000611f8b565b565b61084f611f2a565b1561086c5760405162461bcd60e51b815260040161080d90612e4b565b610874611f55565b156108915760405162461bcd60e51b815260040161080d90612e72565b336108a26040850160208601612bcf565b6001600160a01b0316146108f85760405162461bcd60e51b815260206004820152601c60248201527f6368616c6c656e6765546f6b656e3a2077726f6e672073656e64657200000000604482015260640161080d565b610900612142565b6001600160401b03166109166020850185612ddc565b6001600160401b03161461096c5760405162461bcd60e51b815260206004820152601b60248201527f6368616c6c656e6765546f6b656e3a2077726f6e672065706f63680000000000604482015260640161080d565b6109ba61097e36859003850185612dc0565b83838080601f01602080910402602001604051908101604052809392919081815260200183838082843760009201919091525061110d92505050565b6109d76109cd6060850160408601612bcf565b8460600135612154565b505050565b6109e4611f2a565b15610a015760405162461bcd60e51b815260040161080d90612e4b565b610a09611f55565b15610a265760405162461bcd60e51b815260040161080d90612e72565b6001600160a01b038216610a7c5760405162461bcd60e51b815260206004820152601860248201527f6465706f736974546f6b656e3a207a65726f20746f6b656e0000000000000000604482015260640161080d565b6000610a8661234c565b6040516323b872dd60e01b8152336004820152306024820152604481018490529091506001600160a01b038416906323b872dd906064016020604051808303816000875af1158015610adc573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610b009190612e9b565b610b4c5760405162461bcd60e51b815260206004820152601d60248201527f6465706f736974546f6b656e3a207472616e73666572206661696c6564000000604482015260640161080d565b6001600160401b038116600090815260026020908152604080832033845282528083206001600160a01b038716845290915281208054849290610b90908490612ed3565b90915550506040518281526001600160a01b0384169033906001600160401b038416907f1c891b3c176c1dce4404ae29bd5192f23d8e6789b77b152af75017ba53aa1314906020015b60405180910390a4505050565b610bee611f2a565b15610c0b5760405162461bcd60e51b815260040161080d90612e4b565b610c13611f55565b15610c305760405162461bcd60e51b815260040161080d90612e72565b610c3861235b565b6001600160401b0316610c4e6020850185612ddc565b6001600160401b031614610c9d5760405162461bcd60e51b81526020600482015260166024820152750caf0d2e8a8ded6cadc7440eee4dedcce40cae0dec6d60531b604482015260640161080d565b610caf61097e36859003850185612dc0565b60056000610cc06020860186612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000846020016020810190610cf59190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000846040016020810190610d2a9190612bcf565b6001600160a01b03166001600160a01b0316815260200190815260200160002054600003610dbe5733610d636040850160208601612bcf565b6001600160a01b031614610db95760405162461bcd60e51b815260206004820152601760248201527f65786974546f6b656e3a2077726f6e672073656e646572000000000000000000604482015260640161080d565b610e97565b6000600581610dd06020870187612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000856020016020810190610e059190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000856040016020810190610e3a9190612bcf565b6001600160a01b0316815260208082019290925260400160009081209290925560069190610e6a90860186612ddc565b6001600160401b0316815260208101919091526040016000908120805491610e9183612ee6565b91905055505b606083013560036000610ead6020870187612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000856020016020810190610ee29190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000856040016020810190610f179190612bcf565b6001600160a01b03166001600160a01b0316815260200190815260200160002081905550826040016020810190610f4e9190612bcf565b6001600160a01b0316610f676040850160208601612bcf565b6001600160a01b0316610f7d6020860186612ddc565b6001600160401b03167ff761b84651786104dcc6f0cc72cb53e5efada23bad607d7da2d05b9ae838dc128660600135604051610bd991815260200190565b610fc36114d6565b600060046000610fde6009546001600160401b031660010190565b6001600160401b03168152602080820192909252604090810160009081203382529092529020549050806110245760405162461bcd60e51b815260040161080d90612efd565b61102d81612367565b50565b6110386114d6565b6000600560006110536009546001600160401b031660010190565b6001600160401b031681526020808201929092526040908101600090812033825283528181206001600160a01b03861682529092529020549050806110aa5760405162461bcd60e51b815260040161080d90612efd565b6110b4828261244a565b5050565b6110c0611f2a565b156110dd5760405162461bcd60e51b815260040161080d90612e4b565b6110e5611f55565b156111025760405162461bcd60e51b815260040161080d90612e72565b61102d816000612154565b61114061111983611597565b827f0000000000000000000000000000000000000000000000000000000000000000612601565b6110b45760405162461bcd60e51b8152602060048201526011602482015270696e76616c6964207369676e617475726560781b604482015260640161080d565b611188611f2a565b156111a55760405162461bcd60e51b815260040161080d90612e4b565b6111ad611f55565b156111ca5760405162461bcd60e51b815260040161080d90612e72565b6111d261235b565b6001600160401b03166111e86020850185612ddc565b6001600160401b0316146112325760405162461bcd60e51b81526020600482015260116024820152700caf0d2e87440eee4dedcce40cae0dec6d607b1b604482015260640161080d565b61128061124436859003850185612a5f565b83838080601f016020809104026020016040519081016040528093929190818152602001838380828437600092019190915250611a9592505050565b600460006112916020860186612ddc565b6001600160401b03166001600160401b0316815260200190815260200160002060008460200160208101906112c69190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000205460000361134f57336112ff6040850160208601612bcf565b6001600160a01b03161461134a5760405162461bcd60e51b815260206004820152601260248201527132bc34ba1d103bb937b7339039b2b73232b960711b604482015260640161080d565b6113f3565b60006004816113616020870187612ddc565b6001600160401b03166001600160401b0316815260200190815260200160002060008560200160208101906113969190612bcf565b6001600160a01b03168152602080820192909252604001600090812092909255600691906113c690860186612ddc565b6001600160401b03168152602081019190915260400160009081208054916113ed83612ee6565b91905055505b6040830135600160006114096020870187612ddc565b6001600160401b03166001600160401b03168152602001908152602001600020600085602001602081019061143e9190612bcf565b6001600160a01b03166001600160a01b03168152602001908152602001600020819055508260200160208101906114759190612bcf565b6001600160a01b031661148b6020850185612ddc565b6001600160401b03167f874e6a4ac09c210cf4cd123caaf949f43c3c6f07f2f46f26ccc5b0fd881c3d0485604001356040516114c991815260200190565b60405180910390a3505050565b6114de611f2a565b156114e557565b6114ed611f55565b6115395760405162461bcd60e51b815260206004820152601a60248201527f6e6f206368616c6c656e676520696e206c6173742065706f6368000000000000604482015260640161080d565b60006001611545612142565b6009805467ffffffffffffffff1916929091036001600160401b0381169283179091556040519092507f5e20151a99b0432a9ac06d33b91b77d3134ce0638cc70d7df042947ca48a2caf90600090a250565b8051602080830151604080850151606086810151835160c0968101879052601460e0820152734572647374616c6c546f6b656e42616c616e636560601b61010082015230948101949094526001600160401b03909616838201526001600160a01b039384166080840152921660a082015291820192909252610120016107d2565b6116206114d6565b336116316040850160208601612bcf565b6001600160a01b0316146116915760405162461bcd60e51b815260206004820152602160248201527f776974686472617746726f7a656e546f6b656e3a2077726f6e672073656e64656044820152603960f91b606482015260840161080d565b6009546001600160401b03166116aa6020850185612ddc565b6001600160401b0316146117005760405162461bcd60e51b815260206004820181905260248201527f776974686472617746726f7a656e546f6b656e3a2077726f6e672065706f6368604482015260640161080d565b61171261097e36859003850185612dc0565b60006002600061172d6009546001600160401b031660010190565b6001600160401b031681526020808201929092526040908101600090812033825290925280822091906117669060608801908801612bcf565b6001600160a01b0316815260208101919091526040016000205461178e906060860135612ed3565b90506117a96117a36060860160408701612bcf565b8261244a565b50505050565b6117b7611f2a565b156117d45760405162461bcd60e51b815260040161080d90612e4b565b6117dc611f55565b156117f95760405162461bcd60e51b815260040161080d90612e72565b61180161235b565b6001600160401b0316816001600160401b0316106118575760405162461bcd60e51b815260206004820152601360248201527277697468647261773a20746f6f206561726c7960681b604482015260640161080d565b6001600160401b0381166000908152600160209081526040808320338452909152902054806118c35760405162461bcd60e51b81526020600482015260186024820152776e6f7468696e67206c65667420746f20776974686472617760401b604482015260640161080d565b6001600160401b038216600090815260016020908152604080832033808552925280832083905551909183156108fc02918491818181858888f19350505050158015611913573d6000803e3d6000fd5b5060405181815233906001600160401b038416907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d35339060200160405180910390a35050565b611960611f2a565b1561197d5760405162461bcd60e51b815260040161080d90612e4b565b611985611f55565b156119a25760405162461bcd60e51b815260040161080d90612e72565b336119b36040850160208601612bcf565b6001600160a01b031614611a095760405162461bcd60e51b815260206004820152601760248201527f6368616c6c656e67653a2077726f6e672073656e646572000000000000000000604482015260640161080d565b611a11612142565b6001600160401b0316611a276020850185612ddc565b6001600160401b031614611a765760405162461bcd60e51b81526020600482015260166024820152750c6d0c2d8d8cadcceca7440eee4dedcce40cae0dec6d60531b604482015260640161080d565b611a8861124436859003850185612a5f565b6109d78360400135611f8b565b61114061111983610763565b611aa9611f2a565b15611ac65760405162461bcd60e51b815260040161080d90612e4b565b611ace611f55565b15611aeb5760405162461bcd60e51b815260040161080d90612e72565b611af361235b565b6001600160401b0316826001600160401b031610611b535760405162461bcd60e51b815260206004820152601860248201527f7769746864726177546f6b656e3a20746f6f206561726c790000000000000000604482015260640161080d565b6001600160401b038216600090815260036020908152604080832033845282528083206001600160a01b038516845290915290205480611bd05760405162461bcd60e51b81526020600482015260186024820152776e6f7468696e67206c65667420746f20776974686472617760401b604482015260640161080d565b6001600160401b0383166000908152600360209081526040808320338085529083528184206001600160a01b0387168086529352818420939093555163a9059cbb60e01b81526004810192909252602482018390529063a9059cbb906044016020604051808303816000875af1158015611c4e573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190611c729190612e9b565b611cbe5760405162461bcd60e51b815260206004820152601e60248201527f7769746864726177546f6b656e3a207472616e73666572206661696c65640000604482015260640161080d565b816001600160a01b0316336001600160a01b0316846001600160401b03167f97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa2184604051610bd991815260200190565b611d15611f2a565b15611d325760405162461bcd60e51b815260040161080d90612e4b565b611d3a611f55565b15611d575760405162461bcd60e51b815260040161080d90612e72565b6000611d6161234c565b6001600160401b038116600090815260208181526040808320338452909152812080549293503492909190611d97908490612ed3565b909155505060405134815233906001600160401b038316907fe007c38a05fbf2010d1c1ed20f91e675c91d41699926124738a8c3fe9fc791b4906020015b60405180910390a350565b611de86114d6565b33611df96040850160208601612bcf565b6001600160a01b031614611e4f5760405162461bcd60e51b815260206004820152601c60248201527f776974686472617746726f7a656e3a2077726f6e672073656e64657200000000604482015260640161080d565b6009546001600160401b0316611e686020850185612ddc565b6001600160401b031614611ebe5760405162461bcd60e51b815260206004820152601b60248201527f776974686472617746726f7a656e3a2077726f6e672065706f63680000000000604482015260640161080d565b611ed061124436859003850185612a5f565b6000806000611eea6009546001600160401b031660010190565b6001600160401b03168152602080820192909252604090810160009081203382529092529081902054611f1f91860135612ed3565b90506117a981612367565b6000611f3e60016001600160401b03612f3e565b6009546001600160401b0390811691161415919050565b60008060066000611f64612142565b6001600160401b03166001600160401b031681526020019081526020016000205411905090565b611f9361268a565b15611fe05760405162461bcd60e51b815260206004820152601b60248201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604482015260640161080d565b6000611fea61235b565b6001600160401b0381166000908152600460209081526040808320338452909152902054909150156120535760405162461bcd60e51b8152602060048201526012602482015271185b1c9958591e4818da185b1b195b99d95960721b604482015260640161080d565b6001600160401b03811660009081526020818152604080832033845290915281205461207f9084612ed3565b9050600081116120c65760405162461bcd60e51b81526020600482015260126024820152716e6f2076616c756520696e2073797374656d60701b604482015260640161080d565b6001600160401b038216600081815260046020908152604080832033845282528083208590559282526006905290812080549161210283612f65565b909155505060405133906001600160401b038416907f9f71686e9e2eed0a0a99340b1c3b230369f255b1d452130cead54f8308654dfd90600090a3505050565b6000600361214e612745565b03905090565b61215c61268a565b156121a95760405162461bcd60e51b815260206004820152601b60248201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604482015260640161080d565b60006121b361235b565b6001600160401b038116600090815260056020908152604080832033845282528083206001600160a01b03881684529091529020549091501561222d5760405162461bcd60e51b8152602060048201526012602482015271185b1c9958591e4818da185b1b195b99d95960721b604482015260640161080d565b6001600160401b038116600090815260026020908152604080832033845282528083206001600160a01b038716845290915281205461226c9084612ed3565b9050600081116122b35760405162461bcd60e51b81526020600482015260126024820152716e6f2076616c756520696e2073797374656d60701b604482015260640161080d565b6001600160401b038216600081815260056020908152604080832033845282528083206001600160a01b038916845282528083208590559282526006905290812080549161230083612f65565b90915550506040516001600160a01b0385169033906001600160401b038516907fefed69ee1f43f5c9bb9b86161ef5be3f3ca8ad114409046f151759c4e19adad390600090a450505050565b6000612356612745565b905090565b6000600261214e612745565b3360009081526007602052604090205460ff16156123c75760405162461bcd60e51b815260206004820152601a60248201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604482015260640161080d565b33600081815260076020526040808220805460ff191660011790555183156108fc0291849190818181858888f1935050505015801561240a573d6000803e3d6000fd5b5060095460405182815233916001600160401b0316907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d353390602001611dd5565b3360009081526008602090815260408083206001600160a01b038616845290915290205460ff16156124be5760405162461bcd60e51b815260206004820152601a60248201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604482015260640161080d565b3360008181526008602090815260408083206001600160a01b038716808552925291829020805460ff19166001179055905163a9059cbb60e01b81526004810192909252602482018390529063a9059cbb906044016020604051808303816000875af1158015612532573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906125569190612e9b565b6125ae5760405162461bcd60e51b8152602060048201526024808201527f776974686472617746726f7a656e546f6b656e3a207472616e736665722066616044820152631a5b195960e21b606482015260840161080d565b6009546040518281526001600160a01b0384169133916001600160401b03909116907f97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa219060200160405180910390a45050565b60008061266285805190602001206040517f19457468657265756d205369676e6564204d6573736167653a0a3332000000006020820152603c8101829052600090605c01604051602081830303815290604052805190602001209050919050565b90506000612670828661279c565b6001600160a01b0390811690851614925050509392505050565b60006001600160401b037f0000000000000000000000000000000000000000000000000000000000000000167f00000000000000000000000000000000000000000000000000000000000000006127017f000000000000000000000000000000000000000000000000000000000000000043612f3e565b61270b9190612f94565b612735907f0000000000000000000000000000000000000000000000000000000000000000612f3e565b6001600160401b03161115905090565b60007f00000000000000000000000000000000000000000000000000000000000000006127927f000000000000000000000000000000000000000000000000000000000000000043612f3e565b6123569190612fba565b600081516041146127ef5760405162461bcd60e51b815260206004820152601f60248201527f45434453413a20696e76616c6964207369676e6174757265206c656e67746800604482015260640161080d565b60208201516040830151606084015160001a7f7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a082111561287c5760405162461bcd60e51b815260206004820152602260248201527f45434453413a20696e76616c6964207369676e6174757265202773272076616c604482015261756560f01b606482015260840161080d565b8060ff16601b1415801561289457508060ff16601c14155b156128ec5760405162461bcd60e51b815260206004820152602260248201527f45434453413a20696e76616c6964207369676e6174757265202776272076616c604482015261756560f01b606482015260840161080d565b6040805160008082526020820180845289905260ff841692820192909252606081018590526080810184905260019060a0016020604051602081039080840390855afa158015612940573d6000803e3d6000fd5b5050604051601f1901519150506001600160a01b0381166129a35760405162461bcd60e51b815260206004820152601860248201527f45434453413a20696e76616c6964207369676e61747572650000000000000000604482015260640161080d565b93505050505b92915050565b634e487b7160e01b600052604160045260246000fd5b80356001600160401b03811681146129dc57600080fd5b919050565b80356001600160a01b03811681146129dc57600080fd5b600060608284031215612a0a57600080fd5b604051606081018181106001600160401b0382111715612a2c57612a2c6129af565b604052905080612a3b836129c5565b8152612a49602084016129e1565b6020820152604083013560408201525092915050565b600060608284031215612a7157600080fd5b612a7b83836129f8565b9392505050565b600060208083528351808285015260005b81811015612aaf57858101830151858201604001528201612a93565b506000604082860101526040601f19601f8301168501019250505092915050565b60008083601f840112612ae257600080fd5b5081356001600160401b03811115612af957600080fd5b602083019150836020828501011115612b1157600080fd5b9250929050565b600080600083850360a0811215612b2e57600080fd5b6080811215612b3c57600080fd5b5083925060808401356001600160401b03811115612b5957600080fd5b612b6586828701612ad0565b9497909650939450505050565b60008060408385031215612b8557600080fd5b612b8e836129c5565b9150612b9c602084016129e1565b90509250929050565b60008060408385031215612bb857600080fd5b612bc1836129e1565b946020939093013593505050565b600060208284031215612be157600080fd5b612a7b826129e1565b600060808284031215612bfc57600080fd5b604051608081018181106001600160401b0382111715612c1e57612c1e6129af565b604052905080612c2d836129c5565b8152612c3b602084016129e1565b6020820152612c4c604084016129e1565b6040820152606083013560608201525092915050565b600082601f830112612c7357600080fd5b81356001600160401b0380821115612c8d57612c8d6129af565b604051601f8301601f19908116603f01168101908282118183101715612cb557612cb56129af565b81604052838152866020858801011115612cce57600080fd5b836020870160208301376000602085830101528094505050505092915050565b60008060a08385031215612d0157600080fd5b612d0b8484612bea565b915060808301356001600160401b03811115612d2657600080fd5b612d3285828601612c62565b9150509250929050565b600080600060608486031215612d5157600080fd5b612d5a846129c5565b9250612d68602085016129e1565b9150612d76604085016129e1565b90509250925092565b60008060008385036080811215612d9557600080fd5b6060811215612da357600080fd5b5083925060608401356001600160401b03811115612b5957600080fd5b600060808284031215612dd257600080fd5b612a7b8383612bea565b600060208284031215612dee57600080fd5b612a7b826129c5565b60008060808385031215612e0a57600080fd5b612e1484846129f8565b915060608301356001600160401b03811115612d2657600080fd5b60008060408385031215612e4257600080fd5b612b8e836129e1565b6020808252600d908201526c383630b9b6b090333937bd32b760991b604082015260600190565b6020808252600f908201526e706c61736d6120667265657a696e6760881b604082015260600190565b600060208284031215612ead57600080fd5b81518015158114612a7b57600080fd5b634e487b7160e01b600052601160045260246000fd5b808201808211156129a9576129a9612ebd565b600081612ef557612ef5612ebd565b506000190190565b60208082526021908201527f6e6f7468696e67206c65667420746f207769746864726177202866726f7a656e6040820152602960f81b606082015260800190565b6001600160401b03828116828216039080821115612f5e57612f5e612ebd565b5092915050565b600060018201612f7757612f77612ebd565b5060010190565b634e487b7160e01b600052601260045260246000fd5b60006001600160401b0380841680612fae57612fae612f7e565b92169190910692915050565b60006001600160401b0380841680612fd457612fd4612f7e565b9216919091049291505056fea2646970667358221220cab85c986b646c67490623f85a4a5c8c0b84684da14e3cb75cd2b9cb57d02daa64736f6c63430008150033"
 
 // DeployErdstall deploys a new Ethereum contract, binding an instance of Erdstall to it.
 func DeployErdstall(auth *bind.TransactOpts, backend bind.ContractBackend, _tee common.Address, _phaseDuration uint64, _responseDuration uint64) (common.Address, *types.Transaction, *Erdstall, error) {
@@ -505,6 +526,37 @@ func (_Erdstall *ErdstallCallerSession) EncodeBalanceProof(balance ErdstallBalan
 	return _Erdstall.Contract.EncodeBalanceProof(&_Erdstall.CallOpts, balance)
 }
 
+// EncodeTokenBalanceProof is a free data retrieval call binding the contract method 0x67a68415.
+//
+// Solidity: function encodeTokenBalanceProof((uint64,address,address,uint256) balance) view returns(bytes)
+func (_Erdstall *ErdstallCaller) EncodeTokenBalanceProof(opts *bind.CallOpts, balance ErdstallTokenBalance) ([]byte, error) {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "encodeTokenBalanceProof", balance)
+
+	if err != nil {
+		return *new([]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]byte)).(*[]byte)
+
+	return out0, err
+
+}
+
+// EncodeTokenBalanceProof is a free data retrieval call binding the contract method 0x67a68415.
+//
+// Solidity: function encodeTokenBalanceProof((uint64,address,address,uint256) balance) view returns(bytes)
+func (_Erdstall *ErdstallSession) EncodeTokenBalanceProof(balance ErdstallTokenBalance) ([]byte, error) {
+	return _Erdstall.Contract.EncodeTokenBalanceProof(&_Erdstall.CallOpts, balance)
+}
+
+// EncodeTokenBalanceProof is a free data retrieval call binding the contract method 0x67a68415.
+//
+// Solidity: function encodeTokenBalanceProof((uint64,address,address,uint256) balance) view returns(bytes)
+func (_Erdstall *ErdstallCallerSession) EncodeTokenBalanceProof(balance ErdstallTokenBalance) ([]byte, error) {
+	return _Erdstall.Contract.EncodeTokenBalanceProof(&_Erdstall.CallOpts, balance)
+}
+
 // Exits is a free data retrieval call binding the contract method 0x70e4a2c4.
 //
 // Solidity: function exits(uint64 , address ) view returns(uint256)
@@ -567,6 +619,37 @@ func (_Erdstall *ErdstallCallerSession) FrozenEpoch() (uint64, error) {
 	return _Erdstall.Contract.FrozenEpoch(&_Erdstall.CallOpts)
 }
 
+// FrozenTokenWithdrawals is a free data retrieval call binding the contract method 0xbfe275e9.
+//
+// Solidity: function frozenTokenWithdrawals(address , address ) view returns(bool)
+func (_Erdstall *ErdstallCaller) FrozenTokenWithdrawals(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address) (bool, error) {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "frozenTokenWithdrawals", arg0, arg1)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// FrozenTokenWithdrawals is a free data retrieval call binding the contract method 0xbfe275e9.
+//
+// Solidity: function frozenTokenWithdrawals(address , address ) view returns(bool)
+func (_Erdstall *ErdstallSession) FrozenTokenWithdrawals(arg0 common.Address, arg1 common.Address) (bool, error) {
+	return _Erdstall.Contract.FrozenTokenWithdrawals(&_Erdstall.CallOpts, arg0, arg1)
+}
+
+// FrozenTokenWithdrawals is a free data retrieval call binding the contract method 0xbfe275e9.
+//
+// Solidity: function frozenTokenWithdrawals(address , address ) view returns(bool)
+func (_Erdstall *ErdstallCallerSession) FrozenTokenWithdrawals(arg0 common.Address, arg1 common.Address) (bool, error) {
+	return _Erdstall.Contract.FrozenTokenWithdrawals(&_Erdstall.CallOpts, arg0, arg1)
+}
+
 // FrozenWithdrawals is a free data retrieval call binding the contract method 0x3f48a2a8.
 //
 // Solidity: function frozenWithdrawals(address ) view returns(bool)
@@ -722,6 +805,99 @@ func (_Erdstall *ErdstallCallerSession) Tee() (common.Address, error) {
 	return _Erdstall.Contract.Tee(&_Erdstall.CallOpts)
 }
 
+// TokenChallenges is a free data retrieval call binding the contract method 0xe99ce0ad.
+//
+// Solidity: function tokenChallenges(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCaller) TokenChallenges(opts *bind.CallOpts, arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "tokenChallenges", arg0, arg1, arg2)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TokenChallenges is a free data retrieval call binding the contract method 0xe99ce0ad.
+//
+// Solidity: function tokenChallenges(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallSession) TokenChallenges(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenChallenges(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
+// TokenChallenges is a free data retrieval call binding the contract method 0xe99ce0ad.
+//
+// Solidity: function tokenChallenges(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCallerSession) TokenChallenges(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenChallenges(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
+// TokenDeposits is a free data retrieval call binding the contract method 0x547780ce.
+//
+// Solidity: function tokenDeposits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCaller) TokenDeposits(opts *bind.CallOpts, arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "tokenDeposits", arg0, arg1, arg2)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TokenDeposits is a free data retrieval call binding the contract method 0x547780ce.
+//
+// Solidity: function tokenDeposits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallSession) TokenDeposits(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenDeposits(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
+// TokenDeposits is a free data retrieval call binding the contract method 0x547780ce.
+//
+// Solidity: function tokenDeposits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCallerSession) TokenDeposits(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenDeposits(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
+// TokenExits is a free data retrieval call binding the contract method 0x91c99dd9.
+//
+// Solidity: function tokenExits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCaller) TokenExits(opts *bind.CallOpts, arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "tokenExits", arg0, arg1, arg2)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TokenExits is a free data retrieval call binding the contract method 0x91c99dd9.
+//
+// Solidity: function tokenExits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallSession) TokenExits(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenExits(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
+// TokenExits is a free data retrieval call binding the contract method 0x91c99dd9.
+//
+// Solidity: function tokenExits(uint64 , address , address ) view returns(uint256)
+func (_Erdstall *ErdstallCallerSession) TokenExits(arg0 uint64, arg1 common.Address, arg2 common.Address) (*big.Int, error) {
+	return _Erdstall.Contract.TokenExits(&_Erdstall.CallOpts, arg0, arg1, arg2)
+}
+
 // VerifyBalance is a free data retrieval call binding the contract method 0xa608911d.
 //
 // Solidity: function verifyBalance((uint64,address,uint256) balance, bytes sig) view returns()
@@ -751,6 +927,35 @@ func (_Erdstall *ErdstallCallerSession) VerifyBalance(balance ErdstallBalance, s
 	return _Erdstall.Contract.VerifyBalance(&_Erdstall.CallOpts, balance, sig)
 }
 
+// VerifyTokenBalance is a free data retrieval call binding the contract method 0x524a2bb3.
+//
+// Solidity: function verifyTokenBalance((uint64,address,address,uint256) balance, bytes sig) view returns()
+func (_Erdstall *ErdstallCaller) VerifyTokenBalance(opts *bind.CallOpts, balance ErdstallTokenBalance, sig []byte) error {
+	var out []interface{}
+	err := _Erdstall.contract.Call(opts, &out, "verifyTokenBalance", balance, sig)
+
+	if err != nil {
+		return err
+	}
+
+	return err
+
+}
+
+// VerifyTokenBalance is a free data retrieval call binding the contract method 0x524a2bb3.
+//
+// Solidity: function verifyTokenBalance((uint64,address,address,uint256) balance, bytes sig) view returns()
+func (_Erdstall *ErdstallSession) VerifyTokenBalance(balance ErdstallTokenBalance, sig []byte) error {
+	return _Erdstall.Contract.VerifyTokenBalance(&_Erdstall.CallOpts, balance, sig)
+}
+
+// VerifyTokenBalance is a free data retrieval call binding the contract method 0x524a2bb3.
+//
+// Solidity: function verifyTokenBalance((uint64,address,address,uint256) balance, bytes sig) view returns()
+func (_Erdstall *ErdstallCallerSession) VerifyTokenBalance(balance ErdstallTokenBalance, sig []byte) error {
+	return _Erdstall.Contract.VerifyTokenBalance(&_Erdstall.CallOpts, balance, sig)
+}
+
 // Challenge is a paid mutator transaction binding the contract method 0x778a2707.
 //
 // Solidity: function challenge((uint64,address,uint256) balance, bytes sig) returns()
@@ -793,6 +998,48 @@ func (_Erdstall *ErdstallTransactorSession) ChallengeDeposit() (*types.Transacti
 	return _Erdstall.Contract.ChallengeDeposit(&_Erdstall.TransactOpts)
 }
 
+// ChallengeToken is a paid mutator transaction binding the contract method 0x10e6312f.
+//
+// Solidity: function challengeToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactor) ChallengeToken(opts *bind.TransactOpts, balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "challengeToken", balance, sig)
+}
+
+// ChallengeToken is a paid mutator transaction binding the contract method 0x10e6312f.
+//
+// Solidity: function challengeToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallSession) ChallengeToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.ChallengeToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
+// ChallengeToken is a paid mutator transaction binding the contract method 0x10e6312f.
+//
+// Solidity: function challengeToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactorSession) ChallengeToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.ChallengeToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
+// ChallengeTokenDeposit is a paid mutator transaction binding the contract method 0x4e6c2ded.
+//
+// Solidity: function challengeTokenDeposit(address token) returns()
+func (_Erdstall *ErdstallTransactor) ChallengeTokenDeposit(opts *bind.TransactOpts, token common.Address) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "challengeTokenDeposit", token)
+}
+
+// ChallengeTokenDeposit is a paid mutator transaction binding the contract method 0x4e6c2ded.
+//
+// Solidity: function challengeTokenDeposit(address token) returns()
+func (_Erdstall *ErdstallSession) ChallengeTokenDeposit(token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.ChallengeTokenDeposit(&_Erdstall.TransactOpts, token)
+}
+
+// ChallengeTokenDeposit is a paid mutator transaction binding the contract method 0x4e6c2ded.
+//
+// Solidity: function challengeTokenDeposit(address token) returns()
+func (_Erdstall *ErdstallTransactorSession) ChallengeTokenDeposit(token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.ChallengeTokenDeposit(&_Erdstall.TransactOpts, token)
+}
+
 // Deposit is a paid mutator transaction binding the contract method 0xd0e30db0.
 //
 // Solidity: function deposit() payable returns()
@@ -814,6 +1061,27 @@ func (_Erdstall *ErdstallTransactorSession) Deposit() (*types.Transaction, error
 	return _Erdstall.Contract.Deposit(&_Erdstall.TransactOpts)
 }
 
+// DepositToken is a paid mutator transaction binding the contract method 0x338b5dea.
+//
+// Solidity: function depositToken(address token, uint256 amount) returns()
+func (_Erdstall *ErdstallTransactor) DepositToken(opts *bind.TransactOpts, token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "depositToken", token, amount)
+}
+
+// DepositToken is a paid mutator transaction binding the contract method 0x338b5dea.
+//
+// Solidity: function depositToken(address token, uint256 amount) returns()
+func (_Erdstall *ErdstallSession) DepositToken(token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Erdstall.Contract.DepositToken(&_Erdstall.TransactOpts, token, amount)
+}
+
+// DepositToken is a paid mutator transaction binding the contract method 0x338b5dea.
+//
+// Solidity: function depositToken(address token, uint256 amount) returns()
+func (_Erdstall *ErdstallTransactorSession) DepositToken(token common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Erdstall.Contract.DepositToken(&_Erdstall.TransactOpts, token, amount)
+}
+
 // EnsureFrozen is a paid mutator transaction binding the contract method 0x64c38ddd.
 //
 // Solidity: function ensureFrozen() returns()
@@ -856,6 +1124,27 @@ func (_Erdstall *ErdstallTransactorSession) Exit(balance ErdstallBalance, sig []
 	return _Erdstall.Contract.Exit(&_Erdstall.TransactOpts, balance, sig)
 }
 
+// ExitToken is a paid mutator transaction binding the contract method 0x35b892be.
+//
+// Solidity: function exitToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactor) ExitToken(opts *bind.TransactOpts, balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "exitToken", balance, sig)
+}
+
+// ExitToken is a paid mutator transaction binding the contract method 0x35b892be.
+//
+// Solidity: function exitToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallSession) ExitToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.ExitToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
+// ExitToken is a paid mutator transaction binding the contract method 0x35b892be.
+//
+// Solidity: function exitToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactorSession) ExitToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.ExitToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
 // Withdraw is a paid mutator transaction binding the contract method 0x750f0acc.
 //
 // Solidity: function withdraw(uint64 epoch) returns()
@@ -919,6 +1208,69 @@ func (_Erdstall *ErdstallTransactorSession) WithdrawFrozen(balance ErdstallBalan
 	return _Erdstall.Contract.WithdrawFrozen(&_Erdstall.TransactOpts, balance, sig)
 }
 
+// WithdrawFrozenToken is a paid mutator transaction binding the contract method 0x738508c8.
+//
+// Solidity: function withdrawFrozenToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactor) WithdrawFrozenToken(opts *bind.TransactOpts, balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "withdrawFrozenToken", balance, sig)
+}
+
+// WithdrawFrozenToken is a paid mutator transaction binding the contract method 0x738508c8.
+//
+// Solidity: function withdrawFrozenToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallSession) WithdrawFrozenToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawFrozenToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
+// WithdrawFrozenToken is a paid mutator transaction binding the contract method 0x738508c8.
+//
+// Solidity: function withdrawFrozenToken((uint64,address,address,uint256) balance, bytes sig) returns()
+func (_Erdstall *ErdstallTransactorSession) WithdrawFrozenToken(balance ErdstallTokenBalance, sig []byte) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawFrozenToken(&_Erdstall.TransactOpts, balance, sig)
+}
+
+// WithdrawToken is a paid mutator transaction binding the contract method 0xccc40097.
+//
+// Solidity: function withdrawToken(uint64 epoch, address token) returns()
+func (_Erdstall *ErdstallTransactor) WithdrawToken(opts *bind.TransactOpts, epoch uint64, token common.Address) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "withdrawToken", epoch, token)
+}
+
+// WithdrawToken is a paid mutator transaction binding the contract method 0xccc40097.
+//
+// Solidity: function withdrawToken(uint64 epoch, address token) returns()
+func (_Erdstall *ErdstallSession) WithdrawToken(epoch uint64, token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawToken(&_Erdstall.TransactOpts, epoch, token)
+}
+
+// WithdrawToken is a paid mutator transaction binding the contract method 0xccc40097.
+//
+// Solidity: function withdrawToken(uint64 epoch, address token) returns()
+func (_Erdstall *ErdstallTransactorSession) WithdrawToken(epoch uint64, token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawToken(&_Erdstall.TransactOpts, epoch, token)
+}
+
+// WithdrawTokenChallenge is a paid mutator transaction binding the contract method 0x4075d3f2.
+//
+// Solidity: function withdrawTokenChallenge(address token) returns()
+func (_Erdstall *ErdstallTransactor) WithdrawTokenChallenge(opts *bind.TransactOpts, token common.Address) (*types.Transaction, error) {
+	return _Erdstall.contract.Transact(opts, "withdrawTokenChallenge", token)
+}
+
+// WithdrawTokenChallenge is a paid mutator transaction binding the contract method 0x4075d3f2.
+//
+// Solidity: function withdrawTokenChallenge(address token) returns()
+func (_Erdstall *ErdstallSession) WithdrawTokenChallenge(token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawTokenChallenge(&_Erdstall.TransactOpts, token)
+}
+
+// WithdrawTokenChallenge is a paid mutator transaction binding the contract method 0x4075d3f2.
+//
+// Solidity: function withdrawTokenChallenge(address token) returns()
+func (_Erdstall *ErdstallTransactorSession) WithdrawTokenChallenge(token common.Address) (*types.Transaction, error) {
+	return _Erdstall.Contract.WithdrawTokenChallenge(&_Erdstall.TransactOpts, token)
+}
+
 // ErdstallChallengedIterator is returned from FilterChallenged and is used to iterate over the raw logs and unpacked data for Challenged events raised by the Erdstall contract.
 type ErdstallChallengedIterator struct {
 	Event *ErdstallChallenged // Event containing the contract specifics and raw log
@@ -1520,9 +1872,9 @@ func (_Erdstall *ErdstallFilterer) ParseFrozen(log types.Log) (*ErdstallFrozen,
 	return event, nil
 }
 
-// ErdstallWithdrawnIterator is returned from FilterWithdrawn and is used to iterate over the raw logs and unpacked data for Withdrawn events raised by the Erdstall contract.
-type ErdstallWithdrawnIterator struct {
-	Event *ErdstallWithdrawn // Event containing the contract specifics and raw log
+// ErdstallTokenChallengedIterator is returned from FilterTokenChallenged and is used to iterate over the raw logs and unpacked data for TokenChallenged events raised by the Erdstall contract.
+type ErdstallTokenChallengedIterator struct {
+	Event *ErdstallTokenChallenged // Event containing the contract specifics and raw log
 
 	contract *bind.BoundContract // Generic contract to use for unpacking event data
 	event    string              // Event name to use for unpacking event data
@@ -1536,7 +1888,7 @@ type ErdstallWithdrawnIterator struct {
 // Next advances the iterator to the subsequent event, returning whether there
 // are any more events found. In case of a retrieval or parsing error, false is
 // returned and Error() can be queried for the exact failure.
-func (it *ErdstallWithdrawnIterator) Next() bool {
+func (it *ErdstallTokenChallengedIterator) Next() bool {
 	// If the iterator failed, stop iterating
 	if it.fail != nil {
 		return false
@@ -1545,7 +1897,7 @@ func (it *ErdstallWithdrawnIterator) Next() bool {
 	if it.done {
 		select {
 		case log := <-it.logs:
-			it.Event = new(ErdstallWithdrawn)
+			it.Event = new(ErdstallTokenChallenged)
 			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
 				it.fail = err
 				return false
@@ -1560,7 +1912,7 @@ func (it *ErdstallWithdrawnIterator) Next() bool {
 	// Iterator still in progress, wait for either a data or an error event
 	select {
 	case log := <-it.logs:
-		it.Event = new(ErdstallWithdrawn)
+		it.Event = new(ErdstallTokenChallenged)
 		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
 			it.fail = err
 			return false
@@ -1576,29 +1928,29 @@ func (it *ErdstallWithdrawnIterator) Next() bool {
 }
 
 // Error returns any retrieval or parsing error occurred during filtering.
-func (it *ErdstallWithdrawnIterator) Error() error {
+func (it *ErdstallTokenChallengedIterator) Error() error {
 	return it.fail
 }
 
 // Close terminates the iteration process, releasing any pending underlying
 // resources.
-func (it *ErdstallWithdrawnIterator) Close() error {
+func (it *ErdstallTokenChallengedIterator) Close() error {
 	it.sub.Unsubscribe()
 	return nil
 }
 
-// ErdstallWithdrawn represents a Withdrawn event raised by the Erdstall contract.
-type ErdstallWithdrawn struct {
+// ErdstallTokenChallenged represents a TokenChallenged event raised by the Erdstall contract.
+type ErdstallTokenChallenged struct {
 	Epoch   uint64
 	Account common.Address
-	Value   *big.Int
+	Token   common.Address
 	Raw     types.Log // Blockchain specific contextual infos
 }
 
-// FilterWithdrawn is a free log retrieval operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+// FilterTokenChallenged is a free log retrieval operation binding the contract event 0xefed69ee1f43f5c9bb9b86161ef5be3f3ca8ad114409046f151759c4e19adad3.
 //
-// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
-func (_Erdstall *ErdstallFilterer) FilterWithdrawn(opts *bind.FilterOpts, epoch []uint64, account []common.Address) (*ErdstallWithdrawnIterator, error) {
+// Solidity: event TokenChallenged(uint64 indexed epoch, address indexed account, address indexed token)
+func (_Erdstall *ErdstallFilterer) FilterTokenChallenged(opts *bind.FilterOpts, epoch []uint64, account []common.Address, token []common.Address) (*ErdstallTokenChallengedIterator, error) {
 
 	var epochRule []interface{}
 	for _, epochItem := range epoch {
@@ -1608,18 +1960,22 @@ func (_Erdstall *ErdstallFilterer) FilterWithdrawn(opts *bind.FilterOpts, epoch
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
 
-	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "Withdrawn", epochRule, accountRule)
+	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "TokenChallenged", epochRule, accountRule, tokenRule)
 	if err != nil {
 		return nil, err
 	}
-	return &ErdstallWithdrawnIterator{contract: _Erdstall.contract, event: "Withdrawn", logs: logs, sub: sub}, nil
+	return &ErdstallTokenChallengedIterator{contract: _Erdstall.contract, event: "TokenChallenged", logs: logs, sub: sub}, nil
 }
 
-// WatchWithdrawn is a free log subscription operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+// WatchTokenChallenged is a free log subscription operation binding the contract event 0xefed69ee1f43f5c9bb9b86161ef5be3f3ca8ad114409046f151759c4e19adad3.
 //
-// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
-func (_Erdstall *ErdstallFilterer) WatchWithdrawn(opts *bind.WatchOpts, sink chan<- *ErdstallWithdrawn, epoch []uint64, account []common.Address) (event.Subscription, error) {
+// Solidity: event TokenChallenged(uint64 indexed epoch, address indexed account, address indexed token)
+func (_Erdstall *ErdstallFilterer) WatchTokenChallenged(opts *bind.WatchOpts, sink chan<- *ErdstallTokenChallenged, epoch []uint64, account []common.Address, token []common.Address) (event.Subscription, error) {
 
 	var epochRule []interface{}
 	for _, epochItem := range epoch {
@@ -1629,8 +1985,12 @@ func (_Erdstall *ErdstallFilterer) WatchWithdrawn(opts *bind.WatchOpts, sink cha
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
 
-	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "Withdrawn", epochRule, accountRule)
+	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "TokenChallenged", epochRule, accountRule, tokenRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1640,8 +2000,8 @@ func (_Erdstall *ErdstallFilterer) WatchWithdrawn(opts *bind.WatchOpts, sink cha
 			select {
 			case log := <-logs:
 				// New log arrived, parse the event and forward to the user
-				event := new(ErdstallWithdrawn)
-				if err := _Erdstall.contract.UnpackLog(event, "Withdrawn", log); err != nil {
+				event := new(ErdstallTokenChallenged)
+				if err := _Erdstall.contract.UnpackLog(event, "TokenChallenged", log); err != nil {
 					return err
 				}
 				event.Raw = log
@@ -1662,12 +2022,1268 @@ func (_Erdstall *ErdstallFilterer) WatchWithdrawn(opts *bind.WatchOpts, sink cha
 	}), nil
 }
 
-// ParseWithdrawn is a log parse operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+// ParseTokenChallenged is a log parse operation binding the contract event 0xefed69ee1f43f5c9bb9b86161ef5be3f3ca8ad114409046f151759c4e19adad3.
 //
-// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
-func (_Erdstall *ErdstallFilterer) ParseWithdrawn(log types.Log) (*ErdstallWithdrawn, error) {
-	event := new(ErdstallWithdrawn)
-	if err := _Erdstall.contract.UnpackLog(event, "Withdrawn", log); err != nil {
+// Solidity: event TokenChallenged(uint64 indexed epoch, address indexed account, address indexed token)
+func (_Erdstall *ErdstallFilterer) ParseTokenChallenged(log types.Log) (*ErdstallTokenChallenged, error) {
+	event := new(ErdstallTokenChallenged)
+	if err := _Erdstall.contract.UnpackLog(event, "TokenChallenged", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ErdstallTokenDepositedIterator is returned from FilterTokenDeposited and is used to iterate over the raw logs and unpacked data for TokenDeposited events raised by the Erdstall contract.
+type ErdstallTokenDepositedIterator struct {
+	Event *ErdstallTokenDeposited // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ErdstallTokenDepositedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ErdstallTokenDeposited)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ErdstallTokenDeposited)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ErdstallTokenDepositedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ErdstallTokenDepositedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ErdstallTokenDeposited represents a TokenDeposited event raised by the Erdstall contract.
+type ErdstallTokenDeposited struct {
+	Epoch   uint64
+	Account common.Address
+	Token   common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterTokenDeposited is a free log retrieval operation binding the contract event 0x1c891b3c176c1dce4404ae29bd5192f23d8e6789b77b152af75017ba53aa1314.
+//
+// Solidity: event TokenDeposited(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) FilterTokenDeposited(opts *bind.FilterOpts, epoch []uint64, account []common.Address, token []common.Address) (*ErdstallTokenDepositedIterator, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "TokenDeposited", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ErdstallTokenDepositedIterator{contract: _Erdstall.contract, event: "TokenDeposited", logs: logs, sub: sub}, nil
+}
+
+// WatchTokenDeposited is a free log subscription operation binding the contract event 0x1c891b3c176c1dce4404ae29bd5192f23d8e6789b77b152af75017ba53aa1314.
+//
+// Solidity: event TokenDeposited(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) WatchTokenDeposited(opts *bind.WatchOpts, sink chan<- *ErdstallTokenDeposited, epoch []uint64, account []common.Address, token []common.Address) (event.Subscription, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "TokenDeposited", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ErdstallTokenDeposited)
+				if err := _Erdstall.contract.UnpackLog(event, "TokenDeposited", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTokenDeposited is a log parse operation binding the contract event 0x1c891b3c176c1dce4404ae29bd5192f23d8e6789b77b152af75017ba53aa1314.
+//
+// Solidity: event TokenDeposited(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) ParseTokenDeposited(log types.Log) (*ErdstallTokenDeposited, error) {
+	event := new(ErdstallTokenDeposited)
+	if err := _Erdstall.contract.UnpackLog(event, "TokenDeposited", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ErdstallTokenExitingIterator is returned from FilterTokenExiting and is used to iterate over the raw logs and unpacked data for TokenExiting events raised by the Erdstall contract.
+type ErdstallTokenExitingIterator struct {
+	Event *ErdstallTokenExiting // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ErdstallTokenExitingIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ErdstallTokenExiting)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ErdstallTokenExiting)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ErdstallTokenExitingIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ErdstallTokenExitingIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ErdstallTokenExiting represents a TokenExiting event raised by the Erdstall contract.
+type ErdstallTokenExiting struct {
+	Epoch   uint64
+	Account common.Address
+	Token   common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterTokenExiting is a free log retrieval operation binding the contract event 0xf761b84651786104dcc6f0cc72cb53e5efada23bad607d7da2d05b9ae838dc12.
+//
+// Solidity: event TokenExiting(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) FilterTokenExiting(opts *bind.FilterOpts, epoch []uint64, account []common.Address, token []common.Address) (*ErdstallTokenExitingIterator, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "TokenExiting", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ErdstallTokenExitingIterator{contract: _Erdstall.contract, event: "TokenExiting", logs: logs, sub: sub}, nil
+}
+
+// WatchTokenExiting is a free log subscription operation binding the contract event 0xf761b84651786104dcc6f0cc72cb53e5efada23bad607d7da2d05b9ae838dc12.
+//
+// Solidity: event TokenExiting(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) WatchTokenExiting(opts *bind.WatchOpts, sink chan<- *ErdstallTokenExiting, epoch []uint64, account []common.Address, token []common.Address) (event.Subscription, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "TokenExiting", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ErdstallTokenExiting)
+				if err := _Erdstall.contract.UnpackLog(event, "TokenExiting", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTokenExiting is a log parse operation binding the contract event 0xf761b84651786104dcc6f0cc72cb53e5efada23bad607d7da2d05b9ae838dc12.
+//
+// Solidity: event TokenExiting(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) ParseTokenExiting(log types.Log) (*ErdstallTokenExiting, error) {
+	event := new(ErdstallTokenExiting)
+	if err := _Erdstall.contract.UnpackLog(event, "TokenExiting", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ErdstallTokenWithdrawnIterator is returned from FilterTokenWithdrawn and is used to iterate over the raw logs and unpacked data for TokenWithdrawn events raised by the Erdstall contract.
+type ErdstallTokenWithdrawnIterator struct {
+	Event *ErdstallTokenWithdrawn // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ErdstallTokenWithdrawnIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ErdstallTokenWithdrawn)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ErdstallTokenWithdrawn)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ErdstallTokenWithdrawnIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ErdstallTokenWithdrawnIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ErdstallTokenWithdrawn represents a TokenWithdrawn event raised by the Erdstall contract.
+type ErdstallTokenWithdrawn struct {
+	Epoch   uint64
+	Account common.Address
+	Token   common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterTokenWithdrawn is a free log retrieval operation binding the contract event 0x97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa21.
+//
+// Solidity: event TokenWithdrawn(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) FilterTokenWithdrawn(opts *bind.FilterOpts, epoch []uint64, account []common.Address, token []common.Address) (*ErdstallTokenWithdrawnIterator, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "TokenWithdrawn", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ErdstallTokenWithdrawnIterator{contract: _Erdstall.contract, event: "TokenWithdrawn", logs: logs, sub: sub}, nil
+}
+
+// WatchTokenWithdrawn is a free log subscription operation binding the contract event 0x97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa21.
+//
+// Solidity: event TokenWithdrawn(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) WatchTokenWithdrawn(opts *bind.WatchOpts, sink chan<- *ErdstallTokenWithdrawn, epoch []uint64, account []common.Address, token []common.Address) (event.Subscription, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+	var tokenRule []interface{}
+	for _, tokenItem := range token {
+		tokenRule = append(tokenRule, tokenItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "TokenWithdrawn", epochRule, accountRule, tokenRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ErdstallTokenWithdrawn)
+				if err := _Erdstall.contract.UnpackLog(event, "TokenWithdrawn", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTokenWithdrawn is a log parse operation binding the contract event 0x97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa21.
+//
+// Solidity: event TokenWithdrawn(uint64 indexed epoch, address indexed account, address indexed token, uint256 value)
+func (_Erdstall *ErdstallFilterer) ParseTokenWithdrawn(log types.Log) (*ErdstallTokenWithdrawn, error) {
+	event := new(ErdstallTokenWithdrawn)
+	if err := _Erdstall.contract.UnpackLog(event, "TokenWithdrawn", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ErdstallWithdrawnIterator is returned from FilterWithdrawn and is used to iterate over the raw logs and unpacked data for Withdrawn events raised by the Erdstall contract.
+type ErdstallWithdrawnIterator struct {
+	Event *ErdstallWithdrawn // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ErdstallWithdrawnIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ErdstallWithdrawn)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ErdstallWithdrawn)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ErdstallWithdrawnIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ErdstallWithdrawnIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ErdstallWithdrawn represents a Withdrawn event raised by the Erdstall contract.
+type ErdstallWithdrawn struct {
+	Epoch   uint64
+	Account common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdrawn is a free log retrieval operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+//
+// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
+func (_Erdstall *ErdstallFilterer) FilterWithdrawn(opts *bind.FilterOpts, epoch []uint64, account []common.Address) (*ErdstallWithdrawnIterator, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.FilterLogs(opts, "Withdrawn", epochRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ErdstallWithdrawnIterator{contract: _Erdstall.contract, event: "Withdrawn", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdrawn is a free log subscription operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+//
+// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
+func (_Erdstall *ErdstallFilterer) WatchWithdrawn(opts *bind.WatchOpts, sink chan<- *ErdstallWithdrawn, epoch []uint64, account []common.Address) (event.Subscription, error) {
+
+	var epochRule []interface{}
+	for _, epochItem := range epoch {
+		epochRule = append(epochRule, epochItem)
+	}
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _Erdstall.contract.WatchLogs(opts, "Withdrawn", epochRule, accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ErdstallWithdrawn)
+				if err := _Erdstall.contract.UnpackLog(event, "Withdrawn", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWithdrawn is a log parse operation binding the contract event 0x0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533.
+//
+// Solidity: event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value)
+func (_Erdstall *ErdstallFilterer) ParseWithdrawn(log types.Log) (*ErdstallWithdrawn, error) {
+	event := new(ErdstallWithdrawn)
+	if err := _Erdstall.contract.UnpackLog(event, "Withdrawn", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// IERC20ABI is the input ABI used to generate the binding from.
+const IERC20ABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Approval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"}],\"name\":\"allowance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"approve\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// IERC20FuncSigs maps the 4-byte function signature to its string representation.
+var IERC20FuncSigs = map[string]string{
+	"dd62ed3e": "allowance(address,address)",
+	"095ea7b3": "approve(address,uint256)",
+	"70a08231": "balanceOf(address)",
+	"18160ddd": "totalSupply()",
+	"a9059cbb": "transfer(address,uint256)",
+	"23b872dd": "transferFrom(address,address,uint256)",
+}
+
+// IERC20 is an auto generated Go binding around an Ethereum contract.
+type IERC20 struct {
+	IERC20Caller     // Read-only binding to the contract
+	IERC20Transactor // Write-only binding to the contract
+	IERC20Filterer   // Log filterer for contract events
+}
+
+// IERC20Caller is an auto generated read-only Go binding around an Ethereum contract.
+type IERC20Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IERC20Transactor is an auto generated write-only Go binding around an Ethereum contract.
+type IERC20Transactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IERC20Filterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type IERC20Filterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// IERC20Session is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type IERC20Session struct {
+	Contract     *IERC20           // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// IERC20CallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type IERC20CallerSession struct {
+	Contract *IERC20Caller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// IERC20TransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type IERC20TransactorSession struct {
+	Contract     *IERC20Transactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// IERC20Raw is an auto generated low-level Go binding around an Ethereum contract.
+type IERC20Raw struct {
+	Contract *IERC20 // Generic contract binding to access the raw methods on
+}
+
+// IERC20CallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type IERC20CallerRaw struct {
+	Contract *IERC20Caller // Generic read-only contract binding to access the raw methods on
+}
+
+// IERC20TransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type IERC20TransactorRaw struct {
+	Contract *IERC20Transactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewIERC20 creates a new instance of IERC20, bound to a specific deployed contract.
+func NewIERC20(address common.Address, backend bind.ContractBackend) (*IERC20, error) {
+	contract, err := bindIERC20(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20{IERC20Caller: IERC20Caller{contract: contract}, IERC20Transactor: IERC20Transactor{contract: contract}, IERC20Filterer: IERC20Filterer{contract: contract}}, nil
+}
+
+// NewIERC20Caller creates a new read-only instance of IERC20, bound to a specific deployed contract.
+func NewIERC20Caller(address common.Address, caller bind.ContractCaller) (*IERC20Caller, error) {
+	contract, err := bindIERC20(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20Caller{contract: contract}, nil
+}
+
+// NewIERC20Transactor creates a new write-only instance of IERC20, bound to a specific deployed contract.
+func NewIERC20Transactor(address common.Address, transactor bind.ContractTransactor) (*IERC20Transactor, error) {
+	contract, err := bindIERC20(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20Transactor{contract: contract}, nil
+}
+
+// NewIERC20Filterer creates a new log filterer instance of IERC20, bound to a specific deployed contract.
+func NewIERC20Filterer(address common.Address, filterer bind.ContractFilterer) (*IERC20Filterer, error) {
+	contract, err := bindIERC20(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20Filterer{contract: contract}, nil
+}
+
+// bindIERC20 binds a generic wrapper to an already deployed contract.
+func bindIERC20(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(IERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IERC20 *IERC20Raw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IERC20.Contract.IERC20Caller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IERC20 *IERC20Raw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IERC20.Contract.IERC20Transactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IERC20 *IERC20Raw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IERC20.Contract.IERC20Transactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_IERC20 *IERC20CallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _IERC20.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_IERC20 *IERC20TransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _IERC20.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_IERC20 *IERC20TransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _IERC20.Contract.contract.Transact(opts, method, params...)
+}
+
+// Allowance is a free data retrieval call binding the contract method 0xdd62ed3e.
+//
+// Solidity: function allowance(address owner, address spender) view returns(uint256)
+func (_IERC20 *IERC20Caller) Allowance(opts *bind.CallOpts, owner common.Address, spender common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _IERC20.contract.Call(opts, &out, "allowance", owner, spender)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Allowance is a free data retrieval call binding the contract method 0xdd62ed3e.
+//
+// Solidity: function allowance(address owner, address spender) view returns(uint256)
+func (_IERC20 *IERC20Session) Allowance(owner common.Address, spender common.Address) (*big.Int, error) {
+	return _IERC20.Contract.Allowance(&_IERC20.CallOpts, owner, spender)
+}
+
+// Allowance is a free data retrieval call binding the contract method 0xdd62ed3e.
+//
+// Solidity: function allowance(address owner, address spender) view returns(uint256)
+func (_IERC20 *IERC20CallerSession) Allowance(owner common.Address, spender common.Address) (*big.Int, error) {
+	return _IERC20.Contract.Allowance(&_IERC20.CallOpts, owner, spender)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address account) view returns(uint256)
+func (_IERC20 *IERC20Caller) BalanceOf(opts *bind.CallOpts, account common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _IERC20.contract.Call(opts, &out, "balanceOf", account)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address account) view returns(uint256)
+func (_IERC20 *IERC20Session) BalanceOf(account common.Address) (*big.Int, error) {
+	return _IERC20.Contract.BalanceOf(&_IERC20.CallOpts, account)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address account) view returns(uint256)
+func (_IERC20 *IERC20CallerSession) BalanceOf(account common.Address) (*big.Int, error) {
+	return _IERC20.Contract.BalanceOf(&_IERC20.CallOpts, account)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_IERC20 *IERC20Caller) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _IERC20.contract.Call(opts, &out, "totalSupply")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_IERC20 *IERC20Session) TotalSupply() (*big.Int, error) {
+	return _IERC20.Contract.TotalSupply(&_IERC20.CallOpts)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_IERC20 *IERC20CallerSession) TotalSupply() (*big.Int, error) {
+	return _IERC20.Contract.TotalSupply(&_IERC20.CallOpts)
+}
+
+// Approve is a paid mutator transaction binding the contract method 0x095ea7b3.
+//
+// Solidity: function approve(address spender, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Transactor) Approve(opts *bind.TransactOpts, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.contract.Transact(opts, "approve", spender, amount)
+}
+
+// Approve is a paid mutator transaction binding the contract method 0x095ea7b3.
+//
+// Solidity: function approve(address spender, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Session) Approve(spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.Approve(&_IERC20.TransactOpts, spender, amount)
+}
+
+// Approve is a paid mutator transaction binding the contract method 0x095ea7b3.
+//
+// Solidity: function approve(address spender, uint256 amount) returns(bool)
+func (_IERC20 *IERC20TransactorSession) Approve(spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.Approve(&_IERC20.TransactOpts, spender, amount)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Transactor) Transfer(opts *bind.TransactOpts, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.contract.Transact(opts, "transfer", recipient, amount)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Session) Transfer(recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.Transfer(&_IERC20.TransactOpts, recipient, amount)
+}
+
+// Transfer is a paid mutator transaction binding the contract method 0xa9059cbb.
+//
+// Solidity: function transfer(address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20TransactorSession) Transfer(recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.Transfer(&_IERC20.TransactOpts, recipient, amount)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address sender, address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Transactor) TransferFrom(opts *bind.TransactOpts, sender common.Address, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.contract.Transact(opts, "transferFrom", sender, recipient, amount)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address sender, address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20Session) TransferFrom(sender common.Address, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.TransferFrom(&_IERC20.TransactOpts, sender, recipient, amount)
+}
+
+// TransferFrom is a paid mutator transaction binding the contract method 0x23b872dd.
+//
+// Solidity: function transferFrom(address sender, address recipient, uint256 amount) returns(bool)
+func (_IERC20 *IERC20TransactorSession) TransferFrom(sender common.Address, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _IERC20.Contract.TransferFrom(&_IERC20.TransactOpts, sender, recipient, amount)
+}
+
+// IERC20ApprovalIterator is returned from FilterApproval and is used to iterate over the raw logs and unpacked data for Approval events raised by the IERC20 contract.
+type IERC20ApprovalIterator struct {
+	Event *IERC20Approval // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *IERC20ApprovalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(IERC20Approval)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(IERC20Approval)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *IERC20ApprovalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *IERC20ApprovalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// IERC20Approval represents a Approval event raised by the IERC20 contract.
+type IERC20Approval struct {
+	Owner   common.Address
+	Spender common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterApproval is a free log retrieval operation binding the contract event 0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_IERC20 *IERC20Filterer) FilterApproval(opts *bind.FilterOpts, owner []common.Address, spender []common.Address) (*IERC20ApprovalIterator, error) {
+
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+	var spenderRule []interface{}
+	for _, spenderItem := range spender {
+		spenderRule = append(spenderRule, spenderItem)
+	}
+
+	logs, sub, err := _IERC20.contract.FilterLogs(opts, "Approval", ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20ApprovalIterator{contract: _IERC20.contract, event: "Approval", logs: logs, sub: sub}, nil
+}
+
+// WatchApproval is a free log subscription operation binding the contract event 0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_IERC20 *IERC20Filterer) WatchApproval(opts *bind.WatchOpts, sink chan<- *IERC20Approval, owner []common.Address, spender []common.Address) (event.Subscription, error) {
+
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+	var spenderRule []interface{}
+	for _, spenderItem := range spender {
+		spenderRule = append(spenderRule, spenderItem)
+	}
+
+	logs, sub, err := _IERC20.contract.WatchLogs(opts, "Approval", ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(IERC20Approval)
+				if err := _IERC20.contract.UnpackLog(event, "Approval", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseApproval is a log parse operation binding the contract event 0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_IERC20 *IERC20Filterer) ParseApproval(log types.Log) (*IERC20Approval, error) {
+	event := new(IERC20Approval)
+	if err := _IERC20.contract.UnpackLog(event, "Approval", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// IERC20TransferIterator is returned from FilterTransfer and is used to iterate over the raw logs and unpacked data for Transfer events raised by the IERC20 contract.
+type IERC20TransferIterator struct {
+	Event *IERC20Transfer // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *IERC20TransferIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(IERC20Transfer)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(IERC20Transfer)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *IERC20TransferIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *IERC20TransferIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// IERC20Transfer represents a Transfer event raised by the IERC20 contract.
+type IERC20Transfer struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterTransfer is a free log retrieval operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
+func (_IERC20 *IERC20Filterer) FilterTransfer(opts *bind.FilterOpts, from []common.Address, to []common.Address) (*IERC20TransferIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _IERC20.contract.FilterLogs(opts, "Transfer", fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return &IERC20TransferIterator{contract: _IERC20.contract, event: "Transfer", logs: logs, sub: sub}, nil
+}
+
+// WatchTransfer is a free log subscription operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
+func (_IERC20 *IERC20Filterer) WatchTransfer(opts *bind.WatchOpts, sink chan<- *IERC20Transfer, from []common.Address, to []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _IERC20.contract.WatchLogs(opts, "Transfer", fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(IERC20Transfer)
+				if err := _IERC20.contract.UnpackLog(event, "Transfer", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTransfer is a log parse operation binding the contract event 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
+func (_IERC20 *IERC20Filterer) ParseTransfer(log types.Log) (*IERC20Transfer, error) {
+	event := new(IERC20Transfer)
+	if err := _IERC20.contract.UnpackLog(event, "Transfer", log); err != nil {
 		return nil, err
 	}
 	return event, nil
@@ -1677,7 +3293,7 @@ func (_Erdstall *ErdstallFilterer) ParseWithdrawn(log types.Log) (*ErdstallWithd
 const SigABI = "[]"
 
 // SigBin is the compiled bytecode used for deploying new contracts.
-var SigBin = "0x60566023600b82828239805160001a607314601657fe5b30600052607381538281f3fe73000000000000000000000000000000000000000030146080604052600080fdfea26469706673582212205c0a198de4538b86f09c2b85f0f8cf5cba2c48312423b80a3c38fd793889aba864736f6c63430007040033"
+var SigBin = "0x60566037600b82828239805160001a607314602a57634e487b7160e01b600052600060045260246000fd5b30600052607381538281f3fe73000000000000000000000000000000000000000030146080604052600080fdfea26469706673582212207347b1d90bf051d36cd1d42826537f0bcb86a60d56955a8b06b18e0c984964bb64736f6c63430008150033"
 
 // DeploySig deploys a new Ethereum contract, binding an instance of Sig to it.
 func DeploySig(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Sig, error) {
@@ -1,4 +1,4 @@
 package bindings
 
  // ErdstallBinRuntime is the runtime part of the compiled bytecode used for deploying new contracts.
-var ErdstallBinRuntime = `60806040526004361061011f5760003560e01c806370e4a2c4116100a0578063a608911d11610064578063a608911d14610303578063ac5553ce14610323578063d0e30db014610338578063f291077314610340578063f4a85043146103605761011f565b806370e4a2c41461026e578063750f0acc1461028e578063778a2707146102ae578063854b86d9146102ce5780639b7c7725146102e35761011f565b80633f48a2a8116100e75780633f48a2a8146101d5578063585db72a1461020257806363a3a27f1461021757806364c38ddd1461023757806367eeb62b1461024c5761011f565b806303cf0678146101245780630b7042d21461014f5780630d13fd7b1461017c578063234c49a0146101935780633de970e3146101c0575b600080fd5b34801561013057600080fd5b50610139610380565b604051610146919061197c565b60405180910390f35b34801561015b57600080fd5b5061016f61016a36600461145c565b6103a4565b604051610146919061157e565b34801561018857600080fd5b506101916103e0565b005b34801561019f57600080fd5b506101b36101ae36600461152d565b61043f565b6040516101469190611973565b3480156101cc57600080fd5b5061019161045c565b3480156101e157600080fd5b506101f56101f03660046113b7565b6104b9565b6040516101469190611573565b34801561020e57600080fd5b506101396104ce565b34801561022357600080fd5b506101916102323660046113d8565b6104dd565b34801561024357600080fd5b506101916107d3565b34801561025857600080fd5b50610261610867565b604051610146919061155f565b34801561027a57600080fd5b506101b361028936600461152d565b61088b565b34801561029a57600080fd5b506101916102a9366004611513565b6108a8565b3480156102ba57600080fd5b506101916102c93660046113d8565b610a0b565b3480156102da57600080fd5b50610139610af4565b3480156102ef57600080fd5b506101b36102fe36600461152d565b610b18565b34801561030f57600080fd5b5061019161031e366004611477565b610b32565b34801561032f57600080fd5b50610139610b85565b610191610ba9565b34801561034c57600080fd5b506101b361035b366004611513565b610c65565b34801561036c57600080fd5b5061019161037b3660046113d8565b610c77565b7f000000000000000000000000000000000000000000000000000000000000000081565b6060308260000151836020015184604001516040516020016103c99493929190611782565b60405160208183030381529060405290505b919050565b6103e8610d47565b1561040e5760405162461bcd60e51b815260040161040590611812565b60405180910390fd5b610416610d61565b156104335760405162461bcd60e51b81526004016104059061194a565b61043d6000610d97565b565b600260209081526000928352604080842090915290825290205481565b6104646107d3565b6005546001600160401b03908116600101166000908152600260209081526040808320338452909152902054806104ad5760405162461bcd60e51b815260040161040590611839565b6104b681610eb1565b50565b60046020526000908152604090205460ff1681565b6005546001600160401b031681565b6104e5610d47565b156105025760405162461bcd60e51b815260040161040590611812565b61050a610d61565b156105275760405162461bcd60e51b81526004016104059061194a565b61052f610f64565b6001600160401b03166105456020850185611513565b6001600160401b03161461056b5760405162461bcd60e51b81526004016104059061191f565b6105b961057d3685900385018561145c565b83838080601f016020809104026020016040519081016040528093929190818152602001838380828437600092019190915250610b3292505050565b600260006105ca6020860186611513565b6001600160401b03166001600160401b0316815260200190815260200160002060008460200160208101906105ff91906113b7565b6001600160a01b03168152602081019190915260400160002054610659573361062e60408501602086016113b7565b6001600160a01b0316146106545760405162461bcd60e51b815260040161040590611729565b6106f2565b600060028161066b6020870187611513565b6001600160401b03166001600160401b0316815260200190815260200160002060008560200160208101906106a091906113b7565b6001600160a01b03168152602080820192909252604001600090812092909255600391906106d090860186611513565b6001600160401b03168152602081019190915260400160002080546000190190555b6040830135600160006107086020870187611513565b6001600160401b03166001600160401b03168152602001908152602001600020600085602001602081019061073d91906113b7565b6001600160a01b03166001600160a01b031681526020019081526020016000208190555082602001602081019061077491906113b7565b6001600160a01b031661078a6020850185611513565b6001600160401b03167f874e6a4ac09c210cf4cd123caaf949f43c3c6f07f2f46f26ccc5b0fd881c3d0485604001356040516107c69190611973565b60405180910390a3505050565b6107db610d47565b156107e55761043d565b6107ed610d61565b6108095760405162461bcd60e51b8152600401610405906118b1565b60006001610815610f76565b6005805467ffffffffffffffff1916929091036001600160401b0381169283179091556040519092507f5e20151a99b0432a9ac06d33b91b77d3134ce0638cc70d7df042947ca48a2caf90600090a250565b7f000000000000000000000000000000000000000000000000000000000000000081565b600160209081526000928352604080842090915290825290205481565b6108b0610d47565b156108cd5760405162461bcd60e51b815260040161040590611812565b6108d5610d61565b156108f25760405162461bcd60e51b81526004016104059061194a565b6108fa610f64565b6001600160401b0316816001600160401b03161061092a5760405162461bcd60e51b815260040161040590611755565b6001600160401b03811660009081526001602090815260408083203384529091529020548061096b5760405162461bcd60e51b81526004016104059061165f565b6001600160401b038216600090815260016020908152604080832033808552925280832083905551909183156108fc02918491818181858888f193505050501580156109bb573d6000803e3d6000fd5b50336001600160a01b0316826001600160401b03167f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d3533836040516109ff9190611973565b60405180910390a35050565b610a13610d47565b15610a305760405162461bcd60e51b815260040161040590611812565b610a38610d61565b15610a555760405162461bcd60e51b81526004016104059061194a565b33610a6660408501602086016113b7565b6001600160a01b031614610a8c5760405162461bcd60e51b815260040161040590611696565b610a94610f76565b6001600160401b0316610aaa6020850185611513565b6001600160401b031614610ad05760405162461bcd60e51b8152600401610405906116cd565b610ae261057d3685900385018561145c565b610aef8360400135610d97565b505050565b7f000000000000000000000000000000000000000000000000000000000000000081565b600060208181529281526040808220909352908152205481565b610b65610b3e836103a4565b827f0000000000000000000000000000000000000000000000000000000000000000610f82565b610b815760405162461bcd60e51b8152600401610405906115d1565b5050565b7f000000000000000000000000000000000000000000000000000000000000000081565b610bb1610d47565b15610bce5760405162461bcd60e51b815260040161040590611812565b610bd6610d61565b15610bf35760405162461bcd60e51b81526004016104059061194a565b6000610bfd610fbd565b6001600160401b038116600081815260208181526040808320338085529252918290208054349081019091559151939450927fe007c38a05fbf2010d1c1ed20f91e675c91d41699926124738a8c3fe9fc791b491610c5a91611973565b60405180910390a350565b60036020526000908152604090205481565b610c7f6107d3565b33610c9060408501602086016113b7565b6001600160a01b031614610cb65760405162461bcd60e51b81526004016104059061187a565b6005546001600160401b0316610ccf6020850185611513565b6001600160401b031614610cf55760405162461bcd60e51b8152600401610405906117db565b610d0761057d3685900385018561145c565b6005546001600160401b039081166001011660009081526020818152604080832033845290915290819020549084013501610d4181610eb1565b50505050565b6005546001600160401b031667fffffffffffffffe141590565b60008060036000610d70610f76565b6001600160401b03166001600160401b031681526020019081526020016000205411905090565b610d9f610fcc565b15610dbc5760405162461bcd60e51b8152600401610405906115fc565b6000610dc6610f64565b6001600160401b038116600090815260026020908152604080832033845290915290205490915015610e0a5760405162461bcd60e51b8152600401610405906116fd565b6001600160401b038116600090815260208181526040808320338452909152902054820180610e4b5760405162461bcd60e51b815260040161040590611633565b6001600160401b038216600081815260026020908152604080832033808552908352818420869055848452600390925280832080546001019055519092917f9f71686e9e2eed0a0a99340b1c3b230369f255b1d452130cead54f8308654dfd91a3505050565b3360009081526004602052604090205460ff1615610ee15760405162461bcd60e51b8152600401610405906118e8565b33600081815260046020526040808220805460ff191660011790555183156108fc0291849190818181858888f19350505050158015610f24573d6000803e3d6000fd5b5060055460405133916001600160401b0316907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d353390610c5a908590611973565b60006002610f70611088565b03905090565b60006003610f70611088565b600080610f9585805190602001206110ed565b90506000610fa3828661113e565b6001600160a01b0390811690851614925050509392505050565b6000610fc7611088565b905090565b60007f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f000000000000000000000000000000000000000000000000000000000000000043036001600160401b03168161105557fe5b067f0000000000000000000000000000000000000000000000000000000000000000036001600160401b03161115905090565b60007f00000000000000000000000000000000000000000000000000000000000000006001600160401b03167f000000000000000000000000000000000000000000000000000000000000000043036001600160401b0316816110e757fe5b04905090565b604080517f19457468657265756d205369676e6564204d6573736167653a0a333200000000602080830191909152603c8083019490945282518083039094018452605c909101909152815191012090565b60008151604114611196576040805162461bcd60e51b815260206004820152601f60248201527f45434453413a20696e76616c6964207369676e6174757265206c656e67746800604482015290519081900360640190fd5b60208201516040830151606084015160001a7f7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a08211156112075760405162461bcd60e51b81526004018080602001828103825260228152602001806119b46022913960400191505060405180910390fd5b8060ff16601b1415801561121f57508060ff16601c14155b1561125b5760405162461bcd60e51b81526004018080602001828103825260228152602001806119d66022913960400191505060405180910390fd5b600060018783868660405160008152602001604052604051808581526020018460ff1681526020018381526020018281526020019450505050506020604051602081039080840390855afa1580156112b7573d6000803e3d6000fd5b5050604051601f1901519150506001600160a01b03811661131f576040805162461bcd60e51b815260206004820152601860248201527f45434453413a20696e76616c6964207369676e61747572650000000000000000604482015290519081900360640190fd5b9695505050505050565b80356001600160a01b03811681146103db57600080fd5b600060608284031215611351578081fd5b604051606081018181106001600160401b038211171561136d57fe5b60405290508061137c836113a0565b815261138a60208401611329565b6020820152604083013560408201525092915050565b80356001600160401b03811681146103db57600080fd5b6000602082840312156113c8578081fd5b6113d182611329565b9392505050565b600080600083850360808112156113ed578283fd5b60608112156113fa578283fd5b5083925060608401356001600160401b0380821115611417578384fd5b818601915086601f83011261142a578384fd5b813581811115611438578485fd5b876020828501011115611449578485fd5b6020830194508093505050509250925092565b60006060828403121561146d578081fd5b6113d18383611340565b60008060808385031215611489578182fd5b6114938484611340565b915060608301356001600160401b03808211156114ae578283fd5b818501915085601f8301126114c1578283fd5b8135818111156114cd57fe5b6114e0601f8201601f1916602001611990565b91508082528660208285010111156114f6578384fd5b806020840160208401378101602001929092525090939092509050565b600060208284031215611524578081fd5b6113d1826113a0565b6000806040838503121561153f578182fd5b611548836113a0565b915061155660208401611329565b90509250929050565b6001600160a01b0391909116815260200190565b901515815260200190565b6000602080835283518082850152825b818110156115aa5785810183015185820160400152820161158e565b818111156115bb5783604083870101525b50601f01601f1916929092016040019392505050565b602080825260119082015270696e76616c6964207369676e617475726560781b604082015260600190565b6020808252601b908201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604082015260600190565b6020808252601290820152716e6f2076616c756520696e2073797374656d60701b604082015260600190565b60208082526018908201527f6e6f7468696e67206c65667420746f2077697468647261770000000000000000604082015260600190565b60208082526017908201527f6368616c6c656e67653a2077726f6e672073656e646572000000000000000000604082015260600190565b6020808252601690820152750c6d0c2d8d8cadcceca7440eee4dedcce40cae0dec6d60531b604082015260600190565b602080825260129082015271185b1c9958591e4818da185b1b195b99d95960721b604082015260600190565b60208082526012908201527132bc34ba1d103bb937b7339039b2b73232b960711b604082015260600190565b60208082526013908201527277697468647261773a20746f6f206561726c7960681b604082015260600190565b60a0808252600f908201526e4572647374616c6c42616c616e636560881b60c08201526001600160a01b0394851660208201526001600160401b0393909316604084015292166060820152608081019190915260e00190565b6020808252601b908201527f776974686472617746726f7a656e3a2077726f6e672065706f63680000000000604082015260600190565b6020808252600d908201526c383630b9b6b090333937bd32b760991b604082015260600190565b60208082526021908201527f6e6f7468696e67206c65667420746f207769746864726177202866726f7a656e6040820152602960f81b606082015260800190565b6020808252601c908201527f776974686472617746726f7a656e3a2077726f6e672073656e64657200000000604082015260600190565b6020808252601a908201527f6e6f206368616c6c656e676520696e206c6173742065706f6368000000000000604082015260600190565b6020808252601a908201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604082015260600190565b6020808252601190820152700caf0d2e87440eee4dedcce40cae0dec6d607b1b604082015260600190565b6020808252600f908201526e706c61736d6120667265657a696e6760881b604082015260600190565b90815260200190565b6001600160401b0391909116815260200190565b6040518181016001600160401b03811182821017156119ab57fe5b60405291905056fe45434453413a20696e76616c6964207369676e6174757265202773272076616c756545434453413a20696e76616c6964207369676e6174757265202776272076616c7565a264697066735822122021141d4d04ceab273065b3c35cd6c62bb9fb93b529f2aaf72b215841f4cbb22664736f6c63430007040033`
+var ErdstallBinRuntime = `6080604052600436106101ee5760003560e01c806367a684151161010d5780639b7c7725116100a0578063ccc400971161006f578063ccc40097146106b0578063d0e30db0146106d0578063e99ce0ad146106d8578063f291077314610716578063f4a850431461074357600080fd5b80639b7c7725146105ec578063a608911d14610621578063ac5553ce14610641578063bfe275e91461067557600080fd5b8063750f0acc116100dc578063750f0acc1461053a578063778a27071461055a578063854b86d91461057a57806391c99dd9146105ae57600080fd5b806367a684151461047657806367eeb62b1461049657806370e4a2c4146104e2578063738508c81461051a57600080fd5b80633f48a2a811610185578063547780ce11610154578063547780ce146103e3578063585db72a1461042157806363a3a27f1461044157806364c38ddd1461046157600080fd5b80633f48a2a8146103435780634075d3f2146103835780634e6c2ded146103a3578063524a2bb3146103c357600080fd5b8063234c49a0116101c1578063234c49a0146102a8578063338b5dea146102ee57806335b892be1461030e5780633de970e31461032e57600080fd5b806303cf0678146101f35780630b7042d2146102445780630d13fd7b1461027157806310e6312f14610288575b600080fd5b3480156101ff57600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b6040516001600160401b0390911681526020015b60405180910390f35b34801561025057600080fd5b5061026461025f366004612a5f565b610763565b60405161023b9190612a82565b34801561027d57600080fd5b506102866107e8565b005b34801561029457600080fd5b506102866102a3366004612b18565b610847565b3480156102b457600080fd5b506102e06102c3366004612b72565b600460209081526000928352604080842090915290825290205481565b60405190815260200161023b565b3480156102fa57600080fd5b50610286610309366004612ba5565b6109dc565b34801561031a57600080fd5b50610286610329366004612b18565b610be6565b34801561033a57600080fd5b50610286610fbb565b34801561034f57600080fd5b5061037361035e366004612bcf565b60076020526000908152604090205460ff1681565b604051901515815260200161023b565b34801561038f57600080fd5b5061028661039e366004612bcf565b611030565b3480156103af57600080fd5b506102866103be366004612bcf565b6110b8565b3480156103cf57600080fd5b506102866103de366004612cee565b61110d565b3480156103ef57600080fd5b506102e06103fe366004612d3c565b600260209081526000938452604080852082529284528284209052825290205481565b34801561042d57600080fd5b50600954610227906001600160401b031681565b34801561044d57600080fd5b5061028661045c366004612d7f565b611180565b34801561046d57600080fd5b506102866114d6565b34801561048257600080fd5b50610264610491366004612dc0565b611597565b3480156104a257600080fd5b506104ca7f000000000000000000000000000000000000000000000000000000000000000081565b6040516001600160a01b03909116815260200161023b565b3480156104ee57600080fd5b506102e06104fd366004612b72565b600160209081526000928352604080842090915290825290205481565b34801561052657600080fd5b50610286610535366004612b18565b611618565b34801561054657600080fd5b50610286610555366004612ddc565b6117af565b34801561056657600080fd5b50610286610575366004612d7f565b611958565b34801561058657600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b3480156105ba57600080fd5b506102e06105c9366004612d3c565b600360209081526000938452604080852082529284528284209052825290205481565b3480156105f857600080fd5b506102e0610607366004612b72565b600060208181529281526040808220909352908152205481565b34801561062d57600080fd5b5061028661063c366004612df7565b611a95565b34801561064d57600080fd5b506102277f000000000000000000000000000000000000000000000000000000000000000081565b34801561068157600080fd5b50610373610690366004612e2f565b600860209081526000928352604080842090915290825290205460ff1681565b3480156106bc57600080fd5b506102866106cb366004612b72565b611aa1565b610286611d0d565b3480156106e457600080fd5b506102e06106f3366004612d3c565b600560209081526000938452604080852082529284528284209052825290205481565b34801561072257600080fd5b506102e0610731366004612ddc565b60066020526000908152604090205481565b34801561074f57600080fd5b5061028661075e366004612d7f565b611de0565b8051602080830151604080850151815160a0948101859052600f60c08201526e4572647374616c6c42616c616e636560881b60e082015230928101929092526001600160401b039094166060828101919091526001600160a01b03909216608082015291820192909252610100015b6040516020818303038152906040529050919050565b6107f0611f2a565b156108165760405162461bcd60e51b815260040161080d90612e4b565b60405180910390fd5b61081e611f55565b1561083b5760405162461bcd60e51b815260040161080d90612e72565b6108456000611f8b565b565b61084f611f2a565b1561086c5760405162461bcd60e51b815260040161080d90612e4b565b610874611f55565b156108915760405162461bcd60e51b815260040161080d90612e72565b336108a26040850160208601612bcf565b6001600160a01b0316146108f85760405162461bcd60e51b815260206004820152601c60248201527f6368616c6c656e6765546f6b656e3a2077726f6e672073656e64657200000000604482015260640161080d565b610900612142565b6001600160401b03166109166020850185612ddc565b6001600160401b03161461096c5760405162461bcd60e51b815260206004820152601b60248201527f6368616c6c656e6765546f6b656e3a2077726f6e672065706f63680000000000604482015260640161080d565b6109ba61097e36859003850185612dc0565b83838080601f01602080910402602001604051908101604052809392919081815260200183838082843760009201919091525061110d92505050565b6109d76109cd6060850160408601612bcf565b8460600135612154565b505050565b6109e4611f2a565b15610a015760405162461bcd60e51b815260040161080d90612e4b565b610a09611f55565b15610a265760405162461bcd60e51b815260040161080d90612e72565b6001600160a01b038216610a7c5760405162461bcd60e51b815260206004820152601860248201527f6465706f736974546f6b656e3a207a65726f20746f6b656e0000000000000000604482015260640161080d565b6000610a8661234c565b6040516323b872dd60e01b8152336004820152306024820152604481018490529091506001600160a01b038416906323b872dd906064016020604051808303816000875af1158015610adc573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610b009190612e9b565b610b4c5760405162461bcd60e51b815260206004820152601d60248201527f6465706f736974546f6b656e3a207472616e73666572206661696c6564000000604482015260640161080d565b6001600160401b038116600090815260026020908152604080832033845282528083206001600160a01b038716845290915281208054849290610b90908490612ed3565b90915550506040518281526001600160a01b0384169033906001600160401b038416907f1c891b3c176c1dce4404ae29bd5192f23d8e6789b77b152af75017ba53aa1314906020015b60405180910390a4505050565b610bee611f2a565b15610c0b5760405162461bcd60e51b815260040161080d90612e4b565b610c13611f55565b15610c305760405162461bcd60e51b815260040161080d90612e72565b610c3861235b565b6001600160401b0316610c4e6020850185612ddc565b6001600160401b031614610c9d5760405162461bcd60e51b81526020600482015260166024820152750caf0d2e8a8ded6cadc7440eee4dedcce40cae0dec6d60531b604482015260640161080d565b610caf61097e36859003850185612dc0565b60056000610cc06020860186612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000846020016020810190610cf59190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000846040016020810190610d2a9190612bcf565b6001600160a01b03166001600160a01b0316815260200190815260200160002054600003610dbe5733610d636040850160208601612bcf565b6001600160a01b031614610db95760405162461bcd60e51b815260206004820152601760248201527f65786974546f6b656e3a2077726f6e672073656e646572000000000000000000604482015260640161080d565b610e97565b6000600581610dd06020870187612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000856020016020810190610e059190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000856040016020810190610e3a9190612bcf565b6001600160a01b0316815260208082019290925260400160009081209290925560069190610e6a90860186612ddc565b6001600160401b0316815260208101919091526040016000908120805491610e9183612ee6565b91905055505b606083013560036000610ead6020870187612ddc565b6001600160401b03166001600160401b031681526020019081526020016000206000856020016020810190610ee29190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000206000856040016020810190610f179190612bcf565b6001600160a01b03166001600160a01b0316815260200190815260200160002081905550826040016020810190610f4e9190612bcf565b6001600160a01b0316610f676040850160208601612bcf565b6001600160a01b0316610f7d6020860186612ddc565b6001600160401b03167ff761b84651786104dcc6f0cc72cb53e5efada23bad607d7da2d05b9ae838dc128660600135604051610bd991815260200190565b610fc36114d6565b600060046000610fde6009546001600160401b031660010190565b6001600160401b03168152602080820192909252604090810160009081203382529092529020549050806110245760405162461bcd60e51b815260040161080d90612efd565b61102d81612367565b50565b6110386114d6565b6000600560006110536009546001600160401b031660010190565b6001600160401b031681526020808201929092526040908101600090812033825283528181206001600160a01b03861682529092529020549050806110aa5760405162461bcd60e51b815260040161080d90612efd565b6110b4828261244a565b5050565b6110c0611f2a565b156110dd5760405162461bcd60e51b815260040161080d90612e4b565b6110e5611f55565b156111025760405162461bcd60e51b815260040161080d90612e72565b61102d816000612154565b61114061111983611597565b827f0000000000000000000000000000000000000000000000000000000000000000612601565b6110b45760405162461bcd60e51b8152602060048201526011602482015270696e76616c6964207369676e617475726560781b604482015260640161080d565b611188611f2a565b156111a55760405162461bcd60e51b815260040161080d90612e4b565b6111ad611f55565b156111ca5760405162461bcd60e51b815260040161080d90612e72565b6111d261235b565b6001600160401b03166111e86020850185612ddc565b6001600160401b0316146112325760405162461bcd60e51b81526020600482015260116024820152700caf0d2e87440eee4dedcce40cae0dec6d607b1b604482015260640161080d565b61128061124436859003850185612a5f565b83838080601f016020809104026020016040519081016040528093929190818152602001838380828437600092019190915250611a9592505050565b600460006112916020860186612ddc565b6001600160401b03166001600160401b0316815260200190815260200160002060008460200160208101906112c69190612bcf565b6001600160a01b03166001600160a01b031681526020019081526020016000205460000361134f57336112ff6040850160208601612bcf565b6001600160a01b03161461134a5760405162461bcd60e51b815260206004820152601260248201527132bc34ba1d103bb937b7339039b2b73232b960711b604482015260640161080d565b6113f3565b60006004816113616020870187612ddc565b6001600160401b03166001600160401b0316815260200190815260200160002060008560200160208101906113969190612bcf565b6001600160a01b03168152602080820192909252604001600090812092909255600691906113c690860186612ddc565b6001600160401b03168152602081019190915260400160009081208054916113ed83612ee6565b91905055505b6040830135600160006114096020870187612ddc565b6001600160401b03166001600160401b03168152602001908152602001600020600085602001602081019061143e9190612bcf565b6001600160a01b03166001600160a01b03168152602001908152602001600020819055508260200160208101906114759190612bcf565b6001600160a01b031661148b6020850185612ddc565b6001600160401b03167f874e6a4ac09c210cf4cd123caaf949f43c3c6f07f2f46f26ccc5b0fd881c3d0485604001356040516114c991815260200190565b60405180910390a3505050565b6114de611f2a565b156114e557565b6114ed611f55565b6115395760405162461bcd60e51b815260206004820152601a60248201527f6e6f206368616c6c656e676520696e206c6173742065706f6368000000000000604482015260640161080d565b60006001611545612142565b6009805467ffffffffffffffff1916929091036001600160401b0381169283179091556040519092507f5e20151a99b0432a9ac06d33b91b77d3134ce0638cc70d7df042947ca48a2caf90600090a250565b8051602080830151604080850151606086810151835160c0968101879052601460e0820152734572647374616c6c546f6b656e42616c616e636560601b61010082015230948101949094526001600160401b03909616838201526001600160a01b039384166080840152921660a082015291820192909252610120016107d2565b6116206114d6565b336116316040850160208601612bcf565b6001600160a01b0316146116915760405162461bcd60e51b815260206004820152602160248201527f776974686472617746726f7a656e546f6b656e3a2077726f6e672073656e64656044820152603960f91b606482015260840161080d565b6009546001600160401b03166116aa6020850185612ddc565b6001600160401b0316146117005760405162461bcd60e51b815260206004820181905260248201527f776974686472617746726f7a656e546f6b656e3a2077726f6e672065706f6368604482015260640161080d565b61171261097e36859003850185612dc0565b60006002600061172d6009546001600160401b031660010190565b6001600160401b031681526020808201929092526040908101600090812033825290925280822091906117669060608801908801612bcf565b6001600160a01b0316815260208101919091526040016000205461178e906060860135612ed3565b90506117a96117a36060860160408701612bcf565b8261244a565b50505050565b6117b7611f2a565b156117d45760405162461bcd60e51b815260040161080d90612e4b565b6117dc611f55565b156117f95760405162461bcd60e51b815260040161080d90612e72565b61180161235b565b6001600160401b0316816001600160401b0316106118575760405162461bcd60e51b815260206004820152601360248201527277697468647261773a20746f6f206561726c7960681b604482015260640161080d565b6001600160401b0381166000908152600160209081526040808320338452909152902054806118c35760405162461bcd60e51b81526020600482015260186024820152776e6f7468696e67206c65667420746f20776974686472617760401b604482015260640161080d565b6001600160401b038216600090815260016020908152604080832033808552925280832083905551909183156108fc02918491818181858888f19350505050158015611913573d6000803e3d6000fd5b5060405181815233906001600160401b038416907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d35339060200160405180910390a35050565b611960611f2a565b1561197d5760405162461bcd60e51b815260040161080d90612e4b565b611985611f55565b156119a25760405162461bcd60e51b815260040161080d90612e72565b336119b36040850160208601612bcf565b6001600160a01b031614611a095760405162461bcd60e51b815260206004820152601760248201527f6368616c6c656e67653a2077726f6e672073656e646572000000000000000000604482015260640161080d565b611a11612142565b6001600160401b0316611a276020850185612ddc565b6001600160401b031614611a765760405162461bcd60e51b81526020600482015260166024820152750c6d0c2d8d8cadcceca7440eee4dedcce40cae0dec6d60531b604482015260640161080d565b611a8861124436859003850185612a5f565b6109d78360400135611f8b565b61114061111983610763565b611aa9611f2a565b15611ac65760405162461bcd60e51b815260040161080d90612e4b565b611ace611f55565b15611aeb5760405162461bcd60e51b815260040161080d90612e72565b611af361235b565b6001600160401b0316826001600160401b031610611b535760405162461bcd60e51b815260206004820152601860248201527f7769746864726177546f6b656e3a20746f6f206561726c790000000000000000604482015260640161080d565b6001600160401b038216600090815260036020908152604080832033845282528083206001600160a01b038516845290915290205480611bd05760405162461bcd60e51b81526020600482015260186024820152776e6f7468696e67206c65667420746f20776974686472617760401b604482015260640161080d565b6001600160401b0383166000908152600360209081526040808320338085529083528184206001600160a01b0387168086529352818420939093555163a9059cbb60e01b81526004810192909252602482018390529063a9059cbb906044016020604051808303816000875af1158015611c4e573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190611c729190612e9b565b611cbe5760405162461bcd60e51b815260206004820152601e60248201527f7769746864726177546f6b656e3a207472616e73666572206661696c65640000604482015260640161080d565b816001600160a01b0316336001600160a01b0316846001600160401b03167f97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa2184604051610bd991815260200190565b611d15611f2a565b15611d325760405162461bcd60e51b815260040161080d90612e4b565b611d3a611f55565b15611d575760405162461bcd60e51b815260040161080d90612e72565b6000611d6161234c565b6001600160401b038116600090815260208181526040808320338452909152812080549293503492909190611d97908490612ed3565b909155505060405134815233906001600160401b038316907fe007c38a05fbf2010d1c1ed20f91e675c91d41699926124738a8c3fe9fc791b4906020015b60405180910390a350565b611de86114d6565b33611df96040850160208601612bcf565b6001600160a01b031614611e4f5760405162461bcd60e51b815260206004820152601c60248201527f776974686472617746726f7a656e3a2077726f6e672073656e64657200000000604482015260640161080d565b6009546001600160401b0316611e686020850185612ddc565b6001600160401b031614611ebe5760405162461bcd60e51b815260206004820152601b60248201527f776974686472617746726f7a656e3a2077726f6e672065706f63680000000000604482015260640161080d565b611ed061124436859003850185612a5f565b6000806000611eea6009546001600160401b031660010190565b6001600160401b03168152602080820192909252604090810160009081203382529092529081902054611f1f91860135612ed3565b90506117a981612367565b6000611f3e60016001600160401b03612f3e565b6009546001600160401b0390811691161415919050565b60008060066000611f64612142565b6001600160401b03166001600160401b031681526020019081526020016000205411905090565b611f9361268a565b15611fe05760405162461bcd60e51b815260206004820152601b60248201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604482015260640161080d565b6000611fea61235b565b6001600160401b0381166000908152600460209081526040808320338452909152902054909150156120535760405162461bcd60e51b8152602060048201526012602482015271185b1c9958591e4818da185b1b195b99d95960721b604482015260640161080d565b6001600160401b03811660009081526020818152604080832033845290915281205461207f9084612ed3565b9050600081116120c65760405162461bcd60e51b81526020600482015260126024820152716e6f2076616c756520696e2073797374656d60701b604482015260640161080d565b6001600160401b038216600081815260046020908152604080832033845282528083208590559282526006905290812080549161210283612f65565b909155505060405133906001600160401b038416907f9f71686e9e2eed0a0a99340b1c3b230369f255b1d452130cead54f8308654dfd90600090a3505050565b6000600361214e612745565b03905090565b61215c61268a565b156121a95760405162461bcd60e51b815260206004820152601b60248201527f696e206368616c6c656e676520726573706f6e73652070686173650000000000604482015260640161080d565b60006121b361235b565b6001600160401b038116600090815260056020908152604080832033845282528083206001600160a01b03881684529091529020549091501561222d5760405162461bcd60e51b8152602060048201526012602482015271185b1c9958591e4818da185b1b195b99d95960721b604482015260640161080d565b6001600160401b038116600090815260026020908152604080832033845282528083206001600160a01b038716845290915281205461226c9084612ed3565b9050600081116122b35760405162461bcd60e51b81526020600482015260126024820152716e6f2076616c756520696e2073797374656d60701b604482015260640161080d565b6001600160401b038216600081815260056020908152604080832033845282528083206001600160a01b038916845282528083208590559282526006905290812080549161230083612f65565b90915550506040516001600160a01b0385169033906001600160401b038516907fefed69ee1f43f5c9bb9b86161ef5be3f3ca8ad114409046f151759c4e19adad390600090a450505050565b6000612356612745565b905090565b6000600261214e612745565b3360009081526007602052604090205460ff16156123c75760405162461bcd60e51b815260206004820152601a60248201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604482015260640161080d565b33600081815260076020526040808220805460ff191660011790555183156108fc0291849190818181858888f1935050505015801561240a573d6000803e3d6000fd5b5060095460405182815233916001600160401b0316907f0ff23c4cdc2733f56d8f04d7a351c4332a1cd3334287ed5b2e9c6a28da9d353390602001611dd5565b3360009081526008602090815260408083206001600160a01b038616845290915290205460ff16156124be5760405162461bcd60e51b815260206004820152601a60248201527f616c72656164792077697468647261776e202866726f7a656e29000000000000604482015260640161080d565b3360008181526008602090815260408083206001600160a01b038716808552925291829020805460ff19166001179055905163a9059cbb60e01b81526004810192909252602482018390529063a9059cbb906044016020604051808303816000875af1158015612532573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906125569190612e9b565b6125ae5760405162461bcd60e51b8152602060048201526024808201527f776974686472617746726f7a656e546f6b656e3a207472616e736665722066616044820152631a5b195960e21b606482015260840161080d565b6009546040518281526001600160a01b0384169133916001600160401b03909116907f97302dc80c0f7e286ebf0acd5ca800cd88f944e79eb5d13bb2fc090a3713aa219060200160405180910390a45050565b60008061266285805190602001206040517f19457468657265756d205369676e6564204d6573736167653a0a3332000000006020820152603c8101829052600090605c01604051602081830303815290604052805190602001209050919050565b90506000612670828661279c565b6001600160a01b0390811690851614925050509392505050565b60006001600160401b037f0000000000000000000000000000000000000000000000000000000000000000167f00000000000000000000000000000000000000000000000000000000000000006127017f000000000000000000000000000000000000000000000000000000000000000043612f3e565b61270b9190612f94565b612735907f0000000000000000000000000000000000000000000000000000000000000000612f3e565b6001600160401b03161115905090565b60007f00000000000000000000000000000000000000000000000000000000000000006127927f000000000000000000000000000000000000000000000000000000000000000043612f3e565b6123569190612fba565b600081516041146127ef5760405162461bcd60e51b815260206004820152601f60248201527f45434453413a20696e76616c6964207369676e6174757265206c656e67746800604482015260640161080d565b60208201516040830151606084015160001a7f7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a082111561287c5760405162461bcd60e51b815260206004820152602260248201527f45434453413a20696e76616c6964207369676e6174757265202773272076616c604482015261756560f01b606482015260840161080d565b8060ff16601b1415801561289457508060ff16601c14155b156128ec5760405162461bcd60e51b815260206004820152602260248201527f45434453413a20696e76616c6964207369676e6174757265202776272076616c604482015261756560f01b606482015260840161080d565b6040805160008082526020820180845289905260ff841692820192909252606081018590526080810184905260019060a0016020604051602081039080840390855afa158015612940573d6000803e3d6000fd5b5050604051601f1901519150506001600160a01b0381166129a35760405162461bcd60e51b815260206004820152601860248201527f45434453413a20696e76616c6964207369676e61747572650000000000000000604482015260640161080d565b93505050505b92915050565b634e487b7160e01b600052604160045260246000fd5b80356001600160401b03811681146129dc57600080fd5b919050565b80356001600160a01b03811681146129dc57600080fd5b600060608284031215612a0a57600080fd5b604051606081018181106001600160401b0382111715612a2c57612a2c6129af565b604052905080612a3b836129c5565b8152612a49602084016129e1565b6020820152604083013560408201525092915050565b600060608284031215612a7157600080fd5b612a7b83836129f8565b9392505050565b600060208083528351808285015260005b81811015612aaf57858101830151858201604001528201612a93565b506000604082860101526040601f19601f8301168501019250505092915050565b60008083601f840112612ae257600080fd5b5081356001600160401b03811115612af957600080fd5b602083019150836020828501011115612b1157600080fd5b9250929050565b600080600083850360a0811215612b2e57600080fd5b6080811215612b3c57600080fd5b5083925060808401356001600160401b03811115612b5957600080fd5b612b6586828701612ad0565b9497909650939450505050565b60008060408385031215612b8557600080fd5b612b8e836129c5565b9150612b9c602084016129e1565b90509250929050565b60008060408385031215612bb857600080fd5b612bc1836129e1565b946020939093013593505050565b600060208284031215612be157600080fd5b612a7b826129e1565b600060808284031215612bfc57600080fd5b604051608081018181106001600160401b0382111715612c1e57612c1e6129af565b604052905080612c2d836129c5565b8152612c3b602084016129e1565b6020820152612c4c604084016129e1565b6040820152606083013560608201525092915050565b600082601f830112612c7357600080fd5b81356001600160401b0380821115612c8d57612c8d6129af565b604051601f8301601f19908116603f01168101908282118183101715612cb557612cb56129af565b81604052838152866020858801011115612cce57600080fd5b836020870160208301376000602085830101528094505050505092915050565b60008060a08385031215612d0157600080fd5b612d0b8484612bea565b915060808301356001600160401b03811115612d2657600080fd5b612d3285828601612c62565b9150509250929050565b600080600060608486031215612d5157600080fd5b612d5a846129c5565b9250612d68602085016129e1565b9150612d76604085016129e1565b90509250925092565b60008060008385036080811215612d9557600080fd5b6060811215612da357600080fd5b5083925060608401356001600160401b03811115612b5957600080fd5b600060808284031215612dd257600080fd5b612a7b8383612bea565b600060208284031215612dee57600080fd5b612a7b826129c5565b60008060808385031215612e0a57600080fd5b612e1484846129f8565b915060608301356001600160401b03811115612d2657600080fd5b60008060408385031215612e4257600080fd5b612b8e836129e1565b6020808252600d908201526c383630b9b6b090333937bd32b760991b604082015260600190565b6020808252600f908201526e706c61736d6120667265657a696e6760881b604082015260600190565b600060208284031215612ead57600080fd5b81518015158114612a7b57600080fd5b634e487b7160e01b600052601160045260246000fd5b808201808211156129a9576129a9612ebd565b600081612ef557612ef5612ebd565b506000190190565b60208082526021908201527f6e6f7468696e67206c65667420746f207769746864726177202866726f7a656e6040820152602960f81b606082015260800190565b6001600160401b03828116828216039080821115612f5e57612f5e612ebd565b5092915050565b600060018201612f7757612f77612ebd565b5060010190565b634e487b7160e01b600052601260045260246000fd5b60006001600160401b0380841680612fae57612fae612f7e565b92169190910692915050565b60006001600160401b0380841680612fd457612fd4612f7e565b9216919091049291505056fea2646970667358221220cab85c986b646c67490623f85a4a5c8c0b84684da14e3cb75cd2b9cb57d02daa64736f6c63430008150033`
@@ -1,9 +1,10 @@
 // SPDX-License-Identifier: Apache-2.0
 
-pragma solidity ^0.7.0;
+pragma solidity ^0.8.0;
 pragma experimental ABIEncoderV2;
 
 import "./Sig.sol";
+import "./IERC20.sol";
 
 contract Erdstall {
     // The epoch-balance statements signed by the TEE.
@@ -13,7 +14,15 @@ contract Erdstall {
         uint256 value;
     }
 
-    uint64 constant notFrozen = uint64(-2); // use 2nd-highest number to indicate not-frozen
+    // The epoch-balance statements of ERC-20 tokens signed by the TEE.
+    struct TokenBalance {
+        uint64 epoch;
+        address account;
+        address token;
+        uint256 value;
+    }
+
+    uint64 constant notFrozen = type(uint64).max - 1; // use 2nd-highest number to indicate not-frozen
 
     // Parameters set during deployment.
     address public immutable tee; // yummi 🍵
@@ -23,15 +32,23 @@ contract Erdstall {
 
     mapping(uint64 => mapping(address => uint256)) public deposits; // epoch => account => balance value
     mapping(uint64 => mapping(address => uint256)) public exits; // epoch => account => balance value
+    mapping(uint64 => mapping(address => mapping(address => uint256))) public tokenDeposits; // epoch => account => token => balance value
+    mapping(uint64 => mapping(address => mapping(address => uint256))) public tokenExits; // epoch => account => token => balance value
     mapping(uint64 => mapping(address => uint256)) public challenges; // epoch => account => recovery value
-    mapping(uint64 => uint256) public numChallenges; // epoch => numChallenges
+    mapping(uint64 => mapping(address => mapping(address => uint256))) public tokenChallenges; // epoch => account => token => recovery value
+    mapping(uint64 => uint256) public numChallenges; // epoch => numChallenges, including token challenges
     mapping(address => bool) public frozenWithdrawals; // account => withdrawn-flag
+    mapping(address => mapping(address => bool)) public frozenTokenWithdrawals; // account => token => withdrawn-flag
     uint64 public frozenEpoch = notFrozen; // epoch at which contract was frozen
 
     event Deposited(uint64 indexed epoch, address indexed account, uint256 value);
     event Exiting(uint64 indexed epoch, address indexed account, uint256 value);
     event Withdrawn(uint64 indexed epoch, address indexed account, uint256 value);
+    event TokenDeposited(uint64 indexed epoch, address indexed account, address indexed token, uint256 value);
+    event TokenExiting(uint64 indexed epoch, address indexed account, address indexed token, uint256 value);
+    event TokenWithdrawn(uint64 indexed epoch, address indexed account, address indexed token, uint256 value);
     event Challenged(uint64 indexed epoch, address indexed account);
+    event TokenChallenged(uint64 indexed epoch, address indexed account, address indexed token);
     event Frozen(uint64 indexed epoch);
 
     constructor(address _tee, uint64 _phaseDuration, uint64 _responseDuration) {
@@ -63,7 +80,9 @@ contract Erdstall {
 
     // exit lets a user exit and the end of the epoch's exit period.
     // sig must be signature created by signText(keccak256(abi.encode(balance))).
-    // For now, only full exits are allowed.
+    // The balance may be a full balance proof or an exit proof for a part of the
+    // balance, which the enclave signs on request. The enclave withdraws the
+    // exited value from the account and keeps the rest in the system.
     //
     // exit is also used to answer challenges.
     function exit(Balance calldata balance, bytes calldata sig) external onlyAlive {
@@ -91,10 +110,55 @@ contract Erdstall {
         require(value > 0, "nothing left to withdraw");
         exits[epoch][msg.sender] = 0;
 
-        msg.sender.transfer(value);
+        payable(msg.sender).transfer(value);
         emit Withdrawn(epoch, msg.sender, value);
     }
 
+    // depositToken deposits amount of an ERC-20 token. The contract must be
+    // approved to transfer the amount from the sender beforehand.
+    function depositToken(address token, uint256 amount) external onlyAlive {
+        require(token != address(0), "depositToken: zero token");
+        uint64 epoch = depositEpoch();
+        require(IERC20(token).transferFrom(msg.sender, address(this), amount), "depositToken: transfer failed");
+        tokenDeposits[epoch][msg.sender][token] += amount;
+
+        emit TokenDeposited(epoch, msg.sender, token, amount);
+    }
+
+    // exitToken lets a user exit an ERC-20 token balance at the end of the
+    // epoch's exit period. Like for exit, the balance may also be an exit proof
+    // for a part of the token balance.
+    //
+    // exitToken is also used to answer token challenges.
+    function exitToken(TokenBalance calldata balance, bytes calldata sig) external onlyAlive {
+        require(balance.epoch == exitEpoch(), "exitToken: wrong epoch");
+        verifyTokenBalance(balance, sig);
+        if (tokenChallenges[balance.epoch][balance.account][balance.token] == 0) {
+            // if not challenged, only user can exit
+            require(balance.account == msg.sender, "exitToken: wrong sender");
+        } else {
+            // reset challenge if this is a challenge response
+            tokenChallenges[balance.epoch][balance.account][balance.token] = 0;
+            numChallenges[balance.epoch]--;
+        }
+
+        tokenExits[balance.epoch][balance.account][balance.token] = balance.value;
+
+        emit TokenExiting(balance.epoch, balance.account, balance.token, balance.value);
+    }
+
+    function withdrawToken(uint64 epoch, address token) external onlyAlive {
+        // can only withdraw after exit period
+        require(epoch < exitEpoch(), "withdrawToken: too early");
+
+        uint256 value = tokenExits[epoch][msg.sender][token];
+        require(value > 0, "nothing left to withdraw");
+        tokenExits[epoch][msg.sender][token] = 0;
+
+        require(IERC20(token).transfer(msg.sender, value), "withdrawToken: transfer failed");
+        emit TokenWithdrawn(epoch, msg.sender, token, value);
+    }
+
     //
     // Challenge Functions
     //
@@ -122,6 +186,27 @@ contract Erdstall {
         registerChallenge(0);
     }
 
+    // challengeToken challenges the operator to post the current exit epoch's
+    // token balance statement, like challenge does for ETH. The user needs to
+    // pass the token balance proof of the just sealed epoch.
+    //
+    // The operator (anyone, actually) can respond using function `exitToken`.
+    function challengeToken(TokenBalance calldata balance, bytes calldata sig) external onlyAlive {
+        require(balance.account == msg.sender, "challengeToken: wrong sender");
+        require(balance.epoch == sealedEpoch(), "challengeToken: wrong epoch");
+        verifyTokenBalance(balance, sig);
+
+        registerTokenChallenge(balance.token, balance.value);
+    }
+
+    // challengeTokenDeposit should be called by a user if they deposited a
+    // token but never received a deposit or balance proof from the operator.
+    //
+    // The operator (anyone, actually) can respond using function `exitToken`.
+    function challengeTokenDeposit(address token) external onlyAlive {
+        registerTokenChallenge(token, 0);
+    }
+
     function registerChallenge(uint256 recoveryBalance) internal {
         require(!isChallengeResponsePhase(), "in challenge response phase");
         uint64 epoch = exitEpoch();
@@ -136,6 +221,20 @@ contract Erdstall {
         emit Challenged(epoch, msg.sender);
     }
 
+    function registerTokenChallenge(address token, uint256 recoveryBalance) internal {
+        require(!isChallengeResponsePhase(), "in challenge response phase");
+        uint64 epoch = exitEpoch();
+        require(tokenChallenges[epoch][msg.sender][token] == 0, "already challenged");
+
+        uint256 value = recoveryBalance + tokenDeposits[epoch][msg.sender][token];
+        require(value > 0, "no value in system");
+
+        tokenChallenges[epoch][msg.sender][token] = value;
+        numChallenges[epoch]++;
+
+        emit TokenChallenged(epoch, msg.sender, token);
+    }
+
     // withdrawChallenge lets open challengers withdraw all funds locked in the
     // frozen contract. The funds were already determined when the challenge was
     // posted using either `challenge` or `challengeDeposit`.
@@ -145,7 +244,7 @@ contract Erdstall {
     function withdrawChallenge() external {
         ensureFrozen();
 
-        uint256 value = challenges[frozenEpoch+1][msg.sender];
+        uint256 value = challenges[brokenEpoch()][msg.sender];
         require(value > 0, "nothing left to withdraw (frozen)");
 
         _withdrawFrozen(value);
@@ -165,7 +264,7 @@ contract Erdstall {
         verifyBalance(balance, sig);
 
         // Also recover deposits from broken epoch
-        uint256 value = balance.value + deposits[frozenEpoch+1][msg.sender];
+        uint256 value = balance.value + deposits[brokenEpoch()][msg.sender];
 
         _withdrawFrozen(value);
     }
@@ -174,10 +273,46 @@ contract Erdstall {
         require(!frozenWithdrawals[msg.sender], "already withdrawn (frozen)");
         frozenWithdrawals[msg.sender] = true;
 
-        msg.sender.transfer(value);
+        payable(msg.sender).transfer(value);
         emit Withdrawn(frozenEpoch, msg.sender, value);
     }
 
+    // withdrawTokenChallenge lets open token challengers withdraw the token
+    // funds locked in the frozen contract, like withdrawChallenge does for ETH.
+    function withdrawTokenChallenge(address token) external {
+        ensureFrozen();
+
+        uint256 value = tokenChallenges[brokenEpoch()][msg.sender][token];
+        require(value > 0, "nothing left to withdraw (frozen)");
+
+        _withdrawFrozenToken(token, value);
+    }
+
+    // withdrawFrozenToken lets non-challengers withdraw their token funds
+    // locked in the frozen contract, like withdrawFrozen does for ETH.
+    // Parameter `balance` needs to be the token balance proof of the last
+    // unchallenged epoch.
+    function withdrawFrozenToken(TokenBalance calldata balance, bytes calldata sig) external {
+        ensureFrozen();
+
+        require(balance.account == msg.sender, "withdrawFrozenToken: wrong sender");
+        require(balance.epoch == frozenEpoch, "withdrawFrozenToken: wrong epoch");
+        verifyTokenBalance(balance, sig);
+
+        // Also recover deposits from broken epoch
+        uint256 value = balance.value + tokenDeposits[brokenEpoch()][msg.sender][balance.token];
+
+        _withdrawFrozenToken(balance.token, value);
+    }
+
+    function _withdrawFrozenToken(address token, uint256 value) internal {
+        require(!frozenTokenWithdrawals[msg.sender][token], "already withdrawn (frozen)");
+        frozenTokenWithdrawals[msg.sender][token] = true;
+
+        require(IERC20(token).transfer(msg.sender, value), "withdrawFrozenToken: transfer failed");
+        emit TokenWithdrawn(frozenEpoch, msg.sender, token, value);
+    }
+
     // ensureFrozen ensures that the state of the contract is set to frozen if
     // the last epoch has at least one unanswered challenge.
     //
@@ -188,7 +323,8 @@ contract Erdstall {
         require(isLastEpochChallenged(), "no challenge in last epoch");
 
         // freezing to previous epoch
-        uint64 epoch = freezingEpoch() - 1;
+        uint64 epoch;
+        unchecked { epoch = freezingEpoch() - 1; }
         frozenEpoch = epoch;
 
         emit Frozen(epoch);
@@ -211,21 +347,29 @@ contract Erdstall {
     //
     // Epoch Counter Abstractions
     //
+    // The shifted epochs wrap around during the first epochs, which never
+    // match a real epoch.
 
     function depositEpoch() internal view returns (uint64) {
         return epoch();
     }
 
     function exitEpoch() internal view returns (uint64) {
-        return epoch()-2;
+        unchecked { return epoch()-2; }
     }
 
     function freezingEpoch() internal view returns (uint64) {
-        return epoch()-3;
+        unchecked { return epoch()-3; }
     }
 
     function sealedEpoch() internal view returns (uint64) {
-        return epoch()-3;
+        unchecked { return epoch()-3; }
+    }
+
+    // brokenEpoch returns the epoch after the frozen epoch, whose challenges
+    // were not answered.
+    function brokenEpoch() internal view returns (uint64) {
+        unchecked { return frozenEpoch + 1; }
     }
 
     // epoch returns the current epoch. It should not be used directly in public
@@ -247,4 +391,18 @@ contract Erdstall {
             balance.account,
             balance.value);
     }
+
+    function verifyTokenBalance(TokenBalance memory balance, bytes memory sig) public view {
+        require(Sig.verify(encodeTokenBalanceProof(balance), sig, tee), "invalid signature");
+    }
+
+    function encodeTokenBalanceProof(TokenBalance memory balance) public view returns (bytes memory) {
+        return abi.encode(
+            "ErdstallTokenBalance",
+            address(this),
+            balance.epoch,
+            balance.account,
+            balance.token,
+            balance.value);
+    }
 }
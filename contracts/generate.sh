@@ -5,11 +5,21 @@
 set -e
 
 # Download solc.
-wget -nc "https://github.com/ethereum/solidity/releases/download/v0.7.4/solc-static-linux"
+wget -nc "https://github.com/ethereum/solidity/releases/download/v0.8.21/solc-static-linux"
 chmod +x solc-static-linux
 echo -e "Ensure that the newest version of abigen is installed"
 
 solpath="contracts"
+# The go-ethereum version required by go-perun does not support newer EVM
+# versions.
+evm="istanbul"
+
+# Generates optimized golang bindings for a sol contract and its imports.
+# $1  solidity file path, relative to $solpath/.
+# $2  golang package name.
+function bindings() {
+    abigen --pkg $2 --sol $solpath/$1.sol --out $2/$1.go --solc ./solc-evm
+}
 
 # Generates optimized golang bindings and runtime binaries for sol contracts.
 # $1  solidity file path, relative to $solpath/.
@@ -19,14 +29,20 @@ function generate() {
     file=$1; pkg=$2
     shift; shift   # skip the first two args.
     for contract in "$@"; do
-        abigen --pkg $pkg --sol $solpath/$file.sol --out $pkg/$file.go --solc ./solc-static-linux
-        ./solc-static-linux --bin-runtime --optimize --allow-paths *, $solpath/$file.sol --overwrite -o $pkg/
+        bindings "$file" "$pkg"
+        ./solc-static-linux --bin-runtime --optimize --evm-version $evm --allow-paths *, $solpath/$file.sol --overwrite -o $pkg/
         echo -e "package $pkg\n\n // ${contract}BinRuntime is the runtime part of the compiled bytecode used for deploying new contracts.\nvar ${contract}BinRuntime = \`$(<${pkg}/${contract}.bin-runtime)\`" > "$pkg/${contract}BinRuntime.go"
     done
 }
 
+# abigen does not forward the EVM version, so it calls solc through a wrapper.
+echo -e "#!/bin/bash\nexec ./solc-static-linux --evm-version $evm \"\$@\"" > solc-evm
+chmod +x solc-evm
+
 # Generate bindings
 generate "Erdstall" "bindings" "Erdstall"
+# The test token is only needed for deploying it in tests.
+bindings "TestToken" "bindings"
 
 abigen --version --solc ./solc-static-linux
 echo -e "Generated bindings"
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// catchUpWorkers is the number of blocks that BlockRange fetches in parallel.
+const catchUpWorkers = 8
+
+// VerifiedTip returns the number of the latest block that is pow-depth deep
+// from the tip of the chain. It is only valid if ok is true, i.e., if the
+// chain is at least pow-depth long.
+func (cl *Client) VerifiedTip() (tip uint64, ok bool, err error) {
+	ctx, cancel := ContextNodeReq()
+	defer cancel()
+	header, err := cl.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("retrieving head: %w", err)
+	}
+	head := header.Number.Uint64()
+	if head < cl.params.PowDepth {
+		return 0, false, nil
+	}
+	return head - cl.params.PowDepth, true, nil
+}
+
+// BlockRange retrieves the blocks from, ..., to together with their receipts.
+// The blocks are fetched in parallel and returned in order. It fails if the
+// blocks do not form a chain, which happens when the chain was reorganized
+// while fetching. If the nodes of a multi-node backend do not agree on a
+// block, only the blocks before it are returned.
+func (cl *Client) BlockRange(from, to uint64) ([]*tee.Block, error) {
+	if to < from {
+		return nil, nil
+	}
+	blocks := make([]*tee.Block, to-from+1)
+	errs := make([]error, len(blocks))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < catchUpWorkers && w < len(blocks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				blocks[i], errs[i] = cl.blockByNumber(from + uint64(i))
+			}
+		}()
+	}
+	for i := range blocks {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for i, err := range errs {
+		if errors.Is(err, errNoQuorum) {
+			log.Warnf("EthClient: Holding back block %d: %v", from+uint64(i), err)
+			return blocks[:i], nil
+		} else if err != nil {
+			return nil, fmt.Errorf("retrieving block %d: %w", from+uint64(i), err)
+		}
+		if i > 0 && blocks[i].ParentHash() != blocks[i-1].Hash() {
+			return nil, fmt.Errorf("block %d is no child of block %d", from+uint64(i), from+uint64(i-1))
+		}
+	}
+	return blocks, nil
+}
+
+// blockByNumber retrieves the block with the given number and its receipts.
+func (cl *Client) blockByNumber(num uint64) (*tee.Block, error) {
+	ctx, cancel := ContextNodeReq()
+	defer cancel()
+	block, err := cl.ContractBackend.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.verifyBlock(ctx, block); err != nil {
+		return nil, err
+	}
+	return cl.teeBlock(ctx, block)
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	log "github.com/sirupsen/logrus"
 	peruneth "perun.network/go-perun/backend/ethereum/channel"
@@ -25,8 +24,12 @@ import (
 )
 
 const (
-	// DefaultGasLimit represents the default gas limit for this application.
+	// DefaultGasLimit is a gas limit that suffices for all Erdstall
+	// transactions. It can be set as fixed gas limit of a FeePolicy.
 	DefaultGasLimit = 2000000
+	// TxFinalityDepth is the number of blocks that have to include a
+	// transaction until ConfirmTransaction returns.
+	TxFinalityDepth = 1
 	defaultPowDepth = 0
 )
 
@@ -36,10 +39,25 @@ type (
 		peruneth.ContractBackend
 		account accounts.Account
 		params  tee.Parameters
+		fees    FeePolicy
+		nonces  *nonceManager
 
 		onlyErdstallReceipts bool
 	}
 
+	// FeePolicy determines the fees and gas limit of transactions. On chains
+	// with a base fee (EIP-1559), transactions are dynamic-fee transactions
+	// with PriorityFee as tip and a fee cap of twice the base fee plus the
+	// tip, capped at MaxFee. On other chains, they are legacy transactions
+	// whose gas price is the node's suggested gas price plus GasPriceBump,
+	// capped at MaxFee.
+	FeePolicy struct {
+		MaxFee       uint64 // Maximal fee per gas in wei, 0: unlimited.
+		PriorityFee  uint64 // Priority fee per gas in wei, 0: suggested by the node.
+		GasPriceBump uint64 // Gas price in wei added to the suggested legacy gas price.
+		GasLimit     uint64 // Fixed gas limit, 0: estimated for each call.
+	}
+
 	// BlockSubscription represents a subscription to Ethereum blocks.
 	BlockSubscription struct {
 		sub    ethereum.Subscription
@@ -51,7 +69,10 @@ type (
 	BlockSubscription2 struct {
 		headerSub ethereum.Subscription
 		blocks    chan *tee.Block
+		reverts   chan uint64
 		err       chan error
+		quit      chan struct{}          // Closed on Unsubscribe.
+		pushed    map[uint64]common.Hash // Hashes of recently pushed blocks.
 	}
 
 	ExitingSubscription struct {
@@ -59,6 +80,11 @@ type (
 		events chan *bindings.ErdstallExiting
 	}
 
+	TokenExitingSubscription struct {
+		sub    event.Subscription
+		events chan *bindings.ErdstallTokenExiting
+	}
+
 	FrozenSubscription struct {
 		sub    event.Subscription
 		events chan *bindings.ErdstallFrozen
@@ -70,19 +96,25 @@ func NewClient(
 	cb peruneth.ContractBackend,
 	a accounts.Account,
 ) *Client {
-	return &Client{ContractBackend: cb, account: a}
+	return &Client{
+		ContractBackend: cb,
+		account:         a,
+		nonces:          newNonceManager(cb.ContractInterface, a.Address),
+	}
 }
 
 // NewClientForWalletAndAccount creates a new Erdstall Ethereum client for the
-// given wallet and account.
+// given wallet and account. Transactions are signed for the given chain ID, see
+// NewDefaultTransactor.
 func NewClientForWalletAndAccount(
 	ci peruneth.ContractInterface,
 	w accounts.Wallet,
 	a accounts.Account,
+	chainID *big.Int,
 ) *Client {
-	tr := NewDefaultTransactor(w)
-	cb := peruneth.NewContractBackend(ci, tr)
-	return &Client{ContractBackend: cb, account: a}
+	tr := NewDefaultTransactor(w, chainID)
+	cb := peruneth.NewContractBackend(ci, tr, TxFinalityDepth)
+	return NewClient(cb, a)
 }
 
 // NewClientForWallet returns a new Client using the given wallet as transactor.
@@ -100,34 +132,123 @@ func NewClientForWallet(
 	if err != nil {
 		return nil, fmt.Errorf("deriving account: %w", err)
 	}
-	tr := perunhd.NewTransactor(hdw.Wallet())
-	cb := peruneth.NewContractBackend(ci, tr)
+	tr := perunhd.NewTransactor(hdw.Wallet(), types.HomesteadSigner{})
+	cb := peruneth.NewContractBackend(ci, tr, TxFinalityDepth)
 
 	return NewClient(cb, acc.Account), nil
 }
 
-// OnlyErdstallReceipts can be called during client setup to skip requesting
-// transaction receipts. This affects all methods that create tee.Blocks.
+// OnlyErdstallReceipts can be called during client setup to create compact
+// tee.Blocks, which only contain the receipts of Erdstall transactions together
+// with proofs of their inclusion. This affects all methods that create
+// tee.Blocks.
 func (cl *Client) OnlyErdstallReceipts() {
 	cl.onlyErdstallReceipts = true
 }
 
-// NewTransactor creates a new transactor.
+// SetFeePolicy sets the fee policy of all transactions created by
+// NewTransactor. By default, the fees are suggested by the node and the gas
+// limit is estimated.
+func (cl *Client) SetFeePolicy(p FeePolicy) {
+	cl.fees = p
+}
+
+// NewTransactor creates a new transactor according to the client's fee
+// policy. Unless the caller sets an explicit nonce, the nonce is allocated by
+// the client's nonce manager when the transaction is signed, so that
+// concurrent transactions get consecutive nonces.
+//
+// The fees are always set, GasFeeCap and GasTipCap on chains with a base fee
+// and GasPrice otherwise.
 func (cl *Client) NewTransactor(ctx context.Context) (*bind.TransactOpts, error) {
 	tr, err := cl.ContractBackend.NewTransactor(ctx,
-		DefaultGasLimit,
+		cl.fees.GasLimit,
 		cl.account)
 	if err != nil {
 		return nil, fmt.Errorf("creating transactor: %w", err)
 	}
+	// The nonce manager allocates the nonce and the fee policy sets the fees,
+	// instead of the contract backend.
+	tr.Nonce, tr.GasPrice = nil, nil
 	tr.Context = ctx
+	tr.Signer = cl.nonces.signer(ctx, tr)
+	if err := cl.setFees(ctx, tr); err != nil {
+		return nil, err
+	}
 	return tr, nil
 }
 
-// CreateEthereumClient creates and connects a new ethereum client.
+// MaxFee returns the maximal fee per gas of the client's fee policy, nil if
+// unlimited.
+func (cl *Client) MaxFee() *big.Int {
+	if cl.fees.MaxFee == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(cl.fees.MaxFee)
+}
+
+// setFees sets the fees of the transactor according to the client's fee
+// policy and the current head's base fee.
+func (cl *Client) setFees(ctx context.Context, tr *bind.TransactOpts) error {
+	head, err := cl.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("retrieving head: %w", err)
+	}
+	if head.BaseFee == nil {
+		tr.GasPrice, err = cl.gasPrice(ctx)
+		return err
+	}
+	tr.GasFeeCap, tr.GasTipCap, err = cl.dynamicFees(ctx, head.BaseFee)
+	return err
+}
+
+// dynamicFees returns the fee cap and tip of a dynamic-fee transaction
+// according to the client's fee policy. The fee cap leaves room for the base
+// fee to double, like go-ethereum's default.
+func (cl *Client) dynamicFees(ctx context.Context, baseFee *big.Int) (feeCap, tip *big.Int, err error) {
+	if cl.fees.PriorityFee != 0 {
+		tip = new(big.Int).SetUint64(cl.fees.PriorityFee)
+	} else if tip, err = cl.SuggestGasTipCap(ctx); err != nil {
+		return nil, nil, fmt.Errorf("suggesting tip: %w", err)
+	}
+	feeCap = new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	if max := cl.MaxFee(); max != nil && feeCap.Cmp(max) > 0 {
+		log.Debugf("EthClient: Capping fee cap %v at %v", feeCap, max)
+		feeCap = max
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+	return feeCap, tip, nil
+}
+
+// gasPrice returns the legacy gas price according to the client's fee policy.
+func (cl *Client) gasPrice(ctx context.Context) (*big.Int, error) {
+	price, err := cl.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggesting gas price: %w", err)
+	}
+	price.Add(price, new(big.Int).SetUint64(cl.fees.GasPriceBump))
+	if max := cl.MaxFee(); max != nil && price.Cmp(max) > 0 {
+		log.Debugf("EthClient: Capping gas price %v at %v", price, max)
+		price = max
+	}
+	return price, nil
+}
+
+// CreateEthereumClient creates and connects a new ethereum client. For HTTP(S)
+// URLs, the client polls for new blocks and events, see Dial.
 func CreateEthereumClient(ctx context.Context, url string, wallet accounts.Wallet, a accounts.Account) (*Client, error) {
+	return CreateEthereumClientForNodes(ctx, []string{url}, 0, wallet, a)
+}
+
+// CreateEthereumClientForNodes creates a new ethereum client that is
+// connected to all given nodes, see DialNodes. Its transactions are signed for
+// the nodes' chain ID.
+func CreateEthereumClientForNodes(ctx context.Context, urls []string, quorum int, wallet accounts.Wallet, a accounts.Account) (*Client, error) {
 	for {
-		ethClient, err := ethclient.DialContext(ctx, url)
+		ethClient, err := DialNodes(ctx, urls, quorum)
 
 		if err != nil {
 			select {
@@ -139,7 +260,11 @@ func CreateEthereumClient(ctx context.Context, url string, wallet accounts.Walle
 			}
 		}
 
-		return NewClientForWalletAndAccount(ethClient, wallet, a), nil
+		chainID, err := ChainID(ctx, ethClient)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving chain ID: %w", err)
+		}
+		return NewClientForWalletAndAccount(ethClient, wallet, a, chainID), nil
 	}
 }
 
@@ -272,11 +397,14 @@ func (cl *Client) SubscribeBlocks() (*BlockSubscription, error) {
 // starting from the given block number.
 //
 // It only pushes blocks onto the subscription that are pow-depth deep from the
-// tip of the chain.
+// tip of the chain. If an already pushed block gets orphaned by a chain
+// reorganization, the number of the last common block is sent on the Reverts
+// channel, before the blocks of the new canonical branch are pushed.
 func (cl *Client) SubscribeVerifiedBlocksFrom(start uint64) (*BlockSubscription2, error) {
 	headers := make(chan *types.Header)
 	blocks := make(chan *tee.Block)
-	errChan := make(chan error)
+	reverts := make(chan uint64)
+	errChan := make(chan error, 1) // Buffered, so that the subscription routine can end.
 
 	ctx, cancel := ContextNodeReq()
 	defer cancel()
@@ -286,7 +414,14 @@ func (cl *Client) SubscribeVerifiedBlocksFrom(start uint64) (*BlockSubscription2
 		return nil, fmt.Errorf("subscribing to blockchain head: %w", err)
 	}
 
-	blockSub := &BlockSubscription2{headerSub, blocks, errChan}
+	blockSub := &BlockSubscription2{
+		headerSub: headerSub,
+		blocks:    blocks,
+		reverts:   reverts,
+		err:       errChan,
+		quit:      make(chan struct{}),
+		pushed:    make(map[uint64]common.Hash),
+	}
 
 	run := func() error {
 		var (
@@ -323,18 +458,27 @@ func (cl *Client) SubscribeVerifiedBlocksFrom(start uint64) (*BlockSubscription2
 				log.Debugf("new tip %d", newtip)
 				tip = newtip // equivalent to tip++ unless header jump
 
-				newverified := tip - cl.params.PowDepth
-				if newverified < start {
-					log.Debugf("new verified tip %d before start", newverified)
+				if tip < start+cl.params.PowDepth {
+					log.Debugf("new tip %d before verified start", tip)
 					continue // pow-depth not reached yet
 				}
+				newverified := tip - cl.params.PowDepth
 
-				for ; verified <= newverified; verified++ {
+				for verified <= newverified {
 					// usually loops only a single time unless there was a header jump
 					log.Debugf("pushing new verified block %d", verified)
-					if err := blockSub.pushNextBlock(cl, verified); err != nil {
+					next, err := blockSub.pushNextBlock(cl, verified, start)
+					if errors.Is(err, errNoQuorum) {
+						log.Warnf("EthClient: Holding back block %d: %v", verified, err)
+						break // retry on next header
+					} else if errors.Is(err, errUnsubscribed) {
+						log.Debug("EthClient: Block subscription closed")
+						close(blocks)
+						return nil
+					} else if err != nil {
 						return fmt.Errorf("pushing block #%d: %w", verified, err)
 					}
+					verified = next
 				}
 			}
 		}
@@ -381,6 +525,37 @@ func (s *ExitingSubscription) Unsubscribe() {
 	s.sub.Unsubscribe()
 }
 
+// SubscribeTokenExiting writes received TokenExiting events into the
+// Subscription.
+func (cl *Client) SubscribeTokenExiting(ctx context.Context, contract *bindings.Erdstall, epochs []uint64, accs []common.Address, tokens []common.Address) (*TokenExitingSubscription, error) {
+	wOpts, err := cl.NewWatchOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan *bindings.ErdstallTokenExiting)
+	sub, err := contract.WatchTokenExiting(wOpts, events, epochs, accs, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenExitingSubscription{
+		sub:    sub,
+		events: events,
+	}, nil
+}
+
+func (s *TokenExitingSubscription) Events() <-chan *bindings.ErdstallTokenExiting {
+	return s.events
+}
+
+func (s *TokenExitingSubscription) Err() <-chan error {
+	return s.sub.Err()
+}
+
+func (s *TokenExitingSubscription) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
 // SubscribeFrozen writes received Frozen events into the Subscription.
 // The `epochs` argument can be used to filter for
 // specific events. Passing `nil` will skip the filtering.
@@ -422,21 +597,36 @@ func (cl *Client) BlockByHash(ctx context.Context, hash common.Hash) (*tee.Block
 		return nil, fmt.Errorf("retrieving block: %w", err)
 	}
 
+	return cl.teeBlock(ctx, block)
+}
+
+// teeBlock retrieves the block's transaction receipts and bundles them with the
+// block. If only Erdstall receipts are requested, a compact block is created.
+func (cl *Client) teeBlock(ctx context.Context, block *types.Block) (*tee.Block, error) {
 	receipts, err := cl.TransactionReceipts(ctx, block)
 	if err != nil {
 		return nil, fmt.Errorf("retrieving receipts: %w", err)
 	}
 
+	if cl.onlyErdstallReceipts {
+		return tee.NewCompactBlock(block, receipts, cl.params.Contract)
+	}
 	return &tee.Block{Block: *block, Receipts: receipts}, nil
 }
 
+// verifyBlock lets the nodes of a multi-node backend verify the block, see
+// MultiBackend.VerifyBlock.
+func (cl *Client) verifyBlock(ctx context.Context, block *types.Block) error {
+	if v, ok := cl.ContractInterface.(blockVerifier); ok {
+		return v.VerifyBlock(ctx, block.NumberU64(), block.Hash())
+	}
+	return nil
+}
+
 // TransactionReceipts returns the transaction receipts for the given block.
 func (cl *Client) TransactionReceipts(ctx context.Context, block *types.Block) (types.Receipts, error) {
 	var receipts []*types.Receipt
 	for _, t := range block.Transactions() {
-		if cl.onlyErdstallReceipts && ((t.To() == nil) || (*t.To() != cl.params.Contract)) {
-			continue
-		}
 		r, err := cl.TransactionReceipt(ctx, t.Hash())
 		if err != nil {
 			return nil, fmt.Errorf("retrieving receipt: %w", err)
@@ -446,7 +636,9 @@ func (cl *Client) TransactionReceipts(ctx context.Context, block *types.Block) (
 	return receipts, nil
 }
 
-// NextNonce returns the next Ethereum nonce.
+// NextNonce returns the node's pending nonce of the client's account. Nonces of
+// transactions created by NewTransactor are allocated by the client's nonce
+// manager instead.
 func (cl *Client) NextNonce(ctx context.Context) (uint64, error) {
 	nonce, err := cl.PendingNonceAt(ctx, cl.account.Address)
 	if err != nil {
@@ -466,9 +658,10 @@ func (cl *Client) DeployContracts(params *tee.Parameters) error {
 	if err != nil {
 		return fmt.Errorf("creating transactor: %w", err)
 	}
+	tr.GasLimit = 0 // Estimated, deploying needs more than DefaultGasLimit.
 
 	address, tx, _, err := bindings.DeployErdstall(tr,
-		cl.ContractInterface,
+		cl,
 		params.TEE,
 		params.PhaseDuration,
 		params.ResponseDuration)
@@ -480,6 +673,7 @@ func (cl *Client) DeployContracts(params *tee.Parameters) error {
 	if err != nil {
 		return fmt.Errorf("waiting for contract deployment: %w", err)
 	}
+	cl.nonces.mined(tx)
 	params.Contract = address
 
 	receipt, err := cl.TransactionReceipt(ctx, tx.Hash())
@@ -493,7 +687,7 @@ func (cl *Client) DeployContracts(params *tee.Parameters) error {
 }
 
 func (cl *Client) BindContract(ctx context.Context, addr common.Address) (*tee.Parameters, *bindings.Erdstall, error) {
-	contract, err := bindings.NewErdstall(addr, cl.ContractInterface)
+	contract, err := bindings.NewErdstall(addr, cl)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -541,27 +735,92 @@ func (sub *BlockSubscription) Unsubscribe() {
 // Blocks returns the channel on which to receive subscribed blocks.
 func (blockSub *BlockSubscription2) Blocks() <-chan *tee.Block { return blockSub.blocks }
 
+// Reverts returns the channel on which to receive revert notifications. A
+// received block number n means that all pushed blocks after n were orphaned
+// and that the subscription continues with the new canonical block n+1.
+func (blockSub *BlockSubscription2) Reverts() <-chan uint64 { return blockSub.reverts }
+
 // Unsubscribe ends the subscription.
 func (blockSub *BlockSubscription2) Unsubscribe() {
 	blockSub.headerSub.Unsubscribe()
+	select {
+	case <-blockSub.quit: // already closed
+	default:
+		close(blockSub.quit)
+	}
 }
 
-func (blockSub *BlockSubscription2) pushNextBlock(cl *Client, blockNum uint64) error {
+// revertWindow is the number of pushed block hashes that a BlockSubscription2
+// remembers for detecting chain reorganizations.
+const revertWindow = 64
+
+// errUnsubscribed is returned by pushNextBlock if the subscription was ended
+// while waiting for the receiver.
+var errUnsubscribed = errors.New("unsubscribed")
+
+// pushNextBlock pushes the block with the given number onto the subscription
+// and returns the number of the next block to push. If the block's parent is
+// not the previously pushed block, a revert to the last common block is
+// signaled instead and the number of the first block of the new canonical
+// branch is returned.
+func (blockSub *BlockSubscription2) pushNextBlock(cl *Client, blockNum, start uint64) (uint64, error) {
 	ctx, cancel := ContextNodeReq()
 	defer cancel()
 
 	block, err := cl.ContractBackend.BlockByNumber(ctx, big.NewInt(int64(blockNum)))
 	if err != nil {
-		return fmt.Errorf("retrieving block %d: %w", blockNum, err)
+		return 0, fmt.Errorf("retrieving block %d: %w", blockNum, err)
+	}
+	if err := cl.verifyBlock(ctx, block); err != nil {
+		return 0, err
 	}
 
-	receipts, err := cl.TransactionReceipts(ctx, block)
+	if parent, ok := blockSub.pushed[blockNum-1]; blockNum > start && ok && parent != block.ParentHash() {
+		fork, err := blockSub.forkPoint(ctx, cl, blockNum-1)
+		if err != nil {
+			return 0, err
+		}
+		log.Warnf("eth.Client: Reorg, reverting to block %d", fork)
+		select {
+		case blockSub.reverts <- fork:
+		case <-blockSub.quit:
+			return 0, errUnsubscribed
+		}
+		return fork + 1, nil
+	}
+
+	teeBlock, err := cl.teeBlock(ctx, block)
 	if err != nil {
-		return fmt.Errorf("retrieving block receipts: %w", err)
+		return 0, fmt.Errorf("retrieving block receipts: %w", err)
 	}
 
 	log.Tracef("eth.Client: Pushing block number %d", blockNum)
 
-	blockSub.blocks <- &tee.Block{Block: *block, Receipts: receipts}
-	return nil
+	select {
+	case blockSub.blocks <- teeBlock:
+	case <-blockSub.quit:
+		return 0, errUnsubscribed
+	}
+	blockSub.pushed[blockNum] = block.Hash()
+	delete(blockSub.pushed, blockNum-revertWindow)
+	return blockNum + 1, nil
+}
+
+// forkPoint searches the most recent pushed block, starting at blockNum, that
+// is still part of the canonical chain.
+func (blockSub *BlockSubscription2) forkPoint(ctx context.Context, cl *Client, blockNum uint64) (uint64, error) {
+	for n := blockNum; ; n-- {
+		hash, ok := blockSub.pushed[n]
+		if !ok {
+			return 0, fmt.Errorf("reorg deeper than %d blocks", revertWindow)
+		}
+		header, err := cl.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return 0, fmt.Errorf("retrieving header %d: %w", n, err)
+		}
+		if header.Hash() == hash {
+			return n, nil
+		}
+		delete(blockSub.pushed, n)
+	}
 }
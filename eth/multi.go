@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+)
+
+// errNoQuorum is returned if not enough nodes agree on a block.
+var errNoQuorum = errors.New("no node quorum")
+
+type (
+	// MultiBackend is a ContractInterface that is backed by multiple Ethereum
+	// nodes. All requests go to the current node. If it fails to respond, the
+	// request is retried on the next node, which becomes the current node.
+	// Errors returned by a node, like reverted calls, are not retried.
+	//
+	// Subscriptions are made on the current node. If a subscription fails, it
+	// is renewed on the next node. Events that were emitted while failing over
+	// may be missed.
+	//
+	// If a quorum is set, the Client only hands out verified blocks that
+	// quorum nodes agree on, see VerifyBlock.
+	MultiBackend struct {
+		nodes  []peruneth.ContractInterface
+		quorum int
+
+		mtx     sync.Mutex // protects current.
+		current int
+	}
+
+	// blockVerifier is implemented by backends that verify blocks with
+	// multiple nodes, like the MultiBackend.
+	blockVerifier interface {
+		VerifyBlock(ctx context.Context, num uint64, hash common.Hash) error
+	}
+)
+
+var _ peruneth.ContractInterface = (*MultiBackend)(nil)
+
+// NewMultiBackend creates a backend for the given nodes, of which quorum must
+// agree on every verified block. A quorum of 0 or 1 disables the verification.
+func NewMultiBackend(nodes []peruneth.ContractInterface, quorum int) (*MultiBackend, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes")
+	} else if quorum > len(nodes) {
+		return nil, fmt.Errorf("quorum %d exceeds the number of nodes %d", quorum, len(nodes))
+	}
+	return &MultiBackend{nodes: nodes, quorum: quorum}, nil
+}
+
+// DialNodes connects to the Ethereum nodes at the given URLs, see Dial. A
+// single node without quorum is returned as is, multiple nodes are combined in
+// a MultiBackend.
+func DialNodes(ctx context.Context, urls []string, quorum int) (peruneth.ContractInterface, error) {
+	if len(urls) == 1 && quorum <= 1 {
+		return Dial(ctx, urls[0])
+	}
+	nodes := make([]peruneth.ContractInterface, len(urls))
+	for i, url := range urls {
+		node, err := Dial(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("dialing node %s: %w", url, err)
+		}
+		log.WithFields(log.Fields{"node": i, "url": url}).Info("EthClient: Connected to node")
+		nodes[i] = node
+	}
+	return NewMultiBackend(nodes, quorum)
+}
+
+// VerifyBlock checks that at least quorum nodes agree that the block with the
+// given hash is the canonical block at the given height. Nodes that disagree
+// raise an alarm, even if the quorum is reached.
+func (m *MultiBackend) VerifyBlock(ctx context.Context, num uint64, hash common.Hash) error {
+	if m.quorum <= 1 {
+		return nil
+	}
+
+	hashes := make([]common.Hash, len(m.nodes))
+	errs := make([]error, len(m.nodes))
+	var wg sync.WaitGroup
+	wg.Add(len(m.nodes))
+	for i, node := range m.nodes {
+		go func(i int, node peruneth.ContractInterface) {
+			defer wg.Done()
+			header, err := node.HeaderByNumber(ctx, new(big.Int).SetUint64(num))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			hashes[i] = header.Hash()
+		}(i, node)
+	}
+	wg.Wait()
+
+	var agree int
+	for i := range m.nodes {
+		fields := log.Fields{"node": i, "block": num, "hash": hash.Hex()}
+		switch {
+		case errs[i] != nil:
+			log.WithFields(fields).Warnf("EthClient: Node could not confirm block: %v", errs[i])
+		case hashes[i] != hash:
+			fields["nodeHash"] = hashes[i].Hex()
+			log.WithFields(fields).Error("EthClient: ALARM: Node disagrees on block")
+		default:
+			agree++
+		}
+	}
+	if agree < m.quorum {
+		return fmt.Errorf("%w: %d of %d nodes agree on block %d, need %d",
+			errNoQuorum, agree, len(m.nodes), num, m.quorum)
+	}
+	return nil
+}
+
+// NetworkID returns the network ID of the current node.
+func (m *MultiBackend) NetworkID(ctx context.Context) (id *big.Int, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) error {
+		v, ok := node.(interface {
+			NetworkID(context.Context) (*big.Int, error)
+		})
+		if !ok {
+			return errors.New("node has no method NetworkID")
+		}
+		id, err = v.NetworkID(ctx)
+		return err
+	})
+	return
+}
+
+// ChainID returns the chain ID of the current node.
+func (m *MultiBackend) ChainID(ctx context.Context) (id *big.Int, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		id, err = ChainID(ctx, node)
+		return
+	})
+	return
+}
+
+// do calls fn with the current node. If the node fails to respond, it fails
+// over to the next node and retries until all nodes were tried.
+func (m *MultiBackend) do(ctx context.Context, fn func(peruneth.ContractInterface) error) (err error) {
+	_, err = m.doOn(ctx, fn)
+	return err
+}
+
+// doOn is like do but also returns the index of the last tried node.
+func (m *MultiBackend) doOn(ctx context.Context, fn func(peruneth.ContractInterface) error) (i int, err error) {
+	for range m.nodes {
+		m.mtx.Lock()
+		i = m.current
+		m.mtx.Unlock()
+
+		if err = fn(m.nodes[i]); !isNodeFailure(ctx, err) {
+			return i, err
+		}
+		m.failOver(i, err)
+	}
+	return i, err
+}
+
+// failOver makes the node after the failed node i the current node, unless
+// the current node was already changed.
+func (m *MultiBackend) failOver(i int, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.current == i {
+		m.current = (i + 1) % len(m.nodes)
+		log.WithFields(log.Fields{"node": i, "next": m.current}).
+			Warnf("EthClient: Node failed, failing over: %v", err)
+	}
+}
+
+// subscribe subscribes with fn on the current node, failing over like do. If
+// the node's subscription fails later, it is renewed on the next node with a
+// new node request context. The returned subscription only fails if no node
+// can be subscribed to.
+func (m *MultiBackend) subscribe(ctx context.Context, fn func(context.Context, peruneth.ContractInterface) (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	subscribeOn := func(ctx context.Context) (int, error) {
+		return m.doOn(ctx, func(node peruneth.ContractInterface) (err error) {
+			sub, err = fn(ctx, node)
+			return
+		})
+	}
+	i, err := subscribeOn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case <-quit:
+				sub.Unsubscribe()
+				return nil
+			case err := <-sub.Err():
+				if err == nil {
+					err = errors.New("subscription closed")
+				}
+				m.failOver(i, err)
+				ctx, cancel := ContextNodeReq()
+				i, err = subscribeOn(ctx)
+				cancel()
+				if err != nil {
+					return fmt.Errorf("resubscribing: %w", err)
+				}
+				log.WithField("node", i).Info("EthClient: Resubscribed")
+			}
+		}
+	}), nil
+}
+
+// isNodeFailure tells whether err means that the node failed to respond, as
+// opposed to a response of the node.
+func isNodeFailure(ctx context.Context, err error) bool {
+	var rpcErr rpc.Error
+	return err != nil && ctx.Err() == nil &&
+		!errors.Is(err, ethereum.NotFound) && !errors.As(err, &rpcErr)
+}
+
+func (m *MultiBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		code, err = node.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (res []byte, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		res, err = node.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		code, err = node.PendingCodeAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		nonce, err = node.PendingNonceAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		price, err = node.SuggestGasPrice(ctx)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		tip, err = node.SuggestGasTipCap(ctx)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		gas, err = node.EstimateGas(ctx, call)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return m.do(ctx, func(node peruneth.ContractInterface) error {
+		return node.SendTransaction(ctx, tx)
+	})
+}
+
+func (m *MultiBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		logs, err = node.FilterLogs(ctx, query)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return m.subscribe(ctx, func(ctx context.Context, node peruneth.ContractInterface) (ethereum.Subscription, error) {
+		return node.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+func (m *MultiBackend) BlockByHash(ctx context.Context, hash common.Hash) (block *types.Block, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		block, err = node.BlockByHash(ctx, hash)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		block, err = node.BlockByNumber(ctx, number)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) HeaderByHash(ctx context.Context, hash common.Hash) (header *types.Header, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		header, err = node.HeaderByHash(ctx, hash)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		header, err = node.HeaderByNumber(ctx, number)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) TransactionCount(ctx context.Context, blockHash common.Hash) (count uint, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		count, err = node.TransactionCount(ctx, blockHash)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (tx *types.Transaction, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		tx, err = node.TransactionInBlock(ctx, blockHash, index)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return m.subscribe(ctx, func(ctx context.Context, node peruneth.ContractInterface) (ethereum.Subscription, error) {
+		return node.SubscribeNewHead(ctx, ch)
+	})
+}
+
+func (m *MultiBackend) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		tx, isPending, err = node.TransactionByHash(ctx, txHash)
+		return
+	})
+	return
+}
+
+func (m *MultiBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = m.do(ctx, func(node peruneth.ContractInterface) (err error) {
+		receipt, err = node.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return
+}
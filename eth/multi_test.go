@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+
+	"github.com/perun-network/erdstall/eth"
+)
+
+func TestMultiBackend(t *testing.T) {
+	ctx := context.Background()
+	header := &types.Header{Number: big.NewInt(7)}
+	forged := &types.Header{Number: big.NewInt(7), Extra: []byte("forged")}
+
+	t.Run("failover", func(t *testing.T) {
+		require := require.New(t)
+		down := &fakeNode{err: errors.New("connection refused")}
+		up := &fakeNode{header: header}
+		m, err := eth.NewMultiBackend([]peruneth.ContractInterface{down, up}, 0)
+		require.NoError(err)
+
+		h, err := m.HeaderByNumber(ctx, nil)
+		require.NoError(err)
+		require.Equal(header.Hash(), h.Hash())
+		// The next request goes to the new current node directly.
+		_, err = m.HeaderByNumber(ctx, nil)
+		require.NoError(err)
+		require.Equal(1, down.calls)
+		require.Equal(2, up.calls)
+	})
+
+	t.Run("all-down", func(t *testing.T) {
+		nodes := []peruneth.ContractInterface{
+			&fakeNode{err: errors.New("down")}, &fakeNode{err: errors.New("down")}}
+		m, err := eth.NewMultiBackend(nodes, 0)
+		require.NoError(t, err)
+		_, err = m.HeaderByNumber(ctx, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		require := require.New(t)
+		first := &fakeNode{err: ethereum.NotFound}
+		second := &fakeNode{header: header}
+		m, err := eth.NewMultiBackend([]peruneth.ContractInterface{first, second}, 0)
+		require.NoError(err)
+		_, err = m.HeaderByNumber(ctx, nil)
+		require.True(errors.Is(err, ethereum.NotFound), "responses are not retried")
+		require.Zero(second.calls)
+	})
+
+	t.Run("resubscribe", func(t *testing.T) {
+		require := require.New(t)
+		failing := &fakeNode{sub: newFakeSub(), subscribed: make(chan struct{}, 1)}
+		next := &fakeNode{sub: newFakeSub(), subscribed: make(chan struct{}, 1)}
+		m, err := eth.NewMultiBackend([]peruneth.ContractInterface{failing, next}, 0)
+		require.NoError(err)
+
+		sub, err := m.SubscribeNewHead(ctx, make(chan *types.Header))
+		require.NoError(err)
+		<-failing.subscribed
+
+		// The failed subscription is renewed on the next node.
+		failing.sub.errc <- errors.New("connection lost")
+		select {
+		case <-next.subscribed:
+		case <-time.After(time.Second):
+			t.Fatal("no resubscription")
+		}
+		select {
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		default:
+		}
+
+		sub.Unsubscribe()
+		select {
+		case <-next.sub.unsubscribed:
+		case <-time.After(time.Second):
+			t.Fatal("node subscription not closed")
+		}
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		require := require.New(t)
+		nodes := []peruneth.ContractInterface{
+			&fakeNode{header: header}, &fakeNode{header: header}, &fakeNode{header: forged}}
+		m, err := eth.NewMultiBackend(nodes, 2)
+		require.NoError(err)
+		require.NoError(m.VerifyBlock(ctx, 7, header.Hash()))
+		require.Error(m.VerifyBlock(ctx, 7, forged.Hash()))
+
+		m, err = eth.NewMultiBackend(nodes, 3)
+		require.NoError(err)
+		require.Error(m.VerifyBlock(ctx, 7, header.Hash()))
+
+		_, err = eth.NewMultiBackend(nodes, 4)
+		require.Error(err)
+	})
+}
+
+// fakeNode answers header requests with a fixed header or error and header
+// subscriptions with a fixed subscription. All other methods are not
+// implemented.
+type fakeNode struct {
+	peruneth.ContractInterface
+	header *types.Header
+	err    error
+	calls  int
+
+	sub        *fakeSub
+	subscribed chan struct{}
+}
+
+func (n *fakeNode) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	n.calls++
+	return n.header, n.err
+}
+
+func (n *fakeNode) SubscribeNewHead(context.Context, chan<- *types.Header) (ethereum.Subscription, error) {
+	n.subscribed <- struct{}{}
+	return n.sub, n.err
+}
+
+// fakeSub is a subscription that fails when an error is sent on errc.
+type fakeSub struct {
+	errc         chan error
+	unsubscribed chan struct{}
+}
+
+func newFakeSub() *fakeSub {
+	return &fakeSub{errc: make(chan error, 1), unsubscribed: make(chan struct{})}
+}
+
+func (s *fakeSub) Err() <-chan error { return s.errc }
+func (s *fakeSub) Unsubscribe()      { close(s.unsubscribed) }
@@ -0,0 +1,342 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+)
+
+// ReplacementPriceBump is the minimal gas price increase in percent of a
+// transaction that replaces a pending transaction, as required by geth.
+const ReplacementPriceBump = 10
+
+// DroppedTxChecks is the number of consecutive checks in which a transaction
+// must be unknown to the node before it is considered dropped. A single miss
+// may be caused by a node that has not seen the transaction yet, e.g., after
+// failing over to another node.
+const DroppedTxChecks = 3
+
+// nonceManager allocates the nonces of an account's transactions. It
+// serialises the allocation, so that concurrent transactions get consecutive
+// nonces instead of racing for the node's pending nonce. It tracks all sent
+// transactions until they are mined and detects transactions that were
+// dropped by the node, whose nonces are reused.
+type nonceManager struct {
+	backend peruneth.ContractInterface
+	account common.Address
+
+	mtx      sync.Mutex                    // protects all below.
+	synced   bool                          // Whether next was initialized.
+	next     uint64                        // Next unused nonce.
+	free     []uint64                      // Released nonces below next, ascending.
+	reserved map[uint64]struct{}           // Allocated nonces of unsent transactions.
+	inflight map[uint64]*types.Transaction // Latest sent transaction per nonce.
+	missing  map[uint64]int                // Consecutive misses per in-flight nonce.
+}
+
+func newNonceManager(backend peruneth.ContractInterface, account common.Address) *nonceManager {
+	return &nonceManager{
+		backend:  backend,
+		account:  account,
+		reserved: make(map[uint64]struct{}),
+		inflight: make(map[uint64]*types.Transaction),
+		missing:  make(map[uint64]int),
+	}
+}
+
+// allocate reserves the lowest unused nonce. It must be released if the
+// transaction is not sent.
+func (m *nonceManager) allocate(ctx context.Context) (uint64, error) {
+	// The node's pending nonce is higher than ours if the account was used
+	// elsewhere, and all nonces below it are used. It is retrieved without
+	// holding m.mtx, so that a slow node does not block the other
+	// transactions. A pending nonce that is outdated by the time it is merged
+	// is only lower, which the merge tolerates.
+	pending, err := m.backend.PendingNonceAt(ctx, m.account)
+	if err != nil {
+		return 0, fmt.Errorf("retrieving pending nonce: %w", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if !m.synced || pending > m.next {
+		m.next, m.synced = pending, true
+	}
+	for len(m.free) > 0 && m.free[0] < pending {
+		m.free = m.free[1:]
+	}
+
+	var nonce uint64
+	if len(m.free) > 0 {
+		nonce, m.free = m.free[0], m.free[1:]
+	} else {
+		nonce = m.next
+		m.next++
+	}
+	m.reserved[nonce] = struct{}{}
+	return nonce, nil
+}
+
+// release returns an allocated nonce whose transaction was not sent.
+func (m *nonceManager) release(nonce uint64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.reserved, nonce)
+	m.releaseLocked(nonce)
+}
+
+// releaseLocked marks the nonce as unused. m.mtx must be held.
+func (m *nonceManager) releaseLocked(nonce uint64) {
+	if nonce >= m.next {
+		return
+	}
+	i := sort.Search(len(m.free), func(i int) bool { return m.free[i] >= nonce })
+	if i < len(m.free) && m.free[i] == nonce {
+		return
+	}
+	m.free = append(m.free, 0)
+	copy(m.free[i+1:], m.free[i:])
+	m.free[i] = nonce
+	// Shrink next over trailing unused nonces.
+	for len(m.free) > 0 && m.free[len(m.free)-1] == m.next-1 {
+		m.free = m.free[:len(m.free)-1]
+		m.next--
+	}
+}
+
+// sent tracks the sent transaction. It replaces an in-flight transaction of
+// the same nonce.
+func (m *nonceManager) sent(tx *types.Transaction) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	n := tx.Nonce()
+	delete(m.reserved, n)
+	if i := sort.Search(len(m.free), func(i int) bool { return m.free[i] >= n }); i < len(m.free) && m.free[i] == n {
+		m.free = append(m.free[:i], m.free[i+1:]...)
+	}
+	if n >= m.next && m.synced {
+		m.next = n + 1
+	}
+	m.inflight[n] = tx
+	delete(m.missing, n)
+}
+
+// failed releases the nonce of a transaction that could not be sent, unless a
+// transaction with this nonce is in flight.
+func (m *nonceManager) failed(tx *types.Transaction) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, ok := m.reserved[tx.Nonce()]; ok {
+		delete(m.reserved, tx.Nonce())
+		m.releaseLocked(tx.Nonce())
+	}
+}
+
+// mined stops tracking the transaction's nonce.
+func (m *nonceManager) mined(tx *types.Transaction) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.inflight, tx.Nonce())
+	delete(m.missing, tx.Nonce())
+}
+
+// inFlight returns the latest sent transaction of each unmined nonce, ordered
+// by nonce.
+func (m *nonceManager) inFlight() []*types.Transaction {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	txs := make([]*types.Transaction, 0, len(m.inflight))
+	for _, tx := range m.inflight {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+	return txs
+}
+
+// check looks up all in-flight transactions. Mined transactions are no longer
+// tracked. Transactions that are unknown to the node in DroppedTxChecks
+// consecutive checks and whose nonce is not used yet, i.e., not below the
+// node's pending nonce, were dropped. They are returned and their nonces are
+// reused.
+func (m *nonceManager) check(ctx context.Context) (dropped []*types.Transaction, err error) {
+	var pending *uint64 // retrieved on the first miss
+	for _, tx := range m.inFlight() {
+		// Some backends return no receipt and no error for unknown
+		// transactions.
+		r, err := m.backend.TransactionReceipt(ctx, tx.Hash())
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return dropped, fmt.Errorf("retrieving receipt: %w", err)
+		} else if r != nil {
+			m.mined(tx)
+			continue
+		}
+		if _, _, err := m.backend.TransactionByHash(ctx, tx.Hash()); err == nil {
+			m.mtx.Lock()
+			delete(m.missing, tx.Nonce()) // pending
+			m.mtx.Unlock()
+			continue
+		} else if !errors.Is(err, ethereum.NotFound) {
+			return dropped, fmt.Errorf("retrieving transaction: %w", err)
+		}
+
+		if pending == nil {
+			n, err := m.backend.PendingNonceAt(ctx, m.account)
+			if err != nil {
+				return dropped, fmt.Errorf("retrieving pending nonce: %w", err)
+			}
+			pending = &n
+		}
+		if tx.Nonce() < *pending {
+			// The nonce was used, possibly by this transaction on a node that
+			// does not know it yet.
+			m.mined(tx)
+			continue
+		}
+
+		m.mtx.Lock()
+		if m.inflight[tx.Nonce()] == tx {
+			if m.missing[tx.Nonce()]++; m.missing[tx.Nonce()] >= DroppedTxChecks {
+				delete(m.inflight, tx.Nonce())
+				delete(m.missing, tx.Nonce())
+				m.releaseLocked(tx.Nonce())
+				dropped = append(dropped, tx)
+			}
+		}
+		m.mtx.Unlock()
+	}
+	return dropped, nil
+}
+
+// signer wraps the signer of the transactor. Transactions without an explicit
+// nonce get the next nonce allocated when they are signed, i.e., after their
+// gas was estimated. Transactions with an explicit nonce replace the in-flight
+// transaction of that nonce.
+func (m *nonceManager) signer(ctx context.Context, tr *bind.TransactOpts) bind.SignerFn {
+	sign := tr.Signer
+	return func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if tr.Nonce != nil {
+			return sign(addr, tx)
+		}
+		nonce, err := m.allocate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		signed, err := sign(addr, withNonce(tx, nonce))
+		if err != nil {
+			m.release(nonce)
+			return nil, err
+		}
+		log.WithFields(log.Fields{"nonce": nonce, "tx": signed.Hash().Hex()}).
+			Trace("EthClient: Allocated nonce")
+		return signed, nil
+	}
+}
+
+// withNonce returns a copy of the unsigned transaction with the given nonce.
+func withNonce(tx *types.Transaction, nonce uint64) *types.Transaction {
+	if tx.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      nonce,
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: tx.GasPrice(),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
+
+// replacementGasPrice returns the minimal gas price of a transaction that
+// replaces a transaction with the given gas price.
+func replacementGasPrice(price *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(price, big.NewInt(100+ReplacementPriceBump))
+	bumped.Div(bumped, big.NewInt(100))
+	return bumped.Add(bumped, big.NewInt(1)) // round up
+}
+
+// NewReplacementTransactor creates a transactor for a transaction that replaces
+// the stuck transaction tx. It has the same nonce and fees that are high enough
+// for the node to accept the replacement. Nodes require both the fee cap and the
+// tip of dynamic-fee transactions to be bumped, where a legacy gas price counts
+// as both.
+func (cl *Client) NewReplacementTransactor(ctx context.Context, tx *types.Transaction) (*bind.TransactOpts, error) {
+	tr, err := cl.NewTransactor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tr.Nonce = new(big.Int).SetUint64(tx.Nonce())
+	if tr.GasPrice != nil {
+		if min := replacementGasPrice(tx.GasPrice()); tr.GasPrice.Cmp(min) < 0 {
+			tr.GasPrice = min
+		}
+		return tr, nil
+	}
+	if min := replacementGasPrice(tx.GasFeeCap()); tr.GasFeeCap.Cmp(min) < 0 {
+		tr.GasFeeCap = min
+	}
+	if min := replacementGasPrice(tx.GasTipCap()); tr.GasTipCap.Cmp(min) < 0 {
+		tr.GasTipCap = min
+	}
+	return tr, nil
+}
+
+// SendTransaction sends the transaction and tracks it as in flight. If sending
+// fails, its allocated nonce is reused.
+func (cl *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := cl.ContractBackend.SendTransaction(ctx, tx); err != nil {
+		cl.nonces.failed(tx)
+		return err
+	}
+	cl.nonces.sent(tx)
+	return nil
+}
+
+// ConfirmTransaction waits until the transaction is mined and stops tracking
+// it.
+func (cl *Client) ConfirmTransaction(ctx context.Context, tx *types.Transaction, acc accounts.Account) (*types.Receipt, error) {
+	r, err := cl.ContractBackend.ConfirmTransaction(ctx, tx, acc)
+	if r != nil {
+		cl.nonces.mined(tx)
+	}
+	return r, err
+}
+
+// InFlightTransactions returns the sent but not yet mined transactions of the
+// client, ordered by nonce. Of replaced transactions, only the latest
+// replacement is returned.
+func (cl *Client) InFlightTransactions() []*types.Transaction {
+	return cl.nonces.inFlight()
+}
+
+// CheckTransactions checks whether the in-flight transactions are still known
+// to the node. It returns the transactions that were dropped, i.e., unknown in
+// DroppedTxChecks consecutive calls. Their nonces are reused by the next
+// transactions, which fills the nonce gaps that would otherwise block all
+// later transactions. It should be called periodically, e.g., once per block.
+func (cl *Client) CheckTransactions(ctx context.Context) ([]*types.Transaction, error) {
+	return cl.nonces.check(ctx)
+}
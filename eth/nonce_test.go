@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+	chtest "perun.network/go-perun/backend/ethereum/channel/test"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+)
+
+// droppingBackend is a simulated backend that loses sent transactions while
+// drop is set and rejects them while fail is set. If stall is set,
+// PendingNonceAt blocks until it is closed.
+type droppingBackend struct {
+	*chtest.SimulatedBackend
+	drop, fail bool
+	stall      chan struct{}
+}
+
+func (b *droppingBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if b.stall != nil {
+		<-b.stall
+	}
+	return b.SimulatedBackend.PendingNonceAt(ctx, account)
+}
+
+func (b *droppingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.fail {
+		return errors.New("rejected")
+	} else if b.drop {
+		return nil
+	}
+	return b.SimulatedBackend.SendTransaction(ctx, tx)
+}
+
+func TestClient_Nonces(t *testing.T) {
+	rng := ptest.Prng(t)
+	s := eth.NewSimSetup(rng, 1)
+	acc := s.Accounts[0]
+	backend := &droppingBackend{SimulatedBackend: s.SimBackend}
+	cl := eth.NewClient(peruneth.NewContractBackend(backend,
+		eth.NewDefaultTransactor(s.Wallet.Wallet(), simChainID), eth.TxFinalityDepth), acc)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// sign signs a transfer with the transactor, like a binding does.
+	sign := func(tr *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		var nonce uint64
+		if tr.Nonce != nil {
+			nonce = tr.Nonce.Uint64()
+		}
+		to := acc.Address
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   simChainID,
+			Nonce:     nonce,
+			GasTipCap: tr.GasTipCap,
+			GasFeeCap: tr.GasFeeCap,
+			Gas:       21000,
+			To:        &to,
+			Value:     value,
+		})
+		return tr.Signer(acc.Address, tx)
+	}
+	transfer := func(value *big.Int) (*types.Transaction, error) {
+		tr, err := cl.NewTransactor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := sign(tr, value)
+		if err != nil {
+			return nil, err
+		}
+		return tx, cl.SendTransaction(ctx, tx)
+	}
+	// requireMined checks the in-flight transactions, which must all be mined.
+	requireMined := func(t *testing.T) {
+		dropped, err := cl.CheckTransactions(ctx)
+		require.NoError(t, err)
+		require.Empty(t, dropped)
+		require.Empty(t, cl.InFlightTransactions())
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		require := require.New(t)
+		const n = 16
+		var wg sync.WaitGroup
+		txs, errs := make(chan *types.Transaction, n), make(chan error, n)
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			go func() {
+				defer wg.Done()
+				tr, err := cl.NewTransactor(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				tx, err := sign(tr, big.NewInt(1))
+				if err != nil {
+					errs <- err
+					return
+				}
+				txs <- tx
+			}()
+		}
+		wg.Wait()
+		close(txs)
+		close(errs)
+		require.NoError(<-errs)
+
+		// The simulated backend only accepts transactions in nonce order.
+		sorted := make([]*types.Transaction, n)
+		for tx := range txs {
+			require.Less(tx.Nonce(), uint64(n))
+			require.Nil(sorted[tx.Nonce()], "duplicate nonce %d", tx.Nonce())
+			sorted[tx.Nonce()] = tx
+		}
+		for _, tx := range sorted {
+			require.NoError(cl.SendTransaction(ctx, tx))
+		}
+		require.Len(cl.InFlightTransactions(), n)
+		requireMined(t)
+	})
+
+	t.Run("slow-node", func(t *testing.T) {
+		require := require.New(t)
+		tr, err := cl.NewTransactor(ctx)
+		require.NoError(err)
+		backend.stall = make(chan struct{})
+		var tx *types.Transaction
+		signed := make(chan error, 1)
+		go func() {
+			var err error
+			tx, err = sign(tr, big.NewInt(1))
+			signed <- err
+		}()
+		time.Sleep(10 * time.Millisecond) // Let the allocation wait for the node.
+
+		inflight := make(chan []*types.Transaction)
+		go func() { inflight <- cl.InFlightTransactions() }()
+		select {
+		case txs := <-inflight:
+			require.Empty(txs)
+		case <-time.After(time.Second):
+			t.Fatal("nonce manager locked while waiting for the node")
+		}
+
+		close(backend.stall)
+		require.NoError(<-signed)
+		backend.stall = nil
+		require.NoError(cl.SendTransaction(ctx, tx))
+		requireMined(t)
+	})
+
+	t.Run("failed-send", func(t *testing.T) {
+		require := require.New(t)
+		next, err := cl.NextNonce(ctx)
+		require.NoError(err)
+		backend.fail = true
+		_, err = transfer(big.NewInt(1))
+		backend.fail = false
+		require.Error(err)
+		require.Empty(cl.InFlightTransactions())
+
+		tx, err := transfer(big.NewInt(1))
+		require.NoError(err)
+		require.Equal(next, tx.Nonce(), "nonce reused")
+		requireMined(t)
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		require := require.New(t)
+		backend.drop = true
+		tx, err := transfer(big.NewInt(1))
+		require.NoError(err)
+		backend.drop = false
+		require.Len(cl.InFlightTransactions(), 1)
+
+		// A transaction is only dropped after several consecutive misses.
+		for i := 1; i < eth.DroppedTxChecks; i++ {
+			dropped, err := cl.CheckTransactions(ctx)
+			require.NoError(err)
+			require.Empty(dropped)
+			require.Len(cl.InFlightTransactions(), 1)
+		}
+		dropped, err := cl.CheckTransactions(ctx)
+		require.NoError(err)
+		require.Len(dropped, 1)
+		require.Equal(tx.Hash(), dropped[0].Hash())
+		require.Empty(cl.InFlightTransactions())
+
+		next, err := transfer(big.NewInt(1))
+		require.NoError(err)
+		require.Equal(tx.Nonce(), next.Nonce(), "nonce reused")
+		requireMined(t)
+	})
+
+	t.Run("replacement", func(t *testing.T) {
+		require := require.New(t)
+		backend.drop = true
+		stuck, err := transfer(big.NewInt(1))
+		require.NoError(err)
+		backend.drop = false
+
+		tr, err := cl.NewReplacementTransactor(ctx, stuck)
+		require.NoError(err)
+		require.Equal(stuck.Nonce(), tr.Nonce.Uint64())
+		minFeeCap := new(big.Int).Mul(stuck.GasFeeCap(), big.NewInt(100+eth.ReplacementPriceBump))
+		require.True(new(big.Int).Mul(tr.GasFeeCap, big.NewInt(100)).Cmp(minFeeCap) >= 0)
+		minTip := new(big.Int).Mul(stuck.GasTipCap(), big.NewInt(100+eth.ReplacementPriceBump))
+		require.True(new(big.Int).Mul(tr.GasTipCap, big.NewInt(100)).Cmp(minTip) >= 0)
+
+		tx, err := sign(tr, big.NewInt(2))
+		require.NoError(err)
+		require.NoError(cl.SendTransaction(ctx, tx))
+		require.Equal(stuck.Nonce(), tx.Nonce())
+		inflight := cl.InFlightTransactions()
+		require.Len(inflight, 1)
+		require.Equal(tx.Hash(), inflight[0].Hash(), "replaced")
+
+		requireMined(t)
+		next, err := cl.NextNonce(ctx)
+		require.NoError(err)
+		require.Equal(stuck.Nonce()+1, next)
+	})
+
+	t.Run("nonce-used", func(t *testing.T) {
+		require := require.New(t)
+		backend.drop = true
+		unknown, err := transfer(big.NewInt(1))
+		require.NoError(err)
+		backend.drop = false
+
+		// The nonce is used by a transaction that the client does not track,
+		// e.g., one sent via another node.
+		tr, err := cl.NewReplacementTransactor(ctx, unknown)
+		require.NoError(err)
+		tx, err := sign(tr, big.NewInt(2))
+		require.NoError(err)
+		require.NoError(backend.SimulatedBackend.SendTransaction(ctx, tx))
+
+		requireMined(t)
+		next, err := cl.NextNonce(ctx)
+		require.NoError(err)
+		require.Equal(unknown.Nonce()+1, next, "nonce not reused")
+	})
+}
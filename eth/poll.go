@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	log "github.com/sirupsen/logrus"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+)
+
+// DefaultPollInterval is the interval in which a PollingBackend queries the
+// node for new blocks and logs.
+const DefaultPollInterval = time.Second
+
+// MaxPollFailures is the number of consecutive failed polls after which a
+// polling subscription fails.
+const MaxPollFailures = 5
+
+// PollingBackend implements the subscriptions to new headers and logs by
+// periodically querying the node for the latest block and for logs. This lets
+// the Client and all contract bindings run against HTTP-only JSON-RPC
+// endpoints, which do not support subscriptions.
+//
+// Unlike node subscriptions, it does not send removed logs on chain
+// reorganizations.
+type PollingBackend struct {
+	peruneth.ContractInterface
+	interval time.Duration
+}
+
+var _ peruneth.ContractInterface = (*PollingBackend)(nil)
+
+// NewPollingBackend wraps the contract interface to poll in the given
+// interval.
+func NewPollingBackend(ci peruneth.ContractInterface, interval time.Duration) *PollingBackend {
+	return &PollingBackend{ContractInterface: ci, interval: interval}
+}
+
+// Dial connects to the Ethereum node at the given URL. For HTTP(S) URLs, the
+// connection is wrapped in a PollingBackend, since subscriptions are only
+// supported on websocket and IPC connections.
+func Dial(ctx context.Context, rawurl string) (peruneth.ContractInterface, error) {
+	client, err := ethclient.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(rawurl); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		log.WithField("url", rawurl).Info("EthClient: HTTP endpoint, polling for blocks and logs")
+		return NewPollingBackend(client, DefaultPollInterval), nil
+	}
+	return client, nil
+}
+
+// NetworkID returns the network ID of the wrapped contract interface.
+func (b *PollingBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	if v, ok := b.ContractInterface.(interface {
+		NetworkID(context.Context) (*big.Int, error)
+	}); ok {
+		return v.NetworkID(ctx)
+	}
+	return nil, errors.New("wrapped ContractInterface has no method NetworkID")
+}
+
+// ChainID returns the chain ID of the wrapped contract interface.
+func (b *PollingBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return ChainID(ctx, b.ContractInterface)
+}
+
+// SubscribeNewHead polls for new blocks and sends the header of every new
+// block on ch, in ascending order. If the head was replaced by a block of the
+// same height, the new head is sent again.
+func (b *PollingBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	last, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving head: %w", err)
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		return b.poll(quit, func() error {
+			headers, err := b.newHeaders(last)
+			if err != nil {
+				return err
+			}
+			for _, h := range headers {
+				select {
+				case ch <- h:
+					last = h
+				case <-quit:
+					return nil
+				}
+			}
+			return nil
+		})
+	}), nil
+}
+
+// newHeaders returns the headers of all blocks after last up to the current
+// head.
+func (b *PollingBackend) newHeaders(last *types.Header) ([]*types.Header, error) {
+	ctx, cancel := ContextNodeReq()
+	defer cancel()
+	head, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving head: %w", err)
+	}
+	lastNum, headNum := last.Number.Uint64(), head.Number.Uint64()
+	switch {
+	case headNum < lastNum, headNum == lastNum && head.Hash() == last.Hash():
+		return nil, nil
+	case headNum == lastNum:
+		return []*types.Header{head}, nil // Reorg of the head.
+	}
+
+	headers := make([]*types.Header, 0, headNum-lastNum)
+	for n := lastNum + 1; n < headNum; n++ {
+		h, err := b.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return nil, fmt.Errorf("retrieving header %d: %w", n, err)
+		}
+		headers = append(headers, h)
+	}
+	return append(headers, head), nil
+}
+
+// SubscribeFilterLogs polls for logs matching the query in all new blocks
+// and sends them on ch. If the query has no start block, it starts at the
+// block after the current head. The query's end block is ignored.
+func (b *PollingBackend) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if q.BlockHash != nil {
+		return nil, errors.New("polling logs of a single block is not supported")
+	}
+	var from uint64
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	} else {
+		head, err := b.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving head: %w", err)
+		}
+		from = head.Number.Uint64() + 1
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		return b.poll(quit, func() error {
+			logs, to, err := b.newLogs(q, from)
+			if err != nil {
+				return err
+			}
+			for _, l := range logs {
+				select {
+				case ch <- l:
+				case <-quit:
+					return nil
+				}
+			}
+			from = to + 1
+			return nil
+		})
+	}), nil
+}
+
+// newLogs returns the logs matching the query from block from up to the
+// current head, and the head's number.
+func (b *PollingBackend) newLogs(q ethereum.FilterQuery, from uint64) ([]types.Log, uint64, error) {
+	ctx, cancel := ContextNodeReq()
+	defer cancel()
+	head, err := b.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("retrieving head: %w", err)
+	}
+	to := head.Number.Uint64()
+	if to < from {
+		return nil, from - 1, nil
+	}
+	q.FromBlock, q.ToBlock = new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)
+	logs, err := b.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("filtering logs: %w", err)
+	}
+	return logs, to, nil
+}
+
+// poll calls fn in the polling interval until quit is closed. Failed polls
+// are logged and retried in the next interval. After MaxPollFailures
+// consecutive failures, the last error is returned, which fails the
+// subscription.
+func (b *PollingBackend) poll(quit <-chan struct{}, fn func() error) error {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	var failures int
+	for {
+		select {
+		case <-ticker.C:
+			err := fn()
+			if err == nil {
+				failures = 0
+				continue
+			}
+			if failures++; failures >= MaxPollFailures {
+				return fmt.Errorf("polling failed %d times: %w", failures, err)
+			}
+			log.Warnf("EthClient: Polling: %v", err)
+		case <-quit:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/contracts/bindings"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestPollingBackend(t *testing.T) {
+	rng := ptest.Prng(t)
+	s := eth.NewSimSetup(rng, 1)
+	poll := eth.NewPollingBackend(s.SimBackend, 10*time.Millisecond)
+	cb := peruneth.NewContractBackend(poll, eth.NewDefaultTransactor(s.Wallet.Wallet(), simChainID), eth.TxFinalityDepth)
+	cl := eth.NewClient(cb, s.Accounts[0])
+	params := tee.Parameters{TEE: s.Accounts[0].Address, PhaseDuration: 3, ResponseDuration: 1}
+	require.NoError(t, cl.DeployContracts(&params))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("blocks", func(t *testing.T) {
+		require := require.New(t)
+		sub, err := cl.SubscribeBlocks()
+		require.NoError(err)
+		defer sub.Unsubscribe()
+		head, err := s.SimBackend.HeaderByNumber(ctx, nil)
+		require.NoError(err)
+
+		// Both blocks are sent, even if they are mined within one interval.
+		s.SimBackend.Commit()
+		s.SimBackend.Commit()
+		for i := uint64(1); i <= 2; i++ {
+			select {
+			case b := <-sub.Blocks():
+				require.Equal(head.Number.Uint64()+i, b.NumberU64())
+			case <-ctx.Done():
+				t.Fatal("no block received")
+			}
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		require := require.New(t)
+		node := &failingNode{ContractInterface: s.SimBackend}
+		sub, err := eth.NewPollingBackend(node, time.Millisecond).
+			SubscribeNewHead(ctx, make(chan *types.Header))
+		require.NoError(err)
+		defer sub.Unsubscribe()
+
+		atomic.StoreInt32(&node.fail, 1)
+		select {
+		case err := <-sub.Err():
+			require.Error(err)
+		case <-ctx.Done():
+			t.Fatal("subscription did not fail")
+		}
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		require := require.New(t)
+		contract, err := bindings.NewErdstall(params.Contract, cb)
+		require.NoError(err)
+		events := make(chan *bindings.ErdstallDeposited)
+		sub, err := contract.WatchDeposited(nil, events, nil, nil)
+		require.NoError(err)
+		defer sub.Unsubscribe()
+
+		tr, err := cl.NewTransactor(ctx)
+		require.NoError(err)
+		tr.Value = big.NewInt(100)
+		_, err = contract.Deposit(tr)
+		require.NoError(err)
+		select {
+		case ev := <-events:
+			require.Equal(s.Accounts[0].Address, ev.Account)
+			require.Zero(ev.Value.Cmp(tr.Value))
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("no event received")
+		}
+	})
+}
+
+// failingNode fails all header requests while fail is set.
+type failingNode struct {
+	peruneth.ContractInterface
+	fail int32
+}
+
+func (n *failingNode) HeaderByNumber(ctx context.Context, num *big.Int) (*types.Header, error) {
+	if atomic.LoadInt32(&n.fail) != 0 {
+		return nil, errors.New("node down")
+	}
+	return n.ContractInterface.HeaderByNumber(ctx, num)
+}
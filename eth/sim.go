@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	log "github.com/sirupsen/logrus"
 	ethchannel "perun.network/go-perun/backend/ethereum/channel"
@@ -46,7 +47,8 @@ func NewSimSetup(rng *rand.Rand, numAccounts int) *SimSetup {
 		simBackend.FundAddress(ctx, acc.Account.Address)
 	}
 
-	cb := ethchannel.NewContractBackend(simBackend, hd.NewTransactor(pwallet.Wallet()))
+	signer := types.LatestSignerForChainID(simBackend.Blockchain().Config().ChainID)
+	cb := ethchannel.NewContractBackend(simBackend, hd.NewTransactor(pwallet.Wallet(), signer), TxFinalityDepth)
 
 	return &SimSetup{
 		SimBackend: simBackend,
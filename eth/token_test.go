@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/contracts/bindings"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+// TestErdstall_Tokens tests token deposits, exits, challenges and frozen
+// withdrawals against the compiled contract on a simulated chain.
+func TestErdstall_Tokens(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	s := eth.NewSimSetup(rng, 3)
+	op, alice, bob := s.Accounts[0], s.Accounts[1], s.Accounts[2]
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// The operator's account also acts as the TEE.
+	params := tee.Parameters{TEE: op.Address, PhaseDuration: 20, ResponseDuration: 5}
+	require.NoError(eth.NewClient(*s.CB, op).DeployContracts(&params))
+	contract, err := bindings.NewErdstall(params.Contract, s.SimBackend)
+	require.NoError(err)
+
+	// transact sends a transaction of acc and waits until it is mined.
+	transact := func(acc accounts.Account, f func(*bind.TransactOpts) (*types.Transaction, error)) error {
+		cl := eth.NewClient(*s.CB, acc)
+		tr, err := cl.NewTransactor(ctx)
+		require.NoError(err)
+		tx, err := f(tr)
+		if err != nil {
+			return err
+		}
+		_, err = cl.ConfirmTransaction(ctx, tx, acc)
+		return err
+	}
+	var tokenAddr common.Address
+	require.NoError(transact(alice, func(tr *bind.TransactOpts) (tx *types.Transaction, err error) {
+		tokenAddr, tx, _, err = bindings.DeployTestToken(tr, s.SimBackend, big.NewInt(1000))
+		return tx, err
+	}))
+	token, err := bindings.NewTestToken(tokenAddr, s.SimBackend)
+	require.NoError(err)
+
+	// mineUntil mines blocks until the next transaction is in the given epoch.
+	mineUntil := func(epoch uint64) {
+		for s.SimBackend.Blockchain().CurrentBlock().NumberU64()+1 < params.DepositStartBlock(epoch) {
+			s.SimBackend.Commit()
+		}
+	}
+	nextEpoch := func() uint64 {
+		return params.DepositEpoch(s.SimBackend.Blockchain().CurrentBlock().NumberU64() + 1)
+	}
+	// sign signs the token balance like the TEE.
+	sign := func(epoch uint64, acc common.Address, value int64) (bindings.ErdstallTokenBalance, []byte) {
+		bal := tee.Balance{Epoch: epoch, Account: acc, Token: tokenAddr, Value: (*tee.Amount)(big.NewInt(value))}
+		msg, err := tee.EncodeBalanceProof(params.Contract, bal)
+		require.NoError(err)
+		sig, err := s.HdWallet.SignText(op, crypto.Keccak256(msg))
+		require.NoError(err)
+		sig[64] += 27
+		return bal.ToEthTokenBal(), sig
+	}
+	tokenBalance := func(acc common.Address) int64 {
+		bal, err := token.BalanceOf(nil, acc)
+		require.NoError(err)
+		return bal.Int64()
+	}
+	deposit := func(acc accounts.Account, amount int64) uint64 {
+		require.NoError(transact(acc, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return token.Approve(tr, params.Contract, big.NewInt(amount))
+		}))
+		epoch := nextEpoch()
+		require.NoError(transact(acc, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.DepositToken(tr, tokenAddr, big.NewInt(amount))
+		}))
+		return epoch
+	}
+	require.NoError(transact(alice, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+		return token.Transfer(tr, bob.Address, big.NewInt(500))
+	}))
+
+	// A deposit is exited and withdrawn.
+	{
+		epoch := deposit(alice, 100)
+		require.Equal(int64(400), tokenBalance(alice.Address))
+
+		mineUntil(epoch + 2)
+		bal, sig := sign(epoch, alice.Address, 100)
+		require.Error(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.ExitToken(tr, bal, sig)
+		}), "wrong sender")
+		require.NoError(transact(alice, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.ExitToken(tr, bal, sig)
+		}))
+
+		mineUntil(epoch + 3)
+		require.NoError(transact(alice, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawToken(tr, epoch, tokenAddr)
+		}))
+		require.Equal(int64(500), tokenBalance(alice.Address))
+	}
+
+	// A deposit challenge is answered by the operator.
+	{
+		epoch := deposit(bob, 50)
+		mineUntil(epoch + 2)
+		require.NoError(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.ChallengeTokenDeposit(tr, tokenAddr)
+		}))
+		challenged, err := contract.TokenChallenges(nil, epoch, bob.Address, tokenAddr)
+		require.NoError(err)
+		require.Equal(int64(50), challenged.Int64())
+
+		// The operator answers with the token balance proof.
+		bal, sig := sign(epoch, bob.Address, 50)
+		require.NoError(transact(op, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.ExitToken(tr, bal, sig)
+		}))
+		challenged, err = contract.TokenChallenges(nil, epoch, bob.Address, tokenAddr)
+		require.NoError(err)
+		require.Zero(challenged.Sign())
+
+		mineUntil(epoch + 3)
+		require.Error(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.EnsureFrozen(tr)
+		}), "answered challenges do not freeze")
+		require.NoError(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawToken(tr, epoch, tokenAddr)
+		}))
+		require.Equal(int64(500), tokenBalance(bob.Address))
+	}
+
+	// An unanswered challenge freezes the contract.
+	{
+		frozen := nextEpoch()
+		deposit(bob, 20)
+		mineUntil(frozen + 1)
+		broken := deposit(alice, 30)
+		mineUntil(broken + 2)
+		require.NoError(transact(alice, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.ChallengeTokenDeposit(tr, tokenAddr)
+		}))
+
+		// The challenge is not answered, so the contract freezes.
+		mineUntil(broken + 3)
+		require.NoError(transact(alice, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawTokenChallenge(tr, tokenAddr)
+		}))
+		require.Equal(int64(500), tokenBalance(alice.Address))
+		frozenEpoch, err := contract.FrozenEpoch(nil)
+		require.NoError(err)
+		require.Equal(frozen, frozenEpoch)
+
+		bal, sig := sign(frozen, bob.Address, 20)
+		require.NoError(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawFrozenToken(tr, bal, sig)
+		}))
+		require.Equal(int64(500), tokenBalance(bob.Address))
+		require.Error(transact(bob, func(tr *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.WithdrawFrozenToken(tr, bal, sig)
+		}), "already withdrawn")
+		require.Zero(tokenBalance(params.Contract))
+	}
+}
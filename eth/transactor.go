@@ -1,18 +1,29 @@
 package eth
 
 import (
+	"context"
+	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/pkg/errors"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
 )
 
-// DefaultTransactor can be used to make TransactOpts for accounts stored in a HD wallet.
+// DefaultTransactor can be used to make TransactOpts for accounts stored in a
+// HD wallet. It signs replay-protected transactions of all types for its
+// chain.
 type DefaultTransactor struct {
-	wallet accounts.Wallet
+	wallet  accounts.Wallet
+	chainID *big.Int // nil: unprotected Homestead signatures.
+}
+
+// hashSigner is implemented by wallets that can sign arbitrary hashes, like
+// the hdwallet.Wallet.
+type hashSigner interface {
+	SignHash(account accounts.Account, hash []byte) ([]byte, error)
 }
 
 // NewTransactor returns a TransactOpts for the given account. It errors if the account is
@@ -23,19 +34,43 @@ func (t *DefaultTransactor) NewTransactor(account accounts.Account) (*bind.Trans
 	}
 	return &bind.TransactOpts{
 		From: account.Address,
-		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
 			if address != account.Address {
 				return nil, errors.New("not authorized to sign this account")
 			}
-			// Last parameter (chainID) is only relevant when making EIP155 compliant signatures.
-			// Since we use only non EIP155 signatures, set this to zero value.
-			// For more details, see here (https://github.com/ethereum/EIPs/blob/master/EIPS/eip-155.md).
-			return t.wallet.SignTx(account, tx, big.NewInt(0))
+			hs, ok := t.wallet.(hashSigner)
+			if !ok {
+				return t.wallet.SignTx(account, tx, t.chainID)
+			}
+			signer := t.Signer()
+			sig, err := hs.SignHash(account, signer.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, sig)
 		},
 	}, nil
 }
 
-// NewDefaultTransactor returns a backend that can make TransactOpts for accounts contained in the given HD wallet.
-func NewDefaultTransactor(w accounts.Wallet) *DefaultTransactor {
-	return &DefaultTransactor{wallet: w}
+// Signer returns the transaction signer of the transactor's chain.
+func (t *DefaultTransactor) Signer() types.Signer {
+	return types.LatestSignerForChainID(t.chainID)
+}
+
+// NewDefaultTransactor returns a backend that can make TransactOpts for
+// accounts contained in the given HD wallet. The transactions are signed for
+// the given chain ID, see ChainID. If it is nil, the signatures are not
+// replay-protected.
+func NewDefaultTransactor(w accounts.Wallet, chainID *big.Int) *DefaultTransactor {
+	return &DefaultTransactor{wallet: w, chainID: chainID}
+}
+
+// ChainID queries the chain ID of the connected node.
+func ChainID(ctx context.Context, ci peruneth.ContractInterface) (*big.Int, error) {
+	if v, ok := ci.(interface {
+		ChainID(context.Context) (*big.Int, error)
+	}); ok {
+		return v.ChainID(ctx)
+	}
+	return nil, errors.New("ContractInterface has no method ChainID")
 }
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	peruneth "perun.network/go-perun/backend/ethereum/channel"
+	chtest "perun.network/go-perun/backend/ethereum/channel/test"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+// simChainID is the chain ID of the simulated backend.
+var simChainID = big.NewInt(1337)
+
+func TestDefaultTransactor(t *testing.T) {
+	rng := ptest.Prng(t)
+	s := eth.NewSimSetup(rng, 1)
+	acc := s.Accounts[0]
+	transactor := eth.NewDefaultTransactor(s.Wallet.Wallet(), simChainID)
+
+	t.Run("eip155", func(t *testing.T) {
+		require := require.New(t)
+		opts, err := transactor.NewTransactor(acc)
+		require.NoError(err)
+		tx := types.NewTransaction(0, acc.Address, big.NewInt(1), 21000, big.NewInt(1), nil)
+		signed, err := opts.Signer(acc.Address, tx)
+		require.NoError(err)
+		require.True(signed.Protected())
+		require.Zero(signed.ChainId().Cmp(simChainID))
+		sender, err := types.Sender(types.NewEIP155Signer(simChainID), signed)
+		require.NoError(err)
+		require.Equal(acc.Address, sender)
+
+		_, err = transactor.NewTransactor(eth.NewSimSetup(rng, 1).Accounts[0])
+		require.Error(err, "foreign account")
+	})
+
+	t.Run("eip1559", func(t *testing.T) {
+		require := require.New(t)
+		opts, err := transactor.NewTransactor(acc)
+		require.NoError(err)
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   simChainID,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+			To:        &acc.Address,
+			Value:     big.NewInt(1),
+		})
+		signed, err := opts.Signer(acc.Address, tx)
+		require.NoError(err)
+		require.Equal(uint8(types.DynamicFeeTxType), signed.Type())
+		sender, err := types.Sender(types.NewLondonSigner(simChainID), signed)
+		require.NoError(err)
+		require.Equal(acc.Address, sender)
+	})
+
+	t.Run("fee-policy", func(t *testing.T) {
+		require := require.New(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		cl := eth.NewClient(peruneth.NewContractBackend(s.SimBackend, transactor, eth.TxFinalityDepth), acc)
+		head, err := s.SimBackend.HeaderByNumber(ctx, nil)
+		require.NoError(err)
+		suggested, err := s.SimBackend.SuggestGasTipCap(ctx)
+		require.NoError(err)
+		feeCap := func(tip *big.Int) *big.Int {
+			fc := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+			return fc.Add(fc, tip)
+		}
+
+		// By default, the tip is suggested and the gas limit estimated.
+		tr, err := cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Nil(tr.GasPrice)
+		require.Zero(tr.GasTipCap.Cmp(suggested))
+		require.Zero(tr.GasFeeCap.Cmp(feeCap(suggested)))
+		require.Zero(tr.GasLimit)
+		params := tee.Parameters{TEE: acc.Address, PhaseDuration: 3, ResponseDuration: 1}
+		require.NoError(cl.DeployContracts(&params), "signed for the chain, estimated gas")
+
+		head, err = s.SimBackend.HeaderByNumber(ctx, nil)
+		require.NoError(err)
+		cl.SetFeePolicy(eth.FeePolicy{PriorityFee: 5, GasLimit: eth.DefaultGasLimit})
+		tr, err = cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Zero(tr.GasTipCap.Cmp(big.NewInt(5)))
+		require.Zero(tr.GasFeeCap.Cmp(feeCap(big.NewInt(5))))
+		require.Equal(uint64(eth.DefaultGasLimit), tr.GasLimit)
+
+		maxFee := head.BaseFee.Uint64() + 2
+		cl.SetFeePolicy(eth.FeePolicy{PriorityFee: 5, MaxFee: maxFee})
+		tr, err = cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Equal(maxFee, tr.GasFeeCap.Uint64())
+		require.Zero(tr.GasTipCap.Cmp(big.NewInt(5)))
+
+		cl.SetFeePolicy(eth.FeePolicy{PriorityFee: 5, MaxFee: 3})
+		tr, err = cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Equal(uint64(3), tr.GasFeeCap.Uint64())
+		require.Equal(uint64(3), tr.GasTipCap.Uint64(), "tip capped at the fee cap")
+	})
+
+	t.Run("legacy-fee-policy", func(t *testing.T) {
+		require := require.New(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		backend := &legacyBackend{SimulatedBackend: s.SimBackend}
+		cl := eth.NewClient(peruneth.NewContractBackend(backend, transactor, eth.TxFinalityDepth), acc)
+		suggested, err := s.SimBackend.SuggestGasPrice(ctx)
+		require.NoError(err)
+
+		cl.SetFeePolicy(eth.FeePolicy{GasPriceBump: 5, PriorityFee: 1})
+		tr, err := cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Nil(tr.GasFeeCap)
+		require.Nil(tr.GasTipCap)
+		require.Zero(tr.GasPrice.Cmp(new(big.Int).Add(suggested, big.NewInt(5))))
+
+		maxFee := suggested.Uint64() + 2
+		cl.SetFeePolicy(eth.FeePolicy{GasPriceBump: 5, MaxFee: maxFee})
+		tr, err = cl.NewTransactor(ctx)
+		require.NoError(err)
+		require.Equal(maxFee, tr.GasPrice.Uint64())
+	})
+}
+
+// legacyBackend is a simulated backend whose headers have no base fee, like
+// chains before the London fork.
+type legacyBackend struct {
+	*chtest.SimulatedBackend
+}
+
+func (b *legacyBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	h, err := b.SimulatedBackend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	h = types.CopyHeader(h)
+	h.BaseFee = nil
+	return h, nil
+}
@@ -3,14 +3,20 @@ module github.com/perun-network/erdstall
 go 1.15
 
 require (
-	github.com/ethereum/go-ethereum v1.9.24
-	github.com/gorilla/websocket v1.4.1-0.20190629185528-ae1634f6a989
+	github.com/ethereum/go-ethereum v1.10.12
+	github.com/gorilla/websocket v1.4.2
 	github.com/jroimartin/gocui v0.4.0
-	github.com/miguelmota/go-ethereum-hdwallet v0.0.0-20200123000308-a60dcd172b4c
+	github.com/miguelmota/go-ethereum-hdwallet v0.1.1
 	github.com/montanaflynn/stats v0.6.3
 	github.com/nsf/termbox-go v0.0.0-20201107200903-9b52a5faed9e // indirect
-	github.com/pkg/errors v0.9.1
-	github.com/sirupsen/logrus v1.7.0
-	github.com/stretchr/testify v1.6.1
-	perun.network/go-perun v0.5.3-0.20201130161943-52ca47c0a417
+	github.com/prometheus/client_golang v1.9.0
+	github.com/prometheus/common v0.18.0 // indirect
+	github.com/prometheus/procfs v0.6.0 // indirect
+	github.com/rjeczalik/notify v0.9.2 // indirect
+	github.com/sirupsen/logrus v1.8.1
+	github.com/stretchr/testify v1.7.0
+	github.com/tyler-smith/go-bip39 v1.1.0 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+	perun.network/go-perun v0.8.0
 )
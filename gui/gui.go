@@ -84,7 +84,7 @@ func (gui *GUI) handleClientEvent(e *client.Event) error {
 		}
 	case client.CHAIN_MSG:
 		gui.logChain(e.Message, "\n")
-	case client.NEW_EPOCH:
+	case client.NEW_EPOCH, client.STATUS, client.HISTORY, client.CONN_STATE:
 		// ignored
 	case client.SET_EXIT_AVAIL:
 		gui.balance.SetExitPossible(e.ExitAvailable.Clone())
@@ -154,6 +154,14 @@ func (gui *GUI) eval(input string) (chan *client.CmdStatus, error) {
 		go gui.client.CmdDeposit(status, fs[1:]...)
 	case "send":
 		go gui.client.CmdSend(status, fs[1:]...)
+	case "sendmany":
+		go gui.client.CmdSendMany(status, fs[1:]...)
+	case "lock":
+		go gui.client.CmdLock(status, fs[1:]...)
+	case "claim":
+		go gui.client.CmdClaim(status, fs[1:]...)
+	case "refund":
+		go gui.client.CmdRefund(status, fs[1:]...)
 	case "bench":
 		go gui.client.CmdBench(status, fs[1:]...)
 	case "challenge":
@@ -292,11 +300,29 @@ const helpText = `Available commands:
  bench <runs>
    Runs a benchmark of sending <runs> off-chain TX.
  deposit <amount>
-   Deposits <amount> into the Erdstall contract.
+   Deposits <amount> ETH into the Erdstall contract.
+ deposit <token> <amount>
+   Deposits <amount> of ERC-20 <token> into the Erdstall contract.
  send <receiver> <amount>
-   Sends <amount> to <receiver>.
+   Sends <amount> ETH to <receiver>.
+ send <token> <receiver> <amount>
+   Sends <amount> of ERC-20 <token> to <receiver>.
+   Token amounts are given in the token's smallest unit.
+ sendmany [<token>] <csv-file>
+   Sends ETH or ERC-20 <token> to multiple receivers in one batch.
+   Each line of <csv-file> contains a payment as <receiver>,<amount>.
+ lock [<token>] <receiver> <amount> <hashlock> <epochs>
+   Locks <amount> for <receiver> until they reveal the preimage of the
+   SHA-256 <hashlock>, at the latest within <epochs> epochs.
+ claim <lock-id> <preimage>
+   Claims the locked payment <lock-id> by revealing its <preimage>.
+ refund <lock-id>
+   Refunds the expired locked payment <lock-id>.
  leave
-   Withdraws all funds and exits the network.
+   Withdraws all funds, including tokens, and exits the network.
+ leave [<token>] <amount>
+   Withdraws <amount> ETH or <amount> of ERC-20 <token>.
+   The rest of the balance stays in the network.
  exit, quit
    Close the client.
 `
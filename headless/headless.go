@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package headless runs single client commands without the GUI. It is meant
+// for scripts and bots: all output is written as one JSON object per line and
+// the outcome is reported as the process exit code.
+package headless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/perun-network/erdstall/client"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0 // The command succeeded.
+	ExitFailed  = 1 // The command failed.
+	ExitUsage   = 2 // Unknown command.
+	ExitClient  = 3 // The client stopped, e.g., the enclave is not attested.
+	ExitTimeout = 4 // The client did not become ready in time.
+)
+
+// Output types.
+const (
+	TypeLog      = "log"      // Client log message.
+	TypeProgress = "progress" // Command progress.
+	TypeWarning  = "warning"  // Command warning.
+	TypeError    = "error"    // Command or client error, the last output.
+	TypeResult   = "result"   // Command result, the last output.
+)
+
+// Output is a line of the machine-readable output.
+type Output struct {
+	Type    string      `json:"type"`
+	Command string      `json:"command,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// BalanceResult is the result of the balance command.
+type BalanceResult struct {
+	Balance string `json:"balance"` // In wei.
+}
+
+type (
+	// cmdFunc is the signature of the client's commands.
+	cmdFunc = func(*client.Client, chan *client.CmdStatus, ...string)
+
+	// runner runs a command and tracks the client's events.
+	runner struct {
+		c       *client.Client
+		out     *json.Encoder
+		outMtx  sync.Mutex
+		timeout time.Duration
+
+		once      sync.Once
+		ready     chan struct{} // Closed after the first block.
+		exitAvail chan struct{} // Closed once a balance proof allows exiting.
+		balances  chan *big.Int // The latest balance.
+	}
+)
+
+// commands are the client commands that are available in headless mode,
+// excluding balance.
+var commands = map[string]cmdFunc{
+	"deposit":   (*client.Client).CmdDeposit,
+	"send":      (*client.Client).CmdSend,
+	"sendmany":  (*client.Client).CmdSendMany,
+	"lock":      (*client.Client).CmdLock,
+	"claim":     (*client.Client).CmdClaim,
+	"refund":    (*client.Client).CmdRefund,
+	"bench":     (*client.Client).CmdBench,
+	"challenge": (*client.Client).CmdChallenge,
+	"leave":     (*client.Client).CmdLeave,
+}
+
+// Run runs the client, executes the command args[0] with arguments args[1:]
+// and closes the client. It consumes all client events. The output is written
+// to out and the returned exit code describes the outcome.
+//
+// timeout bounds the time that Run waits for the client to be ready, i.e., to
+// know the current block. Commands that need a balance proof, i.e., leave and
+// balance, also wait at most timeout for it.
+func Run(c *client.Client, events <-chan *client.Event, args []string, out io.Writer, timeout time.Duration) int {
+	r := &runner{
+		c:         c,
+		out:       json.NewEncoder(out),
+		timeout:   timeout,
+		ready:     make(chan struct{}),
+		exitAvail: make(chan struct{}),
+		balances:  make(chan *big.Int, 1),
+	}
+	if len(args) == 0 {
+		r.print(Output{Type: TypeError, Message: "no command given"})
+		return ExitUsage
+	}
+	cmd := args[0]
+	fn, ok := commands[cmd]
+	if !ok && cmd != "balance" {
+		r.print(Output{Type: TypeError, Command: cmd, Message: fmt.Sprintf("unknown command %q", cmd)})
+		return ExitUsage
+	}
+	defer c.Close()
+
+	go r.handleEvents(events)
+	runErr := make(chan error, 1)
+	go func() { runErr <- c.Run() }()
+
+	// Wait until the client is ready and, for leave, a balance proof arrived.
+	wait := r.ready
+	if cmd == "leave" {
+		wait = r.exitAvail
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-runErr:
+		if err == nil {
+			err = errors.New("client stopped")
+		}
+		r.print(Output{Type: TypeError, Command: cmd, Message: err.Error()})
+		return ExitClient
+	case <-timer.C:
+		r.print(Output{Type: TypeError, Command: cmd, Message: "timeout waiting for client"})
+		return ExitTimeout
+	case <-wait:
+	}
+
+	if cmd == "balance" {
+		var bal *big.Int
+		select {
+		case bal = <-r.balances:
+		case <-timer.C:
+			r.print(Output{Type: TypeError, Command: cmd, Message: "timeout waiting for balance proof"})
+			return ExitTimeout
+		}
+		r.print(Output{Type: TypeResult, Command: cmd, Result: BalanceResult{Balance: bal.String()}})
+		return ExitOK
+	}
+
+	return r.runCmd(cmd, fn, args[1:])
+}
+
+// runCmd runs a client command and prints its progress.
+func (r *runner) runCmd(cmd string, fn cmdFunc, args []string) int {
+	status := make(chan *client.CmdStatus, 2)
+	go fn(r.c, status, args...)
+	for s := range status {
+		if s == nil {
+			break
+		} else if s.Err != nil {
+			r.print(Output{Type: TypeError, Command: cmd, Message: s.Err.Error()})
+			return ExitFailed
+		} else if len(s.War) != 0 {
+			r.print(Output{Type: TypeWarning, Command: cmd, Message: s.War})
+		} else {
+			r.print(Output{Type: TypeProgress, Command: cmd, Message: s.Msg})
+		}
+	}
+	r.print(Output{Type: TypeResult, Command: cmd})
+	return ExitOK
+}
+
+// SetupFailed reports an error that occurred while setting up the client and
+// returns ExitClient.
+func SetupFailed(out io.Writer, err error) int {
+	_ = json.NewEncoder(out).Encode(Output{Type: TypeError, Message: err.Error()})
+	return ExitClient
+}
+
+// handleEvents consumes the client's events, prints its messages and tracks
+// its readiness.
+func (r *runner) handleEvents(events <-chan *client.Event) {
+	var exitAvailOnce sync.Once
+	for e := range events {
+		switch e.Type {
+		case client.NEW_BLOCK:
+			r.once.Do(func() { close(r.ready) })
+		case client.SET_EXIT_AVAIL:
+			if e.ExitAvailable != nil {
+				exitAvailOnce.Do(func() { close(r.exitAvail) })
+			}
+		case client.SET_BALANCE:
+			// Only keep the latest balance.
+			select {
+			case <-r.balances:
+			default:
+			}
+			r.balances <- e.Report.Balance
+		case client.CHAIN_MSG:
+			r.print(Output{Type: TypeLog, Message: e.Message})
+		case client.BENCH:
+			r.print(Output{Type: TypeLog, Message: e.Result.String()})
+		}
+	}
+}
+
+// print writes one line of output.
+func (r *runner) print(o Output) {
+	r.outMtx.Lock()
+	defer r.outMtx.Unlock()
+	// Encoding errors cannot be reported anywhere else.
+	_ = r.out.Encode(o)
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package headless_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/erdstall/headless"
+)
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"transfer", "0x00", "1"}} {
+		var out bytes.Buffer
+		// The client is not touched for usage errors.
+		assert.Equal(t, headless.ExitUsage, headless.Run(nil, nil, args, &out, time.Second))
+		var o headless.Output
+		require.NoError(t, json.Unmarshal(out.Bytes(), &o))
+		assert.Equal(t, headless.TypeError, o.Type)
+		assert.NotEmpty(t, o.Message)
+	}
+}
+
+func TestSetupFailed(t *testing.T) {
+	var out bytes.Buffer
+	assert.Equal(t, headless.ExitClient, headless.SetupFailed(&out, errors.New("no operator")))
+	assert.JSONEq(t, `{"type":"error","message":"no operator"}`, out.String())
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+type (
+	// Archive is an append-only, epoch-indexed archive of all deposit proofs,
+	// balance proofs and accepted transactions. If it is backed by a file,
+	// every entry is appended to the file and restored on opening, so that the
+	// operator can still answer challenges and serve historical proofs after
+	// a restart.
+	Archive struct {
+		mtx    sync.RWMutex // protects all.
+		file   *os.File     // nil: in-memory only.
+		epochs map[tee.Epoch]*archiveEpoch
+	}
+
+	// archiveEpoch holds the archived entries of one epoch.
+	archiveEpoch struct {
+		deposits map[proofKey]*tee.DepositProof
+		balances map[proofKey]*tee.BalanceProof
+		txs      []tee.Transaction
+		batches  []tee.BatchTransaction
+	}
+
+	// archiveEntry is a line of the archive file. Exactly one field is set.
+	archiveEntry struct {
+		Deposit *tee.DepositProof     `json:"deposit,omitempty"`
+		Balance *tee.BalanceProof     `json:"balance,omitempty"`
+		Tx      *tee.Transaction      `json:"tx,omitempty"`
+		Batch   *tee.BatchTransaction `json:"batch,omitempty"`
+	}
+)
+
+// NewArchive returns an in-memory archive.
+func NewArchive() *Archive {
+	return &Archive{epochs: make(map[tee.Epoch]*archiveEpoch)}
+}
+
+// OpenArchive opens the archive file at path, or creates it if it does not
+// exist, and restores all archived entries. An incomplete last entry, as left
+// by a crash during writing, is discarded.
+func OpenArchive(path string) (*Archive, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening: %w", err)
+	}
+	a := NewArchive()
+	if err := a.restore(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	a.file = file
+	return a, nil
+}
+
+// restore reads and indexes all entries of the file.
+func (a *Archive) restore(file *os.File) error {
+	r := bufio.NewReader(file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(data) == 0 {
+				return nil
+			}
+			log.WithField("line", line).Warn("Archive: discarding incomplete last entry")
+			return file.Truncate(offset)
+		} else if err != nil {
+			return fmt.Errorf("reading: %w", err)
+		}
+
+		var e archiveEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("decoding line %d: %w", line, err)
+		}
+		if err := a.index(e); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		offset += int64(len(data))
+	}
+}
+
+// Close closes the archive file, if any.
+func (a *Archive) Close() error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// AddDepositProofs archives copies of the given deposit proofs.
+func (a *Archive) AddDepositProofs(dps []*tee.DepositProof) error {
+	entries := make([]archiveEntry, len(dps))
+	for i, dp := range dps {
+		dp := *dp
+		entries[i] = archiveEntry{Deposit: &dp}
+	}
+	return a.add(entries...)
+}
+
+// AddBalanceProofs archives copies of the given balance proofs.
+func (a *Archive) AddBalanceProofs(bps []*tee.BalanceProof) error {
+	entries := make([]archiveEntry, len(bps))
+	for i, bp := range bps {
+		bp := *bp
+		entries[i] = archiveEntry{Balance: &bp}
+	}
+	return a.add(entries...)
+}
+
+// AddTransaction archives an accepted transaction.
+func (a *Archive) AddTransaction(tx tee.Transaction) error {
+	return a.add(archiveEntry{Tx: &tx})
+}
+
+// AddBatchTransaction archives an accepted batch transaction.
+func (a *Archive) AddBatchTransaction(tx tee.BatchTransaction) error {
+	return a.add(archiveEntry{Batch: &tx})
+}
+
+// add indexes the entries and appends them to the file, if any. The entries
+// stay indexed if writing fails.
+func (a *Archive) add(entries ...archiveEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Terminates each entry with a newline.
+	for _, e := range entries {
+		if err := a.index(e); err != nil {
+			return err
+		}
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("encoding: %w", err)
+		}
+	}
+	if a.file == nil {
+		return nil
+	}
+	if _, err := a.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("syncing: %w", err)
+	}
+	return nil
+}
+
+// index adds the entry to the epoch index. a.mtx must be held.
+func (a *Archive) index(e archiveEntry) error {
+	switch {
+	case e.Deposit != nil:
+		b := e.Deposit.Balance
+		a.epoch(b.Epoch).deposits[proofKey{b.Account, b.Token}] = e.Deposit
+	case e.Balance != nil:
+		b := e.Balance.Balance
+		a.epoch(b.Epoch).balances[proofKey{b.Account, b.Token}] = e.Balance
+	case e.Tx != nil:
+		ep := a.epoch(e.Tx.Epoch)
+		ep.txs = append(ep.txs, *e.Tx)
+	case e.Batch != nil:
+		ep := a.epoch(e.Batch.Epoch)
+		ep.batches = append(ep.batches, *e.Batch)
+	default:
+		return errors.New("empty archive entry")
+	}
+	return nil
+}
+
+// epoch returns the entries of the epoch, creating them if needed. a.mtx must
+// be held.
+func (a *Archive) epoch(epoch tee.Epoch) *archiveEpoch {
+	ep, ok := a.epochs[epoch]
+	if !ok {
+		ep = &archiveEpoch{
+			deposits: make(map[proofKey]*tee.DepositProof),
+			balances: make(map[proofKey]*tee.BalanceProof),
+		}
+		a.epochs[epoch] = ep
+	}
+	return ep
+}
+
+// DepositProof returns the deposit proof of the user's token deposits in the
+// given epoch.
+func (a *Archive) DepositProof(epoch tee.Epoch, user, token common.Address) (*tee.DepositProof, bool) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	ep, ok := a.epochs[epoch]
+	if !ok {
+		return nil, false
+	}
+	dp, ok := ep.deposits[proofKey{user, token}]
+	return dp, ok
+}
+
+// BalanceProof returns the balance proof of the user's token balance at the
+// end of the given transaction epoch.
+func (a *Archive) BalanceProof(epoch tee.Epoch, user, token common.Address) (*tee.BalanceProof, bool) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	ep, ok := a.epochs[epoch]
+	if !ok {
+		return nil, false
+	}
+	bp, ok := ep.balances[proofKey{user, token}]
+	return bp, ok
+}
+
+// Transactions returns the accepted transactions of the epoch, in the order
+// in which they were accepted.
+func (a *Archive) Transactions(epoch tee.Epoch) []tee.Transaction {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	ep, ok := a.epochs[epoch]
+	if !ok {
+		return nil
+	}
+	return append([]tee.Transaction(nil), ep.txs...)
+}
+
+// BatchTransactions returns the accepted batch transactions of the epoch, in
+// the order in which they were accepted.
+func (a *Archive) BatchTransactions(epoch tee.Epoch) []tee.BatchTransaction {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	ep, ok := a.epochs[epoch]
+	if !ok {
+		return nil
+	}
+	return append([]tee.BatchTransaction(nil), ep.batches...)
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	op "github.com/perun-network/erdstall/operator"
+	"github.com/perun-network/erdstall/tee"
+	ttest "github.com/perun-network/erdstall/tee/test"
+)
+
+func TestArchive(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+
+	a, err := op.OpenArchive(path)
+	require.NoError(err)
+	dp, bp, tbp := ttest.RandomDP(rng), ttest.RandomBP(rng), ttest.RandomBP(rng)
+	tbp.Balance = ttest.RandomTokenBalance(rng)
+	tx := ttest.RandomTx(t, rng)
+	batch := tee.BatchTransaction{
+		Epoch:    tx.Epoch,
+		Sender:   tx.Sender,
+		Payments: []tee.Payment{{Recipient: tx.Recipient, Amount: tx.Amount}},
+		Sig:      ttest.RandomSig(rng),
+	}
+	require.NoError(a.AddDepositProofs([]*tee.DepositProof{dp}))
+	require.NoError(a.AddBalanceProofs([]*tee.BalanceProof{bp, tbp}))
+	require.NoError(a.AddTransaction(*tx))
+	require.NoError(a.AddBatchTransaction(batch))
+
+	requireArchived := func(a *op.Archive) {
+		got, ok := a.DepositProof(dp.Balance.Epoch, dp.Balance.Account, dp.Balance.Token)
+		require.True(ok)
+		require.Equal(dp.Sig, got.Sig)
+		gotBP, ok := a.BalanceProof(bp.Balance.Epoch, bp.Balance.Account, tee.ETHToken)
+		require.True(ok)
+		require.Equal(bp.Sig, gotBP.Sig)
+		_, ok = a.BalanceProof(tbp.Balance.Epoch, tbp.Balance.Account, tbp.Balance.Token)
+		require.True(ok)
+		_, ok = a.BalanceProof(bp.Balance.Epoch+1, bp.Balance.Account, tee.ETHToken)
+		require.False(ok)
+		require.Len(a.Transactions(tx.Epoch), 1)
+		require.Equal(tx.Hash(), a.Transactions(tx.Epoch)[0].Hash())
+		require.Len(a.BatchTransactions(tx.Epoch), 1)
+	}
+	requireArchived(a)
+	require.NoError(a.Close())
+
+	t.Run("reopen", func(t *testing.T) {
+		a, err := op.OpenArchive(path)
+		require.NoError(err)
+		defer a.Close()
+		requireArchived(a)
+	})
+
+	t.Run("incomplete-entry", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+		require.NoError(err)
+		_, err = f.WriteString(`{"deposit":{"bal`)
+		require.NoError(err)
+		require.NoError(f.Close())
+
+		a, err := op.OpenArchive(path)
+		require.NoError(err)
+		requireArchived(a)
+		// Appending after the discarded entry must work.
+		require.NoError(a.AddTransaction(*tx))
+		require.NoError(a.Close())
+		a, err = op.OpenArchive(path)
+		require.NoError(err)
+		defer a.Close()
+		require.Len(a.Transactions(tx.Epoch), 2)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "corrupt.jsonl")
+		require.NoError(ioutil.WriteFile(corrupt, []byte("{\n{}\n"), 0600))
+		_, err := op.OpenArchive(corrupt)
+		require.Error(err)
+	})
+
+	t.Run("in-memory", func(t *testing.T) {
+		a := op.NewArchive()
+		require.NoError(a.AddBalanceProofs([]*tee.BalanceProof{bp}))
+		_, ok := a.BalanceProof(bp.Balance.Epoch, bp.Balance.Account, tee.ETHToken)
+		require.True(ok)
+		_, ok = a.BalanceProof(bp.Balance.Epoch, eth.NewRandomAddress(rng), tee.ETHToken)
+		require.False(ok)
+		require.NoError(a.Close())
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// catchUpBatch is the number of blocks that are fetched and passed to the
+// enclave at once while catching up.
+const catchUpBatch = 128
+
+// lastBlocker is implemented by enclaves that can resume from persisted state,
+// like the prototype.Enclave.
+type lastBlocker interface {
+	// LastBlock returns the number of the enclave's last known block and
+	// whether it knows any block.
+	LastBlock() (num uint64, known bool, err error)
+}
+
+// resumeBlock returns the number of the first block that the enclave needs.
+// This is the block after the enclave's last known block if it resumed from
+// persisted state, and the initial Erdstall block otherwise.
+func (operator *Operator) resumeBlock() (uint64, error) {
+	lb, ok := operator.enclave.(lastBlocker)
+	if !ok {
+		return operator.params.InitBlock, nil
+	}
+	num, known, err := lb.LastBlock()
+	if err != nil {
+		return 0, fmt.Errorf("retrieving enclave's last block: %w", err)
+	} else if !known || num < operator.params.InitBlock {
+		return operator.params.InitBlock, nil
+	}
+	log.Infof("Operator: Enclave resumed at block %d", num)
+	return num + 1, nil
+}
+
+// catchUp passes all verified blocks starting at block next to the enclave,
+// fetching them in parallel batches. It returns the number of the next block
+// once it reached the verified tip of the chain, or a block that the nodes do
+// not agree on, from which on the operator follows new blocks as they are
+// mined.
+func (operator *Operator) catchUp(next uint64) (uint64, error) {
+	for !operator.IsClosed() {
+		tip, ok, err := operator.EthClient.VerifiedTip()
+		if err != nil {
+			return 0, err
+		} else if !ok || next > tip {
+			return next, nil
+		}
+		end := tip
+		if end-next >= catchUpBatch {
+			end = next + catchUpBatch - 1
+		}
+
+		blocks, err := operator.EthClient.BlockRange(next, end)
+		if err != nil {
+			return 0, fmt.Errorf("fetching blocks: %w", err)
+		} else if len(blocks) == 0 {
+			return next, nil // No quorum, wait for new blocks.
+		}
+		end = blocks[len(blocks)-1].NumberU64()
+		start := time.Now()
+		if err := operator.processBlocks(blocks...); err != nil {
+			return 0, err
+		}
+		operator.metrics.observeBlock(operator.params, end, start)
+		operator.responses.OnBlock(end + operator.params.PowDepth)
+		log.WithFields(log.Fields{"from": next, "to": end, "tip": tip}).
+			Info("Operator: Caught up blocks")
+		next = end + 1
+	}
+	return next, nil
+}
@@ -28,8 +28,24 @@ type Config struct {
 	RespondChallenges      bool
 	SendDepositProofs      bool
 	SendBalanceProofs      bool
-	NodeReqTimeout         uint64 // Node request timeout in seconds.
-	WaitMinedTimeout       uint64 // Transaction mining timeout in seconds.
+	NodeReqTimeout         uint64   // Node request timeout in seconds.
+	WaitMinedTimeout       uint64   // Transaction mining timeout in seconds.
+	EnclaveStateFile       string   // Sealed enclave state file, empty: no persistence.
+	SnapshotInterval       uint64   // Blocks between enclave snapshots, 0: each phase.
+	Consensus              string   // Consensus engine enforced by the enclave: "", "ethash" or "clique".
+	CliqueSigners          []string // Initial clique signers, required for "clique".
+	CliquePeriod           uint64   // Minimal clique block period in seconds.
+	FeeFlat                uint64   // Flat transaction fee in wei.
+	FeeRate                uint64   // Fee of ETH transfers in basis points of the amount.
+	FeeCollector           string   // Account collecting the fees, default: operator account.
+	AttestationKey         string   // Hex key of the mock attestation service, empty: no attestation.
+	ArchiveFile            string   // Proof and transaction archive file, empty: in-memory only.
+	EthereumNodeURLs       []string // Additional Ethereum nodes for failover and quorum reads.
+	NodeQuorum             int      // Number of nodes that must agree on each block, 0: no quorum.
+	MaxFee                 uint64   // Maximal fee per gas in wei, 0: unlimited.
+	PriorityFee            uint64   // Priority fee per gas in wei, 0: suggested by the node.
+	GasPriceBump           uint64   // Gas price in wei added to the node's suggested legacy gas price.
+	GasLimit               uint64   // Fixed transaction gas limit, 0: estimated per call.
 }
 
 // dialTimeout will be used a timeout when dialing to the ethereum node.
@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// metricsNamespace prefixes all metric names.
+const metricsNamespace = "erdstall"
+
+type (
+	// Metrics holds the operator's Prometheus metrics. They are registered at
+	// a registry of their own, which the RPCServer serves at /metrics.
+	Metrics struct {
+		registry *prometheus.Registry
+
+		txsAccepted   *prometheus.CounterVec // type
+		txsRejected   *prometheus.CounterVec // type, reason
+		epoch         prometheus.Gauge
+		block         prometheus.Gauge
+		peers         prometheus.Gauge
+		subscriptions prometheus.Gauge
+		proofs        *prometheus.CounterVec // type
+		epochProofs   *prometheus.GaugeVec   // type
+		challenges    *prometheus.CounterVec // state
+		processTXs    prometheus.Histogram
+		processBlocks prometheus.Histogram
+	}
+
+	// commandQueue is implemented by enclaves that can report the state of
+	// their command queue, like the prototype.Enclave.
+	commandQueue interface {
+		CommandQueue() (depth, capacity int)
+	}
+)
+
+// Label values of the metrics.
+const (
+	txTypeSingle = "tx"
+	txTypeBatch  = "batch"
+
+	rejectNonce     = "nonce"
+	rejectSignature = "signature"
+	rejectBalance   = "balance"
+	rejectFee       = "fee"
+	rejectEpoch     = "epoch"
+	rejectLocked    = "locked"
+	rejectUnknown   = "unknown_sender"
+	rejectStopped   = "enclave_stopped"
+	rejectOther     = "other"
+
+	proofTypeDeposit = "deposit"
+	proofTypeBalance = "balance"
+
+	challengeSeen      = "seen"
+	challengeAnswered  = "answered"
+	challengeAtRisk    = "at_risk"
+	challengeFailed    = "failed"
+	challengeMissed    = "missed"
+	challengeFeeCapped = "fee_capped" // Response fees cannot be bumped further.
+)
+
+// NewMetrics creates and registers all operator metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		txsAccepted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_accepted_total",
+			Help:      "Number of transactions accepted by the enclave.",
+		}, []string{"type"}),
+		txsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_rejected_total",
+			Help:      "Number of transactions rejected by the enclave, by reason.",
+		}, []string{"type", "reason"}),
+		epoch: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "epoch",
+			Help:      "Current deposit epoch.",
+		}),
+		block: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "block",
+			Help:      "Number of the last block processed by the enclave.",
+		}),
+		peers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "peers",
+			Help:      "Number of connected peers.",
+		}),
+		subscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "subscriptions",
+			Help:      "Number of active proof subscriptions.",
+		}),
+		proofs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "proofs_total",
+			Help:      "Number of proofs generated by the enclave.",
+		}, []string{"type"}),
+		epochProofs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "epoch_proofs",
+			Help:      "Number of proofs generated for the last epoch.",
+		}, []string{"type"}),
+		challenges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "challenges_total",
+			Help:      "Number of on-chain challenges that were seen or answered.",
+		}, []string{"state"}),
+		processTXs: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "enclave_process_txs_seconds",
+			Help:      "Latency of processing transactions and batches in the enclave.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}),
+		processBlocks: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "enclave_process_blocks_seconds",
+			Help:      "Latency of processing a block in the enclave.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+	}
+	m.registry.MustRegister(
+		m.txsAccepted, m.txsRejected,
+		m.epoch, m.block,
+		m.peers, m.subscriptions,
+		m.proofs, m.epochProofs,
+		m.challenges,
+		m.processTXs, m.processBlocks,
+	)
+	return m
+}
+
+// Handler returns the HTTP handler that serves the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeEnclave exports the command queue of the enclave, if it reports it.
+func (m *Metrics) observeEnclave(enclave tee.Enclave) {
+	q, ok := enclave.(commandQueue)
+	if !ok {
+		return
+	}
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "enclave_command_queue_depth",
+			Help:      "Number of commands waiting in the enclave's command queue.",
+		}, func() float64 {
+			depth, _ := q.CommandQueue()
+			return float64(depth)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "enclave_command_queue_capacity",
+			Help:      "Capacity of the enclave's command queue.",
+		}, func() float64 {
+			_, capacity := q.CommandQueue()
+			return float64(capacity)
+		}),
+	)
+}
+
+// observeTx records the enclave's verdict on a transaction of the given type
+// and how long it took.
+func (m *Metrics) observeTx(txType string, start time.Time, err error) {
+	m.processTXs.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.txsRejected.WithLabelValues(txType, rejectReason(err)).Inc()
+	} else {
+		m.txsAccepted.WithLabelValues(txType).Inc()
+	}
+}
+
+// observeBlock records the processing of a block.
+func (m *Metrics) observeBlock(params tee.Parameters, num uint64, start time.Time) {
+	m.processBlocks.Observe(time.Since(start).Seconds())
+	m.block.Set(float64(num))
+	m.epoch.Set(float64(params.DepositEpoch(num)))
+}
+
+// observeProofs records the proofs generated for an epoch.
+func (m *Metrics) observeProofs(proofType string, n int) {
+	if n == 0 {
+		return
+	}
+	m.proofs.WithLabelValues(proofType).Add(float64(n))
+	m.epochProofs.WithLabelValues(proofType).Set(float64(n))
+}
+
+// rejectReason classifies a transaction error of the enclave. Remote enclaves
+// only transmit the error message, so the message is classified.
+func rejectReason(err error) string {
+	if errors.Is(err, tee.ErrEnclaveStopped) {
+		return rejectStopped
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "nonce"):
+		return rejectNonce
+	case strings.Contains(msg, "signature"):
+		return rejectSignature
+	case strings.Contains(msg, "insufficient balance"):
+		return rejectBalance
+	case strings.Contains(msg, "fee"):
+		return rejectFee
+	case strings.Contains(msg, "epoch"):
+		return rejectEpoch
+	case strings.Contains(msg, "locked"):
+		return rejectLocked
+	case strings.Contains(msg, "does not exist"):
+		return rejectUnknown
+	case strings.Contains(msg, tee.ErrEnclaveStopped.Error()):
+		return rejectStopped
+	default:
+		return rejectOther
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	op "github.com/perun-network/erdstall/operator"
+	optest "github.com/perun-network/erdstall/operator/test"
+	"github.com/perun-network/erdstall/tee"
+	ttest "github.com/perun-network/erdstall/tee/test"
+)
+
+const metricsTestPort = 8405
+
+func TestMetrics(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	metrics := op.NewMetrics()
+	enclave := optest.NewMockedEnclave()
+	rpcOp := op.NewRPCOperator(enclave, tee.Parameters{}, nil, nil, metrics)
+
+	tx := ttest.RandomTx(t, rng)
+	require.NoError(rpcOp.Send(*tx))
+	enclave.SetProcessTXsError(errors.New("nonce mismatch: 1 != 2"))
+	require.Error(rpcOp.Send(*tx))
+	enclave.SetProcessTXsError(nil)
+
+	rpc := op.NewRPC(rpcOp, op.OpServerConfig{Port: metricsTestPort, Metrics: metrics})
+	go rpc.Serve() // nolint: errcheck
+	defer rpc.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, _, err := gorilla.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/ws", metricsTestPort), nil)
+	require.NoError(err)
+	defer conn.Close()
+	_, _, err = conn.ReadMessage() // Config
+	require.NoError(err)
+
+	scrape := func() string {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", metricsTestPort))
+		require.NoError(err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(err)
+		return string(body)
+	}
+	require.Eventually(func() bool {
+		return strings.Contains(scrape(), "erdstall_peers 1\n")
+	}, time.Second, 10*time.Millisecond)
+
+	body := scrape()
+	require.Contains(body, `erdstall_txs_accepted_total{type="tx"} 1`)
+	require.Contains(body, `erdstall_txs_rejected_total{reason="nonce",type="tx"} 1`)
+	require.Contains(body, "erdstall_enclave_process_txs_seconds_count 2")
+	require.Contains(body, "erdstall_subscriptions 0")
+}
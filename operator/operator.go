@@ -6,8 +6,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	log "github.com/sirupsen/logrus"
 	perrors "perun.network/go-perun/pkg/errors"
@@ -17,6 +21,7 @@ import (
 	"github.com/perun-network/erdstall/contracts/bindings"
 	"github.com/perun-network/erdstall/eth"
 	"github.com/perun-network/erdstall/tee"
+	"github.com/perun-network/erdstall/tee/attestation"
 	"github.com/perun-network/erdstall/tee/prototype"
 	"github.com/perun-network/erdstall/tee/rpc"
 )
@@ -24,15 +29,27 @@ import (
 // Operator resprents a TEE Plasma operator.
 type Operator struct {
 	pkgsync.Closer
-	enclave   tee.Enclave
-	params    tee.Parameters
-	EthClient *eth.Client
-	*depositProofs
-	*balanceProofs
+	enclave     tee.Enclave
+	params      tee.Parameters
+	EthClient   *eth.Client
+	archive     *Archive // Proofs and transactions, see Archive.
+	metrics     *Metrics // Served at the RPC server's /metrics.
+	responses   *responseManager
 	TxReceipts  *txReceipts
 	rpcOperator *RPCOperator
 	contract    *bindings.Erdstall
 	cfg         Config
+	attestation []byte // Enclave attestation, published to clients.
+}
+
+// Archive returns the operator's archive of proofs and transactions.
+func (operator *Operator) Archive() *Archive {
+	return operator.archive
+}
+
+// Metrics returns the operator's metrics.
+func (operator *Operator) Metrics() *Metrics {
+	return operator.metrics
 }
 
 // EnclaveParams returns the enclave parameters.
@@ -52,17 +69,32 @@ func New(
 		return nil, fmt.Errorf("loading contract: %w", err)
 	}
 
+	archive := NewArchive()
+	if cfg.ArchiveFile != "" {
+		if archive, err = OpenArchive(cfg.ArchiveFile); err != nil {
+			return nil, fmt.Errorf("opening archive: %w", err)
+		}
+	}
+
+	metrics := NewMetrics()
 	op := &Operator{
-		enclave:       enclave,
-		params:        params,
-		EthClient:     client,
-		depositProofs: newDepositProofs(),
-		balanceProofs: newBalanceProofs(),
-		TxReceipts:    newTXReceipts(),
-		contract:      _contract,
-		cfg:           cfg,
-	}
-	op.OnClose(func() { close(op.TxReceipts.closed) })
+		enclave:    enclave,
+		params:     params,
+		EthClient:  client,
+		archive:    archive,
+		metrics:    metrics,
+		TxReceipts: newTXReceipts(),
+		contract:   _contract,
+		cfg:        cfg,
+		responses: newResponseManager(params,
+			&ethResponseChain{client: client, contract: _contract}, metrics),
+	}
+	op.OnClose(func() {
+		close(op.TxReceipts.closed)
+		if err := op.archive.Close(); err != nil {
+			log.Errorf("Closing archive: %v", err)
+		}
+	})
 	return op, nil
 }
 
@@ -77,6 +109,19 @@ func SetupWithPrototypeEnclave(cfg *Config) *Operator {
 	log.WithField("enclave", enclaveAccount.Address.Hex()).
 		Debug("Operator.Setup: account loaded")
 	enclave := prototype.NewEnclaveWithAccount(wallet, enclaveAccount)
+	if cfg.EnclaveStateFile != "" {
+		enclave.EnablePersistence(cfg.EnclaveStateFile, cfg.SnapshotInterval)
+		log.WithField("file", cfg.EnclaveStateFile).
+			Info("Operator.Setup: enclave persistence enabled")
+	}
+	if cfg.AttestationKey != "" {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.AttestationKey, "0x"))
+		if err != nil {
+			log.Fatalf("Config: Invalid attestation key: %v", err)
+		}
+		enclave.SetQuoter(attestation.NewMockQuoter(key, attestation.PrototypeMeasurement))
+		log.Info("Operator.Setup: mock attestation enabled")
+	}
 	return Setup(cfg, enclave)
 }
 
@@ -86,9 +131,12 @@ func Setup(cfg *Config, enclave tee.Enclave) *Operator {
 	wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
 	AssertNoError(err)
 
-	enclavePublicKey, _, err := enclave.Init()
+	enclavePublicKey, att, err := enclave.Init()
 	AssertNoError(err)
 	log.Info("Operator.Setup: Enclave created")
+	if len(att) == 0 {
+		log.Warn("Operator.Setup: Enclave is not attested, clients will refuse to deposit")
+	}
 
 	operatorAccountDerivationPath := hdwallet.MustParseDerivationPath(cfg.OperatorDerivationPath)
 	operatorAccount, err := wallet.Derive(operatorAccountDerivationPath, true)
@@ -99,13 +147,41 @@ func Setup(cfg *Config, enclave tee.Enclave) *Operator {
 
 	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
-	client, err := eth.CreateEthereumClient(ctx, cfg.EthereumNodeURL, wallet, operatorAccount)
+	nodeURLs := append([]string{cfg.EthereumNodeURL}, cfg.EthereumNodeURLs...)
+	if cfg.NodeQuorum > len(nodeURLs) {
+		log.Fatalf("Config: Node quorum %d exceeds the number of nodes %d", cfg.NodeQuorum, len(nodeURLs))
+	}
+	client, err := eth.CreateEthereumClientForNodes(ctx, nodeURLs, cfg.NodeQuorum, wallet, operatorAccount)
 	AssertNoError(err)
+	client.SetFeePolicy(eth.FeePolicy{MaxFee: cfg.MaxFee, PriorityFee: cfg.PriorityFee, GasPriceBump: cfg.GasPriceBump, GasLimit: cfg.GasLimit})
 	// Skip retrieving other receipts as they're currently not checked in the
 	// prototype enclave...
 	client.OnlyErdstallReceipts()
 	log.Info("Operator.Setup: Ethereum client initialized")
 
+	feeCollector := operatorAccount.Address
+	if cfg.FeeCollector != "" {
+		if !common.IsHexAddress(cfg.FeeCollector) {
+			log.Fatalf("Config: No hex address: %s", cfg.FeeCollector)
+		}
+		feeCollector = common.HexToAddress(cfg.FeeCollector)
+	}
+	// The enclave enforces the consensus rules of these parameters.
+	consensus := tee.Parameters{
+		Consensus:     tee.ConsensusEngine(cfg.Consensus),
+		CliqueSigners: make([]common.Address, len(cfg.CliqueSigners)),
+		CliquePeriod:  cfg.CliquePeriod,
+	}
+	for i, s := range cfg.CliqueSigners {
+		if !common.IsHexAddress(s) {
+			log.Fatalf("Config: No hex address: %s", s)
+		}
+		consensus.CliqueSigners[i] = common.HexToAddress(s)
+	}
+	chainID, err := eth.ChainID(ctx, client.ContractInterface)
+	AssertNoError(err)
+	consensus.ChainID = chainID.Uint64()
+
 	var params tee.Parameters
 	if cfg.ContractAddr != "" {
 		if !common.IsHexAddress(cfg.ContractAddr) {
@@ -117,6 +193,11 @@ func Setup(cfg *Config, enclave tee.Enclave) *Operator {
 		defer cancel()
 		p, _, err := client.BindContract(ctx, contract)
 		AssertNoError(err)
+		p.PowDepth = cfg.PowDepth // The PoW depth is not stored in the contract.
+		p.Consensus, p.ChainID = consensus.Consensus, consensus.ChainID
+		p.CliqueSigners, p.CliquePeriod = consensus.CliqueSigners, consensus.CliquePeriod
+		// Fees are not stored in the contract either.
+		p.FeeFlat, p.FeeRate, p.FeeCollector = cfg.FeeFlat, cfg.FeeRate, feeCollector
 		params = *p
 	} else {
 		log.Infof("Operator.Setup: Deploying contract...")
@@ -125,6 +206,13 @@ func Setup(cfg *Config, enclave tee.Enclave) *Operator {
 			PhaseDuration:    cfg.PhaseDuration,
 			ResponseDuration: cfg.ResponseDuration,
 			PowDepth:         cfg.PowDepth,
+			Consensus:        consensus.Consensus,
+			ChainID:          consensus.ChainID,
+			CliqueSigners:    consensus.CliqueSigners,
+			CliquePeriod:     consensus.CliquePeriod,
+			FeeFlat:          cfg.FeeFlat,
+			FeeRate:          cfg.FeeRate,
+			FeeCollector:     feeCollector,
 		}
 		err = client.DeployContracts(&params)
 		AssertNoError(err)
@@ -139,6 +227,7 @@ func Setup(cfg *Config, enclave tee.Enclave) *Operator {
 
 	operator, err := New(enclave, params, client, *cfg)
 	AssertNoError(err)
+	operator.attestation = att
 
 	return operator
 }
@@ -180,7 +269,8 @@ func (operator *Operator) Serve(port uint16) error {
 	})
 	log.Info("Operator.Serve: Enclave running")
 
-	operator.rpcOperator = NewRPCOperator(operator.enclave, operator.TxReceipts)
+	operator.metrics.observeEnclave(operator.enclave)
+	operator.rpcOperator = NewRPCOperator(operator.enclave, operator.params, operator.TxReceipts, operator.archive, operator.metrics)
 
 	var netIDStr string
 	if netID, err := operator.EthClient.NetworkID(); err != nil {
@@ -190,9 +280,15 @@ func (operator *Operator) Serve(port uint16) error {
 	}
 
 	clientConfig := config.OpClientConfig{
-		Contract:  operator.params.Contract,
-		NetworkID: netIDStr,
-		POWDepth:  operator.params.PowDepth,
+		Contract:    operator.params.Contract,
+		NetworkID:   netIDStr,
+		POWDepth:    operator.params.PowDepth,
+		Attestation: operator.attestation,
+		Fees: config.Fees{
+			Flat:      operator.params.FeeFlat,
+			Rate:      operator.params.FeeRate,
+			Collector: operator.params.FeeCollector,
+		},
 	}
 	osc := OpServerConfig{
 		Host:         "0.0.0.0",
@@ -200,6 +296,7 @@ func (operator *Operator) Serve(port uint16) error {
 		CertFilePath: operator.cfg.CertFile,
 		KeyFilePath:  operator.cfg.KeyFile,
 		ClientConfig: clientConfig,
+		Metrics:      operator.metrics,
 	}
 
 	// Handle RPC
@@ -231,8 +328,35 @@ func (operator *Operator) Serve(port uint16) error {
 	return errg.Wait()
 }
 
+// checkTransactions logs the operator's in-flight transactions that were
+// dropped by the node. Their nonces are reused by the next transactions.
+func (operator *Operator) checkTransactions() {
+	if len(operator.EthClient.InFlightTransactions()) == 0 {
+		return
+	}
+	ctx, cancel := eth.ContextNodeReq()
+	defer cancel()
+	dropped, err := operator.EthClient.CheckTransactions(ctx)
+	if err != nil {
+		log.Warnf("Operator: checking transactions: %v", err)
+	}
+	for _, tx := range dropped {
+		log.WithFields(log.Fields{"nonce": tx.Nonce(), "tx": tx.Hash().Hex()}).
+			Warn("Operator: transaction dropped by node, reusing its nonce")
+	}
+}
+
 func (operator *Operator) handleBlocks() error {
-	blockSub, err := operator.EthClient.SubscribeVerifiedBlocksFrom(operator.params.InitBlock)
+	start, err := operator.resumeBlock()
+	if err != nil {
+		return err
+	}
+	if start, err = operator.catchUp(start); err != nil {
+		return fmt.Errorf("catching up: %w", err)
+	}
+	log.Infof("Operator.Serve: following new blocks from block %d", start)
+
+	blockSub, err := operator.EthClient.SubscribeVerifiedBlocksFrom(start)
 	if err != nil {
 		return fmt.Errorf("creating block subscription: %w", err)
 	}
@@ -241,17 +365,37 @@ func (operator *Operator) handleBlocks() error {
 		select {
 		case b := <-blockSub.Blocks():
 			log.Infof("Operator.Serve: incoming block %d", b.NumberU64())
-			if err := operator.enclave.ProcessBlocks(b); err != nil {
+			start := time.Now()
+			if err := operator.processBlocks(b); err != nil {
 				//TODO check for ErrEnclaveStopped error, see enclave internal tests
 				return err
 			}
+			operator.metrics.observeBlock(operator.params, b.NumberU64(), start)
 			log.Debugf("Operator.Serve: processed block %d", b.NumberU64())
+			// Verified blocks lag behind the head by the PoW depth.
+			operator.responses.OnBlock(b.NumberU64() + operator.params.PowDepth)
+			operator.checkTransactions()
+		case n := <-blockSub.Reverts():
+			// The enclave reverts by itself once it receives the next block of
+			// the new canonical branch.
+			log.Warnf("Operator.Serve: chain reorg, reverting to block %d", n)
 		case <-operator.Closed():
 			return nil
 		}
 	}
 }
 
+// processBlocks passes blocks to the enclave. If a reorg dropped events of a
+// sealed phase, the enclave cannot follow the chain anymore, which is logged
+// separately as the operator has to intervene.
+func (operator *Operator) processBlocks(blocks ...*tee.Block) error {
+	err := operator.enclave.ProcessBlocks(blocks...)
+	if errors.Is(err, tee.ErrSealedEventsDropped) {
+		log.WithError(err).Error("Operator: enclave halted, the chain dropped events of a sealed phase")
+	}
+	return err
+}
+
 func (operator *Operator) handleChallenges() error {
 	challenges := make(chan *bindings.ErdstallChallenged)
 	sub, err := operator.contract.WatchChallenged(nil, challenges, nil, nil)
@@ -259,22 +403,34 @@ func (operator *Operator) handleChallenges() error {
 		return fmt.Errorf("creating challenge subcription: %w", err)
 	}
 	defer sub.Unsubscribe()
+	tokenChallenges := make(chan *bindings.ErdstallTokenChallenged)
+	tokenSub, err := operator.contract.WatchTokenChallenged(nil, tokenChallenges, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("creating token challenge subcription: %w", err)
+	}
+	defer tokenSub.Unsubscribe()
 
 	for {
+		var c challengedEvent
 		select {
 		case err := <-sub.Err():
 			return fmt.Errorf("subscription error: %w", err)
+		case err := <-tokenSub.Err():
+			return fmt.Errorf("token subscription error: %w", err)
 
 		case _c := <-challenges:
-			c := challengedEvent(*_c)
-			log.Warnf("Operator.handleChallenges: Incoming challenge %v", c)
-
-			if err := operator.handleChallengedEvent(c); err != nil {
-				log.Errorf("Operator.handleChallenges: Failed to handle challenged event %v: %v", c, err)
-			}
+			c = challengedEvent{Epoch: _c.Epoch, Account: _c.Account, Token: tee.ETHToken, Raw: _c.Raw}
+		case _c := <-tokenChallenges:
+			c = challengedEvent{Epoch: _c.Epoch, Account: _c.Account, Token: _c.Token, Raw: _c.Raw}
 		case <-operator.Closed():
 			return nil
 		}
+
+		log.Warnf("Operator.handleChallenges: Incoming challenge %v", c)
+		operator.metrics.challenges.WithLabelValues(challengeSeen).Inc()
+		if err := operator.handleChallengedEvent(c); err != nil {
+			log.Errorf("Operator.handleChallenges: Failed to handle challenged event %v: %v", c, err)
+		}
 	}
 }
 
@@ -284,37 +440,15 @@ func (operator *Operator) handleChallengedEvent(c challengedEvent) error {
 		return nil
 	}
 
-	balanceProof, ok := operator.balanceProofs.Get(c.Account)
+	// The response needs the balance proof of the challenged token in the
+	// exit epoch.
+	balanceProof, ok := operator.archive.BalanceProof(c.Epoch, c.Account, c.Token)
 	if !ok {
-		return errors.New("getting balance proof")
+		return fmt.Errorf("no balance proof for epoch %d", c.Epoch)
 	}
 
-	ctx, cancel := eth.ContextNodeReq()
-	defer cancel()
-
-	tr, err := operator.EthClient.NewTransactor(ctx)
-	if err != nil {
-		return fmt.Errorf("creating transactor: %w", err)
-	}
-
-	tx, err := operator.contract.Exit(tr, balanceProof.Balance.ToEthBal(), balanceProof.Sig)
-	if err != nil {
-		return fmt.Errorf("sending challenge response: %w", err)
-	}
-
-	// Track challenge response transaction status
-	go func() {
-		ctx, cancel := eth.ContextWaitMined()
-		defer cancel()
-
-		if _, err := operator.EthClient.ConfirmTransaction(ctx, tx, operator.EthClient.Account()); err != nil {
-			log.Errorf("Operator.handleChallengedEvent: Failed to wait for mining of response to challenge %v: %v", c, err)
-			return
-		}
-
-		log.Infof("Operator.handleChallengedEvent: Resolved dispute for challenge %v", c)
-	}()
-
+	// The response manager sends the response and tracks it until it landed.
+	operator.responses.Add(*balanceProof, c.Raw.BlockNumber)
 	return nil
 }
 
@@ -332,7 +466,10 @@ func (operator *Operator) handleDepositProofs() error {
 		if len(dps) > 0 {
 			log.Debugf("Operator.Serve: Retrieved %d deposit proofs", len(dps))
 		}
-		operator.depositProofs.AddAll(dps)
+		operator.metrics.observeProofs(proofTypeDeposit, len(dps))
+		if err := operator.archive.AddDepositProofs(dps); err != nil {
+			log.Errorf("Operator.Serve: Archiving deposit proofs: %v", err)
+		}
 		for _, dp := range dps {
 			operator.rpcOperator.PushDepositProof(*dp)
 		}
@@ -353,7 +490,10 @@ func (operator *Operator) handleBalanceProofs() error {
 		if len(bps) > 0 {
 			log.Debugf("Operator.Serve: Retrieved %d balance proofs", len(bps))
 		}
-		operator.balanceProofs.AddAll(bps)
+		operator.metrics.observeProofs(proofTypeBalance, len(bps))
+		if err := operator.archive.AddBalanceProofs(bps); err != nil {
+			log.Errorf("Operator.Serve: Archiving balance proofs: %v", err)
+		}
 		for _, bp := range bps {
 			operator.rpcOperator.PushBalanceProof(*bp)
 		}
@@ -367,9 +507,19 @@ func AssertNoError(err error) {
 	}
 }
 
-// challengedEvent provides formatting for bindings.ErdstallChallenged.
-type challengedEvent bindings.ErdstallChallenged
+// challengedEvent is a bindings.ErdstallChallenged or
+// bindings.ErdstallTokenChallenged event. Token is tee.ETHToken for ETH
+// challenges.
+type challengedEvent struct {
+	Epoch   uint64
+	Account common.Address
+	Token   common.Address
+	Raw     types.Log
+}
 
 func (c challengedEvent) String() string {
-	return fmt.Sprintf("ChallengedEvent{Account: %v, Epoch: %v}", c.Account.String(), c.Epoch)
+	if c.Token == tee.ETHToken {
+		return fmt.Sprintf("ChallengedEvent{Account: %v, Epoch: %v}", c.Account.String(), c.Epoch)
+	}
+	return fmt.Sprintf("ChallengedEvent{Account: %v, Epoch: %v, Token: %v}", c.Account.String(), c.Epoch, c.Token.String())
 }
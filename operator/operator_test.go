@@ -247,5 +247,21 @@ func newDefaultConfig() *op.Config {
 		true,
 		0,
 		0,
+		"",
+		0,
+		"",
+		nil,
+		0,
+		0,
+		0,
+		"",
+		"",
+		"",
+		nil,
+		0,
+		0,
+		0,
+		0,
+		0,
 	}
 }
@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/perun-network/erdstall/contracts/bindings"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+const (
+	// responseBumpBlocks is the number of blocks after which an unmined
+	// challenge response is resubmitted with higher fees.
+	responseBumpBlocks = 2
+	// responseGasBumpPercent is the fee increase of a resubmission. It must be
+	// at least 10% for nodes to accept the replacement.
+	responseGasBumpPercent = 25
+	// responseMaxGasFactor caps the fee cap of resubmissions at this factor of
+	// the first response's fee cap. The fee policy's max fee applies too. Once
+	// a resubmission would not exceed the latest one by the nodes' replacement
+	// bump, the response is no longer resubmitted.
+	responseMaxGasFactor = 10
+)
+
+type (
+	// responseManager tracks all open challenges until the operator's
+	// response landed on-chain. Responses that are not mined in time are
+	// resubmitted with the same nonce and increased fees. It alerts
+	// when a challenge is still open in the challenge response phase, which
+	// precedes the response deadline.
+	responseManager struct {
+		params  tee.Parameters
+		chain   responseChain
+		metrics *Metrics
+
+		mtx  sync.Mutex // protects open.
+		open map[challengeKey]*openChallenge
+	}
+
+	// responseChain sends challenge responses and checks whether they landed.
+	responseChain interface {
+		// SendResponse sends the balance proof as challenge response. If nonce
+		// or fees are nil, the next nonce and the fees of the fee policy are
+		// used.
+		SendResponse(ctx context.Context, proof tee.BalanceProof, nonce *big.Int, fees *responseFees) (*types.Transaction, error)
+		// ResponseStatus returns the status of a sent response to the
+		// challenge.
+		ResponseStatus(ctx context.Context, tx common.Hash, c challengeKey) (responseStatus, error)
+		// MaxFee returns the maximal fee per gas of the fee policy, nil if
+		// unlimited.
+		MaxFee() *big.Int
+	}
+
+	// responseFees are the fees of a resubmitted response. For legacy
+	// transactions, the gas price is feeCap.
+	responseFees struct {
+		feeCap, tip *big.Int
+	}
+
+	// responseStatus is the on-chain status of a response transaction.
+	responseStatus int
+
+	challengeKey struct {
+		epoch   tee.Epoch
+		account common.Address
+		token   common.Address // tee.ETHToken for ETH challenges
+	}
+
+	// openChallenge is a challenge whose response did not land yet.
+	openChallenge struct {
+		proof    tee.BalanceProof
+		deadline uint64               // First block in which responses fail.
+		txs      []*types.Transaction // All submissions, the last is the latest.
+		sentAt   uint64               // Block of the latest submission.
+		maxFee   *big.Int             // Fee cap of resubmissions.
+		capped   bool                 // Whether the fees cannot be bumped further.
+		alerted  bool                 // Whether the deadline alert was raised.
+	}
+
+	// ethResponseChain is the responseChain of the operator's Ethereum client.
+	ethResponseChain struct {
+		client   *eth.Client
+		contract *bindings.Erdstall
+	}
+)
+
+const (
+	responsePending responseStatus = iota // Not mined yet.
+	responseLanded                        // Mined and emitted the (Token)Exiting event.
+	responseFailed                        // Mined, but reverted or without event.
+)
+
+var _ responseChain = (*ethResponseChain)(nil)
+
+func newResponseManager(params tee.Parameters, chain responseChain, metrics *Metrics) *responseManager {
+	return &responseManager{
+		params:  params,
+		chain:   chain,
+		metrics: metrics,
+		open:    make(map[challengeKey]*openChallenge),
+	}
+}
+
+func (k challengeKey) String() string {
+	if k.token == tee.ETHToken {
+		return fmt.Sprintf("Challenge{Account: %s, Epoch: %d}", k.account.Hex(), k.epoch)
+	}
+	return fmt.Sprintf("Challenge{Account: %s, Epoch: %d, Token: %s}", k.account.Hex(), k.epoch, k.token.Hex())
+}
+
+// Add starts tracking the challenge of the proof's account, epoch and token
+// and sends the first response. block is the current block. If sending fails,
+// it is retried on the next block.
+func (m *responseManager) Add(proof tee.BalanceProof, block uint64) {
+	k := challengeKey{proof.Balance.Epoch, proof.Balance.Account, proof.Balance.Token}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, ok := m.open[k]; ok {
+		log.Warnf("responseManager: %v already open", k)
+		return
+	}
+	c := &openChallenge{
+		proof:    proof,
+		deadline: m.params.ExitDoneBlock(uint64(k.epoch)),
+	}
+	m.open[k] = c
+	m.submit(k, c, block)
+}
+
+// OnBlock checks all open challenges at the given block. Landed responses are
+// closed, stuck ones resubmitted, and challenges whose deadline is at risk or
+// passed are reported.
+func (m *responseManager) OnBlock(block uint64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for k, c := range m.open {
+		if m.check(k, c, block) {
+			delete(m.open, k)
+		}
+	}
+}
+
+// Open returns the number of open challenges.
+func (m *responseManager) Open() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return len(m.open)
+}
+
+// check checks the challenge at the given block and returns whether it is
+// closed. m.mtx must be held.
+func (m *responseManager) check(k challengeKey, c *openChallenge, block uint64) (closed bool) {
+	for _, tx := range c.txs {
+		ctx, cancel := eth.ContextNodeReq()
+		status, err := m.chain.ResponseStatus(ctx, tx.Hash(), k)
+		cancel()
+		if err != nil {
+			log.Warnf("responseManager: Checking response %s to %v: %v", tx.Hash().Hex(), k, err)
+			continue
+		}
+		switch status {
+		case responseLanded:
+			log.Infof("responseManager: Resolved dispute for %v with tx %s", k, tx.Hash().Hex())
+			m.metrics.challenges.WithLabelValues(challengeAnswered).Inc()
+			return true
+		case responseFailed:
+			log.Errorf("responseManager: Response %s to %v failed", tx.Hash().Hex(), k)
+			m.metrics.challenges.WithLabelValues(challengeFailed).Inc()
+			return true
+		}
+	}
+
+	if block >= c.deadline {
+		log.Errorf("responseManager: Missed response deadline of %v at block %d, the contract freezes", k, c.deadline)
+		m.metrics.challenges.WithLabelValues(challengeMissed).Inc()
+		return true
+	}
+	if !c.alerted && block+m.params.ResponseDuration >= c.deadline {
+		c.alerted = true
+		log.Errorf("responseManager: Response deadline of %v at risk: %d blocks left, %d submissions", k, c.deadline-block, len(c.txs))
+		m.metrics.challenges.WithLabelValues(challengeAtRisk).Inc()
+	}
+	if len(c.txs) == 0 || (!c.capped && block >= c.sentAt+responseBumpBlocks) {
+		m.submit(k, c, block)
+	}
+	return false
+}
+
+// submit sends the first response or resubmits the latest one with the same
+// nonce and increased fees. Both the fee cap and the tip are bumped, as
+// required by nodes for replacements. m.mtx must be held.
+func (m *responseManager) submit(k challengeKey, c *openChallenge, block uint64) {
+	var nonce *big.Int
+	var fees *responseFees
+	if len(c.txs) > 0 {
+		last := c.txs[len(c.txs)-1]
+		nonce = new(big.Int).SetUint64(last.Nonce())
+		fees = &responseFees{feeCap: bumpResponseFee(last.GasFeeCap()), tip: bumpResponseFee(last.GasTipCap())}
+		if fees.feeCap.Cmp(c.maxFee) > 0 {
+			fees.feeCap.Set(c.maxFee)
+		}
+		if fees.tip.Cmp(fees.feeCap) > 0 {
+			fees.tip.Set(fees.feeCap)
+		}
+		// Nodes would reject a replacement at the same or a slightly higher
+		// price.
+		if !isReplacementFee(fees.feeCap, last.GasFeeCap()) || !isReplacementFee(fees.tip, last.GasTipCap()) {
+			m.setCapped(k, c)
+			return
+		}
+	}
+
+	ctx, cancel := eth.ContextNodeReq()
+	defer cancel()
+	tx, err := m.chain.SendResponse(ctx, c.proof, nonce, fees)
+	if err != nil {
+		// The previous submission might have been mined in the meantime.
+		log.Warnf("responseManager: Sending response to %v: %v", k, err)
+		return
+	}
+	if len(c.txs) == 0 {
+		c.maxFee = new(big.Int).Mul(tx.GasFeeCap(), big.NewInt(responseMaxGasFactor))
+		if limit := m.chain.MaxFee(); limit != nil && c.maxFee.Cmp(limit) > 0 {
+			c.maxFee.Set(limit)
+		}
+	}
+	log.WithFields(log.Fields{"tx": tx.Hash().Hex(), "nonce": tx.Nonce(), "feeCap": tx.GasFeeCap(), "tip": tx.GasTipCap()}).
+		Infof("responseManager: Sent response to %v", k)
+	c.txs = append(c.txs, tx)
+	c.sentAt = block
+	if tx.GasFeeCap().Cmp(c.maxFee) >= 0 {
+		m.setCapped(k, c)
+	}
+}
+
+// setCapped stops resubmitting the response to the challenge, whose fees
+// reached the cap. It is counted in the challenges metric with state
+// fee_capped. m.mtx must be held.
+func (m *responseManager) setCapped(k challengeKey, c *openChallenge) {
+	c.capped = true
+	log.Warnf("responseManager: Response to %v reached fee cap %v, not resubmitting it anymore", k, c.maxFee)
+	m.metrics.challenges.WithLabelValues(challengeFeeCapped).Inc()
+}
+
+// isReplacementFee returns whether fee is high enough for nodes to accept a
+// replacement of a transaction with fee last.
+func isReplacementFee(fee, last *big.Int) bool {
+	min := new(big.Int).Mul(last, big.NewInt(100+eth.ReplacementPriceBump))
+	return new(big.Int).Mul(fee, big.NewInt(100)).Cmp(min) >= 0
+}
+
+// bumpResponseFee returns the fee increased by responseGasBumpPercent.
+func bumpResponseFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+responseGasBumpPercent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func (e *ethResponseChain) SendResponse(ctx context.Context, proof tee.BalanceProof, nonce *big.Int, fees *responseFees) (*types.Transaction, error) {
+	tr, err := e.client.NewTransactor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating transactor: %w", err)
+	}
+	tr.Nonce = nonce
+	if fees != nil && tr.GasPrice != nil {
+		tr.GasPrice = fees.feeCap // Otherwise set by the fee policy.
+	} else if fees != nil {
+		tr.GasFeeCap, tr.GasTipCap = fees.feeCap, fees.tip
+	}
+	if proof.Balance.Token == tee.ETHToken {
+		return e.contract.Exit(tr, proof.Balance.ToEthBal(), proof.Sig)
+	}
+	return e.contract.ExitToken(tr, proof.Balance.ToEthTokenBal(), proof.Sig)
+}
+
+func (e *ethResponseChain) MaxFee() *big.Int {
+	return e.client.MaxFee()
+}
+
+func (e *ethResponseChain) ResponseStatus(ctx context.Context, tx common.Hash, c challengeKey) (responseStatus, error) {
+	receipt, err := e.client.TransactionReceipt(ctx, tx)
+	if errors.Is(err, ethereum.NotFound) {
+		return responsePending, nil
+	} else if err != nil {
+		return responsePending, fmt.Errorf("retrieving receipt: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return responseFailed, nil
+	}
+	for _, l := range receipt.Logs {
+		if c.token == tee.ETHToken {
+			ev, err := e.contract.ParseExiting(*l)
+			if err != nil {
+				continue // Not an Exiting event.
+			}
+			if ev.Epoch == uint64(c.epoch) && ev.Account == c.account {
+				return responseLanded, nil
+			}
+			continue
+		}
+		ev, err := e.contract.ParseTokenExiting(*l)
+		if err != nil {
+			continue // Not a TokenExiting event.
+		}
+		if ev.Epoch == uint64(c.epoch) && ev.Account == c.account && ev.Token == c.token {
+			return responseLanded, nil
+		}
+	}
+	return responseFailed, nil
+}
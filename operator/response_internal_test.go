@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/tee"
+	ttest "github.com/perun-network/erdstall/tee/test"
+)
+
+func TestResponseManager(t *testing.T) {
+	rng := ptest.Prng(t)
+	params := tee.Parameters{InitBlock: 100, PhaseDuration: 10, ResponseDuration: 3}
+	newProof := func() tee.BalanceProof {
+		bp := ttest.RandomBP(rng)
+		bp.Balance.Epoch = 2
+		return *bp
+	}
+	deadline := params.ExitDoneBlock(2) // 150
+	counter := func(m *Metrics, state string) float64 {
+		return testutil.ToFloat64(m.challenges.WithLabelValues(state))
+	}
+
+	t.Run("bump-and-land", func(t *testing.T) {
+		require := require.New(t)
+		chain, metrics := newFakeResponseChain(), NewMetrics()
+		m := newResponseManager(params, chain, metrics)
+		proof := newProof()
+
+		m.Add(proof, 130)
+		require.Len(chain.sent, 1)
+		m.OnBlock(131)
+		require.Len(chain.sent, 1, "no bump before responseBumpBlocks")
+		m.OnBlock(132)
+		require.Len(chain.sent, 2)
+		first, second := chain.sent[0], chain.sent[1]
+		require.Equal(first.Nonce(), second.Nonce())
+		require.Zero(second.GasPrice().Cmp(big.NewInt(125)))
+
+		chain.setStatus(first.Hash(), responseLanded) // Older submission landed.
+		m.OnBlock(133)
+		require.Zero(m.Open())
+		require.Len(chain.sent, 2)
+		require.Equal(1.0, counter(metrics, challengeAnswered))
+	})
+
+	t.Run("gas-cap", func(t *testing.T) {
+		require := require.New(t)
+		chain, metrics := newFakeResponseChain(), NewMetrics()
+		m := newResponseManager(params, chain, metrics)
+		m.Add(newProof(), 100)
+		for b := uint64(102); b < 140; b += responseBumpBlocks {
+			m.OnBlock(b)
+		}
+		// The 11th submission at 921 can only be replaced above the cap of
+		// 1000, so it is the last one.
+		require.Len(chain.sent, 11, "stops bumping at the cap")
+		last := chain.sent[len(chain.sent)-1]
+		require.True(last.GasPrice().Cmp(big.NewInt(100*responseMaxGasFactor)) < 0)
+		require.Equal(1.0, counter(metrics, challengeFeeCapped))
+		require.Equal(1, m.Open(), "still waiting for the response to land")
+	})
+
+	t.Run("fee-policy-cap", func(t *testing.T) {
+		require := require.New(t)
+		chain, metrics := newFakeResponseChain(), NewMetrics()
+		chain.maxFee = big.NewInt(180)
+		m := newResponseManager(params, chain, metrics)
+		m.Add(newProof(), 100)
+		for b := uint64(102); b < 140; b += responseBumpBlocks {
+			m.OnBlock(b)
+		}
+		require.Len(chain.sent, 4, "100, 125, 156, capped bump to 180")
+		last := chain.sent[len(chain.sent)-1]
+		require.Zero(last.GasPrice().Cmp(big.NewInt(180)))
+		require.Equal(1.0, counter(metrics, challengeFeeCapped), "capped once")
+	})
+
+	t.Run("dynamic-fee", func(t *testing.T) {
+		require := require.New(t)
+		chain := newFakeResponseChain()
+		chain.dynamic = true
+		m := newResponseManager(params, chain, NewMetrics())
+		m.Add(newProof(), 130)
+		m.OnBlock(132)
+		require.Len(chain.sent, 2)
+		second := chain.sent[1]
+		require.Equal(uint8(types.DynamicFeeTxType), second.Type())
+		require.Zero(second.GasFeeCap().Cmp(big.NewInt(125)))
+		require.Zero(second.GasTipCap().Cmp(big.NewInt(12)), "tip bumped too")
+	})
+
+	t.Run("send-error", func(t *testing.T) {
+		require := require.New(t)
+		chain := newFakeResponseChain()
+		chain.sendErr = errors.New("node down")
+		m := newResponseManager(params, chain, NewMetrics())
+		m.Add(newProof(), 130)
+		require.Empty(chain.sent)
+		require.Equal(1, m.Open())
+
+		chain.sendErr = nil
+		m.OnBlock(131)
+		require.Len(chain.sent, 1, "retried on next block")
+	})
+
+	t.Run("at-risk-and-missed", func(t *testing.T) {
+		require := require.New(t)
+		chain, metrics := newFakeResponseChain(), NewMetrics()
+		m := newResponseManager(params, chain, metrics)
+		m.Add(newProof(), 140)
+
+		m.OnBlock(deadline - params.ResponseDuration - 1)
+		require.Zero(counter(metrics, challengeAtRisk))
+		m.OnBlock(deadline - params.ResponseDuration)
+		m.OnBlock(deadline - 1)
+		require.Equal(1.0, counter(metrics, challengeAtRisk), "alerts once")
+		require.Equal(1, m.Open())
+
+		m.OnBlock(deadline)
+		require.Zero(m.Open())
+		require.Equal(1.0, counter(metrics, challengeMissed))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		require := require.New(t)
+		chain, metrics := newFakeResponseChain(), NewMetrics()
+		m := newResponseManager(params, chain, metrics)
+		m.Add(newProof(), 130)
+		chain.setStatus(chain.sent[0].Hash(), responseFailed)
+		m.OnBlock(131)
+		require.Zero(m.Open())
+		require.Equal(1.0, counter(metrics, challengeFailed))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		chain := newFakeResponseChain()
+		m := newResponseManager(params, chain, NewMetrics())
+		proof := newProof()
+		m.Add(proof, 130)
+		m.Add(proof, 130)
+		require.Len(t, chain.sent, 1)
+		require.Equal(t, 1, m.Open())
+	})
+
+	t.Run("token", func(t *testing.T) {
+		chain := newFakeResponseChain()
+		m := newResponseManager(params, chain, NewMetrics())
+		proof := newProof()
+		proof.Balance.Token = tee.ETHToken
+		tokenProof := proof
+		tokenProof.Balance.Token = common.Address{1}
+		m.Add(proof, 130)
+		m.Add(tokenProof, 130)
+		require.Len(t, chain.sent, 2, "token challenges are tracked separately")
+		require.Equal(t, 2, m.Open())
+	})
+}
+
+// fakeResponseChain records sent responses. Their status is set manually and
+// defaults to pending.
+type fakeResponseChain struct {
+	mtx     sync.Mutex
+	nonce   uint64
+	sent    []*types.Transaction
+	status  map[common.Hash]responseStatus
+	sendErr error
+
+	maxFee  *big.Int
+	dynamic bool // Whether to send dynamic-fee instead of legacy transactions.
+}
+
+func newFakeResponseChain() *fakeResponseChain {
+	return &fakeResponseChain{status: make(map[common.Hash]responseStatus)}
+}
+
+func (c *fakeResponseChain) SendResponse(_ context.Context, proof tee.BalanceProof, nonce *big.Int, fees *responseFees) (*types.Transaction, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.sendErr != nil {
+		return nil, c.sendErr
+	}
+	n := c.nonce
+	if nonce != nil {
+		n = nonce.Uint64()
+	} else {
+		c.nonce++
+	}
+	if fees == nil {
+		fees = &responseFees{feeCap: big.NewInt(100), tip: big.NewInt(10)}
+	}
+	tx := types.NewTransaction(n, proof.Balance.Account, new(big.Int), 0, fees.feeCap, proof.Sig)
+	if c.dynamic {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			Nonce:     n,
+			GasTipCap: fees.tip,
+			GasFeeCap: fees.feeCap,
+			To:        &proof.Balance.Account,
+			Value:     new(big.Int),
+			Data:      proof.Sig,
+		})
+	}
+	c.sent = append(c.sent, tx)
+	return tx, nil
+}
+
+func (c *fakeResponseChain) ResponseStatus(_ context.Context, tx common.Hash, _ challengeKey) (responseStatus, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.status[tx], nil
+}
+
+func (c *fakeResponseChain) MaxFee() *big.Int {
+	return c.maxFee
+}
+
+func (c *fakeResponseChain) setStatus(tx common.Hash, s responseStatus) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.status[tx] = s
+}
@@ -4,6 +4,7 @@ package operator
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	pkgsync "perun.network/go-perun/pkg/sync"
 
 	"github.com/perun-network/erdstall/config"
+	"github.com/perun-network/erdstall/tee"
 	"github.com/perun-network/erdstall/wire"
 )
 
@@ -24,8 +26,9 @@ type (
 	// RPCServer handels RPC requests and forwards them to the enclave.
 	RPCServer struct {
 		pkgsync.Closer
-		op     WireAPI
-		server *opServer
+		op      WireAPI
+		server  *opServer
+		metrics *Metrics
 	}
 
 	// opServer is an implementation detail and wraps a `http.Server` and holds
@@ -48,17 +51,24 @@ type (
 		CertFilePath string
 		KeyFilePath  string
 		ClientConfig config.OpClientConfig
+		Metrics      *Metrics // Served at /metrics if not nil.
 	}
 
 	// Peer is a connected client.
 	Peer struct {
 		pkgsync.Closer
-		op WireAPI
+		op       WireAPI
+		contract common.Address // signed over in subscribe requests.
+		metrics  *Metrics
 
 		connMtx sync.Mutex // protects conn.
 		conn    *gorilla.Conn
 
-		sub *ClientSub
+		// challenge is the issued, not yet used subscription challenge. It is
+		// only accessed by the readMessages routine.
+		challenge *common.Hash
+		sub       *ClientSub
+		subWho    common.Address // Authenticated account of sub.
 	}
 )
 
@@ -93,10 +103,16 @@ func (s *opServer) Shutdown(ctx context.Context) error {
 func NewRPC(op WireAPI, osc OpServerConfig) *RPCServer {
 	server := newOpServer(osc)
 	rpc := &RPCServer{
-		op:     op,
-		server: server,
+		op:      op,
+		server:  server,
+		metrics: osc.Metrics,
 	}
 	server.serveMux.HandleFunc("/ws", rpc.connectionHandler)
+	if rpc.metrics != nil {
+		server.serveMux.Handle("/metrics", rpc.metrics.Handler())
+	} else {
+		rpc.metrics = NewMetrics()
+	}
 	return rpc
 }
 
@@ -129,7 +145,7 @@ func (r *RPCServer) connectionHandler(out http.ResponseWriter, in *http.Request)
 		return
 	}
 
-	peer := &Peer{conn: conn, op: r.op}
+	peer := &Peer{conn: conn, op: r.op, contract: r.server.clientConfig.Contract, metrics: r.metrics}
 	if err := peer.sendJSON(wire.PushConfig{
 		Result: wire.Result{Topic: wire.Config},
 		Config: r.server.clientConfig,
@@ -137,6 +153,7 @@ func (r *RPCServer) connectionHandler(out http.ResponseWriter, in *http.Request)
 		r.Log().WithError(err).Error("Pushing ClientConfig")
 		return
 	}
+	r.metrics.peers.Inc()
 	// Start client handler routine.
 	go func() {
 		err := peer.readMessages()
@@ -162,7 +179,11 @@ func (p *Peer) readMessages() error {
 		if err := json.Unmarshal(msg, &call); err != nil {
 			sendErr = p.sendResult("", fmt.Errorf("Invalid json: %w", err))
 		} else {
-			sendErr = p.sendResult(call.ID, p.handleCall(call.ID, call.Method, msg))
+			if res, err := p.handleCall(call.ID, call.Method, msg); err == nil && res != nil {
+				sendErr = p.sendJSON(res)
+			} else {
+				sendErr = p.sendResult(call.ID, err)
+			}
 		}
 		if sendErr != nil {
 			p.Log().WithField("id", call.ID).WithError(sendErr).Error("Could not send result")
@@ -178,40 +199,177 @@ func (p *Peer) Close() error {
 	p.conn.Close()
 	if p.sub != nil {
 		p.sub.Unsubscribe()
+		p.metrics.subscriptions.Dec()
 	}
+	p.metrics.peers.Dec()
 	return nil
 }
 
-func (p *Peer) handleCall(id wire.ID, method wire.Method, msg []byte) error {
+// handleCall handles a call and returns its result. If the result is nil, a
+// plain `wire.Result` is sent back.
+func (p *Peer) handleCall(id wire.ID, method wire.Method, msg []byte) (interface{}, error) {
 	p.Log().Trace("Server received ", string(msg))
 	switch method {
 	case wire.MethodSendTx:
 		var call wire.SendTx
 		if err := json.Unmarshal(msg, &call); err != nil {
-			return fmt.Errorf("unmarshalling SendTx: %w", err)
+			return nil, fmt.Errorf("unmarshalling SendTx: %w", err)
+		}
+		return nil, p.op.Send(call.Tx)
+	case wire.MethodSendBatchTx:
+		var call wire.SendBatchTx
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling SendBatchTx: %w", err)
+		}
+		return nil, p.op.SendBatch(call.Tx)
+	case wire.MethodLockHTLC:
+		var call wire.LockHTLC
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling LockHTLC: %w", err)
+		}
+		return nil, p.op.SendHTLC(tee.HTLCOp{Lock: &call.HTLC})
+	case wire.MethodClaimHTLC:
+		var call wire.ClaimHTLC
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling ClaimHTLC: %w", err)
 		}
-		return p.op.Send(call.Tx)
+		return nil, p.op.SendHTLC(tee.HTLCOp{Claim: &call.Claim})
+	case wire.MethodRefundHTLC:
+		var call wire.RefundHTLC
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling RefundHTLC: %w", err)
+		}
+		return nil, p.op.SendHTLC(tee.HTLCOp{Refund: &call.Refund})
+	case wire.MethodGetChallenge:
+		challenge, err := p.newChallenge()
+		if err != nil {
+			return nil, err
+		}
+		return &wire.Challenge{Result: wire.Result{ID: id}, Challenge: challenge}, nil
 	case wire.MethodSubscribe:
 		var call wire.Subscribe
 		if err := json.Unmarshal(msg, &call); err != nil {
-			return fmt.Errorf("unmarshalling Subscribe: %w", err)
+			return nil, fmt.Errorf("unmarshalling Subscribe: %w", err)
+		}
+		return nil, p.subscribe(call.Req)
+	case wire.MethodRequestExit:
+		var call wire.RequestExit
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling RequestExit: %w", err)
+		}
+		proof, err := p.op.RequestExit(call.Req)
+		if err != nil {
+			return nil, err
+		}
+		return &wire.ExitProof{Result: wire.Result{ID: id}, Proof: proof}, nil
+	case wire.MethodGetBalance, wire.MethodGetNonce:
+		var call wire.GetAccount
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling GetAccount: %w", err)
+		}
+		if err := p.authorize(call.Who); err != nil {
+			return nil, err
+		}
+		acc, err := p.op.Account(call.Who)
+		if err != nil {
+			return nil, err
+		}
+		if method == wire.MethodGetNonce {
+			return &wire.Nonce{Result: wire.Result{ID: id}, Nonce: acc.Nonce + 1}, nil
+		}
+		return &wire.Balance{Result: wire.Result{ID: id}, Account: acc}, nil
+	case wire.MethodGetBalanceProof:
+		var call wire.GetProof
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling GetProof: %w", err)
+		}
+		if err := p.authorize(call.Who); err != nil {
+			return nil, err
+		}
+		proof, err := p.op.BalanceProof(call.Epoch, call.Who, call.Token)
+		if err != nil {
+			return nil, err
+		}
+		return &wire.BalanceProof{Result: wire.Result{ID: id}, Proof: proof}, nil
+	case wire.MethodGetDepositProof:
+		var call wire.GetProof
+		if err := json.Unmarshal(msg, &call); err != nil {
+			return nil, fmt.Errorf("unmarshalling GetProof: %w", err)
+		}
+		if err := p.authorize(call.Who); err != nil {
+			return nil, err
+		}
+		proof, err := p.op.DepositProof(call.Epoch, call.Who, call.Token)
+		if err != nil {
+			return nil, err
 		}
-		return p.subscribe(call.Who)
+		return &wire.DepositProof{Result: wire.Result{ID: id}, Proof: proof}, nil
+	case wire.MethodGetParams:
+		return &wire.Params{Result: wire.Result{ID: id}, Params: p.op.Params()}, nil
 	default:
-		return fmt.Errorf("unknown method '%s'", method)
+		return nil, fmt.Errorf("unknown method '%s'", method)
 	}
 }
 
-func (p *Peer) subscribe(who common.Address) error {
+// newChallenge issues a new random subscription challenge, replacing any
+// previous one.
+func (p *Peer) newChallenge() (common.Hash, error) {
+	var challenge common.Hash
+	if _, err := rand.Read(challenge[:]); err != nil {
+		return common.Hash{}, fmt.Errorf("generating challenge: %w", err)
+	}
+	p.challenge = &challenge
+	return challenge, nil
+}
+
+// authenticate checks that the subscribe request is signed by its account
+// over the issued challenge. The challenge is used up, even if the check
+// fails.
+func (p *Peer) authenticate(req tee.SubscribeRequest) error {
+	challenge := p.challenge
+	p.challenge = nil
+	if challenge == nil {
+		return errors.New("no challenge requested")
+	} else if req.Challenge != *challenge {
+		return errors.New("wrong challenge")
+	}
+	if ok, err := tee.VerifySubscribeRequest(p.contract, req); err != nil {
+		return fmt.Errorf("verifying signature: %w", err)
+	} else if !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// authorize checks that the peer authenticated itself as who by subscribing,
+// so that account queries do not leak other accounts' states.
+func (p *Peer) authorize(who common.Address) error {
+	if p.sub == nil {
+		return errors.New("not subscribed")
+	} else if who != p.subWho {
+		return errors.New("not subscribed as queried account")
+	}
+	return nil
+}
+
+func (p *Peer) subscribe(req tee.SubscribeRequest) error {
 	if p.sub != nil {
 		return errors.New("subscribed twice to proofs")
 	}
+	if err := p.authenticate(req); err != nil {
+		p.Log().WithError(err).WithFields(log.Fields{
+			"who":    req.Who.Hex(),
+			"remote": p.conn.RemoteAddr().String(),
+		}).Warn("Rejected unauthenticated subscription")
+		return fmt.Errorf("authenticating subscription: %w", err)
+	}
 
-	sub, err := p.op.SubscribeProofs(who)
+	sub, err := p.op.SubscribeProofs(req.Who)
 	if err != nil {
 		return fmt.Errorf("subscribing to proofs: %w", err)
 	}
-	p.sub = &sub
+	p.sub, p.subWho = &sub, req.Who
+	p.metrics.subscriptions.Inc()
 
 	go func() {
 		for {
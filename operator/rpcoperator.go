@@ -3,6 +3,8 @@
 package operator
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -19,7 +21,14 @@ type (
 	// implementation of it over the wire.
 	WireAPI interface {
 		Send(tee.Transaction) error
+		SendBatch(tee.BatchTransaction) error
+		SendHTLC(tee.HTLCOp) error
 		SubscribeProofs(common.Address) (ClientSub, error)
+		RequestExit(tee.ExitRequest) (tee.BalanceProof, error)
+		Account(common.Address) (tee.Account, error)
+		BalanceProof(epoch tee.Epoch, who, token common.Address) (tee.BalanceProof, error)
+		DepositProof(epoch tee.Epoch, who, token common.Address) (tee.DepositProof, error)
+		Params() tee.Parameters
 	}
 
 	// RPCOperator will be exposed to the client as a websocket RPC Server.
@@ -27,17 +36,20 @@ type (
 	RPCOperator struct {
 		mtx     sync.Mutex // protects all
 		enclave tee.Enclave
+		params  tee.Parameters
 
 		txReceipts *txReceipts
+		archive    *Archive // Archives accepted transactions, may be nil.
+		metrics    *Metrics
 		subs       map[common.Address]*BufferedClientSubs
 	}
 
 	// BufferedClientSub describes a slice of subs, which also holds the latest
-	// available deposit- and/or balance-proof.
+	// available deposit- and/or balance-proof of each token.
 	BufferedClientSubs struct {
 		subs     []ClientSub
-		latestDP *tee.DepositProof
-		latestBP *tee.BalanceProof
+		latestDP map[common.Address]*tee.DepositProof // token => proof
+		latestBP map[common.Address]*tee.BalanceProof // token => proof
 	}
 
 	// ClientSub is a subscription on TEE proofs.
@@ -53,14 +65,27 @@ var _ WireAPI = (*RPCOperator)(nil)
 
 var txReceiptDeliveryTimeout = 20 * time.Second
 
-func NewRPCOperator(enclave tee.Enclave, txReceipts *txReceipts) *RPCOperator {
+// proofBufferSize is the number of proofs that a subscription buffers, which
+// limits the number of tokens whose latest proofs are buffered.
+const proofBufferSize = 16
+
+// NewRPCOperator returns a new RPCOperator. If archive is not nil, accepted
+// transactions are archived in it and it serves historical proofs. If metrics
+// is nil, new metrics are created.
+func NewRPCOperator(enclave tee.Enclave, params tee.Parameters, txReceipts *txReceipts, archive *Archive, metrics *Metrics) *RPCOperator {
 	if txReceipts == nil {
 		txReceipts = newTXReceipts()
 	}
+	if metrics == nil {
+		metrics = NewMetrics()
+	}
 	return &RPCOperator{
 		enclave:    enclave,
+		params:     params,
 		subs:       make(map[common.Address]*BufferedClientSubs),
 		txReceipts: txReceipts,
+		archive:    archive,
+		metrics:    metrics,
 	}
 }
 
@@ -68,9 +93,24 @@ func (o *RPCOperator) Send(tx tee.Transaction) error {
 	o.mtx.Lock()
 	defer o.mtx.Unlock()
 	log.Infof("Sending %d WEI 0x%s…->0x%s…", (*big.Int)(tx.Amount).Uint64(), tx.Sender.Hex()[:5], tx.Recipient.Hex()[:5])
-	if err := o.enclave.ProcessTXs(&tx); err != nil {
+	start := time.Now()
+	err := o.enclave.ProcessTXs(&tx)
+	o.metrics.observeTx(txTypeSingle, start, err)
+	if err != nil {
 		return err
 	}
+	if o.archive != nil {
+		if err := o.archive.AddTransaction(tx); err != nil {
+			log.Errorf("Archiving transaction: %v", err)
+		}
+	}
+	o.notifyRecipient(tx)
+	return nil
+}
+
+// notifyRecipient sends a transaction receipt to all subscriptions of the
+// transaction's recipient.
+func (o *RPCOperator) notifyRecipient(tx tee.Transaction) {
 	if bsub, ok := o.subs[tx.Recipient]; ok {
 		timeout := time.After(txReceiptDeliveryTimeout)
 		for _, sub := range bsub.subs {
@@ -83,9 +123,88 @@ func (o *RPCOperator) Send(tx tee.Transaction) error {
 			}()
 		}
 	}
+}
+
+// SendBatch forwards a batch transaction to the enclave and notifies all
+// recipients about their payments.
+func (o *RPCOperator) SendBatch(tx tee.BatchTransaction) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	log.Infof("Sending batch of %d payments from 0x%s…", len(tx.Payments), tx.Sender.Hex()[:5])
+	start := time.Now()
+	err := o.enclave.ProcessBatchTXs(&tx)
+	o.metrics.observeTx(txTypeBatch, start, err)
+	if err != nil {
+		return err
+	}
+	if o.archive != nil {
+		if err := o.archive.AddBatchTransaction(tx); err != nil {
+			log.Errorf("Archiving batch transaction: %v", err)
+		}
+	}
+	for _, receipt := range tx.Receipts() {
+		o.notifyRecipient(receipt)
+	}
 	return nil
 }
 
+// SendHTLC forwards an operation on hash-time-locked payments to the enclave.
+func (o *RPCOperator) SendHTLC(op tee.HTLCOp) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.enclave.ProcessHTLCs(&op)
+}
+
+// RequestExit requests an exit proof for a partial exit from the enclave.
+func (o *RPCOperator) RequestExit(req tee.ExitRequest) (tee.BalanceProof, error) {
+	log.WithFields(log.Fields{"who": req.Account.Hex(), "token": req.Token.Hex()}).Debug("Exit requested")
+	proof, err := o.enclave.ExitProof(&req)
+	if err != nil {
+		return tee.BalanceProof{}, err
+	}
+	return *proof, nil
+}
+
+// Account returns the current off-chain state of the account.
+func (o *RPCOperator) Account(who common.Address) (tee.Account, error) {
+	acc, err := o.enclave.Account(who)
+	if err != nil {
+		return tee.Account{}, err
+	}
+	return *acc, nil
+}
+
+// BalanceProof returns the archived balance proof of the account's token at
+// the end of the given transaction epoch.
+func (o *RPCOperator) BalanceProof(epoch tee.Epoch, who, token common.Address) (tee.BalanceProof, error) {
+	if o.archive == nil {
+		return tee.BalanceProof{}, errors.New("no archive")
+	}
+	proof, ok := o.archive.BalanceProof(epoch, who, token)
+	if !ok {
+		return tee.BalanceProof{}, fmt.Errorf("no balance proof for epoch %d", epoch)
+	}
+	return *proof, nil
+}
+
+// DepositProof returns the archived deposit proof of the account's token
+// deposits in the given epoch.
+func (o *RPCOperator) DepositProof(epoch tee.Epoch, who, token common.Address) (tee.DepositProof, error) {
+	if o.archive == nil {
+		return tee.DepositProof{}, errors.New("no archive")
+	}
+	proof, ok := o.archive.DepositProof(epoch, who, token)
+	if !ok {
+		return tee.DepositProof{}, fmt.Errorf("no deposit proof for epoch %d", epoch)
+	}
+	return *proof, nil
+}
+
+// Params returns the enclave parameters.
+func (o *RPCOperator) Params() tee.Parameters {
+	return o.params
+}
+
 // SubscribeProofs returns a subscription on TEE proofs for the given address.
 // The subscription buffers the most recent proof until the client retrieves it.
 func (o *RPCOperator) SubscribeProofs(addr common.Address) (ClientSub, error) {
@@ -100,19 +219,34 @@ func (o *RPCOperator) SubscribeProofs(addr common.Address) (ClientSub, error) {
 func (o *RPCOperator) subscribe(addr common.Address) ClientSub {
 	sub := *newProofSub(o.txReceipts.AddPeer(addr))
 	if _, ok := o.subs[addr]; !ok {
-		o.subs[addr] = new(BufferedClientSubs)
+		o.subs[addr] = newBufferedClientSubs()
 	} else {
-		if dp := o.subs[addr].latestDP; dp != nil {
-			sub.deposits <- *dp
+		for _, dp := range o.subs[addr].latestDP {
+			select {
+			case sub.deposits <- *dp:
+			default:
+				log.WithField("who", addr.Hex()).Warn("Deposit proof buffer full")
+			}
 		}
-		if bp := o.subs[addr].latestBP; bp != nil {
-			sub.balances <- *bp
+		for _, bp := range o.subs[addr].latestBP {
+			select {
+			case sub.balances <- *bp:
+			default:
+				log.WithField("who", addr.Hex()).Warn("Balance proof buffer full")
+			}
 		}
 	}
 	o.subs[addr].subs = append(o.subs[addr].subs, sub)
 	return sub
 }
 
+func newBufferedClientSubs() *BufferedClientSubs {
+	return &BufferedClientSubs{
+		latestDP: make(map[common.Address]*tee.DepositProof),
+		latestBP: make(map[common.Address]*tee.BalanceProof),
+	}
+}
+
 func (o *RPCOperator) PushDepositProof(proof tee.DepositProof) {
 	o.mtx.Lock()
 	defer o.mtx.Unlock()
@@ -120,17 +254,18 @@ func (o *RPCOperator) PushDepositProof(proof tee.DepositProof) {
 	bufsub, ok := o.subs[who]
 	if !ok {
 		log.WithField("who", who.Hex()).Debug("Received DP without subscription - buffering")
-		bufsub = new(BufferedClientSubs)
+		bufsub = newBufferedClientSubs()
 		o.subs[who] = bufsub
 	}
-	bufsub.latestDP = &proof
+	bufsub.latestDP[proof.Balance.Token] = &proof
 	subs := bufsub.subs
 
 	for i := 0; i < len(subs); i++ {
-		// Clear out old buffered proof.
-		select {
-		case <-subs[i].deposits:
-		default:
+		// Clear out old buffered proof of the same token.
+		for _, dp := range drainDepositProofs(subs[i].deposits) {
+			if dp.Balance.Token != proof.Balance.Token {
+				subs[i].deposits <- dp
+			}
 		}
 		// Write new proof in.
 		select {
@@ -152,17 +287,18 @@ func (o *RPCOperator) PushBalanceProof(proof tee.BalanceProof) {
 	bufsub, ok := o.subs[who]
 	if !ok {
 		log.WithField("who", who.Hex()).Debug("Received BP without subscription - buffering")
-		bufsub = new(BufferedClientSubs)
+		bufsub = newBufferedClientSubs()
 		o.subs[who] = bufsub
 	}
-	bufsub.latestBP = &proof
+	bufsub.latestBP[proof.Balance.Token] = &proof
 	subs := bufsub.subs
 
 	for i := 0; i < len(subs); i++ {
-		// Clear out old buffered proof.
-		select {
-		case <-subs[i].balances:
-		default:
+		// Clear out old buffered proof of the same token.
+		for _, bp := range drainBalanceProofs(subs[i].balances) {
+			if bp.Balance.Token != proof.Balance.Token {
+				subs[i].balances <- bp
+			}
 		}
 		// Write new proof in.
 		select {
@@ -177,11 +313,40 @@ func (o *RPCOperator) PushBalanceProof(proof tee.BalanceProof) {
 	bufsub.subs = subs
 }
 
-// newProofSub returns a new proofSub. The proof channels have buffer size 1.
+// drainDepositProofs removes and returns all buffered proofs from ch. Must be
+// called with the RPCOperator's mutex held so that the drained proofs can be
+// written back without blocking.
+func drainDepositProofs(ch chan tee.DepositProof) (proofs []tee.DepositProof) {
+	for {
+		select {
+		case dp := <-ch:
+			proofs = append(proofs, dp)
+		default:
+			return
+		}
+	}
+}
+
+// drainBalanceProofs removes and returns all buffered proofs from ch. Must be
+// called with the RPCOperator's mutex held so that the drained proofs can be
+// written back without blocking.
+func drainBalanceProofs(ch chan tee.BalanceProof) (proofs []tee.BalanceProof) {
+	for {
+		select {
+		case bp := <-ch:
+			proofs = append(proofs, bp)
+		default:
+			return
+		}
+	}
+}
+
+// newProofSub returns a new proofSub. The proof channels have buffer size
+// proofBufferSize.
 func newProofSub(receipts chan tee.Transaction) *ClientSub {
 	return &ClientSub{
-		deposits: make(chan tee.DepositProof, 1),
-		balances: make(chan tee.BalanceProof, 1),
+		deposits: make(chan tee.DepositProof, proofBufferSize),
+		balances: make(chan tee.BalanceProof, proofBufferSize),
 		receipts: receipts,
 		quit:     make(chan struct{}),
 	}
@@ -3,6 +3,9 @@
 package test
 
 import (
+	"math/big"
+	"sync"
+
 	"github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
 
@@ -12,9 +15,14 @@ import (
 type Enclave struct {
 	processTXsError error
 	txs             chan *tee.Transaction
+	batches         chan *tee.BatchTransaction
+	htlcs           chan *tee.HTLCOp
 
 	deps chan *tee.DepositProof
 	bals chan *tee.BalanceProof
+
+	accMtx   sync.Mutex // protects accounts.
+	accounts map[common.Address]*tee.Account
 }
 
 var _ tee.Enclave = (*Enclave)(nil)
@@ -22,9 +30,13 @@ var _ tee.Enclave = (*Enclave)(nil)
 func NewMockedEnclave() *Enclave {
 	return &Enclave{
 		// Buffer some TX to make testing easier.
-		txs:  make(chan *tee.Transaction, 10),
-		deps: make(chan *tee.DepositProof, 10),
-		bals: make(chan *tee.BalanceProof, 10),
+		txs:     make(chan *tee.Transaction, 10),
+		batches: make(chan *tee.BatchTransaction, 10),
+		htlcs:   make(chan *tee.HTLCOp, 10),
+		deps:    make(chan *tee.DepositProof, 10),
+		bals:    make(chan *tee.BalanceProof, 10),
+
+		accounts: make(map[common.Address]*tee.Account),
 	}
 }
 
@@ -54,6 +66,26 @@ func (e *Enclave) ProcessTXs(txs ...*tee.Transaction) error {
 	return nil
 }
 
+func (e *Enclave) ProcessBatchTXs(txs ...*tee.BatchTransaction) error {
+	if e.processTXsError != nil {
+		return e.processTXsError
+	}
+	for _, tx := range txs {
+		e.batches <- tx
+	}
+	return nil
+}
+
+func (e *Enclave) ProcessHTLCs(ops ...*tee.HTLCOp) error {
+	if e.processTXsError != nil {
+		return e.processTXsError
+	}
+	for _, op := range ops {
+		e.htlcs <- op
+	}
+	return nil
+}
+
 func (e *Enclave) DepositProofs() (ret []*tee.DepositProof, err error) {
 	for {
 		select {
@@ -76,12 +108,35 @@ func (e *Enclave) BalanceProofs() (ret []*tee.BalanceProof, err error) {
 	}
 }
 
+func (e *Enclave) ExitProof(req *tee.ExitRequest) (*tee.BalanceProof, error) {
+	// Mocked exit proofs are not signed.
+	return &tee.BalanceProof{Balance: req.Balance(), Sig: make(tee.Sig, 65)}, nil
+}
+
+// Account returns the account that was set with SetAccount, or an empty
+// account.
+func (e *Enclave) Account(who common.Address) (*tee.Account, error) {
+	e.accMtx.Lock()
+	defer e.accMtx.Unlock()
+	if acc, ok := e.accounts[who]; ok {
+		return acc, nil
+	}
+	return &tee.Account{Value: (*tee.Amount)(new(big.Int))}, nil
+}
+
 func (e *Enclave) Shutdown() {}
 
 func (e *Enclave) SetProcessTXsError(err error) {
 	e.processTXsError = err
 }
 
+// SetAccount sets the account that Account returns for who.
+func (e *Enclave) SetAccount(who common.Address, acc *tee.Account) {
+	e.accMtx.Lock()
+	defer e.accMtx.Unlock()
+	e.accounts[who] = acc
+}
+
 func (e *Enclave) PushDepositProof(proof *tee.DepositProof) {
 	e.Log().WithField("acc", proof.Balance.Account.Hex()).Debug("Produced Deposit proof")
 	e.deps <- proof
@@ -95,3 +150,11 @@ func (e *Enclave) PushBalanceProof(proof *tee.BalanceProof) {
 func (e *Enclave) Transactions() <-chan *tee.Transaction {
 	return e.txs
 }
+
+func (e *Enclave) BatchTransactions() <-chan *tee.BatchTransaction {
+	return e.batches
+}
+
+func (e *Enclave) HTLCOps() <-chan *tee.HTLCOp {
+	return e.htlcs
+}
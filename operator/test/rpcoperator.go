@@ -21,6 +21,7 @@ type (
 	RPCOperator struct {
 		enclave *Enclave
 		op      *op.RPCOperator
+		archive *op.Archive
 
 		subscribeError error
 	}
@@ -28,11 +29,14 @@ type (
 
 var _ op.WireAPI = (*RPCOperator)(nil)
 
-// NewRPROperator returns a new mocked WireAPI.
-func NewRPROperator(enclave *Enclave) *RPCOperator {
+// NewRPROperator returns a new mocked WireAPI with the given enclave
+// parameters.
+func NewRPROperator(enclave *Enclave, params tee.Parameters) *RPCOperator {
+	archive := op.NewArchive()
 	return &RPCOperator{
 		enclave: enclave,
-		op:      op.NewRPCOperator(enclave, nil),
+		op:      op.NewRPCOperator(enclave, params, nil, archive, nil),
+		archive: archive,
 	}
 }
 
@@ -43,6 +47,7 @@ func (r *RPCOperator) Run() {
 		for {
 			// Mocked subscriptions never error.
 			proofs, _ := r.enclave.DepositProofs()
+			r.archive.AddDepositProofs(proofs) // nolint: errcheck
 			for _, proof := range proofs {
 				r.op.PushDepositProof(*proof)
 			}
@@ -53,6 +58,7 @@ func (r *RPCOperator) Run() {
 		for {
 			// Mocked subscriptions never error.
 			proofs, _ := r.enclave.BalanceProofs()
+			r.archive.AddBalanceProofs(proofs) // nolint: errcheck
 			for _, proof := range proofs {
 				r.op.PushBalanceProof(*proof)
 			}
@@ -69,6 +75,20 @@ func (r *RPCOperator) Send(tx tee.Transaction) error {
 	return r.op.Send(tx)
 }
 
+// SendBatch is part of the operator.WireAPI interface and adds a batch
+// transaction to the enclave.
+// Can be read back from the enclave's BatchTransactions().
+func (r *RPCOperator) SendBatch(tx tee.BatchTransaction) error {
+	return r.op.SendBatch(tx)
+}
+
+// SendHTLC is part of the operator.WireAPI interface and adds an HTLC
+// operation to the enclave.
+// Can be read back from the enclave's HTLCOps().
+func (r *RPCOperator) SendHTLC(op tee.HTLCOp) error {
+	return r.op.SendHTLC(op)
+}
+
 // SubscribeProofs subscribed to the proofs that can be added via
 // PushDepositProof and PushBalanceProof which buffers one proof.
 // Returns the error that was set by SetSubscribeProofsError.
@@ -79,6 +99,36 @@ func (r *RPCOperator) SubscribeProofs(addr common.Address) (op.ClientSub, error)
 	return r.op.SubscribeProofs(addr)
 }
 
+// RequestExit is part of the operator.WireAPI interface and returns the
+// enclave's exit proof.
+func (r *RPCOperator) RequestExit(req tee.ExitRequest) (tee.BalanceProof, error) {
+	return r.op.RequestExit(req)
+}
+
+// Account is part of the operator.WireAPI interface and returns the
+// account that was set in the enclave.
+func (r *RPCOperator) Account(who common.Address) (tee.Account, error) {
+	return r.op.Account(who)
+}
+
+// BalanceProof is part of the operator.WireAPI interface and returns an
+// archived balance proof that was pushed to the enclave.
+func (r *RPCOperator) BalanceProof(epoch tee.Epoch, who, token common.Address) (tee.BalanceProof, error) {
+	return r.op.BalanceProof(epoch, who, token)
+}
+
+// DepositProof is part of the operator.WireAPI interface and returns an
+// archived deposit proof that was pushed to the enclave.
+func (r *RPCOperator) DepositProof(epoch tee.Epoch, who, token common.Address) (tee.DepositProof, error) {
+	return r.op.DepositProof(epoch, who, token)
+}
+
+// Params is part of the operator.WireAPI interface and returns the
+// parameters given to NewRPROperator.
+func (r *RPCOperator) Params() tee.Parameters {
+	return r.op.Params()
+}
+
 // SetSubscribeProofsError sets the error that should be returned
 // by SubscribeProofs.
 func (r *RPCOperator) SetSubscribeProofsError(err error) {
@@ -74,7 +74,7 @@ func CreateUser(
 	if err != nil {
 		t.Fatal("dialing rpc:", err)
 	}
-	sub, err := rpcClient.Subscribe(ctx, account.Address)
+	sub, err := rpcClient.Subscribe(ctx, account, wallet)
 	if err != nil {
 		t.Fatal("subscribing:", err)
 	}
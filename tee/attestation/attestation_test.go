@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee/attestation"
+)
+
+func TestVerify(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	teeAddr := eth.NewRandomAddress(rng)
+	measurement := common.Hash{1, 2, 3}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	quoter := attestation.NewMockQuoter(key, measurement)
+	quote, err := quoter.Quote(teeAddr)
+	require.NoError(err)
+	att, err := quote.Marshal()
+	require.NoError(err)
+
+	verifier := attestation.NewMockVerifier(quoter.Signer(), measurement)
+	require.NoError(attestation.Verify(verifier, teeAddr, att))
+
+	t.Run("no-attestation", func(t *testing.T) {
+		require.True(errors.Is(attestation.Verify(verifier, teeAddr, nil), attestation.ErrNoAttestation))
+		require.Error(attestation.Verify(verifier, teeAddr, []byte("garbage")))
+	})
+
+	t.Run("other-tee", func(t *testing.T) {
+		require.Error(attestation.Verify(verifier, eth.NewRandomAddress(rng), att))
+	})
+
+	t.Run("untrusted-measurement", func(t *testing.T) {
+		v := attestation.NewMockVerifier(quoter.Signer(), common.Hash{4})
+		require.Error(attestation.Verify(v, teeAddr, att))
+	})
+
+	t.Run("untrusted-signer", func(t *testing.T) {
+		v := attestation.NewMockVerifier(eth.NewRandomAddress(rng), measurement)
+		require.Error(attestation.Verify(v, teeAddr, att))
+	})
+
+	t.Run("forged-measurement", func(t *testing.T) {
+		forged := *quote
+		forged.Measurement = common.Hash{4}
+		v := attestation.NewMockVerifier(quoter.Signer(), forged.Measurement)
+		require.Error(v.VerifyQuote(&forged))
+	})
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type (
+	// MockQuoter creates quotes like an SGX quoting enclave, but signs them
+	// with a local key instead of a hardware-backed attestation key.
+	MockQuoter struct {
+		key         *ecdsa.PrivateKey
+		measurement common.Hash
+	}
+
+	// MockVerifier verifies quotes of a MockQuoter. It trusts the quotes signed
+	// by the attestation service Signer that report one of the trusted
+	// measurements.
+	MockVerifier struct {
+		Signer  common.Address
+		trusted map[common.Hash]struct{}
+	}
+)
+
+var _ Quoter = (*MockQuoter)(nil)
+var _ Verifier = (*MockVerifier)(nil)
+
+// NewMockQuoter creates a mock quoter that signs quotes for the given
+// measurement with key.
+func NewMockQuoter(key *ecdsa.PrivateKey, measurement common.Hash) *MockQuoter {
+	return &MockQuoter{key: key, measurement: measurement}
+}
+
+// Signer returns the address of the attestation service key.
+func (m *MockQuoter) Signer() common.Address {
+	return crypto.PubkeyToAddress(m.key.PublicKey)
+}
+
+// Quote creates a signed quote for the enclave signing address tee.
+func (m *MockQuoter) Quote(tee common.Address) (*Quote, error) {
+	q := &Quote{Measurement: m.measurement, ReportData: ReportData(tee)}
+	sig, err := crypto.Sign(q.SigHash().Bytes(), m.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing quote: %w", err)
+	}
+	q.Sig = sig
+	return q, nil
+}
+
+// NewMockVerifier creates a mock verifier that trusts quotes of the given
+// measurements signed by signer.
+func NewMockVerifier(signer common.Address, trusted ...common.Hash) *MockVerifier {
+	v := &MockVerifier{Signer: signer, trusted: make(map[common.Hash]struct{}, len(trusted))}
+	for _, m := range trusted {
+		v.trusted[m] = struct{}{}
+	}
+	return v
+}
+
+// VerifyQuote verifies that the quote was signed by the verifier's signer and
+// that its measurement is trusted.
+func (v *MockVerifier) VerifyQuote(q *Quote) error {
+	if len(q.Sig) != crypto.SignatureLength {
+		return errors.New("invalid quote signature length")
+	}
+	pub, err := crypto.SigToPub(q.SigHash().Bytes(), q.Sig)
+	if err != nil {
+		return fmt.Errorf("recovering quote signer: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != v.Signer {
+		return fmt.Errorf("quote signed by untrusted attestation service %s", signer.Hex())
+	}
+	if _, ok := v.trusted[q.Measurement]; !ok {
+		return fmt.Errorf("untrusted measurement %s", q.Measurement.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package attestation implements the remote attestation of Erdstall enclaves.
+//
+// During initialization, an enclave binds its signing address to an
+// attestation Quote, which states that the enclave code with a certain
+// measurement runs inside a genuine TEE. Clients verify the quote of the TEE
+// address stored in the contract before trusting the operator with deposits.
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type (
+	// Quote is an SGX-style attestation quote. It states that an enclave with
+	// code Measurement runs inside a TEE and reported ReportData during
+	// initialization. The quote is signed by the attestation service.
+	Quote struct {
+		Measurement common.Hash `json:"measurement"` // Hash of the enclave code, like MRENCLAVE.
+		ReportData  common.Hash `json:"reportData"`  // Binds the enclave's signing address.
+		Sig         []byte      `json:"sig"`         // Signature of the attestation service.
+	}
+
+	// A Quoter creates quotes for an enclave's signing address. It is used by
+	// enclaves during initialization.
+	Quoter interface {
+		Quote(tee common.Address) (*Quote, error)
+	}
+
+	// A Verifier verifies that a quote is authentic and that its measurement
+	// is trusted.
+	Verifier interface {
+		VerifyQuote(*Quote) error
+	}
+)
+
+// PrototypeMeasurement is the measurement reported for the prototype enclave,
+// which does not run inside a TEE.
+var PrototypeMeasurement = crypto.Keccak256Hash([]byte("ErdstallPrototypeEnclave"))
+
+// ErrNoAttestation is returned by Verify if there is no attestation.
+var ErrNoAttestation = errors.New("no attestation")
+
+// ReportData returns the report data that binds the given enclave signing
+// address to a quote.
+func ReportData(tee common.Address) common.Hash {
+	return common.BytesToHash(tee.Bytes())
+}
+
+// SigHash returns the hash that is signed by the attestation service.
+func (q *Quote) SigHash() common.Hash {
+	return crypto.Keccak256Hash([]byte("ErdstallQuote"), q.Measurement[:], q.ReportData[:])
+}
+
+// Marshal encodes the quote as an attestation, as returned by tee.Enclave.Init.
+func (q *Quote) Marshal() ([]byte, error) {
+	return json.Marshal(q)
+}
+
+// Unmarshal decodes an attestation, as returned by tee.Enclave.Init.
+func Unmarshal(attestation []byte) (*Quote, error) {
+	q := new(Quote)
+	if err := json.Unmarshal(attestation, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Verify verifies that the attestation binds the enclave signing address tee
+// to a trusted measurement.
+func Verify(v Verifier, tee common.Address, attestation []byte) error {
+	if len(attestation) == 0 {
+		return ErrNoAttestation
+	}
+	q, err := Unmarshal(attestation)
+	if err != nil {
+		return fmt.Errorf("decoding quote: %w", err)
+	}
+	if q.ReportData != ReportData(tee) {
+		return fmt.Errorf("quote not bound to tee address %s", tee.Hex())
+	}
+	return v.VerifyQuote(q)
+}
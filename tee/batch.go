@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxBatchSize is the maximal number of payments in a BatchTransaction.
+const MaxBatchSize = 1000
+
+type (
+	// BatchTransaction is a payment of Token from Sender to multiple
+	// recipients, signed once by the Sender. It uses a single nonce and is
+	// applied atomically: either all payments succeed or none.
+	//
+	// The Fee is paid once for the whole batch, see Parameters.BatchFee.
+	BatchTransaction struct {
+		Nonce    uint64         `json:"nonce"` // shared with Transaction nonces
+		Epoch    Epoch          `json:"epoch"`
+		Sender   common.Address `json:"sender"`
+		Token    common.Address `json:"token"` // ETHToken for ETH transfers
+		Payments []Payment      `json:"payments"`
+		Fee      *Amount        `json:"fee"` // in ETH, nil for no fee
+		Sig      Sig            `json:"sig"`
+	}
+
+	// Payment is a single payment of a BatchTransaction.
+	Payment struct {
+		Recipient common.Address `json:"recipient"`
+		Amount    *Amount        `json:"amount"`
+	}
+)
+
+// Total returns the sum of all payment amounts.
+func (t *BatchTransaction) Total() *big.Int {
+	total := new(big.Int)
+	for _, p := range t.Payments {
+		total.Add(total, (*big.Int)(p.Amount))
+	}
+	return total
+}
+
+// FeeValue returns the batch's fee, zero if it has no fee.
+func (t *BatchTransaction) FeeValue() *big.Int {
+	if t.Fee == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(t.Fee)
+}
+
+// Receipts returns the batch's payments as unsigned transactions, which are
+// used as receipts for the recipients.
+func (t *BatchTransaction) Receipts() []Transaction {
+	txs := make([]Transaction, len(t.Payments))
+	for i, p := range t.Payments {
+		txs[i] = Transaction{
+			Nonce:     t.Nonce,
+			Epoch:     t.Epoch,
+			Sender:    t.Sender,
+			Recipient: p.Recipient,
+			Token:     t.Token,
+			Amount:    p.Amount,
+		}
+	}
+	return txs
+}
+
+// Sign signs the batch transaction with the given account and signer. It
+// checks that the account matches the batch's sender.
+func (t *BatchTransaction) Sign(contract common.Address, account accounts.Account, w TextSigner) error {
+	if account.Address != t.Sender {
+		return errors.New("not Sender's account")
+	}
+	msg, err := EncodeBatchTransaction(contract, *t)
+	if err != nil {
+		return fmt.Errorf("encoding batch tx: %w", err)
+	}
+	hash := crypto.Keccak256Hash(msg)
+	sig, err := w.SignText(account, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing batch tx hash: %w", err)
+	}
+	sig[64] += 27
+
+	t.Sig = sig
+	return nil
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+	wiretest "github.com/perun-network/erdstall/wire/test"
+)
+
+func TestBatchTransaction_SignVerify(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	sender, err := w.NewAccount()
+	require.NoError(err)
+
+	contract := eth.NewRandomAddress(rng)
+	tx := tee.BatchTransaction{
+		Nonce:  uint64(rng.Int63()),
+		Epoch:  uint64(rng.Int63()),
+		Sender: sender.Account.Address,
+		Fee:    (*tee.Amount)(big.NewInt(rng.Int63())),
+	}
+	for i := 0; i < 3; i++ {
+		tx.Payments = append(tx.Payments, tee.Payment{
+			Recipient: eth.NewRandomAddress(rng),
+			Amount:    (*tee.Amount)(big.NewInt(rng.Int63())),
+		})
+	}
+
+	require.NoError(tx.Sign(contract, sender.Account, hdw))
+	ok, err := tee.VerifyBatchTransaction(contract, tx)
+	require.NoError(err)
+	require.True(ok)
+
+	wiretest.GenericJSONMarshallingTest(t, tx, &tee.BatchTransaction{})
+
+	tx.Payments[1].Recipient = eth.NewRandomAddress(rng)
+	ok, err = tee.VerifyBatchTransaction(contract, tx)
+	require.NoError(err)
+	require.False(ok)
+}
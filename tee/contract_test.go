@@ -82,6 +82,7 @@ func deployErdstall(ctx context.Context, params *tee.Parameters, cl *eth.Client)
 	if err != nil {
 		return nil, fmt.Errorf("creating transactor: %w", err)
 	}
+	tr.GasLimit = 0 // Estimated, deploying needs more than DefaultGasLimit.
 
 	address, tx, contract, err := bindings.DeployErdstall(tr, cl.ContractInterface,
 		params.TEE,
@@ -13,14 +13,24 @@ var (
 	abiUint256, _ = abi.NewType("uint256", "", nil)
 	abiUint64, _  = abi.NewType("uint64", "", nil)
 	abiAddress, _ = abi.NewType("address", "", nil)
+	abiBytes32, _ = abi.NewType("bytes32", "", nil)
 	abiString, _  = abi.NewType("string", "", nil)
+
+	abiUint256Arr, _ = abi.NewType("uint256[]", "", nil)
+	abiAddressArr, _ = abi.NewType("address[]", "", nil)
 )
 
 func EncodeDepositProof(contract common.Address, balance Balance) ([]byte, error) {
+	if !balance.IsETH() {
+		return encodeTokenBalance("ErdstallTokenDeposit", contract, balance)
+	}
 	return encodeBalance("ErdstallDeposit", contract, balance)
 }
 
 func EncodeBalanceProof(contract common.Address, balance Balance) ([]byte, error) {
+	if !balance.IsETH() {
+		return encodeTokenBalance("ErdstallTokenBalance", contract, balance)
+	}
 	return encodeBalance("ErdstallBalance", contract, balance)
 }
 
@@ -40,6 +50,62 @@ func encodeBalance(tag string, contract common.Address, balance Balance) ([]byte
 	)
 }
 
+// encodeTokenBalance encodes token balances like the contract's
+// encodeTokenBalanceProof.
+func encodeTokenBalance(tag string, contract common.Address, balance Balance) ([]byte, error) {
+	return abi.Arguments{
+		{Type: abiString},  // tag
+		{Type: abiAddress}, // contract
+		{Type: abiUint64},  // epoch
+		{Type: abiAddress}, // account
+		{Type: abiAddress}, // token
+		{Type: abiUint256}, // balance
+	}.Pack(
+		tag,
+		contract,
+		balance.Epoch,
+		balance.Account,
+		balance.Token,
+		(*big.Int)(balance.Value),
+	)
+}
+
+// EncodeExitRequest abi-encodes an off-chain exit request. Like transactions,
+// it is never used on-chain and should only be used for signing purposes.
+func EncodeExitRequest(contract common.Address, req ExitRequest) ([]byte, error) {
+	return abi.Arguments{
+		{Type: abiString},  // tag
+		{Type: abiAddress}, // contract
+		{Type: abiUint64},  // epoch
+		{Type: abiAddress}, // account
+		{Type: abiAddress}, // token
+		{Type: abiUint256}, // value
+	}.Pack(
+		"ErdstallExitRequest",
+		contract,
+		req.Epoch,
+		req.Account,
+		req.Token,
+		(*big.Int)(req.Value),
+	)
+}
+
+// EncodeSubscribeRequest abi-encodes a subscribe request. It is never used
+// on-chain and should only be used for signing purposes.
+func EncodeSubscribeRequest(contract common.Address, req SubscribeRequest) ([]byte, error) {
+	return abi.Arguments{
+		{Type: abiString},  // tag
+		{Type: abiAddress}, // contract
+		{Type: abiAddress}, // who
+		{Type: abiBytes32}, // challenge
+	}.Pack(
+		"ErdstallSubscribe",
+		contract,
+		req.Who,
+		req.Challenge,
+	)
+}
+
 // EncodeTransaction abi-encodes an off-chain transaction. We use abi encoding
 // for consistency even though this message is never used on-chain.
 // Should only be used for signing purposes.
@@ -51,7 +117,9 @@ func EncodeTransaction(contract common.Address, tx Transaction) ([]byte, error)
 		{Type: abiUint64},  // epoch
 		{Type: abiAddress}, // sender
 		{Type: abiAddress}, // recipient
+		{Type: abiAddress}, // token
 		{Type: abiUint256}, // amount
+		{Type: abiUint256}, // fee
 	}.Pack(
 		"ErdstallTransaction",
 		contract,
@@ -59,6 +127,68 @@ func EncodeTransaction(contract common.Address, tx Transaction) ([]byte, error)
 		tx.Epoch,
 		tx.Sender,
 		tx.Recipient,
+		tx.Token,
 		(*big.Int)(tx.Amount),
+		tx.FeeValue(),
+	)
+}
+
+func EncodeBatchTransaction(contract common.Address, tx BatchTransaction) ([]byte, error) {
+	recipients := make([]common.Address, len(tx.Payments))
+	amounts := make([]*big.Int, len(tx.Payments))
+	for i, p := range tx.Payments {
+		recipients[i], amounts[i] = p.Recipient, (*big.Int)(p.Amount)
+	}
+	return abi.Arguments{
+		{Type: abiString},     // tag
+		{Type: abiAddress},    // contract
+		{Type: abiUint64},     // nonce
+		{Type: abiUint64},     // epoch
+		{Type: abiAddress},    // sender
+		{Type: abiAddress},    // token
+		{Type: abiAddressArr}, // recipients
+		{Type: abiUint256Arr}, // amounts
+		{Type: abiUint256},    // fee
+	}.Pack(
+		"ErdstallBatchTransaction",
+		contract,
+		tx.Nonce,
+		tx.Epoch,
+		tx.Sender,
+		tx.Token,
+		recipients,
+		amounts,
+		tx.FeeValue(),
+	)
+}
+
+// EncodeHTLC abi-encodes an off-chain hash-time-locked payment. Like
+// transactions, it is never used on-chain and should only be used for signing
+// purposes.
+func EncodeHTLC(contract common.Address, h HTLC) ([]byte, error) {
+	return abi.Arguments{
+		{Type: abiString},  // tag
+		{Type: abiAddress}, // contract
+		{Type: abiUint64},  // nonce
+		{Type: abiUint64},  // epoch
+		{Type: abiAddress}, // sender
+		{Type: abiAddress}, // recipient
+		{Type: abiAddress}, // token
+		{Type: abiUint256}, // amount
+		{Type: abiBytes32}, // hash lock
+		{Type: abiUint64},  // expiry
+		{Type: abiUint256}, // fee
+	}.Pack(
+		"ErdstallHTLC",
+		contract,
+		h.Nonce,
+		h.Epoch,
+		h.Sender,
+		h.Recipient,
+		h.Token,
+		(*big.Int)(h.Amount),
+		h.HashLock,
+		h.Expiry,
+		h.FeeValue(),
 	)
 }
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// An ExitRequest requests an exit proof for Value of Account's balance of
+// Token in the exit epoch Epoch. It is signed by the Account.
+//
+// The Enclave answers with a balance proof over the requested Value, which
+// the Account can use to exit Value on-chain while keeping the rest of its
+// balance in the system.
+type ExitRequest struct {
+	Epoch   Epoch          `json:"epoch"`
+	Account common.Address `json:"account"`
+	Token   common.Address `json:"token"` // ETHToken for ETH exits
+	Value   *Amount        `json:"value"`
+	Sig     Sig            `json:"sig"`
+}
+
+// Sign signs the exit request with the given account and signer. It checks
+// that the account matches the request's account.
+func (r *ExitRequest) Sign(contract common.Address, account accounts.Account, w TextSigner) error {
+	if account.Address != r.Account {
+		return errors.New("not the requesting account")
+	}
+	msg, err := EncodeExitRequest(contract, *r)
+	if err != nil {
+		return fmt.Errorf("encoding exit request: %w", err)
+	}
+	hash := crypto.Keccak256Hash(msg)
+	sig, err := w.SignText(account, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing exit request hash: %w", err)
+	}
+	sig[64] += 27
+
+	r.Sig = sig
+	return nil
+}
+
+// Balance returns the balance that the exit proof of the request states.
+func (r *ExitRequest) Balance() Balance {
+	return Balance{
+		Epoch:   r.Epoch,
+		Account: r.Account,
+		Token:   r.Token,
+		Value:   r.Value,
+	}
+}
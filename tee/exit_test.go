@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+	wiretest "github.com/perun-network/erdstall/wire/test"
+)
+
+func TestExitRequest_SignVerify(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	acc, err := w.NewAccount()
+	require.NoError(err)
+
+	contract := eth.NewRandomAddress(rng)
+	req := tee.ExitRequest{
+		Epoch:   tee.Epoch(rng.Uint64()),
+		Account: acc.Account.Address,
+		Token:   eth.NewRandomAddress(rng),
+		Value:   (*tee.Amount)(big.NewInt(rng.Int63())),
+	}
+
+	other := req
+	other.Account = eth.NewRandomAddress(rng)
+	require.Error(other.Sign(contract, acc.Account, hdw))
+
+	require.NoError(req.Sign(contract, acc.Account, hdw))
+	ok, err := tee.VerifyExitRequest(contract, req)
+	require.NoError(err)
+	require.True(ok)
+
+	req.Value = (*tee.Amount)(new(big.Int).Add((*big.Int)(req.Value), big.NewInt(1)))
+	ok, err = tee.VerifyExitRequest(contract, req)
+	require.NoError(err)
+	require.False(ok)
+
+	wiretest.GenericJSONMarshallingTest(t, req, &tee.ExitRequest{})
+}
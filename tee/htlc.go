@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type (
+	// HTLC is a hash-time-locked conditional payment of Token from Sender to
+	// Recipient, signed by the Sender. The Amount is locked until the Recipient
+	// claims it by revealing the preimage of HashLock until the end of the
+	// transaction epoch Expiry. Afterwards, the Sender can refund it.
+	//
+	// Locking uses up one of the Sender's nonces, like a Transaction. Open
+	// locks are part of the Sender's balance proofs, so a frozen contract pays
+	// them back to the Sender.
+	HTLC struct {
+		Nonce     uint64         `json:"nonce"` // shared with Transaction nonces
+		Epoch     Epoch          `json:"epoch"`
+		Sender    common.Address `json:"sender"`
+		Recipient common.Address `json:"recipient"`
+		Token     common.Address `json:"token"` // ETHToken for ETH locks
+		Amount    *Amount        `json:"amount"`
+		HashLock  common.Hash    `json:"hashLock"` // SHA-256 of the preimage
+		Expiry    Epoch          `json:"expiry"`   // last transaction epoch to claim
+		Fee       *Amount        `json:"fee"`      // in ETH, nil for no fee
+		Sig       Sig            `json:"sig"`
+	}
+
+	// HTLCClaim claims the lock with the given ID by revealing its preimage.
+	// The amount is always paid to the lock's recipient, so anyone knowing the
+	// preimage can claim.
+	HTLCClaim struct {
+		ID       common.Hash `json:"id"`
+		Preimage common.Hash `json:"preimage"`
+	}
+
+	// HTLCRefund refunds the expired lock with the given ID to its sender.
+	HTLCRefund struct {
+		ID common.Hash `json:"id"`
+	}
+
+	// HTLCOp is an operation on hash-time-locked payments. Exactly one of its
+	// fields is set.
+	HTLCOp struct {
+		Lock   *HTLC
+		Claim  *HTLCClaim
+		Refund *HTLCRefund
+	}
+)
+
+// HashLock returns the hash lock of the given preimage.
+func HashLock(preimage common.Hash) common.Hash {
+	return sha256.Sum256(preimage[:])
+}
+
+// ID returns the lock's ID, which is derived from its sender, nonce and epoch.
+// The epoch keeps IDs unique when nonces restart after an account was
+// removed.
+func (h *HTLC) ID() common.Hash {
+	nonce, epoch := make([]byte, 8), make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, h.Nonce)
+	binary.BigEndian.PutUint64(epoch, uint64(h.Epoch))
+	return crypto.Keccak256Hash(h.Sender.Bytes(), nonce, epoch)
+}
+
+// FeeValue returns the lock's fee, zero if it has no fee.
+func (h *HTLC) FeeValue() *big.Int {
+	if h.Fee == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(h.Fee)
+}
+
+// Clone returns a deep copy of the lock.
+func (h *HTLC) Clone() *HTLC {
+	c := *h
+	c.Amount = (*Amount)(new(big.Int).Set((*big.Int)(h.Amount)))
+	if h.Fee != nil {
+		c.Fee = (*Amount)(new(big.Int).Set((*big.Int)(h.Fee)))
+	}
+	c.Sig = append(Sig(nil), h.Sig...)
+	return &c
+}
+
+// Sign signs the lock with the given account and signer. It checks that the
+// account matches the lock's sender.
+func (h *HTLC) Sign(contract common.Address, account accounts.Account, w TextSigner) error {
+	if account.Address != h.Sender {
+		return errors.New("not Sender's account")
+	}
+	msg, err := EncodeHTLC(contract, *h)
+	if err != nil {
+		return fmt.Errorf("encoding htlc: %w", err)
+	}
+	hash := crypto.Keccak256Hash(msg)
+	sig, err := w.SignText(account, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing htlc hash: %w", err)
+	}
+	sig[64] += 27
+
+	h.Sig = sig
+	return nil
+}
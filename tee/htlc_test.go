@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+	wiretest "github.com/perun-network/erdstall/wire/test"
+)
+
+func TestHTLC_SignVerify(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	acc, err := w.NewAccount()
+	require.NoError(err)
+
+	contract := eth.NewRandomAddress(rng)
+	h := tee.HTLC{
+		Nonce:     rng.Uint64(),
+		Epoch:     tee.Epoch(rng.Uint32()),
+		Sender:    acc.Account.Address,
+		Recipient: eth.NewRandomAddress(rng),
+		Token:     eth.NewRandomAddress(rng),
+		Amount:    (*tee.Amount)(big.NewInt(rng.Int63())),
+		HashLock:  tee.HashLock(common.Hash{1}),
+		Fee:       (*tee.Amount)(big.NewInt(rng.Int63())),
+	}
+	h.Expiry = h.Epoch + 3
+
+	require.NoError(h.Sign(contract, acc.Account, hdw))
+	ok, err := tee.VerifyHTLC(contract, h)
+	require.NoError(err)
+	require.True(ok)
+
+	other := h
+	other.HashLock = tee.HashLock(common.Hash{2})
+	ok, err = tee.VerifyHTLC(contract, other)
+	require.NoError(err)
+	require.False(ok)
+
+	other = h
+	other.Nonce++
+	require.NotEqual(h.ID(), other.ID())
+	other = h
+	other.Epoch++
+	require.NotEqual(h.ID(), other.ID())
+
+	wiretest.GenericJSONMarshallingTest(t, h, &tee.HTLC{})
+}
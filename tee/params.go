@@ -2,15 +2,66 @@
 
 package tee
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type Parameters struct {
-	PowDepth         uint64         // required confirmed block depth
-	PhaseDuration    uint64         // number of blocks of one phase (not epoch)
-	ResponseDuration uint64         // challenge response grace period for operator at end of exit phase
-	InitBlock        uint64         // block at which Erdstall contract was deployed
-	TEE              common.Address // Enclave's public key address
-	Contract         common.Address // Erdstall contract address
+	PowDepth         uint64           // required confirmed block depth
+	PhaseDuration    uint64           // number of blocks of one phase (not epoch)
+	ResponseDuration uint64           // challenge response grace period for operator at end of exit phase
+	InitBlock        uint64           // block at which Erdstall contract was deployed
+	TEE              common.Address   // Enclave's public key address
+	Contract         common.Address   // Erdstall contract address
+	Consensus        ConsensusEngine  // consensus engine whose rules the enclave enforces
+	ChainID          uint64           // chain ID of the blockchain, selects the ethash chain config
+	CliqueSigners    []common.Address // initial clique signers, required for clique
+	CliquePeriod     uint64           // minimal clique block period in seconds
+	FeeFlat          uint64           // flat fee in wei per transaction
+	FeeRate          uint64           // fee of ETH transfers in basis points of the amount
+	FeeCollector     common.Address   // account that collects the transaction fees
+}
+
+// FeeRateBase is the base of Parameters.FeeRate, so that a rate of 1 is one
+// basis point of the transferred amount.
+const FeeRateBase = 10000
+
+// ConsensusEngine identifies the consensus engine of the underlying blockchain.
+type ConsensusEngine string
+
+const (
+	NoConsensus     ConsensusEngine = ""       // block headers are not verified
+	EthashConsensus ConsensusEngine = "ethash" // proof-of-work
+	CliqueConsensus ConsensusEngine = "clique" // proof-of-authority
+)
+
+// Equal returns whether both parameters are equal. Empty and nil signer lists
+// are equal.
+func (p Parameters) Equal(q Parameters) bool {
+	if len(p.CliqueSigners) == 0 && len(q.CliqueSigners) == 0 {
+		p.CliqueSigners, q.CliqueSigners = nil, nil
+	}
+	return reflect.DeepEqual(p, q)
+}
+
+// TxFee returns the minimal fee in wei of a transaction of amount token. Fees
+// are always paid in ETH, the proportional fee only applies to ETH transfers.
+func (p Parameters) TxFee(token common.Address, amount *big.Int) *big.Int {
+	fee := new(big.Int).SetUint64(p.FeeFlat)
+	if token == ETHToken && p.FeeRate != 0 {
+		prop := new(big.Int).Mul(amount, new(big.Int).SetUint64(p.FeeRate))
+		fee.Add(fee, prop.Div(prop, big.NewInt(FeeRateBase)))
+	}
+	return fee
+}
+
+// BatchFee returns the minimal fee in wei of a batch transaction of token with
+// the given total amount. A batch pays the flat fee only once.
+func (p Parameters) BatchFee(token common.Address, total *big.Int) *big.Int {
+	return p.TxFee(token, total)
 }
 
 // DepositEpoch returns the deposit epoch at the given block number.
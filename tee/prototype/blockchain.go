@@ -13,62 +13,159 @@ import (
 
 // Blockchain keeps track of the blockchain and ensures the integrity of new
 // blocks entered in to the enclave.
+//
+// It keeps a window of the depth+1 most recent blocks, so that chain
+// reorganizations of up to depth blocks can be followed.
 type blockchain struct {
-	head *tee.Block
+	head      *tee.Block
+	recent    []*tee.Block      // Reorg window, oldest first, head last.
+	depth     uint64            // Maximal reorg depth.
+	consensus ConsensusVerifier // Header verifier, nil: no verification.
+}
+
+// ConsensusState returns the state of the consensus verifier, the zero state
+// if headers are not verified.
+func (b *blockchain) ConsensusState() ConsensusState {
+	if b.consensus == nil {
+		return ConsensusState{}
+	}
+	return b.consensus.State()
+}
+
+// SetConsensusState sets the state of the consensus verifier, e.g., when
+// reverting blocks.
+func (b *blockchain) SetConsensusState(s ConsensusState) {
+	if b.consensus != nil {
+		b.consensus.SetState(s)
+	}
 }
 
 // Head returns the latest verified and processed block or nil.
 func (b *blockchain) Head() *tee.Block { return b.head }
 func (b *blockchain) empty() bool      { return b.head == nil }
 
+// Contains tells whether the block with the given hash is part of the reorg
+// window.
+func (b *blockchain) Contains(hash common.Hash) bool {
+	for _, block := range b.recent {
+		if block.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// RevertPath returns all blocks that have to be reverted so that the block
+// with the given hash becomes the new head, newest first, and that block. It
+// returns an error if the block is not part of the reorg window.
+func (b *blockchain) RevertPath(hash common.Hash) ([]*tee.Block, *tee.Block, error) {
+	var path []*tee.Block
+	for i := len(b.recent) - 1; i >= 0; i-- {
+		if b.recent[i].Hash() == hash {
+			return path, b.recent[i], nil
+		}
+		path = append(path, b.recent[i])
+	}
+	return nil, nil, fmt.Errorf("reorg deeper than %d blocks, fork point %x unknown", b.depth, hash)
+}
+
+// Rewind removes the n most recent blocks from the chain. The caller has to
+// ensure that n is smaller than the size of the reorg window, e.g., by
+// calling RevertPath first.
+func (b *blockchain) Rewind(n int) {
+	if n >= len(b.recent) {
+		panic("rewinding beyond the reorg window")
+	}
+	b.recent = b.recent[:len(b.recent)-n]
+	b.head = b.recent[len(b.recent)-1]
+}
+
 // PushVerify pushes the block onto the chain, verifying that it is indeed a
-// valid successor block of the previous head. If the block is valid, returns
-// all of the block's deposit and exit events. If the block is invalid, the
-// chain remains unchanged.
+// valid successor block of the previous head and that its receipts are proven
+// by the block header. If the block is valid, returns
+// all of the block's deposit and exit events and updates the consensus state.
+// If the block is invalid, the chain remains unchanged.
 func (b *blockchain) PushVerify(
 	block *tee.Block,
 	params *tee.Parameters,
-	epoch *Epoch,
 ) ([]*erdstallDepEvent, []*erdstallExitEvent, error) {
-	if !b.empty() {
-		if err := verifySuccessorBlock(block, b.head); err != nil {
-			return nil, nil, fmt.Errorf("verifying successor block: %v", err)
+	deps, exits, consensus, err := b.Verify(block, b.head, b.ConsensusState(), params)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.Push(block, consensus)
+	return deps, exits, nil
+}
+
+// Verify verifies that the block is a valid successor of parent, given the
+// consensus state after parent, and that its receipts are proven by the block
+// header. parent is nil for the first block of the chain. If the block is
+// valid, returns all of the block's deposit and exit events and the consensus
+// state after the block. The chain is not changed.
+func (b *blockchain) Verify(
+	block, parent *tee.Block,
+	consensus ConsensusState,
+	params *tee.Parameters,
+) ([]*erdstallDepEvent, []*erdstallExitEvent, ConsensusState, error) {
+	if parent != nil {
+		var err error
+		if consensus, err = verifySuccessorBlock(block, parent, b.consensus, consensus); err != nil {
+			return nil, nil, ConsensusState{}, fmt.Errorf("verifying successor block: %v", err)
 		}
 	}
 
+	if err := block.VerifyReceipts(params.Contract); err != nil {
+		return nil, nil, ConsensusState{}, fmt.Errorf("verifying receipts: %w", err)
+	}
+
 	deps, exits, err := extractEvents(block, params)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid block events: %w", err)
+		return nil, nil, ConsensusState{}, fmt.Errorf("invalid block events: %w", err)
 	}
 
 	blockN := block.NumberU64()
 	depEpoch, exitEpoch := params.DepositEpoch(blockN), params.ExitEpoch(blockN)
 
 	if err = verifyDeposits(deps, depEpoch); err != nil {
-		return nil, nil, fmt.Errorf("invalid deposits: %w", err)
-	} else if err = verifyExits(exits, exitEpoch, epoch); err != nil {
-		return nil, nil, fmt.Errorf("invalid exits: %w", err)
+		return nil, nil, ConsensusState{}, fmt.Errorf("invalid deposits: %w", err)
+	} else if err = verifyExits(exits, exitEpoch); err != nil {
+		return nil, nil, ConsensusState{}, fmt.Errorf("invalid exits: %w", err)
 	}
+	return deps, exits, consensus, nil
+}
 
+// Push makes a block that was verified by Verify against the current head the
+// new head and sets the consensus state after it.
+func (b *blockchain) Push(block *tee.Block, consensus ConsensusState) {
+	b.SetConsensusState(consensus)
 	b.head = block
-	return deps, exits, err
+	b.recent = append(b.recent, block)
+	if uint64(len(b.recent)) > b.depth+1 {
+		b.recent = b.recent[1:]
+	}
 }
 
-// verifySuccessorBlock verifies that next is a valid successor of head.
-func verifySuccessorBlock(next, head *tee.Block) error {
+// verifySuccessorBlock verifies that next is a valid successor of head. If a
+// consensus verifier is given, next's header is also verified by it, given the
+// consensus state after head, and the state after next is returned.
+func verifySuccessorBlock(next, head *tee.Block, consensus ConsensusVerifier, state ConsensusState) (ConsensusState, error) {
 	nextHash, headHash := next.Header().ParentHash, head.Hash()
 	nextN, headN := next.NumberU64(), head.NumberU64()
 
-	// TODO: Extend validation to test consensus (PoW).
-	//   This function then probably becomes a method on nextchain after we add a
-	//   consensus engine to nextchain.
 	switch {
 	case nextN != headN+1:
-		return fmt.Errorf("next is not successor, head: %d, next: %d", headN, nextN)
+		return ConsensusState{}, fmt.Errorf("next is not successor, head: %d, next: %d", headN, nextN)
 	case nextHash != headHash:
-		return fmt.Errorf("head header mismatch, expected %x, got: %x", nextHash, headHash)
+		return ConsensusState{}, fmt.Errorf("head header mismatch, expected %x, got: %x", nextHash, headHash)
 	}
-	return nil
+	if consensus == nil {
+		return ConsensusState{}, nil
+	}
+	s, err := consensus.VerifyHeader(next.Header(), head.Header(), state)
+	if err != nil {
+		return ConsensusState{}, fmt.Errorf("%s consensus: %w", consensus.Engine(), err)
+	}
+	return s, nil
 }
 
 // extractEvents extracts all deposit and exit events from a block.
@@ -130,16 +227,16 @@ func verifyDeposits(
 	return nil
 }
 
+// verifyExits verifies the epochs of the exits. Their values are not checked
+// against the current balances, because the contract accepts any valid proof
+// of the exit epoch. Exits exceeding the balance are clamped when applied.
 func verifyExits(
 	exits []*erdstallExitEvent,
 	exitEpoch tee.Epoch,
-	epoch *Epoch,
 ) error {
 	for i, exit := range exits {
 		if exit.Epoch != exitEpoch {
 			return fmt.Errorf("invalid epoch %d != %d in exit[%d]", exit.Epoch, exitEpoch, i)
-		} else if exit.Value.Cmp(epoch.Balance(exit.Account)) != 0 {
-			return fmt.Errorf("balance mismatch in exit[%d]", i)
 		} // TODO: assert frozen.
 	}
 	return nil
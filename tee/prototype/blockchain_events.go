@@ -15,23 +15,29 @@ import (
 )
 
 var (
-	contractAbi, _ = abi.JSON(strings.NewReader(bindings.ErdstallABI))
-	depositedEvent = contractAbi.Events["Deposited"].ID
-	exitingEvent   = contractAbi.Events["Exiting"].ID
+	contractAbi, _      = abi.JSON(strings.NewReader(bindings.ErdstallABI))
+	depositedEvent      = contractAbi.Events["Deposited"].ID
+	exitingEvent        = contractAbi.Events["Exiting"].ID
+	tokenDepositedEvent = contractAbi.Events["TokenDeposited"].ID
+	tokenExitingEvent   = contractAbi.Events["TokenExiting"].ID
 )
 
 type (
-	// erdstallDepEvent is a generic wrapper type for `Deposited` events.
+	// erdstallDepEvent is a generic wrapper type for `Deposited` and
+	// `TokenDeposited` events. Token is tee.ETHToken for ETH deposits.
 	erdstallDepEvent struct {
 		Epoch   uint64
 		Account common.Address
+		Token   common.Address
 		Value   *big.Int
 	}
 
-	// erdstallDepEvent is wrapper type for `Exiting` events.
+	// erdstallDepEvent is wrapper type for `Exiting` and `TokenExiting` events.
+	// Token is tee.ETHToken for ETH exits.
 	erdstallExitEvent struct {
 		Epoch   uint64
 		Account common.Address
+		Token   common.Address
 		Value   *big.Int
 	}
 
@@ -41,6 +47,9 @@ type (
 // parseDepEvent parses a given `log` and returns an `erdstallEvent`.
 func parseDepEvent(l *types.Log) (*erdstallDepEvent, error) {
 	name := "Deposited"
+	if l.Topics[0] == tokenDepositedEvent {
+		name = "TokenDeposited"
+	}
 	event := new(erdstallDepEvent)
 	err := unpackLog(event, name, *l)
 	if err != nil {
@@ -52,6 +61,9 @@ func parseDepEvent(l *types.Log) (*erdstallDepEvent, error) {
 // parseExitEvent parses a given `log` and returns an `erdstallEvent`.
 func parseExitEvent(l *types.Log) (*erdstallExitEvent, error) {
 	name := "Exiting"
+	if l.Topics[0] == tokenExitingEvent {
+		name = "TokenExiting"
+	}
 	event := new(erdstallExitEvent)
 	err := unpackLog(event, name, *l)
 	if err != nil {
@@ -78,9 +90,59 @@ func unpackLog(out interface{}, event string, log types.Log) error {
 }
 
 func logIsDepositEvt(l *types.Log) bool {
-	return l.Topics[0].String() == depositedEvent.String()
+	return l.Topics[0] == depositedEvent || l.Topics[0] == tokenDepositedEvent
 }
 
 func logIsExitEvt(l *types.Log) bool {
-	return l.Topics[0].String() == exitingEvent.String()
+	return l.Topics[0] == exitingEvent || l.Topics[0] == tokenExitingEvent
+}
+
+func (ev *erdstallDepEvent) equal(o *erdstallDepEvent) bool {
+	return ev.Epoch == o.Epoch && ev.Account == o.Account && ev.Token == o.Token && ev.Value.Cmp(o.Value) == 0
+}
+
+func (ev *erdstallExitEvent) equal(o *erdstallExitEvent) bool {
+	return ev.Epoch == o.Epoch && ev.Account == o.Account && ev.Token == o.Token && ev.Value.Cmp(o.Value) == 0
+}
+
+func (b *blockEvents) empty() bool { return b.len() == 0 }
+func (b *blockEvents) len() int    { return len(b.deps) + len(b.exits) }
+
+func (b *blockEvents) clone() blockEvents {
+	return blockEvents{
+		deps:  append([]*erdstallDepEvent(nil), b.deps...),
+		exits: append([]*erdstallExitEvent(nil), b.exits...),
+	}
+}
+
+// add adds the events.
+func (b *blockEvents) add(events blockEvents) {
+	b.deps = append(b.deps, events.deps...)
+	b.exits = append(b.exits, events.exits...)
+}
+
+// remove removes the events. If any event is missing, nothing is removed and
+// an error is returned.
+func (b *blockEvents) remove(events blockEvents) error {
+	rest := b.clone()
+	for _, dep := range events.deps {
+		i := 0
+		for ; i < len(rest.deps) && !rest.deps[i].equal(dep); i++ {
+		}
+		if i == len(rest.deps) {
+			return fmt.Errorf("unknown deposit of %s", dep.Account.Hex())
+		}
+		rest.deps = append(rest.deps[:i], rest.deps[i+1:]...)
+	}
+	for _, exit := range events.exits {
+		i := 0
+		for ; i < len(rest.exits) && !rest.exits[i].equal(exit); i++ {
+		}
+		if i == len(rest.exits) {
+			return fmt.Errorf("unknown exit of %s", exit.Account.Hex())
+		}
+		rest.exits = append(rest.exits[:i], rest.exits[i+1:]...)
+	}
+	*b = rest
+	return nil
 }
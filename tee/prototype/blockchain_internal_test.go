@@ -24,7 +24,6 @@ func TestBlockchain(t *testing.T) {
 		}
 		bc     blockchain
 		params = &tee.Parameters{PhaseDuration: 10}
-		epoch  = newEpoch(0)
 	)
 
 	t.Run("empty chain", func(t *testing.T) {
@@ -34,7 +33,7 @@ func TestBlockchain(t *testing.T) {
 
 	t.Run("origin block", func(t *testing.T) {
 		ethbc.Commit()
-		deps, exits, err := bc.PushVerify(head(), params, epoch)
+		deps, exits, err := bc.PushVerify(head(), params)
 		require.Empty(deps)
 		require.Empty(exits)
 		require.NoError(err)
@@ -45,14 +44,14 @@ func TestBlockchain(t *testing.T) {
 	b1 := head()
 
 	t.Run("normal successor", func(t *testing.T) {
-		deps, exits, err := bc.PushVerify(b1, params, epoch)
+		deps, exits, err := bc.PushVerify(b1, params)
 		require.Empty(deps)
 		require.Empty(exits)
 		require.NoError(err)
 	})
 
 	t.Run("duplicate block", func(t *testing.T) {
-		_, _, err := bc.PushVerify(b1, params, epoch)
+		_, _, err := bc.PushVerify(b1, params)
 		require.Error(err)
 	})
 
@@ -62,7 +61,7 @@ func TestBlockchain(t *testing.T) {
 		h2 := head().Header()
 		h2.ParentHash[0] ^= 0x3f
 		b2 := &tee.Block{Block: *types.NewBlockWithHeader(h2)}
-		_, _, err := bc.PushVerify(b2, params, epoch)
+		_, _, err := bc.PushVerify(b2, params)
 		require.Error(err)
 		require.Equal(bc.Head(), b1)
 	})
@@ -72,16 +71,16 @@ func TestBlockchain(t *testing.T) {
 	b3 := head()
 
 	t.Run("block gap", func(t *testing.T) {
-		_, _, err := bc.PushVerify(b3, params, epoch)
+		_, _, err := bc.PushVerify(b3, params)
 		require.Error(err)
 		require.Equal(bc.Head(), b1)
-		_, _, err = bc.PushVerify(b2, params, epoch)
+		_, _, err = bc.PushVerify(b2, params)
 		require.NoError(err)
 		require.Equal(bc.Head(), b2)
 	})
 
 	t.Run("past block", func(t *testing.T) {
-		_, _, err := bc.PushVerify(b1, params, epoch)
+		_, _, err := bc.PushVerify(b1, params)
 		require.Error(err)
 		require.Equal(bc.Head(), b2)
 	})
@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+type (
+	// A ConsensusVerifier verifies that block headers follow the consensus rules
+	// of the underlying blockchain, so that the operator cannot feed fabricated
+	// blocks into the enclave.
+	ConsensusVerifier interface {
+		// Engine returns the consensus engine whose rules are verified.
+		Engine() tee.ConsensusEngine
+		// VerifyHeader verifies that header is a valid successor of parent,
+		// given the state after parent, and returns the state after header.
+		// The verifier itself is not changed, the state has to be set once the
+		// header is accepted.
+		VerifyHeader(header, parent *types.Header, state ConsensusState) (ConsensusState, error)
+		// State returns the verifier's current state.
+		State() ConsensusState
+		// SetState replaces the verifier's state, e.g., with the state of an
+		// accepted header or an earlier state when reverting headers.
+		SetState(ConsensusState)
+	}
+
+	// ConsensusState is the part of a ConsensusVerifier's state that changes
+	// with the verified headers. It is the zero value for stateless verifiers.
+	ConsensusState struct {
+		CliqueSigners []common.Address // Authorized clique signers, sorted ascending.
+	}
+
+	// EthashVerifier verifies the difficulty and proof-of-work seal of ethash
+	// block headers.
+	EthashVerifier struct {
+		config *params.ChainConfig // Chain config for the difficulty calculation.
+		engine *ethash.Ethash      // Engine for the seal verification.
+	}
+
+	// CliqueVerifier verifies that clique block headers are signed by an
+	// authorized signer. The signer set changes at every checkpoint block.
+	// Signer votes between checkpoints and the recent signer limit are not
+	// enforced.
+	CliqueVerifier struct {
+		config  params.CliqueConfig
+		signers []common.Address // Authorized signers, sorted ascending.
+	}
+)
+
+// Clique header constants, see package go-ethereum/consensus/clique.
+const (
+	cliqueExtraVanity = 32
+	cliqueExtraSeal   = crypto.SignatureLength
+	cliqueEpochLength = 30000 // Default checkpoint interval.
+)
+
+var (
+	cliqueDiffInTurn = big.NewInt(2) // Difficulty of in-turn signatures.
+	cliqueDiffNoTurn = big.NewInt(1) // Difficulty of out-of-turn signatures.
+)
+
+// newConsensusVerifier returns the verifier of the parameters' consensus
+// engine, or nil if headers are not verified. The verifier is only configured
+// by the parameters, so that the operator cannot choose, e.g., the clique
+// signers independently of them.
+func newConsensusVerifier(p tee.Parameters) (ConsensusVerifier, error) {
+	switch p.Consensus {
+	case tee.NoConsensus:
+		return nil, nil
+	case tee.EthashConsensus:
+		config, err := ethashChainConfig(p.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		return NewEthashVerifier(config, ethash.New(ethash.Config{
+			PowMode:     ethash.ModeNormal,
+			CachesInMem: 2,
+		}, nil, false)), nil
+	case tee.CliqueConsensus:
+		if len(p.CliqueSigners) == 0 {
+			return nil, errors.New("clique verifier requires initial signers")
+		}
+		return NewCliqueVerifier(params.CliqueConfig{Period: p.CliquePeriod}, p.CliqueSigners...), nil
+	default:
+		return nil, fmt.Errorf("unknown consensus engine %q", p.Consensus)
+	}
+}
+
+// ethashChainConfig returns the config of the ethash chain with the given
+// chain ID.
+func ethashChainConfig(chainID uint64) (*params.ChainConfig, error) {
+	for _, c := range []*params.ChainConfig{params.MainnetChainConfig, params.RopstenChainConfig} {
+		if c.ChainID.Uint64() == chainID {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown ethash chain ID %d", chainID)
+}
+
+// NewEthashVerifier creates an ethash verifier that calculates difficulties
+// according to the given chain config and verifies seals with the given engine.
+func NewEthashVerifier(config *params.ChainConfig, engine *ethash.Ethash) *EthashVerifier {
+	return &EthashVerifier{config: config, engine: engine}
+}
+
+// Engine returns tee.EthashConsensus.
+func (*EthashVerifier) Engine() tee.ConsensusEngine { return tee.EthashConsensus }
+
+// VerifyHeader verifies the header's fields, like its timestamp, difficulty
+// and base fee, and its proof-of-work with the ethash engine. Ethash
+// verification is stateless.
+func (v *EthashVerifier) VerifyHeader(header, parent *types.Header, _ ConsensusState) (ConsensusState, error) {
+	if err := v.engine.VerifyHeader(&parentReader{v.config, parent}, header, true); err != nil {
+		return ConsensusState{}, err
+	}
+	return ConsensusState{}, nil
+}
+
+// parentReader is a consensus.ChainHeaderReader that only knows the parent of
+// the verified header, which suffices for verifying ethash headers.
+type parentReader struct {
+	config *params.ChainConfig
+	parent *types.Header
+}
+
+var _ consensus.ChainHeaderReader = (*parentReader)(nil)
+
+func (r *parentReader) Config() *params.ChainConfig  { return r.config }
+func (r *parentReader) CurrentHeader() *types.Header { return r.parent }
+
+func (r *parentReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if hash != r.parent.Hash() || number != r.parent.Number.Uint64() {
+		return nil
+	}
+	return r.parent
+}
+
+func (r *parentReader) GetHeaderByNumber(number uint64) *types.Header {
+	return r.GetHeader(r.parent.Hash(), number)
+}
+
+func (r *parentReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	return r.GetHeader(hash, r.parent.Number.Uint64())
+}
+
+// State returns the zero state.
+func (*EthashVerifier) State() ConsensusState { return ConsensusState{} }
+
+// SetState does nothing, as ethash verification is stateless.
+func (*EthashVerifier) SetState(ConsensusState) {}
+
+// NewCliqueVerifier creates a clique verifier with the given config and initial
+// signer set. If config.Epoch is 0, the default checkpoint interval is used.
+func NewCliqueVerifier(config params.CliqueConfig, signers ...common.Address) *CliqueVerifier {
+	if config.Epoch == 0 {
+		config.Epoch = cliqueEpochLength
+	}
+	v := &CliqueVerifier{config: config}
+	v.setSigners(signers)
+	return v
+}
+
+// Engine returns tee.CliqueConsensus.
+func (*CliqueVerifier) Engine() tee.ConsensusEngine { return tee.CliqueConsensus }
+
+// Signers returns the currently authorized signers.
+func (v *CliqueVerifier) Signers() []common.Address {
+	return append([]common.Address(nil), v.signers...)
+}
+
+// State returns the current signer set.
+func (v *CliqueVerifier) State() ConsensusState {
+	return ConsensusState{CliqueSigners: v.Signers()}
+}
+
+// SetState replaces the signer set.
+func (v *CliqueVerifier) SetState(s ConsensusState) {
+	v.setSigners(s.CliqueSigners)
+}
+
+// VerifyHeader verifies the header's timestamp, difficulty and signature
+// against the signers of state. If the header is a valid checkpoint, the
+// returned state holds the checkpoint's signers, otherwise those of state.
+func (v *CliqueVerifier) VerifyHeader(header, parent *types.Header, state ConsensusState) (ConsensusState, error) {
+	number := header.Number.Uint64()
+	checkpoint := number%v.config.Epoch == 0
+
+	if header.Time < parent.Time+v.config.Period {
+		return ConsensusState{}, fmt.Errorf("timestamp %d before end of period after parent's %d", header.Time, parent.Time)
+	}
+	if len(header.Extra) < cliqueExtraVanity+cliqueExtraSeal {
+		return ConsensusState{}, errors.New("extra data too short")
+	}
+	signersBytes := header.Extra[cliqueExtraVanity : len(header.Extra)-cliqueExtraSeal]
+	if !checkpoint && len(signersBytes) != 0 {
+		return ConsensusState{}, errors.New("signer list in non-checkpoint block")
+	} else if checkpoint && (len(signersBytes) == 0 || len(signersBytes)%common.AddressLength != 0) {
+		return ConsensusState{}, errors.New("invalid checkpoint signer list")
+	}
+
+	signer, err := cliqueSigner(header)
+	if err != nil {
+		return ConsensusState{}, fmt.Errorf("recovering signer: %w", err)
+	}
+	idx := signerIndex(state.CliqueSigners, signer)
+	if idx < 0 {
+		return ConsensusState{}, fmt.Errorf("unauthorized signer %s", signer.Hex())
+	}
+	diff := cliqueDiffNoTurn
+	if number%uint64(len(state.CliqueSigners)) == uint64(idx) {
+		diff = cliqueDiffInTurn
+	}
+	if header.Difficulty == nil || header.Difficulty.Cmp(diff) != 0 {
+		return ConsensusState{}, fmt.Errorf("invalid difficulty %v, expected %v", header.Difficulty, diff)
+	}
+
+	if !checkpoint {
+		return state, nil
+	}
+	signers := make([]common.Address, len(signersBytes)/common.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], signersBytes[i*common.AddressLength:])
+	}
+	return ConsensusState{CliqueSigners: sortSigners(signers)}, nil
+}
+
+// setSigners replaces the signer set, sorting it like clique does.
+func (v *CliqueVerifier) setSigners(signers []common.Address) {
+	v.signers = sortSigners(append([]common.Address(nil), signers...))
+}
+
+// sortSigners sorts the signers in place like clique does and returns them.
+func sortSigners(signers []common.Address) []common.Address {
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i][:], signers[j][:]) < 0
+	})
+	return signers
+}
+
+// signerIndex returns the index of signer in the sorted signers, or -1.
+func signerIndex(signers []common.Address, signer common.Address) int {
+	for i, s := range signers {
+		if s == signer {
+			return i
+		}
+	}
+	return -1
+}
+
+// cliqueSigner recovers the address that signed a clique header.
+func cliqueSigner(header *types.Header) (common.Address, error) {
+	sig := header.Extra[len(header.Extra)-cliqueExtraSeal:]
+	pub, err := crypto.SigToPub(clique.SealHash(header).Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
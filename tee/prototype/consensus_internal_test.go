@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethparams "github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEthashVerifier(t *testing.T) {
+	var (
+		require = require.New(t)
+		ethbc   = backends.NewSimulatedBackend(nil, 8000000)
+		head    = func() *tee.Block {
+			b, err := ethbc.BlockByNumber(context.Background(), nil)
+			require.NoError(err)
+			return &tee.Block{Block: *b}
+		}
+		bc     = blockchain{consensus: NewEthashVerifier(ethparams.AllEthashProtocolChanges, ethash.NewFaker())}
+		params = &tee.Parameters{PhaseDuration: 10}
+	)
+
+	ethbc.Commit()
+	_, _, err := bc.PushVerify(head(), params)
+	require.NoError(err)
+
+	ethbc.Commit()
+	b := head()
+
+	t.Run("invalid difficulty", func(t *testing.T) {
+		h := b.Header()
+		h.Difficulty.Add(h.Difficulty, big.NewInt(1))
+		_, _, err := bc.PushVerify(&tee.Block{Block: *types.NewBlockWithHeader(h)}, params)
+		require.Error(err)
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		h := b.Header()
+		h.Time = bc.Head().Time()
+		_, _, err := bc.PushVerify(&tee.Block{Block: *types.NewBlockWithHeader(h)}, params)
+		require.Error(err)
+	})
+
+	t.Run("valid block", func(t *testing.T) {
+		_, _, err := bc.PushVerify(b, params)
+		require.NoError(err)
+	})
+}
+
+func TestNewConsensusVerifier(t *testing.T) {
+	require := require.New(t)
+	signer := common.Address{1}
+
+	v, err := newConsensusVerifier(tee.Parameters{})
+	require.NoError(err)
+	require.Nil(v)
+
+	_, err = newConsensusVerifier(tee.Parameters{Consensus: tee.EthashConsensus, ChainID: 1337})
+	require.Error(err, "unknown ethash chain")
+	v, err = newConsensusVerifier(tee.Parameters{Consensus: tee.EthashConsensus, ChainID: 3})
+	require.NoError(err)
+	require.Equal(ethparams.RopstenChainConfig, v.(*EthashVerifier).config)
+
+	_, err = newConsensusVerifier(tee.Parameters{Consensus: tee.CliqueConsensus})
+	require.Error(err, "missing signers")
+	v, err = newConsensusVerifier(tee.Parameters{Consensus: tee.CliqueConsensus, CliqueSigners: []common.Address{signer}, CliquePeriod: 5})
+	require.NoError(err)
+	require.Equal([]common.Address{signer}, v.(*CliqueVerifier).Signers())
+	require.Equal(uint64(5), v.(*CliqueVerifier).config.Period)
+}
+
+func TestCliqueVerifier(t *testing.T) {
+	require := require.New(t)
+	keys := make([]*ecdsa.PrivateKey, 3)
+	addrs := make([]common.Address, len(keys))
+	for i := range keys {
+		var err error
+		keys[i], err = crypto.GenerateKey()
+		require.NoError(err)
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	v := NewCliqueVerifier(ethparams.CliqueConfig{Period: 5, Epoch: 4}, addrs[0], addrs[1])
+	signers := v.Signers()
+	keyOf := func(addr common.Address) *ecdsa.PrivateKey {
+		for i, a := range addrs {
+			if a == addr {
+				return keys[i]
+			}
+		}
+		panic("unknown signer")
+	}
+
+	parent := &types.Header{Number: big.NewInt(2), Time: 100}
+	// Block 3 is in turn for signers[1].
+	header := func(signer common.Address, diff int64, extra ...common.Address) *types.Header {
+		h := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(3),
+			Time:       105,
+			Difficulty: big.NewInt(diff),
+			Extra:      make([]byte, cliqueExtraVanity),
+		}
+		for _, a := range extra {
+			h.Extra = append(h.Extra, a.Bytes()...)
+		}
+		h.Extra = append(h.Extra, make([]byte, cliqueExtraSeal)...)
+		signCliqueHeader(t, h, keyOf(signer))
+		return h
+	}
+
+	verify := func(h *types.Header) error {
+		_, err := v.VerifyHeader(h, parent, v.State())
+		return err
+	}
+
+	require.NoError(verify(header(signers[1], 2)))
+	require.NoError(verify(header(signers[0], 1)))
+
+	t.Run("invalid difficulty", func(t *testing.T) {
+		require.Error(verify(header(signers[1], 1)))
+		require.Error(verify(header(signers[0], 2)))
+	})
+
+	t.Run("unauthorized signer", func(t *testing.T) {
+		require.Error(verify(header(addrs[2], 1)))
+		// Signers are authorized by the given state, not the verifier's.
+		_, err := v.VerifyHeader(header(addrs[2], 2), parent, ConsensusState{CliqueSigners: addrs[2:3]})
+		require.NoError(err)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		h := header(signers[1], 2)
+		h.Time = parent.Time + 4
+		signCliqueHeader(t, h, keyOf(signers[1]))
+		require.Error(verify(h))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		h := header(signers[1], 2)
+		h.Root[0] ^= 1
+		require.Error(verify(h))
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		require.Error(verify(header(signers[1], 2, addrs[2])))
+
+		cp := header(signers[0], 2, addrs[2]) // Block 4 is in turn for signers[0].
+		cp.Number = big.NewInt(4)
+		signCliqueHeader(t, cp, keyOf(signers[0]))
+		state, err := v.VerifyHeader(cp, parent, v.State())
+		require.NoError(err)
+		require.Equal([]common.Address{addrs[2]}, state.CliqueSigners)
+		// Verification does not change the signers, only accepting the block does.
+		require.Equal(signers, v.Signers())
+		v.SetState(state)
+		require.Equal([]common.Address{addrs[2]}, v.Signers())
+	})
+}
+
+// signCliqueHeader writes the clique signature of key into the header's extra
+// data.
+func signCliqueHeader(t *testing.T, h *types.Header, key *ecdsa.PrivateKey) {
+	sig, err := crypto.Sign(clique.SealHash(h).Bytes(), key)
+	require.NoError(t, err)
+	copy(h.Extra[len(h.Extra)-cliqueExtraSeal:], sig)
+}
@@ -3,6 +3,7 @@
 package prototype
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -12,6 +13,7 @@ import (
 	"perun.network/go-perun/pkg/sync/atomic"
 
 	"github.com/perun-network/erdstall/tee"
+	"github.com/perun-network/erdstall/tee/attestation"
 )
 
 type (
@@ -32,9 +34,17 @@ type (
 
 		account *accounts.Account
 		wallet  accounts.Wallet
+		quoter  attestation.Quoter // Attests the account, nil: no attestation.
 
-		chain blockchain
-		epoch *Epoch // Epoch manager, nil until first block is known.
+		chain  blockchain
+		deltas map[common.Hash]*blockDelta // State changes of revertible blocks.
+		epoch  *Epoch                      // Epoch manager, nil until first block is known.
+
+		// Blocks before unsealedFrom belong to sealed phases, whose proofs are
+		// published. If such blocks are reverted, their events are kept in
+		// orphaned until the new branch contains them again.
+		unsealedFrom uint64
+		orphaned     blockEvents
 
 		// Incoming commands are queued here to be executed in order.
 		commands chan command
@@ -46,6 +56,8 @@ type (
 		// cache
 		depositProofCache []*tee.DepositProof // Accumulated until phase shift.
 
+		persistence persistence // Sealed state snapshots.
+
 		// Running/stopping
 		shutdownRequested bool // User requested shutdown.
 		shutdownApproved  bool // Enclave wants to shut down.
@@ -66,6 +78,7 @@ func NewEnclave(wallet accounts.Wallet) *Enclave {
 	return &Enclave{
 		wallet:        wallet,
 		chain:         blockchain{},
+		deltas:        make(map[common.Hash]*blockDelta),
 		commands:      make(chan command, enclaveMaxCommandQueue),
 		depositProofs: make(chan []*tee.DepositProof, 1),
 		balanceProofs: make(chan []*tee.BalanceProof, 1),
@@ -79,10 +92,27 @@ func NewEnclaveWithAccount(wallet accounts.Wallet, account accounts.Account) *En
 	return e
 }
 
+// SetQuoter sets the quoter that attests the enclave's signing address in
+// Init. If no quoter is set, Init returns no attestation.
+//
+// Must be called before Init.
+func (e *Enclave) SetQuoter(q attestation.Quoter) {
+	if e.running.IsSet() {
+		log.Panic("SetQuoter called on running Enclave")
+	}
+	e.quoter = q
+}
+
 func (e *Enclave) BlockNum() uint64 {
 	return e.chain.Head().NumberU64()
 }
 
+// CommandQueue returns the number of commands waiting in the command queue
+// and its capacity enclaveMaxCommandQueue.
+func (e *Enclave) CommandQueue() (depth, capacity int) {
+	return len(e.commands), cap(e.commands)
+}
+
 func (e *Enclave) IsAtPhaseEnd() bool {
 	if e.chain.empty() {
 		return false
@@ -109,9 +139,30 @@ func (e *Enclave) mainLoop() (err error) {
 		case *processTxsCmd:
 			errs := make([]error, len(cmd.txs))
 			for i, tx := range cmd.txs {
-				errs[i] = e.epoch.ProcessTx(e.Params.Contract, tx)
+				errs[i] = e.epoch.ProcessTx(e.Params, tx)
+			}
+			cmd.result <- errs
+		case *processBatchTxsCmd:
+			errs := make([]error, len(cmd.txs))
+			for i, tx := range cmd.txs {
+				errs[i] = e.epoch.ProcessBatchTx(e.Params, tx)
 			}
 			cmd.result <- errs
+		case *processHTLCsCmd:
+			errs := make([]error, len(cmd.ops))
+			for i, op := range cmd.ops {
+				errs[i] = e.epoch.ProcessHTLCOp(e.Params, op)
+			}
+			cmd.result <- errs
+		case *exitProofCmd:
+			proof, err := e.exitProof(cmd.req)
+			cmd.result <- exitProofResult{proof: proof, err: err}
+		case *accountCmd:
+			acc, err := e.accountState(cmd.who)
+			cmd.result <- accountResult{acc: acc, err: err}
+		case *lastBlockCmd:
+			num, known := e.lastBlock()
+			cmd.result <- lastBlockResult{num: num, known: known}
 		case *shutdownCmd:
 			e.shutdownRequested = true
 		default:
@@ -141,6 +192,9 @@ func (e *Enclave) ProcessBlocks(blocks ...*tee.Block) error {
 		case errs := <-errCh:
 			errg := perrors.NewGatherer()
 			for _, e := range errs {
+				if errors.Is(e, tee.ErrSealedEventsDropped) {
+					return e // Not gathered, so that the operator can detect it.
+				}
 				errg.Add(e)
 			}
 			return errg.Err()
@@ -179,6 +233,125 @@ func (e *Enclave) ProcessTXs(txs ...*tee.Transaction) error {
 	}
 }
 
+// ProcessBatchTXs is the equivalent of ProcessTXs for batch transactions. Each
+// batch is applied atomically, invalid batches are ignored without affecting
+// the others. Returns the accumulated error messages.
+func (e *Enclave) ProcessBatchTXs(txs ...*tee.BatchTransaction) error {
+	if e.shutdownApproved {
+		return tee.ErrEnclaveStopped
+	}
+
+	errCh := make(chan []error, 1)
+	select {
+	case e.commands <- &processBatchTxsCmd{txs: txs, result: errCh}:
+		select {
+		case errs := <-errCh:
+			errg := perrors.NewGatherer()
+			for _, e := range errs {
+				errg.Add(e)
+			}
+			return errg.Err()
+		case <-e.stopped:
+			return tee.ErrEnclaveStopped
+		}
+	case <-e.stopped:
+		return tee.ErrEnclaveStopped
+	}
+}
+
+// ProcessHTLCs processes operations on hash-time-locked payments, i.e., locks,
+// claims and refunds. Invalid operations are ignored without affecting the
+// others. Returns the accumulated error messages.
+func (e *Enclave) ProcessHTLCs(ops ...*tee.HTLCOp) error {
+	if e.shutdownApproved {
+		return tee.ErrEnclaveStopped
+	}
+
+	errCh := make(chan []error, 1)
+	select {
+	case e.commands <- &processHTLCsCmd{ops: ops, result: errCh}:
+		select {
+		case errs := <-errCh:
+			errg := perrors.NewGatherer()
+			for _, e := range errs {
+				errg.Add(e)
+			}
+			return errg.Err()
+		case <-e.stopped:
+			return tee.ErrEnclaveStopped
+		}
+	case <-e.stopped:
+		return tee.ErrEnclaveStopped
+	}
+}
+
+// ExitProof returns a balance proof over the value of the given exit request,
+// which lets the user exit only a part of their balance. The request's epoch
+// must be the current exit epoch and its value must not exceed the user's
+// balance of that epoch.
+func (e *Enclave) ExitProof(req *tee.ExitRequest) (*tee.BalanceProof, error) {
+	if e.shutdownApproved {
+		return nil, tee.ErrEnclaveStopped
+	}
+
+	resCh := make(chan exitProofResult, 1)
+	select {
+	case e.commands <- &exitProofCmd{req: req, result: resCh}:
+		select {
+		case res := <-resCh:
+			return res.proof, res.err
+		case <-e.stopped:
+			return nil, tee.ErrEnclaveStopped
+		}
+	case <-e.stopped:
+		return nil, tee.ErrEnclaveStopped
+	}
+}
+
+// Account returns the current state of the given account in the running
+// transaction epoch. Unknown accounts have a zero balance and nonce.
+func (e *Enclave) Account(who common.Address) (*tee.Account, error) {
+	if e.shutdownApproved {
+		return nil, tee.ErrEnclaveStopped
+	}
+
+	resCh := make(chan accountResult, 1)
+	select {
+	case e.commands <- &accountCmd{who: who, result: resCh}:
+		select {
+		case res := <-resCh:
+			return res.acc, res.err
+		case <-e.stopped:
+			return nil, tee.ErrEnclaveStopped
+		}
+	case <-e.stopped:
+		return nil, tee.ErrEnclaveStopped
+	}
+}
+
+// LastBlock returns the number of the last block that the enclave processed
+// or resumed from its snapshot, and whether it knows any block. The operator
+// can continue feeding blocks after it. It blocks until Run has restored the
+// snapshot, if any.
+func (e *Enclave) LastBlock() (num uint64, known bool, err error) {
+	if e.shutdownApproved {
+		return 0, false, tee.ErrEnclaveStopped
+	}
+
+	resCh := make(chan lastBlockResult, 1)
+	select {
+	case e.commands <- &lastBlockCmd{result: resCh}:
+		select {
+		case res := <-resCh:
+			return res.num, res.known, nil
+		case <-e.stopped:
+			return 0, false, tee.ErrEnclaveStopped
+		}
+	case <-e.stopped:
+		return 0, false, tee.ErrEnclaveStopped
+	}
+}
+
 // Shutdown lets the Enclave gracefully shutdown after the next phase is sealed. It
 // will continue receiving transactions and blocks until the last block of the
 // current phase is received via ProcessBlocks.
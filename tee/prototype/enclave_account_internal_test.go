@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_AccountState(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, _ := newSnapshotEnclave(t, rng)
+	alice, token := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	_, err := enc.accountState(alice)
+	require.Error(err, "no epoch")
+
+	enc.epoch = newEpoch(3)
+	enc.epoch.accs[alice] = &Acc{Nonce: 5, Value: big.NewInt(100), Tokens: map[common.Address]*big.Int{
+		token: big.NewInt(7),
+	}}
+
+	acc, err := enc.accountState(alice)
+	require.NoError(err)
+	require.Equal(tee.Epoch(2), acc.Epoch)
+	require.Equal(uint64(5), acc.Nonce)
+	require.Zero((*big.Int)(acc.Value).Cmp(big.NewInt(100)))
+	require.Zero((*big.Int)(acc.Tokens[token]).Cmp(big.NewInt(7)))
+
+	// The returned state is a copy.
+	(*big.Int)(acc.Tokens[token]).SetInt64(0)
+	require.Zero(enc.epoch.TokenBalance(alice, token).Cmp(big.NewInt(7)))
+
+	unknown, err := enc.accountState(eth.NewRandomAddress(rng))
+	require.NoError(err)
+	require.Zero(unknown.Nonce)
+	require.Zero((*big.Int)(unknown.Value).Sign())
+	require.Empty(unknown.Tokens)
+}
@@ -5,6 +5,7 @@ package prototype
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -29,20 +30,100 @@ type (
 		result chan<- []error
 	}
 
+	processBatchTxsCmd struct {
+		txs    []*tee.BatchTransaction
+		result chan<- []error
+	}
+
+	processHTLCsCmd struct {
+		ops    []*tee.HTLCOp
+		result chan<- []error
+	}
+
+	exitProofCmd struct {
+		req    *tee.ExitRequest
+		result chan<- exitProofResult
+	}
+
+	exitProofResult struct {
+		proof *tee.BalanceProof
+		err   error
+	}
+
+	accountCmd struct {
+		who    common.Address
+		result chan<- accountResult
+	}
+
+	accountResult struct {
+		acc *tee.Account
+		err error
+	}
+
+	lastBlockCmd struct {
+		result chan<- lastBlockResult
+	}
+
+	lastBlockResult struct {
+		num   uint64
+		known bool
+	}
+
 	shutdownCmd struct{}
 )
 
 var _ command = (*processBlocksCmd)(nil)
 var _ command = (*processTxsCmd)(nil)
+var _ command = (*processBatchTxsCmd)(nil)
+var _ command = (*processHTLCsCmd)(nil)
+var _ command = (*exitProofCmd)(nil)
+var _ command = (*accountCmd)(nil)
+var _ command = (*lastBlockCmd)(nil)
 var _ command = (*shutdownCmd)(nil)
 
-func (processBlocksCmd) command() {}
-func (processTxsCmd) command()    {}
-func (shutdownCmd) command()      {}
+type (
+	// blockDelta contains all state changes caused by a block.
+	blockDelta struct {
+		number    uint64                  // Block number.
+		events    blockEvents             // The block's events.
+		exitReqs  map[common.Address]*Acc // Exit requests before the block, nil if none.
+		consensus ConsensusState          // Consensus state before the block.
+	}
+
+	// blockEvents are the deposit and exit events of blocks.
+	blockEvents struct {
+		deps  []*erdstallDepEvent
+		exits []*erdstallExitEvent
+	}
+)
+
+// minReorgDepth is the minimal number of blocks that the enclave can revert
+// on a chain reorganization.
+const minReorgDepth = 8
+
+// reorgDepth returns the number of blocks that the enclave keeps for following
+// chain reorganizations. It is at least the PoW depth.
+func reorgDepth(p tee.Parameters) uint64 {
+	if p.PowDepth < minReorgDepth {
+		return minReorgDepth
+	}
+	return p.PowDepth
+}
+
+func (processBlocksCmd) command()   {}
+func (processTxsCmd) command()      {}
+func (processBatchTxsCmd) command() {}
+func (processHTLCsCmd) command()    {}
+func (exitProofCmd) command()       {}
+func (accountCmd) command()         {}
+func (lastBlockCmd) command()       {}
+func (shutdownCmd) command()        {}
 
 // Run starts the enclave's main loop.
 //
-// Run must be called after Init.
+// Run must be called after Init. If persistence is enabled and a snapshot
+// exists, the enclave resumes from it and only expects the blocks after the
+// snapshot's last block.
 //
 // Run can be stopped by calling Shutdown. However, Run will process blocks and
 // transactions until the current phase has finished.
@@ -54,6 +135,9 @@ func (e *Enclave) Run(params tee.Parameters) error {
 	if err := e.setParams(params); err != nil {
 		return err
 	}
+	if err := e.restoreSnapshot(); err != nil {
+		return fmt.Errorf("restoring snapshot: %w", err)
+	}
 
 	return e.mainLoop()
 }
@@ -63,7 +147,8 @@ func (e *Enclave) Run(params tee.Parameters) error {
 //
 // It returns the public key derived Ethereum address and attestation of
 // correct initialization of the enclave with the generated address. The
-// attestation can be used to verify the enclave with the TEE vendor.
+// attestation can be used to verify the enclave with the TEE vendor. It is
+// an encoded attestation.Quote, or empty if the enclave is not attested.
 //
 // The Operator must deploy the contract with the Enclave's address after
 // calling Init.
@@ -73,24 +158,39 @@ func (e *Enclave) Init() (_ common.Address, _ []byte, err error) {
 		return
 	}
 
-	if e.account != nil {
+	if e.account == nil {
+		acc, err := e.wallet.Derive(accounts.DefaultRootDerivationPath, true)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		e.account = &acc
+	}
+	if e.quoter == nil {
 		return e.account.Address, nil, nil
 	}
-	e.account = new(accounts.Account)
-	*e.account, err = e.wallet.Derive(accounts.DefaultRootDerivationPath, true)
+
+	quote, err := e.quoter.Quote(e.account.Address)
 	if err != nil {
-		return
+		return common.Address{}, nil, fmt.Errorf("creating quote: %w", err)
+	}
+	att, err := quote.Marshal()
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("encoding quote: %w", err)
 	}
-	return e.account.Address, nil, nil
+	return e.account.Address, att, nil
 }
 
-func (e *Enclave) setParams(p tee.Parameters) error {
+func (e *Enclave) setParams(p tee.Parameters) (err error) {
 	if p.TEE != e.account.Address {
 		return errors.New("tee address mismatch")
 	} else if e.params != nil {
 		return errors.New("params already set")
 	}
+	if e.chain.consensus, err = newConsensusVerifier(p); err != nil {
+		return fmt.Errorf("creating consensus verifier: %w", err)
+	}
 	e.params = &p
+	e.chain.depth = reorgDepth(p)
 	return nil
 }
 
@@ -100,6 +200,18 @@ func (e *Enclave) processBlock(block *tee.Block) error {
 		return errors.New("Enclave terminated, does not accept new blocks.")
 	}
 
+	if p := &e.persistence; p.resumed && block.NumberU64() <= p.resumeBlock {
+		// The operator may replay blocks that are already part of the snapshot.
+		// Other blocks of the reorg window's range must follow a reorg.
+		switch n := block.NumberU64(); {
+		case e.chain.Contains(block.Hash()) || n < e.chain.recent[0].NumberU64():
+			log.Tracef("Ignoring block %d already contained in snapshot.", n)
+			return nil
+		case !e.chain.Contains(block.ParentHash()):
+			return fmt.Errorf("block %d does not match resumed blocks", n)
+		}
+	}
+
 	if e.chain.empty() {
 		if n := block.NumberU64(); n > e.params.InitBlock {
 			return fmt.Errorf("first block (%d) not initial Erdstall block (%d)", n, e.params.InitBlock)
@@ -121,7 +233,7 @@ func (e *Enclave) processBlock(block *tee.Block) error {
 	if err := e.pushBlock(block); err != nil {
 		return err
 	}
-	if e.IsAtPhaseEnd() {
+	if e.IsAtPhaseEnd() && e.BlockNum() >= e.unsealedFrom {
 		log.Debug("end of phase, shifting epochs")
 
 		// Progress the epoch and enclave state.
@@ -132,35 +244,236 @@ func (e *Enclave) processBlock(block *tee.Block) error {
 
 		// Publish all balance and deposit proofs of the epoch.
 		e.balanceProofs <- e.generateBalanceProofs(outcome)
-		e.depositProofs <- e.depositProofCache
+		e.depositProofs <- e.aggregateDepositProofs(e.depositProofCache)
 		e.depositProofCache = e.depositProofCache[:0] // Clear deposit proofs.
+		e.unsealedFrom = e.BlockNum() + 1
 
 		if e.shutdownRequested {
 			e.shutdownApproved = true
-			close(e.stopped)
 		}
 	}
 
+	if e.snapshotDue() {
+		if err := e.storeSnapshot(); err != nil {
+			log.WithError(err).Error("Enclave: storing snapshot")
+		}
+	}
+	if e.shutdownApproved {
+		close(e.stopped)
+	}
+
 	return nil
 }
 
 // pushBlock pushes a new block onto the enclave's blockchain and updates the
 // enclave's state to reflect the new block. On error, the enclave remains
 // unchanged.
+//
+// If the block's parent is a known block other than the head, the chain was
+// reorganized and the enclave first reverts to the parent block. The block is
+// verified before, so that an invalid block cannot revert the current branch.
+//
+// Blocks of an already sealed phase do not change the state. Their events must
+// be those of the reverted blocks of the phase, otherwise the block is
+// rejected with tee.ErrSealedEventsDropped.
 func (e *Enclave) pushBlock(block *tee.Block) error {
-	deps, exits, err := e.chain.PushVerify(block, e.Params, e.epoch)
+	if e.chain.Contains(block.Hash()) {
+		return fmt.Errorf("block %d already known", block.NumberU64())
+	}
+
+	var rev *revert
+	parent, consensus, orphaned := e.chain.Head(), e.chain.ConsensusState(), e.orphaned
+	if hash := block.ParentHash(); e.chain.Contains(hash) && hash != parent.Hash() {
+		var err error
+		if rev, err = e.prepareRevert(hash); err != nil {
+			return fmt.Errorf("following reorg: %w", err)
+		}
+		parent, consensus, orphaned = rev.fork, rev.consensus, rev.orphaned
+	}
+	if block.NumberU64() >= e.unsealedFrom && !orphaned.empty() {
+		return fmt.Errorf("%w: %d events missing", tee.ErrSealedEventsDropped, orphaned.len())
+	}
+
+	deps, exits, next, err := e.chain.Verify(block, parent, consensus, e.Params)
 	if err != nil {
 		return fmt.Errorf("pushing block to local blockchain: %w", err)
 	}
+	events := blockEvents{deps: deps, exits: exits}
+	sealed := block.NumberU64() < e.unsealedFrom
+	if sealed {
+		orphaned = orphaned.clone()
+		if err := orphaned.remove(events); err != nil {
+			return fmt.Errorf("block %d of sealed phase: %w", block.NumberU64(), err)
+		}
+	}
 
+	if rev != nil {
+		if err := e.applyRevert(rev); err != nil {
+			return fmt.Errorf("following reorg: %w", err)
+		}
+	}
+	e.chain.Push(block, next)
 	e.State.LastBlock = block.NumberU64()
 	e.State.LastBlockHash = block.Hash()
 
+	if sealed {
+		e.orphaned = orphaned
+		e.recordDelta(block, events, nil, consensus)
+		return nil
+	}
+
 	// Cache any deposit proofs until the end of the phase.
 	e.depositProofCache = append(e.depositProofCache, e.generateDepositProofs(deps...)...)
 
 	e.epoch.ApplyDeposits(deps...)
-	e.epoch.RegisterExits(exits...)
+	exitReqs := e.epoch.RegisterExits(exits...)
 
+	e.recordDelta(block, events, exitReqs, consensus)
 	return nil
 }
+
+// revert describes how to revert all blocks after a fork point.
+type revert struct {
+	path      []*tee.Block        // Reverted blocks, newest first.
+	fork      *tee.Block          // Fork point, the head after the revert.
+	consensus ConsensusState      // Consensus state after the fork point.
+	deps      []*erdstallDepEvent // Reverted deposits of unsealed phases.
+	orphaned  blockEvents         // Orphaned events after the revert.
+}
+
+// prepareRevert prepares the revert of all blocks after the block with the
+// given hash, without changing the enclave. The events of reverted blocks of a
+// sealed phase are orphaned, as the sealed state cannot be reverted. The new
+// branch has to contain them again before the phase end.
+func (e *Enclave) prepareRevert(hash common.Hash) (*revert, error) {
+	path, fork, err := e.chain.RevertPath(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	rev := &revert{path: path, fork: fork, orphaned: e.orphaned.clone()}
+	for _, block := range path {
+		delta, ok := e.deltas[block.Hash()]
+		if !ok {
+			return nil, fmt.Errorf("no state changes of block %d recorded", block.NumberU64())
+		} else if delta.number < e.unsealedFrom {
+			rev.orphaned.add(delta.events)
+		} else {
+			rev.deps = append(rev.deps, delta.events.deps...)
+		}
+	}
+	// The oldest reverted block holds the consensus state of the fork point.
+	rev.consensus = e.deltas[path[len(path)-1].Hash()].consensus
+	return rev, nil
+}
+
+// applyRevert reverts the blocks and their state changes. On error, the
+// enclave remains unchanged.
+func (e *Enclave) applyRevert(rev *revert) error {
+	if err := e.epoch.RevertDeposits(rev.deps...); err != nil {
+		return err
+	}
+
+	for _, block := range rev.path {
+		if delta := e.deltas[block.Hash()]; delta.number >= e.unsealedFrom {
+			e.epoch.RestoreExits(delta.exitReqs)
+		}
+		delete(e.deltas, block.Hash())
+	}
+	e.orphaned = rev.orphaned
+	// The reverted deposits' proofs are the most recent ones in the cache.
+	e.depositProofCache = e.depositProofCache[:len(e.depositProofCache)-len(rev.deps)]
+	e.chain.Rewind(len(rev.path))
+	e.chain.SetConsensusState(rev.consensus)
+	e.State.LastBlock = e.chain.Head().NumberU64()
+	e.State.LastBlockHash = e.chain.Head().Hash()
+
+	log.WithFields(log.Fields{"reverted": len(rev.path), "head": e.State.LastBlock}).
+		Warn("Enclave: chain reorganization")
+	return nil
+}
+
+// recordDelta records the state changes of a block, so that it can be reverted
+// later. Deltas of blocks that left the reorg window are discarded.
+func (e *Enclave) recordDelta(block *tee.Block, events blockEvents, exitReqs map[common.Address]*Acc, consensus ConsensusState) {
+	e.deltas[block.Hash()] = &blockDelta{
+		number:    block.NumberU64(),
+		events:    events,
+		exitReqs:  exitReqs,
+		consensus: consensus,
+	}
+	for hash, delta := range e.deltas {
+		if delta.number+e.chain.depth < block.NumberU64() {
+			delete(e.deltas, hash)
+		}
+	}
+}
+
+// exitProof signs an exit proof for the given exit request. The request must
+// be signed by its account and its value must be positive and not exceed the
+// account's balance of the sealed exit epoch, nor its spendable balance in
+// the current epoch. The value is reserved until the exit epoch ends.
+func (e *Enclave) exitProof(req *tee.ExitRequest) (*tee.BalanceProof, error) {
+	if e.epoch == nil {
+		return nil, errors.New("no epoch started yet")
+	} else if req.Epoch != e.epoch.ExitNum() {
+		return nil, fmt.Errorf("epoch mismatch: %d != %d", req.Epoch, e.epoch.ExitNum())
+	} else if req.Value == nil || (*big.Int)(req.Value).Sign() <= 0 {
+		return nil, errors.New("non-positive exit value")
+	} else if valid, err := tee.VerifyExitRequest(e.params.Contract, *req); err != nil {
+		return nil, fmt.Errorf("verifying exit request signature: %w", err)
+	} else if !valid {
+		return nil, errors.New("invalid exit request signature")
+	}
+
+	value := (*big.Int)(req.Value)
+	acc, ok := e.State.Accounts[req.Account]
+	if !ok {
+		return nil, errors.New("account does not exist")
+	} else if acc.Balance(req.Token).Cmp(value) < 0 {
+		return nil, errors.New("insufficient balance")
+	} else if e.epoch.IsExitLocked(req.Account) {
+		return nil, errors.New("account is locked for withdrawing")
+	}
+	// Earlier exit proofs of the token are replaced on-chain by this one, so
+	// their reservations do not add up.
+	available := e.epoch.spendable(req.Account, req.Token)
+	if r, ok := e.epoch.exitReserved[req.Account]; ok {
+		available.Add(available, r.Balance(req.Token))
+	}
+	if available.Cmp(value) < 0 {
+		return nil, errors.New("insufficient spendable balance")
+	}
+	e.epoch.ReserveExit(req.Account, req.Token, value)
+	return e.signBalanceProof(req.Balance()), nil
+}
+
+// accountState returns the account's state in the current transaction epoch.
+func (e *Enclave) accountState(who common.Address) (*tee.Account, error) {
+	if e.epoch == nil {
+		return nil, errors.New("no epoch started yet")
+	}
+	acc := &tee.Account{
+		Epoch: e.epoch.TxNum(),
+		Value: (*tee.Amount)(e.epoch.Balance(who)),
+	}
+	if a, ok := e.epoch.accs[who]; ok {
+		acc.Nonce = a.Nonce
+		for token, v := range a.Tokens {
+			if acc.Tokens == nil {
+				acc.Tokens = make(map[common.Address]*tee.Amount, len(a.Tokens))
+			}
+			acc.Tokens[token] = (*tee.Amount)(new(big.Int).Set(v))
+		}
+	}
+	return acc, nil
+}
+
+// lastBlock returns the number of the enclave's head block and whether it
+// knows any block.
+func (e *Enclave) lastBlock() (uint64, bool) {
+	if e.chain.empty() {
+		return 0, false
+	}
+	return e.BlockNum(), true
+}
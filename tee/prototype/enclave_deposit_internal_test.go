@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_AggregateDepositProofs(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	alice, bob := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+	token := eth.NewRandomAddress(rng)
+
+	b0 := newFakeBlock(rng, nil, params.Contract)
+	b1 := newFakeBlock(rng, b0, params.Contract,
+		&erdstallDepEvent{Account: alice, Token: tee.ETHToken, Value: big.NewInt(10)},
+		&erdstallDepEvent{Account: bob, Token: tee.ETHToken, Value: big.NewInt(5)})
+	b2 := newFakeBlock(rng, b1, params.Contract,
+		&erdstallDepEvent{Account: alice, Token: tee.ETHToken, Value: big.NewInt(7)},
+		&erdstallDepEvent{Account: alice, Token: token, Value: big.NewInt(3)})
+	for _, b := range []*tee.Block{b0, b1, b2} {
+		require.NoError(enc.processBlock(b))
+	}
+	require.Len(enc.depositProofCache, 4)
+
+	proofs := enc.aggregateDepositProofs(enc.depositProofCache)
+	require.Len(proofs, 3)
+	require.Equal(alice, proofs[0].Balance.Account)
+	require.Equal(tee.ETHToken, proofs[0].Balance.Token)
+	require.Zero((*big.Int)(proofs[0].Balance.Value).Cmp(big.NewInt(17)))
+	require.Equal(bob, proofs[1].Balance.Account)
+	require.Zero((*big.Int)(proofs[1].Balance.Value).Cmp(big.NewInt(5)))
+	require.Equal(alice, proofs[2].Balance.Account)
+	require.Equal(token, proofs[2].Balance.Token)
+	require.Zero((*big.Int)(proofs[2].Balance.Value).Cmp(big.NewInt(3)))
+
+	for _, p := range proofs {
+		ok, err := tee.VerifyDepositProof(params, *p)
+		require.NoError(err)
+		require.True(ok)
+	}
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_ExitProof(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	token := eth.NewRandomAddress(rng)
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice := aliceAcc.Account.Address
+
+	newAcc := func() *Acc {
+		return &Acc{
+			Value:  big.NewInt(100),
+			Tokens: map[common.Address]*big.Int{token: big.NewInt(30)},
+		}
+	}
+	enc.epoch = newEpoch(5)
+	enc.epoch.accs[alice] = newAcc()
+	enc.State = &State{
+		Params:   enc.params,
+		Epoch:    enc.epoch.ExitNum(),
+		Accounts: map[common.Address]*Acc{alice: newAcc()},
+	}
+
+	newReq := func(token common.Address, value int64) *tee.ExitRequest {
+		req := &tee.ExitRequest{
+			Epoch:   enc.epoch.ExitNum(),
+			Account: alice,
+			Token:   token,
+			Value:   (*tee.Amount)(big.NewInt(value)),
+		}
+		require.NoError(req.Sign(params.Contract, aliceAcc.Account, hdw))
+		return req
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		for _, req := range []*tee.ExitRequest{newReq(tee.ETHToken, 40), newReq(token, 30)} {
+			bp, err := enc.exitProof(req)
+			require.NoError(err)
+			require.Equal(req.Balance(), bp.Balance)
+			ok, err := tee.VerifyBalanceProof(params, *bp)
+			require.NoError(err)
+			require.True(ok)
+		}
+	})
+
+	t.Run("reserved", func(t *testing.T) {
+		require.Zero(enc.epoch.spendable(alice, tee.ETHToken).Cmp(big.NewInt(60)))
+		require.Zero(enc.epoch.spendable(alice, token).Sign())
+
+		// A later proof replaces the earlier one.
+		_, err := enc.exitProof(newReq(tee.ETHToken, 50))
+		require.NoError(err)
+		require.Zero(enc.epoch.spendable(alice, tee.ETHToken).Cmp(big.NewInt(50)))
+	})
+
+	t.Run("exceeds-current-balance", func(t *testing.T) {
+		enc.epoch.accs[alice].set(tee.ETHToken, big.NewInt(70))
+		defer enc.epoch.accs[alice].set(tee.ETHToken, big.NewInt(100))
+		_, err := enc.exitProof(newReq(tee.ETHToken, 80))
+		require.Error(err)
+		_, err = enc.exitProof(newReq(tee.ETHToken, 70))
+		require.NoError(err)
+	})
+
+	t.Run("exceeds-balance", func(t *testing.T) {
+		_, err := enc.exitProof(newReq(tee.ETHToken, 101))
+		require.Error(err)
+		_, err = enc.exitProof(newReq(token, 31))
+		require.Error(err)
+	})
+
+	t.Run("non-positive", func(t *testing.T) {
+		_, err := enc.exitProof(newReq(tee.ETHToken, 0))
+		require.Error(err)
+	})
+
+	t.Run("wrong-epoch", func(t *testing.T) {
+		req := newReq(tee.ETHToken, 40)
+		req.Epoch = enc.epoch.TxNum()
+		require.NoError(req.Sign(params.Contract, aliceAcc.Account, hdw))
+		_, err := enc.exitProof(req)
+		require.Error(err)
+	})
+
+	t.Run("invalid-sig", func(t *testing.T) {
+		req := newReq(tee.ETHToken, 40)
+		req.Value = (*tee.Amount)(big.NewInt(50))
+		_, err := enc.exitProof(req)
+		require.Error(err)
+	})
+
+	t.Run("released", func(t *testing.T) {
+		enc.epoch.progressPhase()
+		enc.epoch.Outcome()
+		require.Empty(enc.epoch.exitReserved)
+		require.Zero(enc.epoch.spendable(alice, tee.ETHToken).Cmp(big.NewInt(100)))
+	})
+}
+
+func TestEpoch_PartialExits(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	alice, token := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	ep := newEpoch(2)
+	ep.accs[alice] = &Acc{
+		Value:  big.NewInt(100),
+		Tokens: map[common.Address]*big.Int{token: big.NewInt(30)},
+	}
+	exit := func(token common.Address, value int64) *erdstallExitEvent {
+		return &erdstallExitEvent{Epoch: ep.ExitNum(), Account: alice, Token: token, Value: big.NewInt(value)}
+	}
+
+	// The later exit replaces the earlier one, reverting restores it.
+	require.Nil(ep.RegisterExits(exit(tee.ETHToken, 10))[alice])
+	prev := ep.RegisterExits(exit(tee.ETHToken, 40))
+	require.Zero(prev[alice].Value.Cmp(big.NewInt(10)))
+	ep.RestoreExits(prev)
+	require.Zero(ep.exitReqs[alice].Value.Cmp(big.NewInt(10)))
+	ep.RegisterExits(exit(tee.ETHToken, 40), exit(token, 30))
+	require.Zero(ep.spendable(alice, tee.ETHToken).Cmp(big.NewInt(60)))
+
+	ep.progressPhase()
+	ep.Outcome()
+	ep.progressPhase()
+	exits := ep.Outcome().Exits
+	require.Zero(exits[alice].Value.Cmp(big.NewInt(40)))
+	require.Zero(exits[alice].Balance(token).Cmp(big.NewInt(30)))
+	require.Zero(ep.Balance(alice).Cmp(big.NewInt(60)))
+	require.Zero(ep.TokenBalance(alice, token).Sign())
+
+	// Exiting the remaining balance deletes the account.
+	ep.RegisterExits(exit(tee.ETHToken, 60))
+	ep.progressPhase()
+	ep.Outcome()
+	ep.progressPhase()
+	ep.Outcome()
+	require.NotContains(ep.accs, alice)
+}
+
+func TestEpoch_ExitAfterSpend(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	params := &tee.Parameters{PhaseDuration: 10, Contract: eth.NewRandomAddress(rng)}
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice, bob := aliceAcc.Account.Address, eth.NewRandomAddress(rng)
+
+	ep := newEpoch(5)
+	ep.accs[alice] = &Acc{Value: big.NewInt(100)}
+	// The balance proof of the exit epoch still holds the whole balance.
+	proven := ep.Balance(alice)
+
+	tx := &tee.Transaction{
+		Nonce:     1,
+		Epoch:     ep.TxNum(),
+		Sender:    alice,
+		Recipient: bob,
+		Amount:    (*tee.Amount)(big.NewInt(40)),
+	}
+	require.NoError(tx.Sign(params.Contract, aliceAcc.Account, hdw))
+	require.NoError(ep.ProcessTx(params, tx))
+
+	// The contract accepts the exit with the old proof, so must the enclave.
+	exit := &erdstallExitEvent{Epoch: ep.ExitNum(), Account: alice, Value: proven}
+	require.NoError(verifyExits([]*erdstallExitEvent{exit}, ep.ExitNum()))
+	ep.RegisterExits(exit)
+	ep.progressPhase()
+	ep.Outcome()
+	ep.progressPhase()
+	exits := ep.Outcome().Exits
+	require.Zero(exits[alice].Value.Cmp(big.NewInt(60)), "only the remaining balance is withdrawn")
+	require.NotContains(ep.accs, alice)
+	require.Zero(ep.Balance(bob).Cmp(big.NewInt(40)))
+}
+
+func TestAcc_Withdraw(t *testing.T) {
+	require := require.New(t)
+	token := eth.NewRandomAddress(ptest.Prng(t))
+	acc := &Acc{Value: big.NewInt(10), Tokens: map[common.Address]*big.Int{token: big.NewInt(5)}}
+
+	w, shortfall := acc.withdraw(&Acc{Value: big.NewInt(5), Tokens: map[common.Address]*big.Int{token: big.NewInt(6)}})
+	require.Zero(w.Value.Cmp(big.NewInt(5)))
+	require.Zero(w.Balance(token).Cmp(big.NewInt(5)), "exceeding exits withdraw the balance")
+	require.Zero(shortfall.Value.Sign())
+	require.Zero(shortfall.Balance(token).Cmp(big.NewInt(1)))
+	require.Zero(acc.Value.Cmp(big.NewInt(5)))
+	require.Zero(acc.Balance(token).Sign())
+
+	w, shortfall = acc.withdraw(&Acc{Value: big.NewInt(5)})
+	require.Nil(shortfall)
+	require.Zero(w.Value.Cmp(big.NewInt(5)))
+	require.Zero(acc.Value.Sign())
+}
@@ -5,9 +5,12 @@ package prototype_test
 import (
 	"testing"
 
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
 	ptest "perun.network/go-perun/pkg/test"
 
 	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee/attestation"
 	. "github.com/perun-network/erdstall/tee/prototype"
 	ttest "github.com/perun-network/erdstall/tee/test"
 )
@@ -17,3 +20,24 @@ func TestEnclave(t *testing.T) {
 	encWallet := eth.NewHdWallet(rng)
 	ttest.GenericEnclaveTest(t, NewEnclave(encWallet))
 }
+
+func TestEnclave_Attestation(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc := NewEnclave(eth.NewHdWallet(rng))
+
+	addr, att, err := enc.Init()
+	require.NoError(err)
+	require.Empty(att)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	quoter := attestation.NewMockQuoter(key, attestation.PrototypeMeasurement)
+	enc.SetQuoter(quoter)
+	addr2, att, err := enc.Init()
+	require.NoError(err)
+	require.Equal(addr, addr2)
+
+	verifier := attestation.NewMockVerifier(quoter.Signer(), attestation.PrototypeMeasurement)
+	require.NoError(attestation.Verify(verifier, addr, att))
+}
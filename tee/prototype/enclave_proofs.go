@@ -5,21 +5,46 @@ package prototype
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/perun-network/erdstall/tee"
 )
 
-// generateBalanceProofs creates balance proofs for an epoch outcome.
+// generateBalanceProofs creates balance proofs for an epoch outcome. Each
+// account gets an ETH balance proof and one balance proof per held token.
+// Open locks are added to their sender's balances.
 func (e *Enclave) generateBalanceProofs(o Outcome) []*tee.BalanceProof {
-	proofs := make([]*tee.BalanceProof, 0, len(o.Accounts))
-	for addr, bal := range o.Accounts {
+	accs := cloneAccs(o.Accounts)
+	for sender, locked := range lockedBalances(o.Locks) {
+		acc, ok := accs[sender]
+		if !ok {
+			accs[sender] = locked
+			continue
+		}
+		acc.add(tee.ETHToken, locked.Value)
+		for token, value := range locked.Tokens {
+			acc.add(token, value)
+		}
+	}
+
+	proofs := make([]*tee.BalanceProof, 0, len(accs))
+	for addr, bal := range accs {
 		proofs = append(proofs, e.signBalanceProof(tee.Balance{
 			Epoch:   o.TxEpoch,
 			Account: addr,
+			Token:   tee.ETHToken,
 			Value:   (*tee.Amount)(bal.Value),
 		}))
+		for token, value := range bal.Tokens {
+			proofs = append(proofs, e.signBalanceProof(tee.Balance{
+				Epoch:   o.TxEpoch,
+				Account: addr,
+				Token:   token,
+				Value:   (*tee.Amount)(value),
+			}))
+		}
 	}
 	return proofs
 }
@@ -33,12 +58,48 @@ func (e *Enclave) generateDepositProofs(
 		proofs[i] = e.signDepositProof(tee.Balance{
 			Epoch:   d.Epoch,
 			Account: d.Account,
+			Token:   d.Token,
 			Value:   (*tee.Amount)(new(big.Int).Set(d.Value)),
 		})
 	}
 	return proofs
 }
 
+// aggregateDepositProofs merges the deposit proofs of the same epoch, account
+// and token into a single proof of their sum, as the contract also sums the
+// deposits. The order of first occurrence is kept.
+func (e *Enclave) aggregateDepositProofs(proofs []*tee.DepositProof) []*tee.DepositProof {
+	type key struct {
+		epoch          tee.Epoch
+		account, token common.Address
+	}
+	sums := make(map[key]*big.Int)
+	var keys []key
+	for _, p := range proofs {
+		k := key{p.Balance.Epoch, p.Balance.Account, p.Balance.Token}
+		if sum, ok := sums[k]; ok {
+			sum.Add(sum, (*big.Int)(p.Balance.Value))
+		} else {
+			sums[k] = new(big.Int).Set((*big.Int)(p.Balance.Value))
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == len(proofs) {
+		return proofs // Nothing to merge.
+	}
+
+	aggregated := make([]*tee.DepositProof, len(keys))
+	for i, k := range keys {
+		aggregated[i] = e.signDepositProof(tee.Balance{
+			Epoch:   k.epoch,
+			Account: k.account,
+			Token:   k.token,
+			Value:   (*tee.Amount)(sums[k]),
+		})
+	}
+	return aggregated
+}
+
 func (e *Enclave) signBalanceProof(b tee.Balance) *tee.BalanceProof {
 	msg, err := tee.EncodeBalanceProof(e.params.Contract, b)
 	if err != nil {
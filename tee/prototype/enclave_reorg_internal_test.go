@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_Reorg(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	alice, bob := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	b0 := newFakeBlock(rng, nil, params.Contract)
+	b1 := newFakeBlock(rng, b0, params.Contract, &erdstallDepEvent{Account: alice, Value: big.NewInt(10)})
+	b2 := newFakeBlock(rng, b1, params.Contract, &erdstallDepEvent{Account: bob, Value: big.NewInt(5)})
+	for _, b := range []*tee.Block{b0, b1, b2} {
+		require.NoError(enc.processBlock(b))
+	}
+	require.Zero(enc.epoch.Balance(alice).Cmp(big.NewInt(10)))
+	require.Len(enc.depositProofCache, 2)
+
+	t.Run("duplicate", func(t *testing.T) {
+		require.Error(enc.processBlock(b1))
+		require.Equal(b2.Hash(), enc.State.LastBlockHash)
+	})
+
+	// Fork after b0, orphaning b1 and b2.
+	f1 := newFakeBlock(rng, b0, params.Contract, &erdstallDepEvent{Account: alice, Value: big.NewInt(3)})
+
+	t.Run("spent-deposit", func(t *testing.T) {
+		enc.epoch.accs[alice].Value.SetInt64(7) // Simulate a spent deposit.
+		defer enc.epoch.accs[alice].Value.SetInt64(10)
+		require.Error(enc.processBlock(f1))
+		require.Equal(b2.Hash(), enc.State.LastBlockHash)
+		require.Len(enc.depositProofCache, 2)
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		require.NoError(enc.processBlock(f1))
+		require.Equal(f1.Hash(), enc.State.LastBlockHash)
+		require.Equal(uint64(1), enc.State.LastBlock)
+		require.Zero(enc.epoch.Balance(alice).Cmp(big.NewInt(3)))
+		require.NotContains(enc.epoch.accs, bob)
+		require.Len(enc.depositProofCache, 1)
+		require.Equal(alice, enc.depositProofCache[0].Balance.Account)
+
+		// Blocks of the orphaned branch are not accepted anymore.
+		require.Error(enc.processBlock(b2))
+		f2 := newFakeBlock(rng, f1, params.Contract)
+		require.NoError(enc.processBlock(f2))
+	})
+
+	t.Run("too-deep", func(t *testing.T) {
+		for i := 0; i < minReorgDepth; i++ {
+			require.NoError(enc.processBlock(newFakeBlock(rng, enc.chain.Head(), params.Contract)))
+		}
+		head := enc.chain.Head()
+		require.Error(enc.processBlock(newFakeBlock(rng, b0, params.Contract)))
+		require.Equal(head, enc.chain.Head())
+	})
+}
+
+func TestEnclave_ReorgPhaseEnd(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	alice := eth.NewRandomAddress(rng)
+	dep := func(value int64) *erdstallDepEvent {
+		return &erdstallDepEvent{Account: alice, Value: big.NewInt(value)}
+	}
+
+	// Blocks 0, ..., 9 form the first phase, the deposit is in its last block.
+	var b7, b8 *tee.Block
+	for i := 0; i < 9; i++ {
+		b7, b8 = b8, newFakeBlock(rng, enc.chain.Head(), params.Contract)
+		require.NoError(enc.processBlock(b8))
+	}
+	require.NoError(enc.processBlock(newFakeBlock(rng, b8, params.Contract, dep(10))))
+	require.NoError(enc.processBlock(newFakeBlock(rng, enc.chain.Head(), params.Contract)))
+	require.Equal(tee.Epoch(1), enc.State.Epoch)
+	<-enc.balanceProofs
+	<-enc.depositProofs
+
+	requireUnchanged := func() {
+		require.Equal(tee.Epoch(1), enc.State.Epoch)
+		require.Zero(enc.epoch.Balance(alice).Cmp(big.NewInt(10)))
+		require.Empty(enc.balanceProofs, "phase sealed twice")
+		require.Empty(enc.depositProofs, "phase sealed twice")
+	}
+
+	t.Run("same-events", func(t *testing.T) {
+		f9 := newFakeBlock(rng, b8, params.Contract, dep(10))
+		require.NoError(enc.processBlock(f9))
+		require.NoError(enc.processBlock(newFakeBlock(rng, f9, params.Contract)))
+		requireUnchanged()
+	})
+
+	t.Run("other-events", func(t *testing.T) {
+		head := enc.State.LastBlockHash
+		require.Error(enc.processBlock(newFakeBlock(rng, b8, params.Contract, dep(11))))
+		// The block is rejected before the current branch is reverted.
+		require.Equal(head, enc.State.LastBlockHash)
+		require.True(enc.orphaned.empty())
+		requireUnchanged()
+	})
+
+	t.Run("dropped-events", func(t *testing.T) {
+		g9 := newFakeBlock(rng, b8, params.Contract)
+		require.NoError(enc.processBlock(g9))
+		err := enc.processBlock(newFakeBlock(rng, g9, params.Contract))
+		require.True(errors.Is(err, tee.ErrSealedEventsDropped))
+		require.Equal(g9.Hash(), enc.State.LastBlockHash)
+		requireUnchanged()
+	})
+
+	t.Run("after-revert", func(t *testing.T) {
+		h8 := newFakeBlock(rng, b7, params.Contract)
+		require.NoError(enc.processBlock(h8))
+		recent := append([]*tee.Block(nil), enc.chain.recent...)
+		require.Error(enc.processBlock(newFakeBlock(rng, h8, params.Contract, dep(11))))
+		require.Equal(recent, enc.chain.recent)
+		requireUnchanged()
+	})
+}
+
+func TestEnclave_ReorgConsensus(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	enc.chain.consensus = new(fakeVerifier)
+
+	b0 := newFakeBlock(rng, nil, params.Contract)
+	b1 := newFakeBlock(rng, b0, params.Contract)
+	b2 := newFakeBlock(rng, b1, params.Contract)
+	for _, b := range []*tee.Block{b0, b1, b2} {
+		require.NoError(enc.processBlock(b))
+	}
+	require.Equal(fakeState(b2), enc.chain.ConsensusState())
+
+	t.Run("rejected", func(t *testing.T) {
+		// The header is valid, but the deposit is of the wrong epoch.
+		b3 := newFakeBlock(rng, b2, params.Contract,
+			&erdstallDepEvent{Epoch: 5, Account: eth.NewRandomAddress(rng), Value: big.NewInt(1)})
+		require.Error(enc.processBlock(b3))
+		require.Equal(fakeState(b2), enc.chain.ConsensusState())
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		// The verifier only accepts f2 if the state of b1 was restored.
+		f2 := newFakeBlock(rng, b1, params.Contract)
+		require.NoError(enc.processBlock(f2))
+		require.Equal(fakeState(f2), enc.chain.ConsensusState())
+
+		f1 := newFakeBlock(rng, b0, params.Contract)
+		require.NoError(enc.processBlock(f1))
+		require.Equal(fakeState(f1), enc.chain.ConsensusState())
+	})
+}
+
+// fakeVerifier is a ConsensusVerifier whose state is the hash of the last
+// verified header. It only accepts headers whose parent matches the state.
+type fakeVerifier struct{ state ConsensusState }
+
+// fakeState returns the state of a fakeVerifier after block b.
+func fakeState(b *tee.Block) ConsensusState {
+	return ConsensusState{CliqueSigners: []common.Address{common.BytesToAddress(b.Hash().Bytes())}}
+}
+
+func (*fakeVerifier) Engine() tee.ConsensusEngine { return tee.CliqueConsensus }
+
+func (*fakeVerifier) VerifyHeader(header, parent *types.Header, state ConsensusState) (ConsensusState, error) {
+	s := ConsensusState{CliqueSigners: []common.Address{common.BytesToAddress(parent.Hash().Bytes())}}
+	if state.CliqueSigners != nil && !reflect.DeepEqual(state, s) {
+		return ConsensusState{}, errors.New("state is not the parent's")
+	}
+	return ConsensusState{CliqueSigners: []common.Address{common.BytesToAddress(header.Hash().Bytes())}}, nil
+}
+
+func (v *fakeVerifier) State() ConsensusState     { return v.state }
+func (v *fakeVerifier) SetState(s ConsensusState) { v.state = s }
+
+// newFakeBlock creates a successor block of parent, or a genesis block if
+// parent is nil, containing a transaction with an Erdstall deposit event for
+// each of the given deposits. Deposits of tokens other than tee.ETHToken
+// result in token deposit events. The block's extra data is random, so that forks
+// can be created.
+func newFakeBlock(rng *rand.Rand, parent *tee.Block, contract common.Address, deps ...*erdstallDepEvent) *tee.Block {
+	header := &types.Header{Number: new(big.Int), Extra: make([]byte, 32)}
+	rng.Read(header.Extra)
+	if parent != nil {
+		header.Number.SetUint64(parent.NumberU64() + 1)
+		header.ParentHash = parent.Hash()
+	}
+
+	var (
+		txs      types.Transactions
+		receipts types.Receipts
+	)
+	for i, dep := range deps {
+		topics := []common.Hash{
+			depositedEvent,
+			common.BigToHash(new(big.Int).SetUint64(dep.Epoch)),
+			dep.Account.Hash(),
+		}
+		if dep.Token != tee.ETHToken {
+			topics[0] = tokenDepositedEvent
+			topics = append(topics, dep.Token.Hash())
+		}
+		txs = append(txs, types.NewTransaction(uint64(i), contract, nil, 0, nil, nil))
+		receipts = append(receipts, &types.Receipt{Logs: []*types.Log{{
+			Address: contract,
+			Topics:  topics,
+			Data:    common.LeftPadBytes(dep.Value.Bytes(), 32),
+		}}})
+	}
+	return &tee.Block{
+		Block:    *types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)),
+		Receipts: receipts,
+	}
+}
@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+type (
+	// snapshot contains the complete enclave state. It is sealed and written to
+	// disk periodically so that the enclave can resume after a restart.
+	snapshot struct {
+		Params        tee.Parameters
+		Epoch         tee.Epoch      // Current deposit epoch.
+		LastBlock     uint64         // Last processed block height.
+		LastBlockHash common.Hash    // Last processed block's hash.
+		Head          *tee.Block     // Last processed block.
+		Consensus     ConsensusState // Header verifier state after Head.
+
+		// Reorg state, so that the enclave can follow reorgs of the blocks
+		// before the snapshot.
+		Recent       []*tee.Block                   // Reorg window, oldest first, Head last.
+		Deltas       map[common.Hash]*snapshotDelta // State changes of the window's blocks.
+		UnsealedFrom uint64                         // First block of the unsealed phase.
+		Orphaned     snapshotEvents                 // Events of reverted sealed blocks.
+
+		Accounts      map[common.Address]*Acc   // Balances of the last sealed epoch.
+		EpochAccs     map[common.Address]*Acc   // Latest account states.
+		ExitLocked    map[common.Address]*Acc   // Withdrawing exit values at end of epoch.
+		ExitReqs      map[common.Address]*Acc   // Requested exit values.
+		ExitReserved  map[common.Address]*Acc   // Values of issued exit proofs.
+		Locks         map[common.Hash]*tee.HTLC // Open hash-time-locked payments.
+		DepositProofs []*tee.DepositProof       // Deposit proof cache.
+	}
+
+	// snapshotDelta is the encodable form of a blockDelta.
+	snapshotDelta struct {
+		Number    uint64
+		Events    snapshotEvents
+		ExitReqs  map[common.Address]*Acc
+		Consensus ConsensusState
+	}
+
+	// snapshotEvents is the encodable form of blockEvents.
+	snapshotEvents struct {
+		Deps  []*erdstallDepEvent
+		Exits []*erdstallExitEvent
+	}
+
+	// persistence configures the sealing of enclave snapshots.
+	persistence struct {
+		path      string // Snapshot file path, empty if disabled.
+		interval  uint64 // Number of blocks between snapshots, 0: each phase end.
+		lastBlock uint64 // Block of the last written snapshot.
+
+		resumed     bool   // Whether the enclave was resumed from a snapshot.
+		resumeBlock uint64 // Last block contained in the resumed snapshot.
+	}
+)
+
+// sealingKeyTag is signed by the enclave to derive its sealing key.
+const sealingKeyTag = "ErdstallSealingKey"
+
+// EnablePersistence lets the enclave seal a snapshot of its complete state to
+// the file at path every interval blocks, or at the end of every phase if
+// interval is 0. Run resumes from an existing snapshot file.
+//
+// Must be called before Run.
+func (e *Enclave) EnablePersistence(path string, interval uint64) {
+	if e.running.IsSet() {
+		log.Panic("EnablePersistence called on running Enclave")
+	}
+	e.persistence = persistence{path: path, interval: interval}
+}
+
+// snapshotDue tells whether a new snapshot should be taken after processing
+// the current head block.
+func (e *Enclave) snapshotDue() bool {
+	p := &e.persistence
+	switch {
+	case p.path == "" || e.chain.empty():
+		return false
+	case e.shutdownApproved:
+		return true
+	case p.interval == 0:
+		return e.IsAtPhaseEnd()
+	default:
+		return e.BlockNum()-p.lastBlock >= p.interval
+	}
+}
+
+// storeSnapshot seals the enclave's current state and atomically writes it to
+// the snapshot file.
+func (e *Enclave) storeSnapshot() error {
+	blob, err := e.sealSnapshot(e.takeSnapshot())
+	if err != nil {
+		return fmt.Errorf("sealing snapshot: %w", err)
+	}
+
+	tmp := e.persistence.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, e.persistence.path); err != nil {
+		return fmt.Errorf("replacing snapshot: %w", err)
+	}
+
+	e.persistence.lastBlock = e.State.LastBlock
+	log.WithField("block", e.State.LastBlock).Debug("Enclave: sealed snapshot")
+	return nil
+}
+
+// restoreSnapshot loads the snapshot file, if it exists, and resumes the
+// enclave from it. The snapshot's parameters must match the enclave's
+// parameters.
+func (e *Enclave) restoreSnapshot() error {
+	if e.persistence.path == "" {
+		return nil
+	}
+
+	blob, err := ioutil.ReadFile(e.persistence.path)
+	if os.IsNotExist(err) {
+		log.Info("Enclave: no snapshot found, starting fresh")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	s, err := e.unsealSnapshot(blob)
+	if err != nil {
+		return fmt.Errorf("unsealing snapshot: %w", err)
+	} else if !s.Params.Equal(*e.params) {
+		return errors.New("snapshot parameters mismatch")
+	} else if s.Head == nil || s.Head.Hash() != s.LastBlockHash || s.Head.NumberU64() != s.LastBlock {
+		return errors.New("snapshot head mismatch")
+	} else if n := len(s.Recent); n > 0 && s.Recent[n-1].Hash() != s.LastBlockHash {
+		return errors.New("snapshot reorg window mismatch")
+	}
+
+	e.applySnapshot(s)
+	log.WithFields(log.Fields{"epoch": s.Epoch, "block": s.LastBlock}).
+		Info("Enclave: resumed from snapshot")
+	return nil
+}
+
+// takeSnapshot copies the enclave's current state into a snapshot.
+func (e *Enclave) takeSnapshot() *snapshot {
+	s := &snapshot{
+		Params:        *e.params,
+		Epoch:         e.epoch.number,
+		LastBlock:     e.State.LastBlock,
+		LastBlockHash: e.State.LastBlockHash,
+		Head:          e.chain.Head(),
+		Consensus:     e.chain.ConsensusState(),
+		Accounts:      e.State.Accounts,
+		EpochAccs:     e.epoch.cloneBals(),
+		ExitLocked:    cloneAccs(e.epoch.exitLocked),
+		ExitReqs:      cloneAccs(e.epoch.exitReqs),
+		ExitReserved:  cloneAccs(e.epoch.exitReserved),
+		Locks:         e.epoch.cloneLocks(),
+		DepositProofs: e.depositProofCache,
+		Recent:        e.chain.recent,
+		Deltas:        make(map[common.Hash]*snapshotDelta, len(e.deltas)),
+		UnsealedFrom:  e.unsealedFrom,
+		Orphaned:      snapshotEvents{Deps: e.orphaned.deps, Exits: e.orphaned.exits},
+	}
+	for hash, d := range e.deltas {
+		s.Deltas[hash] = &snapshotDelta{
+			Number:    d.number,
+			Events:    snapshotEvents{Deps: d.events.deps, Exits: d.events.exits},
+			ExitReqs:  d.exitReqs,
+			Consensus: d.consensus,
+		}
+	}
+	return s
+}
+
+// applySnapshot replaces the enclave's state with the snapshot's state.
+func (e *Enclave) applySnapshot(s *snapshot) {
+	e.epoch = newEpoch(s.Epoch)
+	if s.EpochAccs != nil {
+		e.epoch.accs = s.EpochAccs
+	}
+	for addr, req := range s.ExitLocked {
+		e.epoch.exitLocked[addr] = req
+	}
+	for addr, req := range s.ExitReqs {
+		e.epoch.exitReqs[addr] = req
+	}
+	for addr, r := range s.ExitReserved {
+		e.epoch.exitReserved[addr] = r
+	}
+	for id, h := range s.Locks {
+		e.epoch.locks[id] = h
+	}
+
+	e.State = &State{
+		Params:        e.params,
+		Epoch:         s.Epoch,
+		LastBlock:     s.LastBlock,
+		LastBlockHash: s.LastBlockHash,
+		Accounts:      s.Accounts,
+	}
+	e.chain.head = s.Head
+	e.chain.recent = s.Recent
+	if len(s.Recent) == 0 {
+		e.chain.recent = []*tee.Block{s.Head}
+	}
+	e.chain.SetConsensusState(s.Consensus)
+	e.depositProofCache = s.DepositProofs
+
+	e.deltas = make(map[common.Hash]*blockDelta, len(s.Deltas))
+	for hash, d := range s.Deltas {
+		e.deltas[hash] = &blockDelta{
+			number:    d.Number,
+			events:    blockEvents{deps: d.Events.Deps, exits: d.Events.Exits},
+			exitReqs:  d.ExitReqs,
+			consensus: d.Consensus,
+		}
+	}
+	e.unsealedFrom = s.UnsealedFrom
+	e.orphaned = blockEvents{deps: s.Orphaned.Deps, exits: s.Orphaned.Exits}
+
+	e.persistence.lastBlock = s.LastBlock
+	e.persistence.resumed = true
+	e.persistence.resumeBlock = s.LastBlock
+}
+
+// sealSnapshot encodes and encrypts a snapshot with the enclave's sealing key.
+// The encryption is authenticated, so that only the enclave can create valid
+// snapshots. The contract and enclave addresses are bound as additional data.
+func (e *Enclave) sealSnapshot(s *snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	aead, err := e.sealingCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("reading nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, buf.Bytes(), e.sealingData()), nil
+}
+
+// unsealSnapshot decrypts, authenticates and decodes a sealed snapshot.
+func (e *Enclave) unsealSnapshot(blob []byte) (*snapshot, error) {
+	aead, err := e.sealingCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) < aead.NonceSize() {
+		return nil, errors.New("sealed snapshot too short")
+	}
+
+	nonce, ciphertext := blob[:aead.NonceSize()], blob[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, e.sealingData())
+	if err != nil {
+		return nil, fmt.Errorf("authenticating snapshot: %w", err)
+	}
+
+	s := new(snapshot)
+	if err := gob.NewDecoder(bytes.NewReader(plain)).Decode(s); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// sealingCipher returns an AES-GCM cipher keyed with the enclave's sealing key.
+// The key is derived from the enclave's signature on a fixed tag. ECDSA
+// signatures are deterministic (RFC 6979), so the same key is derived after
+// every restart, but only by the holder of the enclave key.
+func (e *Enclave) sealingCipher() (cipher.AEAD, error) {
+	sig, err := e.wallet.SignText(*e.account, crypto.Keccak256([]byte(sealingKeyTag)))
+	if err != nil {
+		return nil, fmt.Errorf("deriving sealing key: %w", err)
+	}
+	block, err := aes.NewCipher(crypto.Keccak256(sig))
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealingData is the additional authenticated data of sealed snapshots.
+func (e *Enclave) sealingData() []byte {
+	return append(e.params.Contract.Bytes(), e.params.TEE.Bytes()...)
+}
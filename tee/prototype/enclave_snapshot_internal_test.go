@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_Snapshot(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+
+	// Fill the enclave with some state.
+	alice, bob := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+	head := &tee.Block{Block: *types.NewBlockWithHeader(&types.Header{Number: big.NewInt(42)})}
+	enc.epoch = newEpoch(3)
+	enc.epoch.accs[alice] = &Acc{Nonce: 5, Value: big.NewInt(100)}
+	enc.epoch.accs[bob] = &Acc{Nonce: 0, Value: big.NewInt(7), Tokens: map[common.Address]*big.Int{
+		eth.NewRandomAddress(rng): big.NewInt(3),
+	}}
+	enc.epoch.exitLocked[alice] = &Acc{Value: big.NewInt(10)}
+	enc.epoch.exitReqs[bob] = &Acc{Value: big.NewInt(7)}
+	enc.chain.head, enc.chain.recent = head, []*tee.Block{head}
+	enc.chain.consensus = &fakeVerifier{ConsensusState{CliqueSigners: []common.Address{alice, bob}}}
+	enc.State = &State{
+		Params:        enc.params,
+		Epoch:         3,
+		LastBlock:     42,
+		LastBlockHash: head.Hash(),
+		Accounts:      map[common.Address]*Acc{alice: {Nonce: 4, Value: big.NewInt(90)}},
+	}
+	enc.depositProofCache = []*tee.DepositProof{enc.signDepositProof(tee.Balance{
+		Epoch: 3, Account: bob, Value: (*tee.Amount)(big.NewInt(7)),
+	})}
+
+	t.Run("seal-unseal", func(t *testing.T) {
+		blob, err := enc.sealSnapshot(enc.takeSnapshot())
+		require.NoError(err)
+		s, err := enc.unsealSnapshot(blob)
+		require.NoError(err)
+		requireSnapshotEqual(t, enc.takeSnapshot(), s)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		blob, err := enc.sealSnapshot(enc.takeSnapshot())
+		require.NoError(err)
+		blob[len(blob)-1] ^= 1
+		_, err = enc.unsealSnapshot(blob)
+		require.Error(err)
+	})
+
+	t.Run("foreign-enclave", func(t *testing.T) {
+		blob, err := enc.sealSnapshot(enc.takeSnapshot())
+		require.NoError(err)
+		other, _ := newSnapshotEnclave(t, rng)
+		other.params.Contract = params.Contract
+		_, err = other.unsealSnapshot(blob)
+		require.Error(err)
+	})
+
+	t.Run("store-restore", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "erdstall-snapshot")
+		require.NoError(err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "enclave.state")
+
+		enc.EnablePersistence(path, 0)
+		require.NoError(enc.storeSnapshot())
+
+		resumed := NewEnclaveWithAccount(enc.wallet, *enc.account)
+		resumed.EnablePersistence(path, 0)
+		require.NoError(resumed.setParams(params))
+		_, known := resumed.lastBlock()
+		require.False(known)
+		resumed.chain.consensus = new(fakeVerifier)
+		require.NoError(resumed.restoreSnapshot())
+		requireSnapshotEqual(t, enc.takeSnapshot(), resumed.takeSnapshot())
+		// The operator resumes feeding blocks after the snapshot's head.
+		last, known := resumed.lastBlock()
+		require.True(known)
+		require.Equal(uint64(42), last)
+
+		// Blocks contained in the snapshot are ignored, others are verified.
+		require.NoError(resumed.processBlock(head))
+		fake := &tee.Block{Block: *types.NewBlockWithHeader(&types.Header{Number: big.NewInt(43)})}
+		require.Error(resumed.processBlock(fake))
+
+		// Snapshots of other Erdstall instances are rejected.
+		mismatch := NewEnclaveWithAccount(enc.wallet, *enc.account)
+		mismatch.EnablePersistence(path, 0)
+		p := params
+		p.PhaseDuration++
+		require.NoError(mismatch.setParams(p))
+		require.Error(mismatch.restoreSnapshot())
+	})
+}
+
+func TestEnclave_SnapshotReorg(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	alice, bob := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+	dep := func(acc common.Address, value int64) *erdstallDepEvent {
+		return &erdstallDepEvent{Account: acc, Value: big.NewInt(value)}
+	}
+
+	// Blocks 0, ..., 9 form the first phase, alice's deposit is in its last
+	// block and bob's deposit in the first block of the next phase.
+	var b8 *tee.Block
+	for i := 0; i < 9; i++ {
+		b8 = newFakeBlock(rng, enc.chain.Head(), params.Contract)
+		require.NoError(enc.processBlock(b8))
+	}
+	b9 := newFakeBlock(rng, b8, params.Contract, dep(alice, 10))
+	require.NoError(enc.processBlock(b9))
+	require.NoError(enc.processBlock(newFakeBlock(rng, b9, params.Contract, &erdstallDepEvent{
+		Epoch: 1, Account: bob, Value: big.NewInt(5),
+	})))
+	require.Equal(tee.Epoch(1), enc.State.Epoch)
+
+	dir, err := ioutil.TempDir("", "erdstall-snapshot")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "enclave.state")
+	enc.EnablePersistence(path, 0)
+	require.NoError(enc.storeSnapshot())
+
+	resumed := NewEnclaveWithAccount(enc.wallet, *enc.account)
+	resumed.EnablePersistence(path, 0)
+	require.NoError(resumed.setParams(params))
+	require.NoError(resumed.restoreSnapshot())
+	requireSnapshotEqual(t, enc.takeSnapshot(), resumed.takeSnapshot())
+
+	// The new branch reverts bob's deposit and the sealed block 9, whose
+	// deposit has to be contained again.
+	require.Error(resumed.processBlock(newFakeBlock(rng, b8, params.Contract, dep(alice, 11))))
+	f9 := newFakeBlock(rng, b8, params.Contract, dep(alice, 10))
+	require.NoError(resumed.processBlock(f9))
+	require.Equal(f9.Hash(), resumed.State.LastBlockHash)
+	require.NotContains(resumed.epoch.accs, bob)
+	require.Zero(resumed.epoch.Balance(alice).Cmp(big.NewInt(10)))
+	require.Empty(resumed.depositProofCache)
+
+	require.NoError(resumed.processBlock(newFakeBlock(rng, f9, params.Contract)))
+	require.Equal(tee.Epoch(1), resumed.State.Epoch)
+	require.Equal(uint64(10), resumed.State.LastBlock)
+}
+
+func newSnapshotEnclave(t *testing.T, rng *rand.Rand) (*Enclave, tee.Parameters) {
+	enc := NewEnclave(eth.NewHdWallet(rng))
+	teeAddr, _, err := enc.Init()
+	require.NoError(t, err)
+	params := tee.Parameters{
+		PhaseDuration:    10,
+		ResponseDuration: 5,
+		TEE:              teeAddr,
+		Contract:         eth.NewRandomAddress(rng),
+	}
+	require.NoError(t, enc.setParams(params))
+	return enc, params
+}
+
+func requireSnapshotEqual(t *testing.T, exp, got *snapshot) {
+	require := require.New(t)
+	require.Equal(exp.Params, got.Params)
+	require.Equal(exp.Epoch, got.Epoch)
+	require.Equal(exp.LastBlock, got.LastBlock)
+	require.Equal(exp.LastBlockHash, got.LastBlockHash)
+	require.Equal(exp.Head.Hash(), got.Head.Hash())
+	requireAccsEqual(t, exp.Accounts, got.Accounts)
+	requireAccsEqual(t, exp.EpochAccs, got.EpochAccs)
+	requireAccsEqual(t, exp.ExitLocked, got.ExitLocked)
+	requireAccsEqual(t, exp.ExitReqs, got.ExitReqs)
+	require.Equal(exp.DepositProofs, got.DepositProofs)
+	require.Equal(exp.Consensus, got.Consensus)
+	require.Equal(exp.UnsealedFrom, got.UnsealedFrom)
+	require.Equal(len(exp.Orphaned.Deps), len(got.Orphaned.Deps))
+	require.Equal(len(exp.Orphaned.Exits), len(got.Orphaned.Exits))
+	require.Len(got.Recent, len(exp.Recent))
+	for i, b := range exp.Recent {
+		require.Equal(b.Hash(), got.Recent[i].Hash())
+	}
+	require.Len(got.Deltas, len(exp.Deltas))
+	for hash, d := range exp.Deltas {
+		require.Contains(got.Deltas, hash)
+		require.Equal(d.Number, got.Deltas[hash].Number)
+		require.Equal(d.Consensus, got.Deltas[hash].Consensus)
+		requireAccsEqual(t, d.ExitReqs, got.Deltas[hash].ExitReqs)
+	}
+}
+
+func requireAccsEqual(t *testing.T, exp, got map[common.Address]*Acc) {
+	require.Len(t, got, len(exp))
+	for addr, acc := range exp {
+		require.Contains(t, got, addr)
+		require.Equal(t, acc.Nonce, got[addr].Nonce)
+		require.Zero(t, acc.Value.Cmp(got[addr].Value))
+		require.Len(t, got[addr].Tokens, len(acc.Tokens))
+		for token, v := range acc.Tokens {
+			require.Zero(t, v.Cmp(got[addr].Tokens[token]))
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEnclave_Tokens(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	token := eth.NewRandomAddress(rng)
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice, bob := aliceAcc.Account.Address, eth.NewRandomAddress(rng)
+
+	b0 := newFakeBlock(rng, nil, params.Contract)
+	b1 := newFakeBlock(rng, b0, params.Contract,
+		&erdstallDepEvent{Account: alice, Value: big.NewInt(10)},
+		&erdstallDepEvent{Account: alice, Token: token, Value: big.NewInt(20)},
+	)
+	for _, b := range []*tee.Block{b0, b1} {
+		require.NoError(enc.processBlock(b))
+	}
+	require.Zero(enc.epoch.Balance(alice).Cmp(big.NewInt(10)))
+	require.Zero(enc.epoch.TokenBalance(alice, token).Cmp(big.NewInt(20)))
+	require.Len(enc.depositProofCache, 2)
+	for _, dp := range enc.depositProofCache {
+		ok, err := tee.VerifyDepositProof(params, *dp)
+		require.NoError(err)
+		require.True(ok)
+	}
+
+	// Advance to the transaction epoch of the deposits.
+	enc.epoch.number++
+	newTx := func(nonce int64, amount int64) *tee.Transaction {
+		tx := &tee.Transaction{
+			Nonce:     uint64(nonce),
+			Epoch:     enc.epoch.TxNum(),
+			Sender:    alice,
+			Recipient: bob,
+			Token:     token,
+			Amount:    (*tee.Amount)(big.NewInt(amount)),
+		}
+		require.NoError(tx.Sign(params.Contract, aliceAcc.Account, hdw))
+		return tx
+	}
+
+	t.Run("transfer", func(t *testing.T) {
+		require.Error(enc.epoch.ProcessTx(&params, newTx(1, 21)))
+		require.NoError(enc.epoch.ProcessTx(&params, newTx(1, 15)))
+		require.Zero(enc.epoch.Balance(alice).Cmp(big.NewInt(10)))
+		require.Zero(enc.epoch.TokenBalance(alice, token).Cmp(big.NewInt(5)))
+		require.Zero(enc.epoch.TokenBalance(bob, token).Cmp(big.NewInt(15)))
+		require.Zero(enc.epoch.Balance(bob).Sign())
+	})
+
+	t.Run("balance-proofs", func(t *testing.T) {
+		enc.epoch.progressPhase()
+		bps := enc.generateBalanceProofs(enc.epoch.Outcome())
+		require.Len(bps, 4) // ETH and token for both.
+		for _, bp := range bps {
+			ok, err := tee.VerifyBalanceProof(params, *bp)
+			require.NoError(err)
+			require.True(ok)
+			if bp.Balance.Account == bob && bp.Balance.Token == token {
+				require.Zero((*big.Int)(bp.Balance.Value).Cmp(big.NewInt(15)))
+			}
+		}
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		exit := &erdstallExitEvent{Epoch: enc.epoch.ExitNum(), Account: bob, Token: token, Value: big.NewInt(16)}
+		require.NoError(verifyExits([]*erdstallExitEvent{exit}, enc.epoch.ExitNum()))
+		exit.Value.SetInt64(9)
+		require.NoError(verifyExits([]*erdstallExitEvent{exit}, enc.epoch.ExitNum()))
+		require.Error(verifyExits([]*erdstallExitEvent{exit}, enc.epoch.TxNum()))
+	})
+
+	t.Run("partial-exit", func(t *testing.T) {
+		enc.epoch.RegisterExits(&erdstallExitEvent{Epoch: enc.epoch.ExitNum(), Account: bob, Token: token, Value: big.NewInt(9)})
+		enc.epoch.progressPhase()
+		enc.epoch.Outcome()
+		enc.epoch.progressPhase()
+		exits := enc.epoch.Outcome().Exits
+		require.Zero(exits[bob].Balance(token).Cmp(big.NewInt(9)))
+		require.Zero(exits[bob].Value.Sign())
+		// The remaining balance stays in the system.
+		require.Zero(enc.epoch.TokenBalance(bob, token).Cmp(big.NewInt(6)))
+	})
+}
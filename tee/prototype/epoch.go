@@ -19,35 +19,46 @@ type (
 	Epoch struct {
 		number tee.Epoch // Current deposit epoch.
 
-		exitLocked map[common.Address]struct{} // Withdrawing at end of epoch.
-		exitReqs   map[common.Address]struct{} // Requested exits.
-		accs       map[common.Address]*Acc     // Latest account states.
+		exitLocked   map[common.Address]*Acc   // Withdrawing exit values at end of epoch.
+		exitReqs     map[common.Address]*Acc   // Requested exit values.
+		exitReserved map[common.Address]*Acc   // Values of issued exit proofs.
+		accs         map[common.Address]*Acc   // Latest account states.
+		locks        map[common.Hash]*tee.HTLC // Open hash-time-locked payments.
 
 		outcome chan Outcome // The last epoch's outcome.
 	}
 
-	// Acc contains an account's balance and transaction nonce.
+	// Acc contains an account's balances and transaction nonce.
 	Acc struct {
-		Nonce uint64
-		Value *big.Int
+		Nonce  uint64
+		Value  *big.Int                    // ETH balance.
+		Tokens map[common.Address]*big.Int // ERC-20 token balances, never zero.
+	}
+
+	// accToken identifies the balance of a token in an account.
+	accToken struct {
+		account, token common.Address
 	}
 
 	// Outcome contains all of an epoch's final balances, as well as all
-	// accounts that exited the system at the end of the epoch.
+	// values that exited the system at the end of the epoch.
 	Outcome struct {
-		TxEpoch  tee.Epoch               // The finished TX epoch.
-		Exits    map[common.Address]*Acc // Exited accounts.
-		Accounts map[common.Address]*Acc // Final balances and nonces.
+		TxEpoch  tee.Epoch                 // The finished TX epoch.
+		Exits    map[common.Address]*Acc   // Exited values.
+		Accounts map[common.Address]*Acc   // Final balances and nonces.
+		Locks    map[common.Hash]*tee.HTLC // Open locks, not part of Accounts.
 	}
 )
 
 func newEpoch(n tee.Epoch) *Epoch {
 	return &Epoch{
-		number:     n,
-		exitLocked: make(map[common.Address]struct{}),
-		exitReqs:   make(map[common.Address]struct{}),
-		accs:       make(map[common.Address]*Acc),
-		outcome:    make(chan Outcome, 1),
+		number:       n,
+		exitLocked:   make(map[common.Address]*Acc),
+		exitReqs:     make(map[common.Address]*Acc),
+		exitReserved: make(map[common.Address]*Acc),
+		accs:         make(map[common.Address]*Acc),
+		locks:        make(map[common.Hash]*tee.HTLC),
+		outcome:      make(chan Outcome, 1),
 	}
 }
 
@@ -73,15 +84,44 @@ func (e *Epoch) Outcome() (o Outcome) {
 }
 
 // RegisterExits registers a exit requests for the end of the current phase. The
-// requested accounts are locked for one epoch before they can be withdrawn.
-func (e *Epoch) RegisterExits(exitReqs ...*erdstallExitEvent) {
+// requested accounts are locked for one epoch before the exit values are
+// withdrawn. Like in the contract, a later exit of the same token replaces an
+// earlier one. Returns the accounts' previous exit requests, nil if there were
+// none, so that they can be restored with RestoreExits.
+func (e *Epoch) RegisterExits(exitReqs ...*erdstallExitEvent) (prev map[common.Address]*Acc) {
+	prev = make(map[common.Address]*Acc)
 	for _, exit := range exitReqs {
 		if exit.Epoch != e.ExitNum() {
 			log.WithFields(log.Fields{
 				"req. epoch": exit.Epoch, "exit epoch": e.ExitNum(),
 			}).Panic("epoch mismatch")
 		}
-		e.exitReqs[exit.Account] = struct{}{}
+		req, ok := e.exitReqs[exit.Account]
+		if _, recorded := prev[exit.Account]; !recorded {
+			if ok {
+				prev[exit.Account] = req.clone()
+			} else {
+				prev[exit.Account] = nil
+			}
+		}
+		if !ok {
+			req = &Acc{Value: new(big.Int)}
+			e.exitReqs[exit.Account] = req
+		}
+		req.set(exit.Token, exit.Value)
+	}
+	return
+}
+
+// RestoreExits restores the exit requests returned by RegisterExits. It is used
+// to revert exits from orphaned blocks.
+func (e *Epoch) RestoreExits(prev map[common.Address]*Acc) {
+	for addr, req := range prev {
+		if req == nil {
+			delete(e.exitReqs, addr)
+		} else {
+			e.exitReqs[addr] = req
+		}
 	}
 }
 
@@ -89,28 +129,60 @@ func (e *Epoch) RegisterExits(exitReqs ...*erdstallExitEvent) {
 // available instantly.
 func (e *Epoch) ApplyDeposits(deposits ...*erdstallDepEvent) {
 	for _, dep := range deposits {
-		e.receive(dep.Account, dep.Value)
+		e.receive(dep.Account, dep.Token, dep.Value)
 	}
 }
 
-// receive increases an account's balance, or creates a new account if it didn't
-// exist yet.
-func (e *Epoch) receive(account common.Address, value *big.Int) {
-	if acc, ok := e.accs[account]; ok {
-		acc.Value.Add(acc.Value, value)
-	} else {
-		e.accs[account] = &Acc{
+// RevertDeposits reverts deposits that were applied from orphaned blocks. If
+// any account already spent its reverted deposits, an error is returned and
+// the epoch remains unchanged. Accounts that only consisted of reverted
+// deposits are removed.
+func (e *Epoch) RevertDeposits(deposits ...*erdstallDepEvent) error {
+	reverted := make(map[accToken]*big.Int)
+	for _, dep := range deposits {
+		key := accToken{dep.Account, dep.Token}
+		if v, ok := reverted[key]; ok {
+			v.Add(v, dep.Value)
+		} else {
+			reverted[key] = new(big.Int).Set(dep.Value)
+		}
+	}
+	for key, v := range reverted {
+		if e.TokenBalance(key.account, key.token).Cmp(v) < 0 {
+			return fmt.Errorf("reverted deposits of %s already spent", key.account.Hex())
+		}
+	}
+
+	for key, v := range reverted {
+		acc := e.accs[key.account]
+		acc.add(key.token, new(big.Int).Neg(v))
+		if acc.isEmpty() {
+			delete(e.accs, key.account)
+		}
+	}
+	return nil
+}
+
+// receive increases an account's balance of the given token, or creates a new
+// account if it didn't exist yet.
+func (e *Epoch) receive(account, token common.Address, value *big.Int) {
+	acc, ok := e.accs[account]
+	if !ok {
+		acc = &Acc{
 			Nonce: 0,
-			Value: new(big.Int).Set(value),
+			Value: new(big.Int),
 		}
+		e.accs[account] = acc
 	}
+	acc.add(token, value)
 }
 
 // ProcessTx processes a transaction. If it is invalid, does nothing and returns
 // an error. A transaction is invalid if either party is currently locked for
 // withdrawal or the transaction is otherwise invalid (e.g., insufficient funds,
-// invalid signature, ...). Valid transactions increase the sender's nonce.
-func (e *Epoch) ProcessTx(contract common.Address, tx *tee.Transaction) error {
+// insufficient fee, invalid signature, ...). Valid transactions increase the
+// sender's nonce and credit the fee to the fee collector.
+func (e *Epoch) ProcessTx(params *tee.Parameters, tx *tee.Transaction) error {
 	sender, ok := e.accs[tx.Sender]
 	if !ok {
 		return errors.New("sender does not exist")
@@ -120,7 +192,14 @@ func (e *Epoch) ProcessTx(contract common.Address, tx *tee.Transaction) error {
 	*tx = tee.Transaction{
 		Nonce: tx.Nonce, Epoch: tx.Epoch,
 		Sender: tx.Sender, Recipient: tx.Recipient,
-		Amount: tx.Amount, Sig: tx.Sig}
+		Token: tx.Token, Amount: tx.Amount, Fee: tx.Fee, Sig: tx.Sig}
+
+	amount, fee := (*big.Int)(tx.Amount), tx.FeeValue()
+	// The sender pays the fee in ETH, on top of ETH transfers.
+	ethCost := new(big.Int).Set(fee)
+	if tx.Token == tee.ETHToken {
+		ethCost.Add(ethCost, amount)
+	}
 
 	// Check whether both participants are eligible for trading and that the
 	// transaction is valid.
@@ -130,22 +209,96 @@ func (e *Epoch) ProcessTx(contract common.Address, tx *tee.Transaction) error {
 		return errors.New("recipient is locked for withdrawing")
 	} else if tx.Nonce != sender.Nonce+1 {
 		return fmt.Errorf("nonce mismatch: %d != %d", tx.Nonce, sender.Nonce+1)
-	} else if sender.Value.Cmp((*big.Int)(tx.Amount)) < 0 {
-		return errors.New("insufficient balance")
-	} else if (*big.Int)(tx.Amount).Sign() < 0 {
+	} else if amount.Sign() < 0 {
 		return errors.New("negative amount")
+	} else if fee.Sign() < 0 {
+		return errors.New("negative fee")
+	} else if minFee := params.TxFee(tx.Token, amount); fee.Cmp(minFee) < 0 {
+		return fmt.Errorf("insufficient fee: %v < %v", fee, minFee)
+	} else if fee.Sign() > 0 && params.FeeCollector == (common.Address{}) {
+		return errors.New("fee without fee collector")
+	} else if e.spendable(tx.Sender, tx.Token).Cmp(amount) < 0 || e.spendable(tx.Sender, tee.ETHToken).Cmp(ethCost) < 0 {
+		return errors.New("insufficient balance")
 	} else if tx.Epoch != e.TxNum() {
 		return fmt.Errorf("epoch mismatch: %d != %d", tx.Epoch, e.TxNum())
-	} else if valid, err := tee.VerifyTransaction(contract, *tx); err != nil {
+	} else if valid, err := tee.VerifyTransaction(params.Contract, *tx); err != nil {
 		return fmt.Errorf("verifying tx signature: %w", err)
 	} else if !valid {
 		return fmt.Errorf("invalid tx signature")
 	}
 
 	// Execute the transaction.
-	sender.Value.Sub(sender.Value, (*big.Int)(tx.Amount))
+	sender.add(tx.Token, new(big.Int).Neg(amount))
+	sender.add(tee.ETHToken, new(big.Int).Neg(fee))
 	sender.Nonce = tx.Nonce
-	e.receive(tx.Recipient, (*big.Int)(tx.Amount))
+	e.receive(tx.Recipient, tx.Token, amount)
+	if fee.Sign() > 0 {
+		e.receive(params.FeeCollector, tee.ETHToken, fee)
+	}
+
+	return nil
+}
+
+// ProcessBatchTx processes a batch transaction atomically. If the batch or any
+// of its payments is invalid, does nothing and returns an error. Otherwise, all
+// payments are executed, the sender's nonce is increased once and the fee is
+// credited to the fee collector.
+func (e *Epoch) ProcessBatchTx(params *tee.Parameters, tx *tee.BatchTransaction) error {
+	sender, ok := e.accs[tx.Sender]
+	if !ok {
+		return errors.New("sender does not exist")
+	}
+
+	if len(tx.Payments) == 0 {
+		return errors.New("empty batch")
+	} else if len(tx.Payments) > tee.MaxBatchSize {
+		return fmt.Errorf("batch too large: %d > %d", len(tx.Payments), tee.MaxBatchSize)
+	}
+	for i, p := range tx.Payments {
+		if p.Amount == nil || (*big.Int)(p.Amount).Sign() < 0 {
+			return fmt.Errorf("invalid amount in payment[%d]", i)
+		} else if _, locked := e.exitLocked[p.Recipient]; locked {
+			return fmt.Errorf("recipient of payment[%d] is locked for withdrawing", i)
+		}
+	}
+
+	total, fee := tx.Total(), tx.FeeValue()
+	// The sender pays the fee in ETH, on top of ETH transfers.
+	ethCost := new(big.Int).Set(fee)
+	if tx.Token == tee.ETHToken {
+		ethCost.Add(ethCost, total)
+	}
+
+	if _, locked := e.exitLocked[tx.Sender]; locked {
+		return errors.New("sender is locked for withdrawing")
+	} else if tx.Nonce != sender.Nonce+1 {
+		return fmt.Errorf("nonce mismatch: %d != %d", tx.Nonce, sender.Nonce+1)
+	} else if fee.Sign() < 0 {
+		return errors.New("negative fee")
+	} else if minFee := params.BatchFee(tx.Token, total); fee.Cmp(minFee) < 0 {
+		return fmt.Errorf("insufficient fee: %v < %v", fee, minFee)
+	} else if fee.Sign() > 0 && params.FeeCollector == (common.Address{}) {
+		return errors.New("fee without fee collector")
+	} else if e.spendable(tx.Sender, tx.Token).Cmp(total) < 0 || e.spendable(tx.Sender, tee.ETHToken).Cmp(ethCost) < 0 {
+		return errors.New("insufficient balance")
+	} else if tx.Epoch != e.TxNum() {
+		return fmt.Errorf("epoch mismatch: %d != %d", tx.Epoch, e.TxNum())
+	} else if valid, err := tee.VerifyBatchTransaction(params.Contract, *tx); err != nil {
+		return fmt.Errorf("verifying batch tx signature: %w", err)
+	} else if !valid {
+		return fmt.Errorf("invalid batch tx signature")
+	}
+
+	// Execute all payments.
+	sender.add(tx.Token, new(big.Int).Neg(total))
+	sender.add(tee.ETHToken, new(big.Int).Neg(fee))
+	sender.Nonce = tx.Nonce
+	for _, p := range tx.Payments {
+		e.receive(p.Recipient, tx.Token, new(big.Int).Set((*big.Int)(p.Amount)))
+	}
+	if fee.Sign() > 0 {
+		e.receive(params.FeeCollector, tee.ETHToken, fee)
+	}
 
 	return nil
 }
@@ -154,42 +307,90 @@ func (e *Epoch) ProcessTx(contract common.Address, tx *tee.Transaction) error {
 // phase-specific fields of the previous phase. It also applies any ongoing
 // exits.
 func (e *Epoch) progressPhase() {
-	// Settle current epoch.
+	// Settle current epoch. Exit proofs of the ending exit epoch can no
+	// longer be used, landed exits are withdrawn via the exit requests.
+	e.exitReserved = make(map[common.Address]*Acc)
 	exits := e.applyExits()
 	bals := e.cloneBals()
+	locks := e.cloneLocks()
 
 	// Publish the epoch's outcome.
-	e.outcome <- Outcome{TxEpoch: e.TxNum(), Exits: exits, Accounts: bals}
+	e.outcome <- Outcome{TxEpoch: e.TxNum(), Exits: exits, Accounts: bals, Locks: locks}
 	e.number++
 }
 
-// applyExits deletes all accounts that exited during the previous epoch and
-// returns their final balances. Exit requests for nonexisting accounts are
-// ignored.
+// applyExits withdraws the exit values of all accounts that were locked for
+// withdrawing during the previous epoch and returns the withdrawn values.
+// Accounts without any balance left are deleted. Exit requests for nonexisting
+// accounts are ignored.
 func (e *Epoch) applyExits() map[common.Address]*Acc {
 	exits := make(map[common.Address]*Acc)
-	// Delete and collect all accounts that were locked for withdrawing during
-	// this epoch.
-	for addr := range e.exitLocked {
-		if bal, ok := e.accs[addr]; ok {
-			delete(e.accs, addr)
-			exits[addr] = bal
+	// Reduce all accounts that were locked for withdrawing during this epoch
+	// and collect the withdrawn values.
+	for addr, req := range e.exitLocked {
+		e.releaseLocks(addr, req)
+		if acc, ok := e.accs[addr]; ok {
+			// The contract accepts exits with any valid balance proof of the
+			// exit epoch, even if the account spent some of the proven balance
+			// afterwards. Such exits can only withdraw what is left.
+			withdrawn, shortfall := acc.withdraw(req)
+			if shortfall != nil {
+				log.WithFields(log.Fields{
+					"account":   addr.Hex(),
+					"shortfall": shortfall.Value,
+					"tokens":    shortfall.Tokens,
+				}).Warn("Epoch.applyExits: Exit exceeds balance, withdrawing remaining balance")
+			}
+			exits[addr] = withdrawn
+			if acc.Value.Sign() == 0 && len(acc.Tokens) == 0 {
+				delete(e.accs, addr)
+			}
 		}
 	}
 	// Lock all accounts that requested to withdraw within the next epoch.
-	e.exitLocked, e.exitReqs = e.exitReqs, make(map[common.Address]struct{})
+	e.exitLocked, e.exitReqs = e.exitReqs, make(map[common.Address]*Acc)
 	return exits
 }
 
 func (e *Epoch) cloneBals() map[common.Address]*Acc {
-	accs := make(map[common.Address]*Acc)
-	for addr, acc := range e.accs {
-		accs[addr] = &Acc{
-			Nonce: acc.Nonce,
-			Value: new(big.Int).Set(acc.Value),
-		}
+	return cloneAccs(e.accs)
+}
+
+// cloneAccs deeply copies the given accounts.
+func cloneAccs(accs map[common.Address]*Acc) map[common.Address]*Acc {
+	clone := make(map[common.Address]*Acc, len(accs))
+	for addr, acc := range accs {
+		clone[addr] = acc.clone()
+	}
+	return clone
+}
+
+// spendable returns the account's balance of the token that is neither
+// reserved by an issued exit proof nor requested to exit. As a later exit
+// replaces an earlier one, only the larger of both is reserved.
+func (e *Epoch) spendable(who, token common.Address) *big.Int {
+	committed := new(big.Int)
+	if r, ok := e.exitReserved[who]; ok {
+		committed = r.Balance(token)
+	}
+	if r, ok := e.exitReqs[who]; ok && r.Balance(token).Cmp(committed) > 0 {
+		committed = r.Balance(token)
+	}
+	return committed.Sub(e.TokenBalance(who, token), committed)
+}
+
+// ReserveExit reserves the value of an issued exit proof of the current exit
+// epoch, so that it cannot be spent until the exit epoch ends. The value must
+// not exceed the account's balance.
+func (e *Epoch) ReserveExit(who, token common.Address, value *big.Int) {
+	r, ok := e.exitReserved[who]
+	if !ok {
+		r = &Acc{Value: new(big.Int)}
+		e.exitReserved[who] = r
+	}
+	if r.Balance(token).Cmp(value) < 0 {
+		r.set(token, value)
 	}
-	return accs
 }
 
 // IsExitLocked returns whether an account is exit locked.
@@ -198,11 +399,96 @@ func (e *Epoch) IsExitLocked(who common.Address) bool {
 	return ok
 }
 
-// Balance looks up an account balance in the epoch.
+// Balance looks up an account's ETH balance in the epoch.
 func (e *Epoch) Balance(who common.Address) *big.Int {
+	return e.TokenBalance(who, tee.ETHToken)
+}
+
+// TokenBalance looks up an account's balance of the given token in the epoch.
+func (e *Epoch) TokenBalance(who, token common.Address) *big.Int {
 	if acc, ok := e.accs[who]; ok {
-		return new(big.Int).Set(acc.Value)
+		return acc.Balance(token)
 	} else {
 		return big.NewInt(0)
 	}
 }
+
+// Balance returns the account's balance of the given token.
+func (a *Acc) Balance(token common.Address) *big.Int {
+	if token == tee.ETHToken {
+		return new(big.Int).Set(a.Value)
+	} else if v, ok := a.Tokens[token]; ok {
+		return new(big.Int).Set(v)
+	}
+	return big.NewInt(0)
+}
+
+// add adds value, which may be negative, to the account's balance of the
+// given token. Zero token balances are removed.
+func (a *Acc) add(token common.Address, value *big.Int) {
+	if token == tee.ETHToken {
+		a.Value.Add(a.Value, value)
+		return
+	}
+	if a.Tokens == nil {
+		a.Tokens = make(map[common.Address]*big.Int)
+	}
+	v, ok := a.Tokens[token]
+	if !ok {
+		v = new(big.Int)
+		a.Tokens[token] = v
+	}
+	if v.Add(v, value).Sign() == 0 {
+		delete(a.Tokens, token)
+	}
+}
+
+// set sets the account's balance of the given token.
+func (a *Acc) set(token common.Address, value *big.Int) {
+	a.add(token, new(big.Int).Sub(value, a.Balance(token)))
+}
+
+// withdraw reduces the account's balances by the given values and returns the
+// withdrawn values. Values that exceed a balance only withdraw the balance, the
+// missing rest is returned as shortfall, which is nil if nothing is missing.
+func (a *Acc) withdraw(values *Acc) (withdrawn, shortfall *Acc) {
+	tokens := []common.Address{tee.ETHToken}
+	for token := range values.Tokens {
+		tokens = append(tokens, token)
+	}
+
+	withdrawn = &Acc{Value: new(big.Int)}
+	for _, token := range tokens {
+		v, bal := values.Balance(token), a.Balance(token)
+		if v.Cmp(bal) > 0 {
+			if shortfall == nil {
+				shortfall = &Acc{Value: new(big.Int)}
+			}
+			shortfall.add(token, new(big.Int).Sub(v, bal))
+			v = bal
+		}
+		a.add(token, new(big.Int).Neg(v))
+		withdrawn.add(token, v)
+	}
+	return withdrawn, shortfall
+}
+
+// isEmpty returns whether the account has no balances and never sent a
+// transaction.
+func (a *Acc) isEmpty() bool {
+	return a.Nonce == 0 && a.Value.Sign() == 0 && len(a.Tokens) == 0
+}
+
+func (a *Acc) clone() *Acc {
+	c := &Acc{
+		Nonce: a.Nonce,
+		Value: new(big.Int).Set(a.Value),
+	}
+	for token, v := range a.Tokens {
+		if c.Tokens == nil {
+			c.Tokens = make(map[common.Address]*big.Int, len(a.Tokens))
+		}
+		c.Tokens[token] = new(big.Int).Set(v)
+	}
+	return c
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEpoch_ProcessBatchTx(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice := aliceAcc.Account.Address
+	recipients := make([]common.Address, 200)
+	for i := range recipients {
+		recipients[i] = eth.NewRandomAddress(rng)
+	}
+
+	params := &tee.Parameters{
+		Contract:     eth.NewRandomAddress(rng),
+		FeeFlat:      10,
+		FeeCollector: eth.NewRandomAddress(rng),
+	}
+	ep := newEpoch(1)
+	ep.accs[alice] = &Acc{Value: big.NewInt(1000)}
+
+	newBatch := func(nonce uint64, amount int64, fee int64) *tee.BatchTransaction {
+		tx := &tee.BatchTransaction{
+			Nonce:  nonce,
+			Epoch:  ep.TxNum(),
+			Sender: alice,
+			Fee:    (*tee.Amount)(big.NewInt(fee)),
+		}
+		for _, r := range recipients {
+			tx.Payments = append(tx.Payments, tee.Payment{Recipient: r, Amount: (*tee.Amount)(big.NewInt(amount))})
+		}
+		require.NoError(tx.Sign(params.Contract, aliceAcc.Account, hdw))
+		return tx
+	}
+	requireUnchanged := func() {
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(1000)))
+		require.Zero(ep.accs[alice].Nonce)
+		for _, r := range recipients {
+			require.NotContains(ep.accs, r)
+		}
+	}
+
+	t.Run("insufficient-funds", func(t *testing.T) {
+		// 200 * 5 + 10 > 1000, no payment must be applied.
+		require.Error(ep.ProcessBatchTx(params, newBatch(1, 5, 10)))
+		requireUnchanged()
+	})
+
+	t.Run("insufficient-fee", func(t *testing.T) {
+		require.Error(ep.ProcessBatchTx(params, newBatch(1, 4, 9)))
+		requireUnchanged()
+	})
+
+	t.Run("invalid-sig", func(t *testing.T) {
+		tx := newBatch(1, 4, 10)
+		tx.Payments[3].Amount = (*tee.Amount)(big.NewInt(0))
+		require.Error(ep.ProcessBatchTx(params, tx))
+		requireUnchanged()
+	})
+
+	t.Run("locked-recipient", func(t *testing.T) {
+		ep.exitLocked[recipients[42]] = &Acc{Value: new(big.Int)}
+		require.Error(ep.ProcessBatchTx(params, newBatch(1, 4, 10)))
+		delete(ep.exitLocked, recipients[42])
+		requireUnchanged()
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(ep.ProcessBatchTx(params, newBatch(1, 4, 10)))
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(190)))
+		require.Equal(uint64(1), ep.accs[alice].Nonce)
+		for _, r := range recipients {
+			require.Zero(ep.Balance(r).Cmp(big.NewInt(4)))
+		}
+		require.Zero(ep.Balance(params.FeeCollector).Cmp(big.NewInt(10)))
+		// Replays are rejected.
+		require.Error(ep.ProcessBatchTx(params, newBatch(1, 0, 10)))
+	})
+}
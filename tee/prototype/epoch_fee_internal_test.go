@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEpoch_Fees(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice, bob, token := aliceAcc.Account.Address, eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	params := &tee.Parameters{
+		Contract:     eth.NewRandomAddress(rng),
+		FeeFlat:      10,
+		FeeRate:      100, // 1%
+		FeeCollector: eth.NewRandomAddress(rng),
+	}
+	require.Zero(params.TxFee(tee.ETHToken, big.NewInt(1000)).Cmp(big.NewInt(20)))
+	require.Zero(params.TxFee(token, big.NewInt(1000)).Cmp(big.NewInt(10)))
+
+	ep := newEpoch(1)
+	ep.accs[alice] = &Acc{
+		Value:  big.NewInt(1000),
+		Tokens: map[common.Address]*big.Int{token: big.NewInt(50)},
+	}
+	var nonce uint64
+	newTx := func(token common.Address, amount, fee int64) *tee.Transaction {
+		tx := &tee.Transaction{
+			Nonce:     nonce + 1,
+			Epoch:     ep.TxNum(),
+			Sender:    alice,
+			Recipient: bob,
+			Token:     token,
+			Amount:    (*tee.Amount)(big.NewInt(amount)),
+			Fee:       (*tee.Amount)(big.NewInt(fee)),
+		}
+		require.NoError(tx.Sign(params.Contract, aliceAcc.Account, hdw))
+		return tx
+	}
+	process := func(tx *tee.Transaction) error {
+		err := ep.ProcessTx(params, tx)
+		if err == nil {
+			nonce++
+		}
+		return err
+	}
+
+	t.Run("insufficient-fee", func(t *testing.T) {
+		require.Error(process(newTx(tee.ETHToken, 500, 14)))
+		require.Error(process(newTx(token, 50, 9)))
+		tx := newTx(tee.ETHToken, 500, 0)
+		tx.Fee = nil
+		require.NoError(tx.Sign(params.Contract, aliceAcc.Account, hdw))
+		require.Error(process(tx))
+	})
+
+	t.Run("fee-not-signed", func(t *testing.T) {
+		tx := newTx(tee.ETHToken, 500, 15)
+		tx.Fee = (*tee.Amount)(big.NewInt(16))
+		require.Error(process(tx))
+	})
+
+	t.Run("eth", func(t *testing.T) {
+		// The fee is paid on top of the amount.
+		require.Error(process(newTx(tee.ETHToken, 990, 20)))
+		require.NoError(process(newTx(tee.ETHToken, 500, 15)))
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(485)))
+		require.Zero(ep.Balance(bob).Cmp(big.NewInt(500)))
+		require.Zero(ep.Balance(params.FeeCollector).Cmp(big.NewInt(15)))
+	})
+
+	t.Run("token", func(t *testing.T) {
+		// Token transfers pay the fee in ETH.
+		require.NoError(process(newTx(token, 50, 10)))
+		require.Zero(ep.TokenBalance(bob, token).Cmp(big.NewInt(50)))
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(475)))
+		require.Zero(ep.Balance(params.FeeCollector).Cmp(big.NewInt(25)))
+	})
+
+	t.Run("no-collector", func(t *testing.T) {
+		noFees := &tee.Parameters{Contract: params.Contract}
+		require.Error(ep.ProcessTx(noFees, newTx(tee.ETHToken, 10, 1)))
+		require.NoError(ep.ProcessTx(noFees, newTx(tee.ETHToken, 10, 0)))
+		nonce++
+	})
+
+	t.Run("balance-proofs", func(t *testing.T) {
+		ep.progressPhase()
+		require.Contains(ep.Outcome().Accounts, params.FeeCollector)
+	})
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/perun-network/erdstall/tee"
+)
+
+// ProcessHTLCOp processes an operation on hash-time-locked payments. If it is
+// invalid, does nothing and returns an error.
+func (e *Epoch) ProcessHTLCOp(params *tee.Parameters, op *tee.HTLCOp) error {
+	switch {
+	case op.Lock != nil && op.Claim == nil && op.Refund == nil:
+		return e.lock(params, op.Lock)
+	case op.Claim != nil && op.Lock == nil && op.Refund == nil:
+		return e.claim(op.Claim)
+	case op.Refund != nil && op.Lock == nil && op.Claim == nil:
+		return e.refund(op.Refund)
+	default:
+		return errors.New("htlc operation must have exactly one operation set")
+	}
+}
+
+// lock locks the amount of a hash-time-locked payment. The lock is validated
+// like a transaction and increases the sender's nonce.
+func (e *Epoch) lock(params *tee.Parameters, h *tee.HTLC) error {
+	sender, ok := e.accs[h.Sender]
+	if !ok {
+		return errors.New("sender does not exist")
+	}
+
+	if h.Amount == nil {
+		return errors.New("missing amount")
+	}
+	amount, fee := (*big.Int)(h.Amount), h.FeeValue()
+	// The sender pays the fee in ETH, on top of locked ETH.
+	ethCost := new(big.Int).Set(fee)
+	if h.Token == tee.ETHToken {
+		ethCost.Add(ethCost, amount)
+	}
+
+	if _, locked := e.exitLocked[h.Sender]; locked {
+		return errors.New("sender is locked for withdrawing")
+	} else if h.Nonce != sender.Nonce+1 {
+		return fmt.Errorf("nonce mismatch: %d != %d", h.Nonce, sender.Nonce+1)
+	} else if amount.Sign() <= 0 {
+		return errors.New("non-positive amount")
+	} else if fee.Sign() < 0 {
+		return errors.New("negative fee")
+	} else if minFee := params.TxFee(h.Token, amount); fee.Cmp(minFee) < 0 {
+		return fmt.Errorf("insufficient fee: %v < %v", fee, minFee)
+	} else if fee.Sign() > 0 && params.FeeCollector == (common.Address{}) {
+		return errors.New("fee without fee collector")
+	} else if e.spendable(h.Sender, h.Token).Cmp(amount) < 0 || e.spendable(h.Sender, tee.ETHToken).Cmp(ethCost) < 0 {
+		return errors.New("insufficient balance")
+	} else if h.Epoch != e.TxNum() {
+		return fmt.Errorf("epoch mismatch: %d != %d", h.Epoch, e.TxNum())
+	} else if h.Expiry < h.Epoch {
+		return fmt.Errorf("expiry %d before epoch %d", h.Expiry, h.Epoch)
+	} else if _, exists := e.locks[h.ID()]; exists {
+		return errors.New("lock already exists")
+	} else if valid, err := tee.VerifyHTLC(params.Contract, *h); err != nil {
+		return fmt.Errorf("verifying htlc signature: %w", err)
+	} else if !valid {
+		return fmt.Errorf("invalid htlc signature")
+	}
+
+	// Lock the amount.
+	sender.add(h.Token, new(big.Int).Neg(amount))
+	sender.add(tee.ETHToken, new(big.Int).Neg(fee))
+	sender.Nonce = h.Nonce
+	e.locks[h.ID()] = h.Clone()
+	if fee.Sign() > 0 {
+		e.receive(params.FeeCollector, tee.ETHToken, fee)
+	}
+	return nil
+}
+
+// claim pays a locked amount to the lock's recipient if the preimage matches
+// the hash lock and the lock did not expire.
+func (e *Epoch) claim(c *tee.HTLCClaim) error {
+	h, ok := e.locks[c.ID]
+	if !ok {
+		return errors.New("unknown lock")
+	} else if e.TxNum() > h.Expiry {
+		return fmt.Errorf("lock expired in epoch %d", h.Expiry)
+	} else if tee.HashLock(c.Preimage) != h.HashLock {
+		return errors.New("preimage does not match hash lock")
+	} else if _, locked := e.exitLocked[h.Recipient]; locked {
+		return errors.New("recipient is locked for withdrawing")
+	} else if _, locked := e.exitLocked[h.Sender]; locked {
+		// The sender's exit may pay out the lock, see releaseLocks.
+		return errors.New("sender is locked for withdrawing")
+	} else if _, exiting := e.exitReqs[h.Sender]; exiting {
+		return errors.New("sender is exiting")
+	}
+
+	delete(e.locks, c.ID)
+	e.receive(h.Recipient, h.Token, (*big.Int)(h.Amount))
+	return nil
+}
+
+// refund pays a locked amount back to the lock's sender after the lock
+// expired.
+func (e *Epoch) refund(r *tee.HTLCRefund) error {
+	h, ok := e.locks[r.ID]
+	if !ok {
+		return errors.New("unknown lock")
+	} else if e.TxNum() <= h.Expiry {
+		return fmt.Errorf("lock expires in epoch %d", h.Expiry)
+	} else if _, locked := e.exitLocked[h.Sender]; locked {
+		return errors.New("sender is locked for withdrawing")
+	}
+
+	delete(e.locks, r.ID)
+	e.receive(h.Sender, h.Token, (*big.Int)(h.Amount))
+	return nil
+}
+
+// releaseLocks refunds the open locks of an exiting sender while its balance
+// of a token does not cover the exit value. Open locks are part of the
+// sender's balance proofs, so an exit may pay them out.
+func (e *Epoch) releaseLocks(sender common.Address, values *Acc) {
+	ids := make([]common.Hash, 0, len(e.locks))
+	for id, h := range e.locks {
+		if h.Sender == sender {
+			ids = append(ids, id)
+		}
+	}
+	// Refund older locks first.
+	sort.Slice(ids, func(i, j int) bool { return e.locks[ids[i]].Nonce < e.locks[ids[j]].Nonce })
+	for _, id := range ids {
+		h := e.locks[id]
+		if e.TokenBalance(sender, h.Token).Cmp(values.Balance(h.Token)) >= 0 {
+			continue
+		}
+		delete(e.locks, id)
+		e.receive(sender, h.Token, (*big.Int)(h.Amount))
+	}
+}
+
+// LockedBalance returns the sum of the sender's open locks of the token.
+func (e *Epoch) LockedBalance(sender, token common.Address) *big.Int {
+	locked := new(big.Int)
+	for _, h := range e.locks {
+		if h.Sender == sender && h.Token == token {
+			locked.Add(locked, (*big.Int)(h.Amount))
+		}
+	}
+	return locked
+}
+
+// lockedBalances returns the sum of the open locks per sender and token.
+func lockedBalances(locks map[common.Hash]*tee.HTLC) map[common.Address]*Acc {
+	locked := make(map[common.Address]*Acc)
+	for _, h := range locks {
+		acc, ok := locked[h.Sender]
+		if !ok {
+			acc = &Acc{Value: new(big.Int)}
+			locked[h.Sender] = acc
+		}
+		acc.add(h.Token, (*big.Int)(h.Amount))
+	}
+	return locked
+}
+
+// cloneLocks deeply copies all open locks.
+func (e *Epoch) cloneLocks() map[common.Hash]*tee.HTLC {
+	locks := make(map[common.Hash]*tee.HTLC, len(e.locks))
+	for id, h := range e.locks {
+		locks[id] = h.Clone()
+	}
+	return locks
+}
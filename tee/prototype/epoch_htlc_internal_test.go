@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package prototype
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	ptest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestEpoch_HTLC(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	aliceAcc, err := w.NewAccount()
+	require.NoError(err)
+	alice, bob := aliceAcc.Account.Address, eth.NewRandomAddress(rng)
+	params := &tee.Parameters{Contract: eth.NewRandomAddress(rng)}
+
+	ep := newEpoch(3)
+	ep.accs[alice] = &Acc{Value: big.NewInt(100), Tokens: make(map[common.Address]*big.Int)}
+	preimage := common.Hash{1, 2, 3}
+	var nonce uint64
+	newLock := func(amount int64, expiry tee.Epoch) *tee.HTLC {
+		nonce++
+		h := &tee.HTLC{
+			Nonce:     nonce,
+			Epoch:     ep.TxNum(),
+			Sender:    alice,
+			Recipient: bob,
+			Token:     tee.ETHToken,
+			Amount:    (*tee.Amount)(big.NewInt(amount)),
+			HashLock:  tee.HashLock(preimage),
+			Expiry:    ep.TxNum() + expiry,
+		}
+		require.NoError(h.Sign(params.Contract, aliceAcc.Account, hdw))
+		return h
+	}
+	process := func(op tee.HTLCOp) error { return ep.ProcessHTLCOp(params, &op) }
+	claim := func(id, preimage common.Hash) error {
+		return process(tee.HTLCOp{Claim: &tee.HTLCClaim{ID: id, Preimage: preimage}})
+	}
+	refund := func(id common.Hash) error {
+		return process(tee.HTLCOp{Refund: &tee.HTLCRefund{ID: id}})
+	}
+
+	h1 := newLock(30, 0)
+	h2 := newLock(20, 0)
+
+	t.Run("invalid-lock", func(t *testing.T) {
+		require.Error(process(tee.HTLCOp{}))
+		require.Error(process(tee.HTLCOp{Lock: h1, Refund: &tee.HTLCRefund{ID: h1.ID()}}))
+		h := *h1
+		h.Amount = (*tee.Amount)(big.NewInt(31))
+		require.Error(process(tee.HTLCOp{Lock: &h}))
+		h = *h1
+		h.Expiry--
+		require.NoError(h.Sign(params.Contract, aliceAcc.Account, hdw))
+		require.Error(process(tee.HTLCOp{Lock: &h}))
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		require.NoError(process(tee.HTLCOp{Lock: h1}))
+		require.NoError(process(tee.HTLCOp{Lock: h2}))
+		require.Error(process(tee.HTLCOp{Lock: h2}), "nonce reuse")
+		ep.accs[alice].Nonce-- // As if the nonce restarted.
+		require.Error(process(tee.HTLCOp{Lock: h2}), "id reuse")
+		ep.accs[alice].Nonce++
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(50)))
+		require.Len(ep.locks, 2)
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		require.Error(refund(h1.ID()), "refund before expiry")
+		require.Error(claim(h1.ID(), common.Hash{4}))
+		require.NoError(claim(h1.ID(), preimage))
+		require.Error(claim(h1.ID(), preimage), "double claim")
+		require.Zero(ep.Balance(bob).Cmp(big.NewInt(30)))
+	})
+
+	t.Run("outcome", func(t *testing.T) {
+		ep.progressPhase()
+		out := ep.Outcome()
+		require.Contains(out.Locks, h2.ID())
+		require.NotContains(out.Locks, h1.ID())
+		require.Zero(out.Accounts[alice].Value.Cmp(big.NewInt(50)))
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		require.Error(claim(h2.ID(), preimage), "claim after expiry")
+		require.NoError(refund(h2.ID()))
+		require.Error(refund(h2.ID()), "double refund")
+		require.Zero(ep.Balance(alice).Cmp(big.NewInt(70)))
+		require.Empty(ep.locks)
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		h3 := newLock(30, 1)
+		h4 := newLock(10, 1)
+		require.NoError(process(tee.HTLCOp{Lock: h3}))
+		require.NoError(process(tee.HTLCOp{Lock: h4}))
+		require.Zero(ep.LockedBalance(alice, tee.ETHToken).Cmp(big.NewInt(40)))
+
+		// Alice exits her balance proof, which includes the locks.
+		ep.RegisterExits(&erdstallExitEvent{Epoch: ep.ExitNum(), Account: alice, Value: big.NewInt(60)})
+		ep.progressPhase()
+		ep.Outcome()
+		require.Error(claim(h3.ID(), preimage), "sender exit locked")
+
+		ep.progressPhase()
+		out := ep.Outcome()
+		require.Zero(out.Exits[alice].Value.Cmp(big.NewInt(60)))
+		require.NotContains(out.Accounts, alice, "emptied account removed")
+		require.NotContains(out.Locks, h3.ID(), "older lock released")
+		require.Contains(out.Locks, h4.ID())
+	})
+}
+
+func TestEnclave_LockedBalanceProofs(t *testing.T) {
+	require := require.New(t)
+	rng := ptest.Prng(t)
+	enc, params := newSnapshotEnclave(t, rng)
+	alice, bob, token := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	out := Outcome{
+		Accounts: map[common.Address]*Acc{
+			alice: {Value: big.NewInt(10)},
+		},
+		Locks: map[common.Hash]*tee.HTLC{
+			{1}: {Sender: alice, Recipient: bob, Token: tee.ETHToken, Amount: (*tee.Amount)(big.NewInt(5))},
+			{2}: {Sender: alice, Recipient: bob, Token: token, Amount: (*tee.Amount)(big.NewInt(7))},
+			{3}: {Sender: bob, Recipient: alice, Token: token, Amount: (*tee.Amount)(big.NewInt(3))},
+		},
+	}
+	values := make(map[accToken]int64)
+	for _, bp := range enc.generateBalanceProofs(out) {
+		ok, err := tee.VerifyBalanceProof(params, *bp)
+		require.NoError(err)
+		require.True(ok)
+		values[accToken{bp.Balance.Account, bp.Balance.Token}] = (*big.Int)(bp.Balance.Value).Int64()
+	}
+	require.Equal(map[accToken]int64{
+		{alice, tee.ETHToken}: 15,
+		{alice, token}:        7,
+		{bob, tee.ETHToken}:   0,
+		{bob, token}:          3,
+	}, values)
+	require.Zero(out.Accounts[alice].Value.Cmp(big.NewInt(10)), "outcome unchanged")
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/perun-network/erdstall/contracts/bindings"
+)
+
+// contractEvents are the topics of all Erdstall contract events.
+var contractEvents = func() []common.Hash {
+	contractAbi, err := abi.JSON(strings.NewReader(bindings.ErdstallABI))
+	if err != nil {
+		panic(fmt.Sprintf("parsing contract ABI: %v", err))
+	}
+	var ids []common.Hash
+	for _, ev := range contractAbi.Events {
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}()
+
+// NewCompactBlock creates a Block in compact mode, which only contains the
+// receipts of transactions sent to the contract, together with proofs of their
+// inclusion in the block's receipt trie. receipts must contain the receipts of
+// all of the block's transactions. If the header's logs bloom indicates
+// contract events, which may also be emitted by internal calls, the block
+// contains all receipts instead.
+func NewCompactBlock(block *types.Block, receipts types.Receipts, contract common.Address) (*Block, error) {
+	txs := block.Transactions()
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("got %d receipts for %d transactions", len(receipts), len(txs))
+	}
+
+	receiptTrie, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		return nil, fmt.Errorf("creating receipt trie: %w", err)
+	}
+	for i, r := range receipts {
+		enc, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("encoding receipt %d: %w", i, err)
+		}
+		receiptTrie.Update(receiptKey(uint64(i)), enc)
+	}
+	if root := receiptTrie.Hash(); root != block.ReceiptHash() {
+		return nil, fmt.Errorf("receipt root mismatch, header: %x, receipts: %x", block.ReceiptHash(), root)
+	}
+
+	if hasContractLogs(block.Header(), contract) {
+		return &Block{Block: *block, Receipts: receipts}, nil
+	}
+
+	compact := &Block{Block: *block}
+	for i, tx := range txs {
+		if !isContractTx(tx, contract) {
+			continue
+		}
+		nodes := memorydb.New()
+		if err := receiptTrie.Prove(receiptKey(uint64(i)), 0, nodes); err != nil {
+			return nil, fmt.Errorf("proving receipt %d: %w", i, err)
+		}
+		proof := ReceiptProof{Index: uint64(i)}
+		it := nodes.NewIterator(nil, nil)
+		for it.Next() {
+			proof.Nodes = append(proof.Nodes, common.CopyBytes(it.Value()))
+		}
+		it.Release()
+		compact.Receipts = append(compact.Receipts, receipts[i])
+		compact.ReceiptProofs = append(compact.ReceiptProofs, proof)
+	}
+	return compact, nil
+}
+
+// VerifyReceipts verifies that the block's transactions match the header's
+// transaction root and that its receipts are part of the header's receipt
+// root. In compact mode, it also verifies that the block contains the receipts
+// of all transactions sent to the contract and that the header's logs bloom
+// rules out contract events in other transactions.
+func (b *Block) VerifyReceipts(contract common.Address) error {
+	txs := b.Transactions()
+	if root := types.DeriveSha(txs, trie.NewStackTrie(nil)); root != b.TxHash() {
+		return fmt.Errorf("transaction root mismatch, header: %x, transactions: %x", b.TxHash(), root)
+	}
+
+	if len(b.Receipts) == len(txs) {
+		if root := types.DeriveSha(b.Receipts, trie.NewStackTrie(nil)); root != b.ReceiptHash() {
+			return fmt.Errorf("receipt root mismatch, header: %x, receipts: %x", b.ReceiptHash(), root)
+		}
+		return nil
+	}
+	return b.verifyReceiptProofs(contract)
+}
+
+// verifyReceiptProofs verifies the receipts of a compact block.
+func (b *Block) verifyReceiptProofs(contract common.Address) error {
+	if len(b.ReceiptProofs) != len(b.Receipts) {
+		return fmt.Errorf("got %d receipt proofs for %d receipts", len(b.ReceiptProofs), len(b.Receipts))
+	} else if hasContractLogs(b.Header(), contract) {
+		return errors.New("logs bloom matches contract events, all receipts required")
+	}
+
+	proven := make(map[uint64]bool)
+	for i, r := range b.Receipts {
+		proof := b.ReceiptProofs[i]
+		if i > 0 && proof.Index <= b.ReceiptProofs[i-1].Index {
+			return errors.New("receipt proofs not in transaction order")
+		}
+		nodes := memorydb.New()
+		for _, node := range proof.Nodes {
+			if err := nodes.Put(crypto.Keccak256(node), node); err != nil {
+				return fmt.Errorf("storing proof node: %w", err)
+			}
+		}
+		value, err := trie.VerifyProof(b.ReceiptHash(), receiptKey(proof.Index), nodes)
+		if err != nil {
+			return fmt.Errorf("invalid proof of receipt %d: %w", proof.Index, err)
+		}
+		enc, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return fmt.Errorf("encoding receipt %d: %w", proof.Index, err)
+		} else if !bytes.Equal(enc, value) {
+			return fmt.Errorf("receipt %d does not match proof", proof.Index)
+		}
+		proven[proof.Index] = true
+	}
+
+	for i, tx := range b.Transactions() {
+		if isContractTx(tx, contract) && !proven[uint64(i)] {
+			return fmt.Errorf("missing receipt of contract transaction %d", i)
+		}
+	}
+	return nil
+}
+
+// receiptKey returns the receipt trie key of the receipt with the given index.
+func receiptKey(index uint64) []byte {
+	return rlp.AppendUint64(nil, index)
+}
+
+// hasContractLogs tells whether the header's logs bloom may contain events of
+// the contract.
+func hasContractLogs(h *types.Header, contract common.Address) bool {
+	if !types.BloomLookup(h.Bloom, contract) {
+		return false
+	}
+	for _, id := range contractEvents {
+		if types.BloomLookup(h.Bloom, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContractTx tells whether tx is sent to the contract.
+func isContractTx(tx *types.Transaction, contract common.Address) bool {
+	return tx.To() != nil && *tx.To() == contract
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+	pkgtest "perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/contracts/bindings"
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+)
+
+func TestBlock_VerifyReceipts(t *testing.T) {
+	require := require.New(t)
+	rng := pkgtest.Prng(t)
+	contract, other := eth.NewRandomAddress(rng), eth.NewRandomAddress(rng)
+
+	// 200 transactions so that the receipt trie has multiple levels, every
+	// tenth transaction is sent to the contract.
+	var (
+		txs      types.Transactions
+		receipts types.Receipts
+	)
+	for i := 0; i < 200; i++ {
+		to := other
+		if i%10 == 3 {
+			to = contract
+		}
+		txs = append(txs, types.NewTransaction(uint64(i), to, big.NewInt(1), 0, nil, nil))
+		receipts = append(receipts, &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i),
+			Logs:              []*types.Log{{Address: to, Data: []byte{byte(i)}}},
+		})
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, receipts, trie.NewStackTrie(nil))
+
+	t.Run("full", func(t *testing.T) {
+		require.NoError((&tee.Block{Block: *block, Receipts: receipts}).VerifyReceipts(contract))
+
+		tampered := append(types.Receipts(nil), receipts...)
+		tampered[5] = &types.Receipt{Logs: []*types.Log{{Address: contract}}}
+		require.Error((&tee.Block{Block: *block, Receipts: tampered}).VerifyReceipts(contract))
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		compact, err := tee.NewCompactBlock(block, receipts, contract)
+		require.NoError(err)
+		require.Len(compact.Receipts, 20)
+		require.NoError(compact.VerifyReceipts(contract))
+
+		// Gob encoding keeps the proofs.
+		var buf bytes.Buffer
+		require.NoError(gob.NewEncoder(&buf).Encode(compact))
+		decoded := new(tee.Block)
+		require.NoError(gob.NewDecoder(&buf).Decode(decoded))
+		require.NoError(decoded.VerifyReceipts(contract))
+	})
+
+	t.Run("compact-missing", func(t *testing.T) {
+		compact, err := tee.NewCompactBlock(block, receipts, contract)
+		require.NoError(err)
+		compact.Receipts = compact.Receipts[1:]
+		compact.ReceiptProofs = compact.ReceiptProofs[1:]
+		require.Error(compact.VerifyReceipts(contract))
+	})
+
+	t.Run("compact-tampered", func(t *testing.T) {
+		compact, err := tee.NewCompactBlock(block, receipts, contract)
+		require.NoError(err)
+		compact.Receipts[0] = &types.Receipt{Logs: []*types.Log{{Address: contract}}}
+		require.Error(compact.VerifyReceipts(contract))
+
+		compact, err = tee.NewCompactBlock(block, receipts, contract)
+		require.NoError(err)
+		compact.ReceiptProofs[0], compact.ReceiptProofs[1] = compact.ReceiptProofs[1], compact.ReceiptProofs[0]
+		require.Error(compact.VerifyReceipts(contract))
+	})
+
+	t.Run("bloom", func(t *testing.T) {
+		// An internal call emits a contract event in a transaction that is
+		// not sent to the contract.
+		contractAbi, err := abi.JSON(strings.NewReader(bindings.ErdstallABI))
+		require.NoError(err)
+		internal := append(types.Receipts(nil), receipts...)
+		internal[5] = &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 5,
+			Logs:              []*types.Log{{Address: contract, Topics: []common.Hash{contractAbi.Events["Deposited"].ID}}},
+		}
+		internal[5].Bloom = types.CreateBloom(types.Receipts{internal[5]})
+		block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, internal, trie.NewStackTrie(nil))
+
+		compact, err := tee.NewCompactBlock(block, internal, contract)
+		require.NoError(err)
+		require.Len(compact.Receipts, len(txs))
+		require.NoError(compact.VerifyReceipts(contract))
+
+		compact, err = tee.NewCompactBlock(block, internal, contract)
+		require.NoError(err)
+		compact.Receipts = nil
+		require.Error(compact.VerifyReceipts(contract))
+	})
+
+	t.Run("tx-root", func(t *testing.T) {
+		h := block.Header()
+		h.TxHash = common.Hash{}
+		fake := types.NewBlockWithHeader(h).WithBody(block.Transactions(), nil)
+		require.Error((&tee.Block{Block: *fake, Receipts: receipts}).VerifyReceipts(contract))
+	})
+}
@@ -24,13 +24,22 @@ var _ tee.Enclave = (*mockEnclave)(nil)
 
 type mockEnclave struct{}
 
-func (*mockEnclave) Init() (_ common.Address, _ []byte, _ error)     { return }
-func (*mockEnclave) Run(tee.Parameters) (_ error)                    { return }
-func (*mockEnclave) Shutdown()                                       {}
-func (*mockEnclave) ProcessBlocks(...*tee.Block) (_ error)           { return }
-func (*mockEnclave) ProcessTXs(...*tee.Transaction) (_ error)        { return }
-func (*mockEnclave) DepositProofs() (_ []*tee.DepositProof, _ error) { return }
-func (*mockEnclave) BalanceProofs() (_ []*tee.BalanceProof, _ error) { return }
+func (*mockEnclave) Init() (_ common.Address, _ []byte, _ error)        { return }
+func (*mockEnclave) Run(tee.Parameters) (_ error)                       { return }
+func (*mockEnclave) Shutdown()                                          {}
+func (*mockEnclave) ProcessBlocks(...*tee.Block) (_ error)              { return }
+func (*mockEnclave) ProcessTXs(...*tee.Transaction) (_ error)           { return }
+func (*mockEnclave) ProcessBatchTXs(...*tee.BatchTransaction) (_ error) { return }
+func (*mockEnclave) ProcessHTLCs(...*tee.HTLCOp) (_ error)              { return }
+func (*mockEnclave) DepositProofs() (_ []*tee.DepositProof, _ error)    { return }
+func (*mockEnclave) BalanceProofs() (_ []*tee.BalanceProof, _ error)    { return }
+func (*mockEnclave) ExitProof(*tee.ExitRequest) (*tee.BalanceProof, error) {
+	return new(tee.BalanceProof), nil
+}
+
+func (*mockEnclave) Account(common.Address) (*tee.Account, error) {
+	return new(tee.Account), nil
+}
 
 var _ net.Listener = (*mockListener)(nil)
 
@@ -78,10 +87,16 @@ func TestConn(t *testing.T) {
 		assert.NoError(t, enc.Run(tee.Parameters{}))
 		assert.NoError(t, enc.ProcessBlocks())
 		assert.NoError(t, enc.ProcessTXs())
+		assert.NoError(t, enc.ProcessBatchTXs())
+		assert.NoError(t, enc.ProcessHTLCs())
 		_, err = enc.DepositProofs()
 		assert.NoError(t, err)
 		_, err = enc.BalanceProofs()
 		assert.NoError(t, err)
+		_, err = enc.ExitProof(&tee.ExitRequest{})
+		assert.NoError(t, err)
+		_, err = enc.Account(common.Address{})
+		assert.NoError(t, err)
 		enc.Shutdown()
 		assert.NoError(t, enc.Stop())
 	})
@@ -99,3 +114,13 @@ func TestRPCEnclave(t *testing.T) {
 	require.NoError(t, err)
 	ttest.GenericEnclaveTest(t, NewRPCEnclave(conn))
 }
+
+func TestGetErr(t *testing.T) {
+	assert.NoError(t, getErr(nil))
+	assert.Equal(t, tee.ErrEnclaveStopped, getErr(errors.New(tee.ErrEnclaveStopped.Error())))
+
+	// Remote errors are plain strings, the sentinels are restored from them.
+	err := getErr(errors.New(tee.ErrSealedEventsDropped.Error() + ": 2 events missing"))
+	assert.True(t, errors.Is(err, tee.ErrSealedEventsDropped))
+	assert.Equal(t, tee.ErrSealedEventsDropped.Error()+": 2 events missing", err.Error())
+}
@@ -3,9 +3,11 @@
 package rpc
 
 import (
+	"fmt"
 	"io"
 	"net"
 	"net/rpc"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/perun-network/erdstall/tee"
@@ -65,6 +67,14 @@ func (re *RPCEnclave) ProcessTXs(txs ...*tee.Transaction) error {
 	return getErr(re.client.Call("Server.ProcessTXs", &txs, &Void{}))
 }
 
+func (re *RPCEnclave) ProcessBatchTXs(txs ...*tee.BatchTransaction) error {
+	return getErr(re.client.Call("Server.ProcessBatchTXs", &txs, &Void{}))
+}
+
+func (re *RPCEnclave) ProcessHTLCs(ops ...*tee.HTLCOp) error {
+	return getErr(re.client.Call("Server.ProcessHTLCs", &ops, &Void{}))
+}
+
 func (re *RPCEnclave) DepositProofs() (res []*tee.DepositProof, err error) {
 	err = getErr(re.client.Call("Server.DepositProofs", Void{}, &res))
 	return
@@ -75,6 +85,22 @@ func (re *RPCEnclave) BalanceProofs() (res []*tee.BalanceProof, err error) {
 	return
 }
 
+func (re *RPCEnclave) ExitProof(req *tee.ExitRequest) (*tee.BalanceProof, error) {
+	var res tee.BalanceProof
+	if err := getErr(re.client.Call("Server.ExitProof", req, &res)); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (re *RPCEnclave) Account(who common.Address) (*tee.Account, error) {
+	var res tee.Account
+	if err := getErr(re.client.Call("Server.Account", who, &res)); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (re *RPCEnclave) Stop() error {
 	return getErr(re.client.Call("Server.Stop", Void{}, &Void{}))
 }
@@ -82,6 +108,9 @@ func (re *RPCEnclave) Stop() error {
 func getErr(err error) error {
 	if err != nil && err.Error() == tee.ErrEnclaveStopped.Error() {
 		return tee.ErrEnclaveStopped
+	} else if err != nil && strings.HasPrefix(err.Error(), tee.ErrSealedEventsDropped.Error()) {
+		return fmt.Errorf("%w%s", tee.ErrSealedEventsDropped,
+			strings.TrimPrefix(err.Error(), tee.ErrSealedEventsDropped.Error()))
 	}
 	return err
 }
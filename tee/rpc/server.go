@@ -76,6 +76,16 @@ func (n *Server) ProcessTXs(txs *[]*tee.Transaction, _ *Void) error {
 	return n.enclave.ProcessTXs(*txs...)
 }
 
+// ProcessBatchTXs wraps Enclave.ProcessBatchTXs.
+func (n *Server) ProcessBatchTXs(txs *[]*tee.BatchTransaction, _ *Void) error {
+	return n.enclave.ProcessBatchTXs(*txs...)
+}
+
+// ProcessHTLCs wraps Enclave.ProcessHTLCs.
+func (n *Server) ProcessHTLCs(ops *[]*tee.HTLCOp, _ *Void) error {
+	return n.enclave.ProcessHTLCs(*ops...)
+}
+
 // DepositProofs wraps Enclave.DepositProofs.
 func (n *Server) DepositProofs(_ Void, res *[]*tee.DepositProof) (err error) {
 	*res, err = n.enclave.DepositProofs()
@@ -88,6 +98,26 @@ func (n *Server) BalanceProofs(_ Void, res *[]*tee.BalanceProof) (err error) {
 	return
 }
 
+// ExitProof wraps Enclave.ExitProof.
+func (n *Server) ExitProof(req *tee.ExitRequest, res *tee.BalanceProof) error {
+	proof, err := n.enclave.ExitProof(req)
+	if err != nil {
+		return err
+	}
+	*res = *proof
+	return nil
+}
+
+// Account wraps Enclave.Account.
+func (n *Server) Account(who common.Address, res *tee.Account) error {
+	acc, err := n.enclave.Account(who)
+	if err != nil {
+		return err
+	}
+	*res = *acc
+	return nil
+}
+
 // Shutdown wraps Enclave.Shutdown.
 func (n *Server) Shutdown(Void, *Void) error {
 	n.enclave.Shutdown()
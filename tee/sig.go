@@ -32,3 +32,35 @@ func VerifyTransaction(contract common.Address, tx Transaction) (bool, error) {
 	}
 	return wallet.VerifySignature(msg, tx.Sig, (*wallet.Address)(&tx.Sender))
 }
+
+func VerifyExitRequest(contract common.Address, req ExitRequest) (bool, error) {
+	msg, err := EncodeExitRequest(contract, req)
+	if err != nil {
+		return false, fmt.Errorf("encoding exit request: %w", err)
+	}
+	return wallet.VerifySignature(msg, req.Sig, (*wallet.Address)(&req.Account))
+}
+
+func VerifySubscribeRequest(contract common.Address, req SubscribeRequest) (bool, error) {
+	msg, err := EncodeSubscribeRequest(contract, req)
+	if err != nil {
+		return false, fmt.Errorf("encoding subscribe request: %w", err)
+	}
+	return wallet.VerifySignature(msg, req.Sig, (*wallet.Address)(&req.Who))
+}
+
+func VerifyBatchTransaction(contract common.Address, tx BatchTransaction) (bool, error) {
+	msg, err := EncodeBatchTransaction(contract, tx)
+	if err != nil {
+		return false, fmt.Errorf("encoding batch tx: %w", err)
+	}
+	return wallet.VerifySignature(msg, tx.Sig, (*wallet.Address)(&tx.Sender))
+}
+
+func VerifyHTLC(contract common.Address, h HTLC) (bool, error) {
+	msg, err := EncodeHTLC(contract, h)
+	if err != nil {
+		return false, fmt.Errorf("encoding htlc: %w", err)
+	}
+	return wallet.VerifySignature(msg, h.Sig, (*wallet.Address)(&h.Sender))
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A SubscribeRequest requests a subscription to the proofs and incoming
+// transactions of account Who. Who signs the Challenge that the operator
+// issued for the connection, proving ownership of the account.
+type SubscribeRequest struct {
+	Who       common.Address `json:"who"`
+	Challenge common.Hash    `json:"challenge"`
+	Sig       Sig            `json:"sig"`
+}
+
+// Sign signs the subscribe request with the given account and signer. It
+// checks that the account matches the request's account.
+func (r *SubscribeRequest) Sign(contract common.Address, account accounts.Account, w TextSigner) error {
+	if account.Address != r.Who {
+		return errors.New("not the subscribing account")
+	}
+	msg, err := EncodeSubscribeRequest(contract, *r)
+	if err != nil {
+		return fmt.Errorf("encoding subscribe request: %w", err)
+	}
+	hash := crypto.Keccak256Hash(msg)
+	sig, err := w.SignText(account, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing subscribe request hash: %w", err)
+	}
+	sig[64] += 27
+
+	r.Sig = sig
+	return nil
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tee_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"perun.network/go-perun/backend/ethereum/wallet/hd"
+	"perun.network/go-perun/pkg/test"
+
+	"github.com/perun-network/erdstall/eth"
+	"github.com/perun-network/erdstall/tee"
+	wiretest "github.com/perun-network/erdstall/wire/test"
+)
+
+func TestSubscribeRequest_SignVerify(t *testing.T) {
+	require := require.New(t)
+	rng := test.Prng(t)
+	hdw := eth.NewHdWallet(rng)
+	w, err := hd.NewWallet(hdw, hd.DefaultRootDerivationPath.String(), 0)
+	require.NoError(err)
+	acc, err := w.NewAccount()
+	require.NoError(err)
+
+	contract := eth.NewRandomAddress(rng)
+	req := tee.SubscribeRequest{
+		Who:       acc.Account.Address,
+		Challenge: common.BytesToHash(eth.NewRandomAddress(rng).Bytes()),
+	}
+
+	other := req
+	other.Who = eth.NewRandomAddress(rng)
+	require.Error(other.Sign(contract, acc.Account, hdw))
+
+	require.NoError(req.Sign(contract, acc.Account, hdw))
+	ok, err := tee.VerifySubscribeRequest(contract, req)
+	require.NoError(err)
+	require.True(ok)
+
+	// A signature for one challenge must not be valid for another.
+	replayed := req
+	replayed.Challenge[0] ^= 1
+	ok, err = tee.VerifySubscribeRequest(contract, replayed)
+	require.NoError(err)
+	require.False(ok)
+
+	// Nor for another account.
+	other.Sig = req.Sig
+	ok, err = tee.VerifySubscribeRequest(contract, other)
+	require.NoError(err)
+	require.False(ok)
+
+	wiretest.GenericJSONMarshallingTest(t, req, &tee.SubscribeRequest{})
+}
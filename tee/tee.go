@@ -25,7 +25,8 @@ type (
 		//
 		// It returns the public key derived Ethereum address and attestation of
 		// correct initialization of the enclave with the generated address. The
-		// attestation can be used to verify the enclave with the TEE vendor.
+		// attestation can be used to verify the enclave with the TEE vendor. It is
+		// an encoded attestation.Quote, or empty if the enclave is not attested.
 		//
 		// The Operator must deploy the contract with the Enclave's address after
 		// calling Init.
@@ -53,6 +54,14 @@ type (
 		// can be received by calling BalanceProofs.
 		ProcessTXs(...*Transaction) error
 
+		// ProcessBatchTXs is the equivalent of ProcessTXs for batch
+		// transactions. Each batch is applied atomically.
+		ProcessBatchTXs(...*BatchTransaction) error
+
+		// ProcessHTLCs processes operations on hash-time-locked payments, i.e.,
+		// locks, claims and refunds, like ProcessTXs processes transactions.
+		ProcessHTLCs(...*HTLCOp) error
+
 		// DepositProofs returns the deposit proofs of all deposits made in an epoch
 		// at the end of the deposit phase.
 		//
@@ -73,11 +82,23 @@ type (
 		// It should be called in a loop by the operator.
 		BalanceProofs() ([]*BalanceProof, error)
 
+		// ExitProof returns a balance proof over the value of the given exit
+		// request, which lets the user exit only a part of their balance. The
+		// request's epoch must be the current exit epoch and its value must not
+		// exceed the user's balance of that epoch.
+		ExitProof(*ExitRequest) (*BalanceProof, error)
+
+		// Account returns the current state of the given account in the
+		// running transaction epoch. Unknown accounts have a zero balance and
+		// nonce.
+		Account(common.Address) (*Account, error)
+
 		// Shutdown signals the Enclave to gracefully shutdown after the next phase
 		// is sealed. It will continue receiving transactions and blocks until the
 		// last block of the current phase is received via ProcessBlocks.
 		//
-		// The functions ProcessBlocks, ProcessTXs, DepositProofs and BalanceProofs
+		// The functions ProcessBlocks, ProcessTXs, ProcessBatchTXs,
+		// ProcessHTLCs, DepositProofs, BalanceProofs, ExitProof and Account
 		// will return an ErrEnclaveStopped error after the Enclave shut down.
 		// The operator should test for this error in their loops around those
 		// functions.
@@ -89,9 +110,21 @@ type (
 
 	// A Block is a go-ethereum block together with its receipts. go-ethereum's
 	// types.Block type doesn't store the receipts...
+	//
+	// If Receipts contains a receipt for every transaction, it is verified
+	// against the header's receipt root directly. Otherwise, the block is in
+	// compact mode and ReceiptProofs must contain an inclusion proof for each
+	// receipt, see NewCompactBlock.
 	Block struct {
 		types.Block
-		Receipts types.Receipts
+		Receipts      types.Receipts
+		ReceiptProofs []ReceiptProof
+	}
+
+	// A ReceiptProof proves the inclusion of a receipt in a block's receipt trie.
+	ReceiptProof struct {
+		Index uint64   // Transaction index of the receipt.
+		Nodes [][]byte // Merkle-Patricia trie nodes on the path to the receipt.
 	}
 
 	// A DepositProof is generated by the Enclave at the end of each deposit phase
@@ -110,16 +143,28 @@ type (
 		Sig     Sig     `json:"sig"`
 	}
 
-	// A Balance states the balance of the user with address Account in the system
-	// at epoch Epoch.
+	// A Balance states the balance of token Token of the user with address
+	// Account in the system at epoch Epoch.
 	//
-	// Its Solidity struct type is (uint64, address, uint256).
+	// Its Solidity struct type is (uint64, address, uint256) for ETH balances
+	// and (uint64, address, address, uint256) for token balances.
 	Balance struct {
 		Epoch   Epoch          `json:"epoch"`   // sol: uint64
 		Account common.Address `json:"account"` // sol: address
+		Token   common.Address `json:"token"`   // sol: address, ETHToken for ETH
 		Value   *Amount        `json:"value"`   // sol: uint256
 	}
 
+	// An Account is the current off-chain state of a user's account in the
+	// Enclave. It is not signed and thus only informative, only balance proofs
+	// can be used on-chain.
+	Account struct {
+		Epoch  Epoch                      `json:"epoch"`  // Current transaction epoch.
+		Nonce  uint64                     `json:"nonce"`  // Nonce of the last transaction.
+		Value  *Amount                    `json:"value"`  // ETH balance.
+		Tokens map[common.Address]*Amount `json:"tokens"` // Non-zero token balances.
+	}
+
 	// Sig represents an ethereum signature. It is always 65 bytes long.
 	Sig []byte
 	// Amount is a big.Int wrapper to allow for json en-decoding.
@@ -128,6 +173,23 @@ type (
 
 var ErrEnclaveStopped = errors.New("Enclave stopped")
 
+// ErrSealedEventsDropped is returned by Enclave.ProcessBlocks if a chain
+// reorganization dropped events of a phase whose proofs are already published.
+// The enclave does not process blocks after that phase until the chain
+// reorganizes back to a branch containing the events. As the enclave only
+// receives blocks after the PoW depth, the operator should treat this as
+// terminal.
+var ErrSealedEventsDropped = errors.New("reorg dropped events of a sealed phase")
+
+// ETHToken is the token address of ether. All other token addresses refer to
+// ERC-20 token contracts.
+var ETHToken = common.Address{}
+
+// IsETH returns whether the balance is an ETH balance.
+func (b *Balance) IsETH() bool {
+	return b.Token == ETHToken
+}
+
 func (s Sig) MarshalJSON() ([]byte, error) {
 	if len(s) != 65 {
 		panic("Invalid signature lenght")
@@ -173,6 +235,7 @@ func (b *Balance) Clone() Balance {
 	return Balance{
 		Epoch:   b.Epoch,
 		Account: b.Account,
+		Token:   b.Token,
 		Value:   (*Amount)(new(big.Int).Set((*big.Int)(b.Value))),
 	}
 }
@@ -182,9 +245,13 @@ func (b Block) GobEncode() ([]byte, error) {
 	if err := b.EncodeRLP(w); err != nil {
 		return nil, fmt.Errorf("encoding block: %w", err)
 	}
-	if err := gob.NewEncoder(w).Encode(&b.Receipts); err != nil {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(&b.Receipts); err != nil {
 		return nil, fmt.Errorf("encoding receipts: %w", err)
 	}
+	if err := enc.Encode(&b.ReceiptProofs); err != nil {
+		return nil, fmt.Errorf("encoding receipt proofs: %w", err)
+	}
 	return w.Bytes(), nil
 }
 
@@ -193,9 +260,13 @@ func (b *Block) GobDecode(blob []byte) error {
 	if err := b.DecodeRLP(rlp.NewStream(r, 0)); err != nil {
 		return fmt.Errorf("decoding block: %w", err)
 	}
-	if err := gob.NewDecoder(r).Decode(&b.Receipts); err != nil {
+	dec := gob.NewDecoder(r)
+	if err := dec.Decode(&b.Receipts); err != nil {
 		return fmt.Errorf("decoding receipts: %w", err)
 	}
+	if err := dec.Decode(&b.ReceiptProofs); err != nil {
+		return fmt.Errorf("decoding receipt proofs: %w", err)
+	}
 	return nil
 }
 
@@ -206,3 +277,12 @@ func (b *Balance) ToEthBal() bindings.ErdstallBalance {
 		Value:   (*big.Int)(b.Value),
 	}
 }
+
+func (b *Balance) ToEthTokenBal() bindings.ErdstallTokenBalance {
+	return bindings.ErdstallTokenBalance{
+		Epoch:   b.Epoch,
+		Account: b.Account,
+		Token:   b.Token,
+		Value:   (*big.Int)(b.Value),
+	}
+}
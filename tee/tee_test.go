@@ -19,6 +19,13 @@ func TestBalance_Json(t *testing.T) {
 	wiretest.GenericJSONMarshallingTest(t, bal, &tee.Balance{})
 }
 
+func TestTokenBalance_Json(t *testing.T) {
+	rng := pkgtest.Prng(t)
+	bal := test.RandomTokenBalance(rng)
+
+	wiretest.GenericJSONMarshallingTest(t, bal, &tee.Balance{})
+}
+
 func TestBalanceProof_Json(t *testing.T) {
 	rng := pkgtest.Prng(t)
 	proof := test.RandomBP(rng)
@@ -206,7 +206,7 @@ func GenericEnclaveTest(t *testing.T, enc tee.Enclave) {
 func checkBals(aliceInit, aliceNew, bobInit, bobNew, difference *big.Int) error {
 	aliceGain := new(big.Int).Sub(aliceNew, aliceInit)
 	bobGain := new(big.Int).Sub(bobNew, bobInit)
-	delta := big.NewInt(1000000)
+	delta := eth.EthToWeiFloat(0.001) // Gas costs, which depend on the base fee.
 	if err := checkInRange(difference, aliceGain, delta); err != nil {
 		return fmt.Errorf("checking alice bals: %w", err)
 	}
@@ -62,3 +62,10 @@ func RandomBalance(rng *rand.Rand) tee.Balance {
 		Value:   (*tee.Amount)(big.NewInt(rng.Int63())),
 	}
 }
+
+// RandomTokenBalance returns a random balance of a random token.
+func RandomTokenBalance(rng *rand.Rand) tee.Balance {
+	bal := RandomBalance(rng)
+	bal.Token = common.Address(wtest.NewRandomAddress(rng))
+	return bal
+}
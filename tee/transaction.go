@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -17,17 +18,22 @@ type Transactor interface {
 	Send(*Transaction) error
 }
 
-// Transaction is a payment transaction from Sender to Recipient, signed by
-// the Sender. The epoch must match the current transaction epoch.
+// Transaction is a payment transaction of Token from Sender to Recipient,
+// signed by the Sender. The epoch must match the current transaction epoch.
 //
 // Nonce tracking allows to send multiple transactions per epoch, each only
 // stating the amount of the individual transaction.
+//
+// The Sender additionally pays the Fee in ETH to the operator's fee collector.
+// It must cover the fee required by the Parameters, see Parameters.TxFee.
 type Transaction struct {
 	Nonce     uint64         `json:"nonce"` // starts at 0, each tx must increase by one, across epochs
 	Epoch     Epoch          `json:"epoch"`
 	Sender    common.Address `json:"sender"`
 	Recipient common.Address `json:"recipient"`
+	Token     common.Address `json:"token"` // ETHToken for ETH transfers
 	Amount    *Amount        `json:"amount"`
+	Fee       *Amount        `json:"fee"` // in ETH, nil for no fee
 	Sig       Sig            `json:"sig"`
 	hash      common.Hash
 }
@@ -42,11 +48,20 @@ func (t *Transaction) Hash() common.Hash {
 	binary.LittleEndian.PutUint64(bs[7:], t.Epoch)
 	bs = append(bs, t.Sender.Bytes()...)
 	bs = append(bs, t.Recipient.Bytes()...)
+	bs = append(bs, t.Token.Bytes()...)
 	bs = append(bs, t.Sig...)
 
 	return crypto.Keccak256Hash(bs)
 }
 
+// FeeValue returns the transaction's fee, zero if it has no fee.
+func (t *Transaction) FeeValue() *big.Int {
+	if t.Fee == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(t.Fee)
+}
+
 // A TextSigner can sign messages. It's usually a wallet like an accounts.Wallet
 type TextSigner interface {
 	// SignText returns a signature with 'v' value of 0 or 1.
@@ -35,13 +35,70 @@ type (
 		Tx tee.Transaction `json:"tx"`
 	}
 
-	// Subscribe sets up a client subscription.
+	// SendBatchTx sends one BatchTransaction to the remote operator.
+	SendBatchTx struct {
+		Call
+		Tx tee.BatchTransaction `json:"tx"`
+	}
+
+	// LockHTLC locks a hash-time-locked payment.
+	LockHTLC struct {
+		Call
+		HTLC tee.HTLC `json:"htlc"`
+	}
+
+	// ClaimHTLC claims a hash-time-locked payment.
+	ClaimHTLC struct {
+		Call
+		Claim tee.HTLCClaim `json:"claim"`
+	}
+
+	// RefundHTLC refunds an expired hash-time-locked payment.
+	RefundHTLC struct {
+		Call
+		Refund tee.HTLCRefund `json:"refund"`
+	}
+
+	// GetChallenge requests a challenge that must be signed to subscribe.
+	GetChallenge struct {
+		Call
+	}
+
+	// Subscribe sets up a client subscription. The request must be signed by
+	// the subscribing account over the challenge of the last GetChallenge
+	// call.
 	Subscribe struct {
 		Call
-		// The Address this subscription is for.
+		Req tee.SubscribeRequest `json:"req"`
+	}
+
+	// RequestExit requests an exit proof for a partial exit.
+	RequestExit struct {
+		Call
+		Req tee.ExitRequest `json:"req"`
+	}
+
+	// GetAccount queries the current off-chain balance or nonce of an
+	// account, depending on the Method.
+	GetAccount struct {
+		Call
 		Who common.Address `json:"who"`
 	}
 
+	// GetProof queries the deposit or balance proof of an account's token in
+	// an epoch, depending on the Method.
+	GetProof struct {
+		Call
+		Who   common.Address `json:"who"`
+		Token common.Address `json:"token"` // tee.ETHToken for ETH.
+		Epoch tee.Epoch      `json:"epoch"`
+	}
+
+	// GetParams queries the enclave parameters.
+	GetParams struct {
+		Call
+	}
+
 	// DepositProof contains one DepositProof from the according subscription.
 	DepositProof struct {
 		Result
@@ -54,6 +111,38 @@ type (
 		Proof tee.BalanceProof `json:"proof"`
 	}
 
+	// ExitProof contains the exit proof that answers a RequestExit call.
+	ExitProof struct {
+		Result
+		Proof tee.BalanceProof `json:"proof"`
+	}
+
+	// Balance answers a getBalance call.
+	Balance struct {
+		Result
+		Account tee.Account `json:"account"`
+	}
+
+	// Nonce answers a getNonce call with the account's next transaction
+	// nonce.
+	Nonce struct {
+		Result
+		Nonce uint64 `json:"nonce"`
+	}
+
+	// Challenge answers a getChallenge call. The challenge is valid for one
+	// subscribe call on the same connection.
+	Challenge struct {
+		Result
+		Challenge common.Hash `json:"challenge"`
+	}
+
+	// Params answers a getParams call.
+	Params struct {
+		Result
+		Params tee.Parameters `json:"params"`
+	}
+
 	// TXReceipt notifies a peer that he received a new transaction.
 	TXReceipt struct {
 		Result
@@ -74,8 +163,21 @@ type (
 )
 
 const (
-	MethodSendTx    Method = "sendTx"
-	MethodSubscribe Method = "subscribe"
+	MethodSendTx      Method = "sendTx"
+	MethodSendBatchTx Method = "sendBatchTx"
+	MethodLockHTLC    Method = "lockHTLC"
+	MethodClaimHTLC   Method = "claimHTLC"
+	MethodRefundHTLC  Method = "refundHTLC"
+	MethodSubscribe   Method = "subscribe"
+	MethodRequestExit Method = "requestExit"
+
+	MethodGetChallenge Method = "getChallenge"
+
+	MethodGetBalance      Method = "getBalance"
+	MethodGetNonce        Method = "getNonce"
+	MethodGetBalanceProof Method = "getBalanceProof"
+	MethodGetDepositProof Method = "getDepositProof"
+	MethodGetParams       Method = "getParams"
 
 	BalanceProofs Topic = "balanceProofs"
 	DepositProofs Topic = "depositProofs"
@@ -94,13 +196,138 @@ func NewSendTx(id ID, tx tee.Transaction) *SendTx {
 	}
 }
 
+// NewSendBatchTx returns a `SendBatchTx` object.
+func NewSendBatchTx(id ID, tx tee.BatchTransaction) *SendBatchTx {
+	return &SendBatchTx{
+		Call: Call{
+			ID:     id,
+			Method: MethodSendBatchTx,
+		},
+		Tx: tx,
+	}
+}
+
+// NewLockHTLC returns a `LockHTLC` object.
+func NewLockHTLC(id ID, htlc tee.HTLC) *LockHTLC {
+	return &LockHTLC{
+		Call: Call{
+			ID:     id,
+			Method: MethodLockHTLC,
+		},
+		HTLC: htlc,
+	}
+}
+
+// NewClaimHTLC returns a `ClaimHTLC` object.
+func NewClaimHTLC(id ID, claim tee.HTLCClaim) *ClaimHTLC {
+	return &ClaimHTLC{
+		Call: Call{
+			ID:     id,
+			Method: MethodClaimHTLC,
+		},
+		Claim: claim,
+	}
+}
+
+// NewRefundHTLC returns a `RefundHTLC` object.
+func NewRefundHTLC(id ID, refund tee.HTLCRefund) *RefundHTLC {
+	return &RefundHTLC{
+		Call: Call{
+			ID:     id,
+			Method: MethodRefundHTLC,
+		},
+		Refund: refund,
+	}
+}
+
+// NewGetChallenge returns a `GetChallenge` object.
+func NewGetChallenge(id ID) *GetChallenge {
+	return &GetChallenge{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetChallenge,
+		},
+	}
+}
+
 // NewSubscribe returns a `Subscribe` object.
-func NewSubscribe(id ID, who common.Address) *Subscribe {
+func NewSubscribe(id ID, req tee.SubscribeRequest) *Subscribe {
 	return &Subscribe{
 		Call: Call{
 			ID:     id,
 			Method: MethodSubscribe,
 		},
+		Req: req,
+	}
+}
+
+// NewRequestExit returns a `RequestExit` object.
+func NewRequestExit(id ID, req tee.ExitRequest) *RequestExit {
+	return &RequestExit{
+		Call: Call{
+			ID:     id,
+			Method: MethodRequestExit,
+		},
+		Req: req,
+	}
+}
+
+// NewGetBalance returns a `GetAccount` object that queries the balance.
+func NewGetBalance(id ID, who common.Address) *GetAccount {
+	return &GetAccount{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetBalance,
+		},
+		Who: who,
+	}
+}
+
+// NewGetNonce returns a `GetAccount` object that queries the next nonce.
+func NewGetNonce(id ID, who common.Address) *GetAccount {
+	return &GetAccount{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetNonce,
+		},
 		Who: who,
 	}
 }
+
+// NewGetBalanceProof returns a `GetProof` object that queries a balance
+// proof.
+func NewGetBalanceProof(id ID, who, token common.Address, epoch tee.Epoch) *GetProof {
+	return &GetProof{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetBalanceProof,
+		},
+		Who:   who,
+		Token: token,
+		Epoch: epoch,
+	}
+}
+
+// NewGetDepositProof returns a `GetProof` object that queries a deposit
+// proof.
+func NewGetDepositProof(id ID, who, token common.Address, epoch tee.Epoch) *GetProof {
+	return &GetProof{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetDepositProof,
+		},
+		Who:   who,
+		Token: token,
+		Epoch: epoch,
+	}
+}
+
+// NewGetParams returns a `GetParams` object.
+func NewGetParams(id ID) *GetParams {
+	return &GetParams{
+		Call: Call{
+			ID:     id,
+			Method: MethodGetParams,
+		},
+	}
+}
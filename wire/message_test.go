@@ -3,8 +3,10 @@
 package wire_test
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	pkgtest "perun.network/go-perun/pkg/test"
 
 	"github.com/perun-network/erdstall/eth"
@@ -26,12 +28,119 @@ func TestJson_Marshalling(t *testing.T) {
 		test.GenericJSONMarshallingTest(t, *obj, &wire.SendTx{})
 	})
 
+	t.Run("SendBatchTx", func(t *testing.T) {
+		tx := tee.BatchTransaction{
+			Payments: []tee.Payment{{
+				Recipient: eth.NewRandomAddress(rng),
+				Amount:    (*tee.Amount)(big.NewInt(rng.Int63())),
+			}},
+			Sig: ttest.RandomSig(rng),
+		}
+		obj := wire.NewSendBatchTx(id, tx)
+		test.GenericJSONMarshallingTest(t, *obj, &wire.SendBatchTx{})
+	})
+
+	t.Run("LockHTLC", func(t *testing.T) {
+		htlc := tee.HTLC{
+			Recipient: eth.NewRandomAddress(rng),
+			Amount:    (*tee.Amount)(big.NewInt(rng.Int63())),
+			HashLock:  tee.HashLock(common.Hash{1}),
+			Expiry:    uint64(rng.Int63()),
+			Sig:       ttest.RandomSig(rng),
+		}
+		obj := wire.NewLockHTLC(id, htlc)
+		test.GenericJSONMarshallingTest(t, *obj, &wire.LockHTLC{})
+	})
+
+	t.Run("ClaimHTLC", func(t *testing.T) {
+		obj := wire.NewClaimHTLC(id, tee.HTLCClaim{ID: common.Hash{2}, Preimage: common.Hash{1}})
+		test.GenericJSONMarshallingTest(t, *obj, &wire.ClaimHTLC{})
+	})
+
+	t.Run("RefundHTLC", func(t *testing.T) {
+		obj := wire.NewRefundHTLC(id, tee.HTLCRefund{ID: common.Hash{2}})
+		test.GenericJSONMarshallingTest(t, *obj, &wire.RefundHTLC{})
+	})
+
+	t.Run("GetChallenge", func(t *testing.T) {
+		obj := wire.NewGetChallenge(id)
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetChallenge{})
+		res := wire.Challenge{Result: wire.Result{ID: id}, Challenge: common.Hash{3}}
+		test.GenericJSONMarshallingTest(t, res, &wire.Challenge{})
+	})
+
 	t.Run("Subscribe", func(t *testing.T) {
-		addr := eth.NewRandomAddress(rng)
-		obj := wire.NewSubscribe(id, addr)
+		req := tee.SubscribeRequest{
+			Who:       eth.NewRandomAddress(rng),
+			Challenge: common.Hash{4},
+			Sig:       ttest.RandomSig(rng),
+		}
+		obj := wire.NewSubscribe(id, req)
 		test.GenericJSONMarshallingTest(t, *obj, &wire.Subscribe{})
 	})
 
+	t.Run("RequestExit", func(t *testing.T) {
+		req := tee.ExitRequest{
+			Account: eth.NewRandomAddress(rng),
+			Value:   (*tee.Amount)(big.NewInt(rng.Int63())),
+			Sig:     ttest.RandomSig(rng),
+		}
+		obj := wire.NewRequestExit(id, req)
+		test.GenericJSONMarshallingTest(t, *obj, &wire.RequestExit{})
+	})
+
+	t.Run("GetBalance", func(t *testing.T) {
+		obj := wire.NewGetBalance(id, eth.NewRandomAddress(rng))
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetAccount{})
+	})
+
+	t.Run("GetNonce", func(t *testing.T) {
+		obj := wire.NewGetNonce(id, eth.NewRandomAddress(rng))
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetAccount{})
+	})
+
+	t.Run("GetBalanceProof", func(t *testing.T) {
+		obj := wire.NewGetBalanceProof(id, eth.NewRandomAddress(rng), eth.NewRandomAddress(rng), rng.Uint64())
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetProof{})
+	})
+
+	t.Run("GetDepositProof", func(t *testing.T) {
+		obj := wire.NewGetDepositProof(id, eth.NewRandomAddress(rng), tee.ETHToken, rng.Uint64())
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetProof{})
+	})
+
+	t.Run("GetParams", func(t *testing.T) {
+		obj := wire.NewGetParams(id)
+		test.GenericJSONMarshallingTest(t, *obj, &wire.GetParams{})
+	})
+
+	t.Run("Balance", func(t *testing.T) {
+		obj := wire.Balance{
+			Result: wire.Result{ID: id},
+			Account: tee.Account{
+				Epoch:  rng.Uint64(),
+				Nonce:  rng.Uint64(),
+				Value:  (*tee.Amount)(big.NewInt(rng.Int63())),
+				Tokens: map[common.Address]*tee.Amount{eth.NewRandomAddress(rng): (*tee.Amount)(big.NewInt(rng.Int63()))},
+			},
+		}
+		test.GenericJSONMarshallingTest(t, obj, &wire.Balance{})
+	})
+
+	t.Run("Params", func(t *testing.T) {
+		obj := wire.Params{
+			Result: wire.Result{ID: id},
+			Params: tee.Parameters{
+				PhaseDuration: rng.Uint64(),
+				TEE:           eth.NewRandomAddress(rng),
+				Contract:      eth.NewRandomAddress(rng),
+				Consensus:     tee.CliqueConsensus,
+				FeeRate:       rng.Uint64(),
+			},
+		}
+		test.GenericJSONMarshallingTest(t, obj, &wire.Params{})
+	})
+
 	t.Run("CallResult", func(t *testing.T) {
 		obj := wire.Result{
 			ID:    id,